@@ -0,0 +1,148 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Version is a version of ArangoDB that is not necessarily a
+// driver.Version, e.g. a version of a semver library.
+// Use CheckVersions to check an upgrade between two of them.
+type Version interface {
+	// Major returns the major version, e.g. 3 for "3.12.1".
+	Major() int
+	// Minor returns the minor version, e.g. 12 for "3.12.1".
+	Minor() int
+	// Patch returns the patch version, e.g. 1 for "3.12.1".
+	// It returns 0 when there is no numeric patch version.
+	Patch() int
+	// PreRelease returns the pre-release part of the version,
+	// e.g. "rc.2" for "3.12.1-rc.2", or an empty string for releases.
+	PreRelease() string
+	// Compare returns -1, 0 or 1 when the version is older than,
+	// equal to or newer than the other version.
+	Compare(other Version) int
+}
+
+// DriverVersion returns the given driver.Version as a Version.
+func DriverVersion(v VersionString) Version {
+	return driverVersion{ParseVersion(v)}
+}
+
+// driverVersion implements Version for a driver.Version.
+type driverVersion struct {
+	p ParsedVersion
+}
+
+// Major returns the major version.
+func (v driverVersion) Major() int {
+	return v.p.major
+}
+
+// Minor returns the minor version.
+func (v driverVersion) Minor() int {
+	return v.p.minor
+}
+
+// Patch returns the patch version, or 0 when there is none.
+func (v driverVersion) Patch() int {
+	patch, _ := v.p.Patch()
+	return patch
+}
+
+// PreRelease returns whatever follows the patch version, without
+// the separator, e.g. "rc.2" for "3.12.1-rc.2" and "rc7" for "3.2.rc7".
+func (v driverVersion) PreRelease() string {
+	sub := v.p.sub
+	for len(sub) > 0 && sub[0] >= '0' && sub[0] <= '9' {
+		sub = sub[1:]
+	}
+	return strings.TrimLeft(sub, ".-+")
+}
+
+// Compare compares the version with the other version.
+func (v driverVersion) Compare(other Version) int {
+	return CompareVersions(v, other)
+}
+
+// String returns the driver.Version.
+func (v driverVersion) String() string {
+	return v.p.String()
+}
+
+// CompareVersions returns -1, 0 or 1 when a is older than, equal to or
+// newer than b. Versions are compared by major, minor and patch version.
+// A pre-release is older than the release with the same patch version,
+// pre-releases are compared as strings.
+func CompareVersions(a, b Version) int {
+	if c := compareInts(a.Major(), b.Major()); c != 0 {
+		return c
+	}
+	if c := compareInts(a.Minor(), b.Minor()); c != 0 {
+		return c
+	}
+	if c := compareInts(a.Patch(), b.Patch()); c != 0 {
+		return c
+	}
+	pa, pb := a.PreRelease(), b.PreRelease()
+	switch {
+	case pa == pb:
+		return 0
+	case pa == "":
+		return 1
+	case pb == "":
+		return -1
+	}
+	return strings.Compare(pa, pb)
+}
+
+// compareInts returns -1, 0 or 1 when a is less than, equal to or
+// greater than b.
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// ParseVersionOf returns the ParsedVersion of the given Version.
+// Versions returned by DriverVersion are returned unchanged, other
+// versions are formatted as "major.minor.patch[-prerelease]".
+func ParseVersionOf(v Version) ParsedVersion {
+	if dv, ok := v.(driverVersion); ok {
+		return dv.p
+	}
+	s := strconv.Itoa(v.Major()) + "." + strconv.Itoa(v.Minor()) + "." + strconv.Itoa(v.Patch())
+	if pre := v.PreRelease(); pre != "" {
+		s += "-" + pre
+	}
+	return ParseVersion(VersionString(s))
+}
+
+// CheckVersions is Check for versions that are not driver.Versions.
+func CheckVersions(from, to Version, opts ...Option) Decision {
+	return check(ParseVersionOf(from), ParseVersionOf(to), defaultOptions(), opts)
+}
@@ -0,0 +1,100 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+// testVersion is a Version that is not backed by a VersionString (driver.Version).
+type testVersion struct {
+	major, minor, patch int
+	pre                 string
+}
+
+func (v testVersion) Major() int                { return v.major }
+func (v testVersion) Minor() int                { return v.minor }
+func (v testVersion) Patch() int                { return v.patch }
+func (v testVersion) PreRelease() string        { return v.pre }
+func (v testVersion) Compare(other Version) int { return CompareVersions(v, other) }
+
+func TestDriverVersion(t *testing.T) {
+	tests := []struct {
+		Version    VersionString
+		Major      int
+		Minor      int
+		Patch      int
+		PreRelease string
+	}{
+		{"3.12.1", 3, 12, 1, ""},
+		{"3.12.1-rc.2", 3, 12, 1, "rc.2"},
+		{"3.2.rc7", 3, 2, 0, "rc7"},
+		{"4.0.0-devel", 4, 0, 0, "devel"},
+		{"3.4", 3, 4, 0, ""},
+	}
+	for _, test := range tests {
+		v := DriverVersion(test.Version)
+		if v.Major() != test.Major || v.Minor() != test.Minor || v.Patch() != test.Patch || v.PreRelease() != test.PreRelease {
+			t.Errorf("%s: expected %d.%d.%d %q, got %d.%d.%d %q", test.Version, test.Major, test.Minor, test.Patch, test.PreRelease,
+				v.Major(), v.Minor(), v.Patch(), v.PreRelease())
+		}
+		if p := ParseVersionOf(v); p.Version() != test.Version {
+			t.Errorf("%s: expected ParseVersionOf to return the VersionString, got %s", test.Version, p)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	ordered := []VersionString{"3.2.rc7", "3.2.1", "3.11.8", "3.12.0-rc.1", "3.12.0-rc.2", "3.12.0", "3.12.1", "4.0.0"}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			expected := compareInts(i, j)
+			if c := DriverVersion(a).Compare(DriverVersion(b)); c != expected {
+				t.Errorf("Compare(%s, %s): expected %d, got %d", a, b, expected, c)
+			}
+		}
+	}
+	if c := CompareVersions(DriverVersion("3.12.1"), testVersion{3, 12, 1, ""}); c != 0 {
+		t.Errorf("Expected versions of different types to be equal, got %d", c)
+	}
+}
+
+func TestCheckVersions(t *testing.T) {
+	tests := []struct {
+		From, To Version
+		Expected VersionString
+		Allowed  bool
+	}{
+		{testVersion{3, 11, 8, ""}, testVersion{3, 12, 1, ""}, "3.12.1", true},
+		{testVersion{3, 10, 8, ""}, testVersion{3, 12, 1, ""}, "3.12.1", false},
+		{testVersion{3, 11, 8, ""}, testVersion{3, 12, 0, "rc.1"}, "3.12.0-rc.1", false},
+		{DriverVersion("3.11.8"), DriverVersion("3.12"), "3.12", true},
+	}
+	for _, test := range tests {
+		d := CheckVersions(test.From, test.To)
+		if d.To != test.Expected {
+			t.Errorf("Expected version %s, got %s", test.Expected, d.To)
+		}
+		if d.Allowed() != test.Allowed {
+			t.Errorf("%s -> %s: expected allowed=%t, got %s", d.From, d.To, test.Allowed, d.Err)
+		}
+	}
+}
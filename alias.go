@@ -0,0 +1,94 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// AliasLatest resolves to the latest generally available release
+	AliasLatest = "latest"
+	// AliasLatestLTS resolves to the latest generally available release of
+	// a long-term support minor version, see WithLTS
+	AliasLatestLTS = "latest-lts"
+	// AliasLTS is a synonym of AliasLatestLTS
+	AliasLTS = "lts"
+)
+
+// WithLTS marks the given minor versions (e.g. "3.11") as long-term
+// support releases, for resolving AliasLatestLTS.
+func WithLTS(minors ...VersionString) Option {
+	return func(o *options) {
+		o.lts = append(append([]VersionString(nil), o.lts...), minors...)
+	}
+}
+
+// ResolveAlias maps a symbolic target onto a concrete version of the
+// releases known from WithReleases. The spec is one of
+//
+//   - "latest": the latest release
+//   - "latest-lts" or "lts": the latest release of an LTS minor version
+//   - a minor version such as "3.11": its latest patch release
+//   - a complete version such as "3.11.8", which is returned unchanged
+//
+// Pre-releases are never the result of an alias.
+func ResolveAlias(spec string, opts ...Option) (VersionString, error) {
+	spec = strings.TrimSpace(spec)
+	p := ParseVersion(VersionString(spec))
+	if _, ok := p.Patch(); ok {
+		return p.version, nil
+	}
+	o := newOptions(opts)
+	var match func(r ParsedVersion) bool
+	switch spec {
+	case AliasLatest:
+		match = func(ParsedVersion) bool { return true }
+	case AliasLatestLTS, AliasLTS:
+		if len(o.lts) == 0 {
+			return "", fmt.Errorf("No long-term support versions are known, see WithLTS")
+		}
+		match = func(r ParsedVersion) bool { return matchesAnyVersion(o.lts, r.version) }
+	default:
+		if spec == "" || p.sub != "" || !strings.Contains(spec, ".") || VersionString(fmt.Sprintf("%d.%d", p.major, p.minor)) != p.version {
+			return "", fmt.Errorf("Unknown version alias '%s'", spec)
+		}
+		match = func(r ParsedVersion) bool { return r.major == p.major && r.minor == p.minor }
+	}
+	if len(o.releases) == 0 {
+		return "", fmt.Errorf("No releases are known to resolve '%s', see WithReleases", spec)
+	}
+	var latest ParsedVersion
+	found := false
+	for _, r := range o.releases {
+		if r.IsPreRelease() || !match(r) {
+			continue
+		}
+		if !found || versionLess(latest, r) {
+			latest, found = r, true
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("No release matches '%s'", spec)
+	}
+	return latest.version, nil
+}
@@ -0,0 +1,69 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestResolveAlias(t *testing.T) {
+	opts := []Option{
+		WithReleases("3.10.13", "3.11.7", "3.11.8", "3.11.10", "3.12.0", "3.12.1", "3.12.2-rc.1", "3.13.0-rc.1"),
+		WithLTS("3.11"),
+	}
+	tests := []struct {
+		Spec     string
+		Expected VersionString
+	}{
+		{"latest", "3.12.1"},
+		{"latest-lts", "3.11.10"},
+		{"lts", "3.11.10"},
+		{"3.11", "3.11.10"},
+		{" 3.10 ", "3.10.13"},
+		{"3.11.8", "3.11.8"},
+		{"3.9.5", "3.9.5"},
+	}
+	for _, test := range tests {
+		v, err := ResolveAlias(test.Spec, opts...)
+		if err != nil {
+			t.Errorf("%q: unexpected error %s", test.Spec, err)
+		} else if v != test.Expected {
+			t.Errorf("%q: expected %s, got %s", test.Spec, test.Expected, v)
+		}
+	}
+	for spec, expected := range map[string]string{
+		"3.13":    "No release matches '3.13'",
+		"3.x":     "Unknown version alias '3.x'",
+		"3":       "Unknown version alias '3'",
+		"":        "Unknown version alias ''",
+		"nightly": "Unknown version alias 'nightly'",
+	} {
+		if _, err := ResolveAlias(spec, opts...); err == nil || err.Error() != expected {
+			t.Errorf("%q: expected error %q, got %v", spec, expected, err)
+		}
+	}
+	if _, err := ResolveAlias("latest"); err == nil {
+		t.Error("Expected an error without releases")
+	}
+	if _, err := ResolveAlias("lts", WithReleases("3.12.1")); err == nil {
+		t.Error("Expected an error without LTS versions")
+	}
+}
@@ -0,0 +1,69 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+)
+
+// RuleAllowList denies upgrades that are not allow-listed in the
+// deny-by-default mode, see WithDenyByDefault.
+const RuleAllowList RuleID = "allow-list"
+
+// Transition is an upgrade between two exact versions.
+type Transition struct {
+	From VersionString `json:"from"`
+	To   VersionString `json:"to"`
+}
+
+// String returns the transition as "from->to".
+func (t Transition) String() string {
+	return string(t.From) + "->" + string(t.To)
+}
+
+// ruleAllowList implements RuleAllowList.
+// It only applies in the deny-by-default mode.
+var ruleAllowList = Rule{
+	ID:      RuleAllowList,
+	Applies: func(in RuleInput) bool { return in.DenyByDefault && in.changesVersion() },
+	Check:   func(in RuleInput) error { return checkAllowList(in.AllowList, in.From.version, in.To.version) },
+}
+
+// checkAllowList implements RuleAllowList.
+func checkAllowList(allowed []Transition, from, to VersionString) error {
+	for _, t := range allowed {
+		if t.From == from && t.To == to {
+			return nil
+		}
+	}
+	return newCausedError(&AllowListError{Transition: Transition{From: from, To: to}}, RuleAllowList, MessageTransitionNotAllowListed)
+}
+
+// AllowListError details a denial by RuleAllowList.
+type AllowListError struct {
+	// Transition is the upgrade that is not allow-listed
+	Transition Transition
+}
+
+// Error describes the upgrade that is not allow-listed.
+func (e *AllowListError) Error() string {
+	return fmt.Sprintf("Upgrade %s is not in the list of allowed upgrades", e.Transition)
+}
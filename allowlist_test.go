@@ -0,0 +1,75 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestWithDenyByDefault(t *testing.T) {
+	opt := WithDenyByDefault(Transition{From: "3.11.8", To: "3.12.1"}, Transition{From: "3.10.8", To: "3.12.1"})
+	tests := []struct {
+		From, To string
+		Rule     RuleID
+	}{
+		{"3.11.8", "3.12.1", ""},
+		{"3.11.8", "3.12.2", RuleAllowList},
+		{"3.11.7", "3.12.1", RuleAllowList},
+		{"3.11.8", "3.11.8", ""},
+		// Allow-listed upgrades must still pass the rules
+		{"3.10.8", "3.12.1", RuleMinorIncrement},
+	}
+	for _, test := range tests {
+		d := Check(VersionString(test.From), VersionString(test.To), opt)
+		if d.Rule != test.Rule {
+			t.Errorf("%s -> %s: expected rule %q, got %q", test.From, test.To, test.Rule, d.Rule)
+		}
+	}
+	if d := Check("3.11.8", "3.12.1", WithDenyByDefault()); d.Rule != RuleAllowList {
+		t.Errorf("Expected empty allow list to deny, got %q", d.Rule)
+	}
+}
+
+func TestDenyByDefaultIgnoresOverrides(t *testing.T) {
+	d := Check("3.11.8", "3.12.1", WithDenyByDefault(), WithOverrides(Override{From: "3.11", To: "3.12"}))
+	if d.Rule != RuleAllowList {
+		t.Errorf("Expected %s, got %q", RuleAllowList, d.Rule)
+	}
+	if e, ok := d.Err.(*Error); !ok || e.MessageID != MessageTransitionNotAllowListed {
+		t.Errorf("Unexpected error %#v", d.Err)
+	}
+}
+
+func TestPolicyDenyByDefault(t *testing.T) {
+	p := Policy{DenyByDefault: true, AllowList: []Transition{{From: "3.11.8", To: "3.12.1"}}}
+	if d := p.Check("3.11.8", "3.12.1"); !d.Allowed() {
+		t.Errorf("Expected allow-listed upgrade to be allowed, got %s", d.Err)
+	}
+	if d := p.Check("3.11.8", "3.12.2"); d.Rule != RuleAllowList {
+		t.Errorf("Expected %s, got %q", RuleAllowList, d.Rule)
+	}
+}
+
+func TestTransitionString(t *testing.T) {
+	if s := (Transition{From: "3.11.8", To: "3.12.1"}).String(); s != "3.11.8->3.12.1" {
+		t.Errorf("Unexpected %s", s)
+	}
+}
@@ -0,0 +1,91 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package audit contains AuditSink implementations that store upgrade
+// decisions as JSON, in a file or by sending them to a webhook.
+package audit
+
+import (
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Entry is the JSON representation of an audited decision.
+type Entry struct {
+	Time        time.Time      `json:"time"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	FromLicense string         `json:"fromLicense,omitempty"`
+	ToLicense   string         `json:"toLicense,omitempty"`
+	Soft        bool           `json:"soft,omitempty"`
+	Outcome     string         `json:"outcome"`
+	Rule        string         `json:"rule,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+	Override    *OverrideEntry `json:"override,omitempty"`
+	Requester   string         `json:"requester,omitempty"`
+}
+
+// OverrideEntry is the JSON representation of the override that
+// allowed an audited decision.
+type OverrideEntry struct {
+	From     string     `json:"from"`
+	To       string     `json:"to"`
+	Ticket   string     `json:"ticket,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Rules    []string   `json:"rules,omitempty"`
+	Approver string     `json:"approver,omitempty"`
+}
+
+// NewEntry converts a decision into an Entry.
+func NewEntry(d upgraderules.Decision) Entry {
+	e := Entry{
+		Time:      d.Time,
+		From:      string(d.From),
+		To:        string(d.To),
+		Soft:      d.Soft,
+		Outcome:   string(d.Outcome()),
+		Requester: d.Requester,
+	}
+	if d.Licensed {
+		e.FromLicense = d.FromLicense.String()
+		e.ToLicense = d.ToLicense.String()
+	}
+	if d.Err != nil {
+		e.Rule = string(d.Rule)
+		e.Reason = upgraderules.Reason(d.Err)
+	}
+	if o := d.Override; o != nil {
+		e.Override = &OverrideEntry{
+			From:     string(o.From),
+			To:       string(o.To),
+			Ticket:   o.Ticket,
+			Approver: o.Approver,
+		}
+		for _, r := range o.Rules {
+			e.Override.Rules = append(e.Override.Rules, string(r))
+		}
+		if !o.Expires.IsZero() {
+			expires := o.Expires
+			e.Override.Expires = &expires
+		}
+	}
+	return e
+}
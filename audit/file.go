@@ -0,0 +1,66 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// FileSink appends every decision as a line of JSON to a file.
+type FileSink struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+var _ upgraderules.AuditSink = &FileSink{}
+
+// NewFileSink opens (or creates) the file with given path for appending
+// decisions to it.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: f}, nil
+}
+
+// Record appends the given decision to the file.
+func (s *FileSink) Record(d upgraderules.Decision) error {
+	encoded, err := json.Marshal(NewEntry(d))
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.file.Write(append(encoded, '\n'))
+	return err
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,82 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	opts := []upgraderules.Option{
+		upgraderules.WithAuditSink(sink),
+		upgraderules.WithClock(func() time.Time { return now }),
+		upgraderules.WithRequester("alice"),
+	}
+	upgraderules.Check("3.11.8", "3.12.1", opts...)
+	upgraderules.Check("3.10.8", "3.12.1", append(opts,
+		upgraderules.WithOverrides(upgraderules.Override{From: "3.10", To: "3.12", Ticket: "OPS-1", Approver: "bob", Rules: []upgraderules.RuleID{upgraderules.RuleMinorIncrement}}))...)
+	upgraderules.Check("3.10.8", "3.12.1", append(opts,
+		upgraderules.WithLicenses(upgraderules.LicenseCommunity, upgraderules.LicenseCommunity))...)
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Invalid line '%s': %s", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Outcome != "allowed" || e.Requester != "alice" || !e.Time.Equal(now) {
+		t.Errorf("Unexpected first entry %+v", e)
+	}
+	if e := entries[1]; e.Outcome != "overridden" || e.Override == nil || e.Override.Ticket != "OPS-1" ||
+		e.Override.Approver != "bob" || len(e.Override.Rules) != 1 || e.Override.Rules[0] != "minor-increment" {
+		t.Errorf("Unexpected second entry %+v", e)
+	}
+	if e := entries[2]; e.Outcome != "denied" || e.Rule != string(upgraderules.RuleMinorIncrement) || e.FromLicense != "community" {
+		t.Errorf("Unexpected third entry %+v", e)
+	}
+}
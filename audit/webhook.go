@@ -0,0 +1,70 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// WebhookSink sends every decision as JSON to an HTTP endpoint
+// using a POST request.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+var _ upgraderules.AuditSink = &WebhookSink{}
+
+// NewWebhookSink creates a sink that sends decisions to the given URL.
+// If client is nil, a client with a 10 second timeout is used.
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookSink{
+		url:    url,
+		client: client,
+	}
+}
+
+// Record sends the given decision to the webhook.
+// Any response status outside of the 2xx range is considered a failure.
+func (s *WebhookSink) Record(d upgraderules.Decision) error {
+	encoded, err := json.Marshal(NewEntry(d))
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Audit webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestWebhookSink(t *testing.T) {
+	var received []Entry
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Entry
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, e)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, nil)
+	upgraderules.Check("3.10.8", "3.12.1", upgraderules.WithAuditSink(sink))
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(received))
+	}
+	if e := received[0]; e.From != "3.10.8" || e.Outcome != "denied" {
+		t.Errorf("Unexpected entry %+v", e)
+	}
+}
+
+func TestWebhookSinkFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, nil)
+	if err := sink.Record(upgraderules.Check("3.11.8", "3.12.1")); err == nil {
+		t.Error("Expected error for status 503")
+	}
+}
@@ -0,0 +1,121 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// CheckRequest describes a single check of a batch (see CheckMany).
+type CheckRequest struct {
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Options for this check only (e.g. WithLicenses).
+	// They are applied after the options of the batch.
+	Options []Option
+}
+
+// CheckMany checks a batch of upgrades concurrently and returns their
+// decisions in the order of the requests.
+// The number of concurrent checks is set with WithParallelism and
+// defaults to GOMAXPROCS.
+// Requests that have not been checked when the given context is done
+// get a decision with a retryable error.
+func CheckMany(ctx context.Context, requests []CheckRequest, opts ...Option) []Decision {
+	o := newOptions(opts)
+	parallelism := o.parallelism
+	if parallelism <= 0 {
+		parallelism = runtime.GOMAXPROCS(0)
+	}
+	if parallelism > len(requests) {
+		parallelism = len(requests)
+	}
+
+	results := make([]Decision, len(requests))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				req := requests[idx]
+				checkOpts := make([]Option, 0, len(opts)+len(req.Options)+1)
+				checkOpts = append(checkOpts, opts...)
+				checkOpts = append(checkOpts, WithContext(ctx))
+				checkOpts = append(checkOpts, req.Options...)
+				results[idx] = Check(req.From, req.To, checkOpts...)
+			}
+		}()
+	}
+
+	next := 0
+	func() {
+		defer close(indexes)
+		for ; next < len(requests); next++ {
+			select {
+			case indexes <- next:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	// Requests that were not handed to a worker
+	for idx := next; idx < len(requests); idx++ {
+		req := requests[idx]
+		ro := newOptions(append(append([]Option(nil), opts...), req.Options...))
+		results[idx] = Decision{
+			From:        req.From,
+			To:          req.To,
+			Licensed:    ro.licensed,
+			FromLicense: ro.fromLicense,
+			ToLicense:   ro.toLicense,
+			Err:         annotateError(retryableError{ctx.Err()}, "", req.From, req.To, &ro),
+		}
+	}
+	return results
+}
+
+// retryableError wraps an error caused by a temporary condition.
+type retryableError struct {
+	err error
+}
+
+// Error returns the message of the wrapped error.
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable returns true.
+func (e retryableError) Retryable() bool {
+	return true
+}
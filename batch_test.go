@@ -0,0 +1,92 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingRecorder struct {
+	count int64
+}
+
+func (r *countingRecorder) RecordDecision(d Decision) {
+	atomic.AddInt64(&r.count, 1)
+}
+
+func TestCheckMany(t *testing.T) {
+	var requests []CheckRequest
+	for minor := 0; minor < 20; minor++ {
+		requests = append(requests, CheckRequest{
+			From: VersionString(fmt.Sprintf("3.%d.1", minor)),
+			To:   VersionString(fmt.Sprintf("3.%d.1", minor+minor%3)),
+		})
+	}
+	requests = append(requests, CheckRequest{
+		From:    "3.11.8",
+		To:      "3.12.1",
+		Options: []Option{WithLicenses(LicenseEnterprise, LicenseCommunity)},
+	})
+
+	var recorder countingRecorder
+	results := CheckMany(context.Background(), requests, WithParallelism(4), WithDecisionRecorder(&recorder))
+	if len(results) != len(requests) {
+		t.Fatalf("Expected %d results, got %d", len(requests), len(results))
+	}
+	for i, d := range results {
+		if d.From != requests[i].From || d.To != requests[i].To {
+			t.Errorf("Result %d is for %s -> %s, expected %s -> %s", i, d.From, d.To, requests[i].From, requests[i].To)
+		}
+		expected := Check(requests[i].From, requests[i].To, requests[i].Options...)
+		if d.Rule != expected.Rule {
+			t.Errorf("Result %d: expected rule '%s', got '%s'", i, expected.Rule, d.Rule)
+		}
+	}
+	if n := atomic.LoadInt64(&recorder.count); n != int64(len(requests)) {
+		t.Errorf("Expected %d recorded decisions, got %d", len(requests), n)
+	}
+}
+
+func TestCheckManyCanceled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	results := CheckMany(ctx, []CheckRequest{{From: "3.11.8", To: "3.12.1"}, {From: "3.11.8", To: "3.12.2"}}, WithParallelism(1))
+	for i, d := range results {
+		if d.Allowed() {
+			// The request may have been handed to the worker before the
+			// cancellation was noticed.
+			continue
+		}
+		if !IsRetryable(d.Err) {
+			t.Errorf("Result %d: expected retryable error, got %v", i, d.Err)
+		}
+		if !errors.Is(d.Err, context.DeadlineExceeded) || d.Err.Error() != context.DeadlineExceeded.Error()+" (from "+string(d.From)+" to "+string(d.To)+")" {
+			t.Errorf("Result %d: expected the transition in the error, got %v", i, d.Err)
+		}
+	}
+}
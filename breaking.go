@@ -0,0 +1,67 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// BreakingChangeKind classifies a BreakingChange.
+type BreakingChangeKind string
+
+const (
+	// BreakingChangeBehavior is a change in the behavior of a feature
+	BreakingChangeBehavior BreakingChangeKind = "behavior"
+	// BreakingChangeDefault is a changed default value of an option
+	BreakingChangeDefault BreakingChangeKind = "default"
+	// BreakingChangeOptionRemoved is a removed startup option
+	BreakingChangeOptionRemoved BreakingChangeKind = "option-removed"
+	// BreakingChangeFeatureRemoved is a removed feature
+	BreakingChangeFeatureRemoved BreakingChangeKind = "feature-removed"
+)
+
+// BreakingChange is an incompatible change introduced by a minor version.
+type BreakingChange struct {
+	// Major version that introduced the change
+	Major int `json:"major"`
+	// Minor version that introduced the change
+	Minor int `json:"minor"`
+	// Kind of the change
+	Kind BreakingChangeKind `json:"kind"`
+	// Summary describes the change and what to do about it
+	Summary string `json:"summary"`
+}
+
+// breakingChanges is the embedded list of breaking changes, ordered by version.
+var breakingChanges = []BreakingChange{
+	{Major: 3, Minor: 4, Kind: BreakingChangeDefault, Summary: "The default storage engine changed from MMFiles to RocksDB, existing deployments keep their engine"},
+	{Major: 3, Minor: 7, Kind: BreakingChangeFeatureRemoved, Summary: "The MMFiles storage engine was removed, deployments using it must be migrated to RocksDB (dump & restore) before upgrading"},
+	{Major: 3, Minor: 12, Kind: BreakingChangeFeatureRemoved, Summary: "Pregel was removed, jobs using it must be ported before upgrading"},
+}
+
+// AllBreakingChanges returns a copy of the embedded list of breaking
+// changes, ordered by version.
+func AllBreakingChanges() []BreakingChange {
+	return append([]BreakingChange(nil), breakingChanges...)
+}
+
+// BreakingChanges returns the breaking changes introduced by the minor
+// versions an upgrade from `from` to `to` enters, ordered by version.
+// It returns nil for downgrades and upgrades within a minor version.
+func BreakingChanges(from, to ParsedVersion) []BreakingChange {
+	return ReleaseCatalog{BreakingChanges: breakingChanges}.BreakingChangesOf(from, to)
+}
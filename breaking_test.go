@@ -0,0 +1,68 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestBreakingChanges(t *testing.T) {
+	tests := []struct {
+		From, To VersionString
+		Minors   []int
+	}{
+		{"3.6.4", "3.7.1", []int{7}},
+		{"3.3.23", "3.12.1", []int{4, 7, 12}},
+		{"3.11.8", "3.12.1", []int{12}},
+		{"3.12.1", "3.12.4", nil},
+		{"3.12.1", "3.6.4", nil},
+		{"3.11.8", "4.0.0", []int{12}},
+	}
+	for _, test := range tests {
+		changes := BreakingChanges(ParseVersion(test.From), ParseVersion(test.To))
+		if len(changes) != len(test.Minors) {
+			t.Errorf("%s -> %s: expected %d changes, got %v", test.From, test.To, len(test.Minors), changes)
+			continue
+		}
+		for i, c := range changes {
+			if c.Major != 3 || c.Minor != test.Minors[i] {
+				t.Errorf("%s -> %s: expected change of 3.%d, got %d.%d", test.From, test.To, test.Minors[i], c.Major, c.Minor)
+			}
+		}
+	}
+}
+
+func TestAllBreakingChanges(t *testing.T) {
+	all := AllBreakingChanges()
+	if len(all) == 0 {
+		t.Fatal("Expected breaking changes")
+	}
+	for i := 1; i < len(all); i++ {
+		prev, c := all[i-1], all[i]
+		if c.Major < prev.Major || c.Major == prev.Major && c.Minor < prev.Minor {
+			t.Errorf("Breaking changes are not ordered at %d", i)
+		}
+	}
+	all[0].Summary = "changed"
+	if AllBreakingChanges()[0].Summary == "changed" {
+		t.Error("Expected a copy")
+	}
+}
@@ -0,0 +1,161 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// PolicyBuilder builds a Policy step by step, e.g.
+//
+//	p := NewPolicyBuilder().AllowSoft().MaxMinorSkip(2).RequireGA().Build()
+//
+// Every method returns a new builder and leaves its receiver unchanged,
+// so a partially configured builder can be shared and extended safely.
+// Build returns a Policy that shares no slices or maps with the builder.
+type PolicyBuilder struct {
+	p Policy
+}
+
+// NewPolicyBuilder creates a builder of a policy with the strict rules.
+func NewPolicyBuilder() PolicyBuilder {
+	return PolicyBuilder{}
+}
+
+// Build returns the policy.
+func (b PolicyBuilder) Build() Policy {
+	return b.p.clone()
+}
+
+// RuleSet sets the rules to evaluate, see WithRuleSet.
+func (b PolicyBuilder) RuleSet(s RuleSetSource) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.RuleSet = s
+	return b
+}
+
+// AllowSoft selects the soft rules, see WithSoft.
+func (b PolicyBuilder) AllowSoft() PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.Soft = true
+	return b
+}
+
+// MaxMinorSkip limits the increase of the minor version allowed by the
+// soft rules, see WithMaxMinorSkip.
+func (b PolicyBuilder) MaxMinorSkip(n int) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.MaxMinorSkip = n
+	return b
+}
+
+// AllowLicenseTransition allows changing the license from `from` to `to`,
+// see WithLicenseMatrix.
+func (b PolicyBuilder) AllowLicenseTransition(from, to License) PolicyBuilder {
+	b.p = b.p.clone()
+	if b.p.Licenses == nil {
+		b.p.Licenses = LicenseMatrix{}
+	}
+	b.p.Licenses[LicenseTransition{From: from, To: to}] = true
+	return b
+}
+
+// DenyByDefault only allows the given upgrades, see WithDenyByDefault.
+func (b PolicyBuilder) DenyByDefault(allowed ...Transition) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.DenyByDefault = true
+	b.p.AllowList = append(b.p.AllowList, allowed...)
+	return b
+}
+
+// RequireGA only allows upgrades to generally available releases,
+// see WithChannels.
+func (b PolicyBuilder) RequireGA() PolicyBuilder {
+	return b.Channels(ChannelGA)
+}
+
+// Channels sets the allowed release channels, see WithChannels.
+func (b PolicyBuilder) Channels(channels ...Channel) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.Channels = append([]Channel(nil), channels...)
+	return b
+}
+
+// AllowGAToPreRelease allows going back from a release to a pre-release
+// of the same patch version, see WithAllowGAToPreRelease.
+func (b PolicyBuilder) AllowGAToPreRelease() PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.AllowGAToPreRelease = true
+	return b
+}
+
+// MaintenanceWindows adds windows in which upgrades are allowed,
+// see WithMaintenanceWindows.
+func (b PolicyBuilder) MaintenanceWindows(windows ...MaintenanceWindow) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.MaintenanceWindows = append(b.p.MaintenanceWindows, windows...)
+	return b
+}
+
+// WarningsAsErrors adds codes of warnings that deny an upgrade,
+// see WithWarningsAsErrors.
+func (b PolicyBuilder) WarningsAsErrors(codes ...WarningCode) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.WarningsAsErrors = append(b.p.WarningsAsErrors, codes...)
+	return b
+}
+
+// Cooldown limits how often a deployment is upgraded, see WithCooldown.
+func (b PolicyBuilder) Cooldown(c Cooldown) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.Cooldown = c
+	return b
+}
+
+// Precondition adds a precondition for the given transition kinds,
+// see WithPrecondition.
+func (b PolicyBuilder) Precondition(p Precondition, kinds ...TransitionKind) PolicyBuilder {
+	b.p = b.p.clone()
+	b.p.Preconditions = append(b.p.Preconditions, PolicyPrecondition{
+		Precondition: p,
+		Transitions:  append([]TransitionKind(nil), kinds...),
+	})
+	return b
+}
+
+// clone returns a copy of the policy that shares no slices or maps with p.
+func (p Policy) clone() Policy {
+	c := p
+	if p.Licenses != nil {
+		c.Licenses = make(LicenseMatrix, len(p.Licenses))
+		for t, allowed := range p.Licenses {
+			c.Licenses[t] = allowed
+		}
+	}
+	c.AllowList = append([]Transition(nil), p.AllowList...)
+	c.Channels = append([]Channel(nil), p.Channels...)
+	c.MaintenanceWindows = append([]MaintenanceWindow(nil), p.MaintenanceWindows...)
+	c.WarningsAsErrors = append([]WarningCode(nil), p.WarningsAsErrors...)
+	c.Preconditions = append([]PolicyPrecondition(nil), p.Preconditions...)
+	if p.moreWindows != nil {
+		c.moreWindows = make([][]MaintenanceWindow, len(p.moreWindows))
+		for i, w := range p.moreWindows {
+			c.moreWindows[i] = append([]MaintenanceWindow(nil), w...)
+		}
+	}
+	return c
+}
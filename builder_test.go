@@ -0,0 +1,60 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestPolicyBuilder(t *testing.T) {
+	p := NewPolicyBuilder().AllowSoft().MaxMinorSkip(2).RequireGA().Build()
+	if !p.Soft || p.MaxMinorSkip != 2 || len(p.Channels) != 1 || p.Channels[0] != ChannelGA {
+		t.Errorf("Unexpected policy %+v", p)
+	}
+	if d := p.Check("3.10.8", "3.12.1"); !d.Allowed() {
+		t.Errorf("Expected upgrade to be allowed, got %v", d.Err)
+	}
+	if d := p.Check("3.10.8", "3.13.0"); d.Rule != RuleMaxMinorSkip {
+		t.Errorf("Expected %s, got %q", RuleMaxMinorSkip, d.Rule)
+	}
+	if d := p.Check("3.12.1", "3.13.0-rc.1"); d.Rule != RuleChannel {
+		t.Errorf("Expected %s, got %q", RuleChannel, d.Rule)
+	}
+}
+
+func TestPolicyBuilderIsImmutable(t *testing.T) {
+	base := NewPolicyBuilder().DenyByDefault(Transition{From: "3.11.8", To: "3.12.1"})
+	a := base.DenyByDefault(Transition{From: "3.11.8", To: "3.12.2"}).AllowLicenseTransition(LicenseEnterprise, LicenseCommunity)
+	b := base.DenyByDefault(Transition{From: "3.11.8", To: "3.12.3"})
+	if pa, pb := a.Build(), b.Build(); len(pa.AllowList) != 2 || len(pb.AllowList) != 2 ||
+		pa.AllowList[1].To != "3.12.2" || pb.AllowList[1].To != "3.12.3" || pb.Licenses != nil {
+		t.Errorf("Expected builders derived from the same base to be independent, got %+v and %+v", pa, pb)
+	}
+	p := a.Build()
+	p.AllowList[0].To = "4.0.0"
+	p.Licenses[LicenseTransition{From: LicenseCommunity, To: LicenseEnterprise}] = false
+	if q := a.Build(); q.AllowList[0].To != "3.12.1" || len(q.Licenses) != 1 {
+		t.Errorf("Expected built policies not to share state with the builder, got %+v", q)
+	}
+	if p := NewPolicyBuilder().Build(); !p.Check("3.11.8", "3.12.1").Allowed() {
+		t.Error("Expected the zero builder to build the default policy")
+	}
+}
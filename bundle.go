@@ -0,0 +1,324 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// BundleFormat is the newest bundle format understood by this version
+// of the library.
+const BundleFormat = 1
+
+// Bundle packages a release catalog, policies and overrides into a single
+// file that can be carried into an air-gapped environment, see WriteBundle
+// and LoadBundle.
+type Bundle struct {
+	// Format of the bundle, at most BundleFormat
+	Format int `json:"format"`
+	// Created is the time the bundle was created
+	Created time.Time `json:"created"`
+	// Catalog is the release catalog, see WithReleaseCatalog
+	Catalog ReleaseCatalog `json:"catalog"`
+	// Policies are the named policies of the bundle
+	Policies []BundlePolicy `json:"policies,omitempty"`
+	// Overrides are the overrides of the bundle, see WithOverrides
+	Overrides []Override `json:"overrides,omitempty"`
+}
+
+// BundlePolicy is the data of a Policy that can be stored in a Bundle.
+// Rule sets and preconditions are code and cannot be bundled; add them
+// with options when checking.
+type BundlePolicy struct {
+	// Name identifies the policy in the bundle
+	Name string `json:"name"`
+	// Profile is the profile the policy is based on, if any.
+	// The other fields refine the policy of the profile.
+	Profile Profile `json:"profile,omitempty"`
+	// Soft selects the soft rules
+	Soft bool `json:"soft,omitempty"`
+	// MaxMinorSkip limits the increase of the minor version allowed by
+	// the soft rules, that of the profile when 0 (see WithMaxMinorSkip)
+	MaxMinorSkip int `json:"maxMinorSkip,omitempty"`
+	// Licenses holds the allowed license transitions
+	Licenses []LicenseTransition `json:"licenses,omitempty"`
+	// DenyByDefault only allows the upgrades in AllowList
+	DenyByDefault bool `json:"denyByDefault,omitempty"`
+	// AllowList holds the allowed upgrades when DenyByDefault is set
+	AllowList []Transition `json:"allowList,omitempty"`
+	// Channels holds the allowed release channels, those of the profile
+	// when empty
+	Channels []Channel `json:"channels,omitempty"`
+	// AllowGAToPreRelease allows going back from a release to a
+	// pre-release of the same patch version
+	AllowGAToPreRelease bool `json:"allowGAToPreRelease,omitempty"`
+	// MaintenanceWindows holds the windows in which upgrades are allowed
+	MaintenanceWindows []BundleWindow `json:"maintenanceWindows,omitempty"`
+	// WarningsAsErrors holds the codes of warnings that deny an upgrade
+	WarningsAsErrors []WarningCode `json:"warningsAsErrors,omitempty"`
+	// Cooldown limits how often a deployment is upgraded, that of the
+	// profile when nil (see WithCooldown)
+	Cooldown *BundleCooldown `json:"cooldown,omitempty"`
+}
+
+// BundleCooldown is a Cooldown in a BundlePolicy.
+type BundleCooldown struct {
+	// AfterUpgrade is the minimum time between any two upgrades, e.g. "24h"
+	AfterUpgrade string `json:"afterUpgrade,omitempty"`
+	// BetweenMinors is the minimum time between two upgrades that change
+	// the minor version, e.g. "720h"
+	BetweenMinors string `json:"betweenMinors,omitempty"`
+}
+
+// BundleWindow is a MaintenanceWindow in a BundlePolicy.
+type BundleWindow struct {
+	// Schedule is the cron schedule of the start of the window
+	Schedule string `json:"schedule"`
+	// Duration of the window, e.g. "2h"
+	Duration string `json:"duration"`
+	// Location is the name of the time zone of the schedule, UTC if empty
+	Location string `json:"location,omitempty"`
+}
+
+// bundleSignature is the signature of a bundle by a single key.
+type bundleSignature struct {
+	Key       []byte `json:"key"`
+	Signature []byte `json:"signature"`
+}
+
+// bundleFile is the representation of a bundle in a file. The bundle is
+// kept as raw JSON, so the signatures are verified on the exact bytes
+// that were signed.
+type bundleFile struct {
+	Bundle     json.RawMessage   `json:"bundle"`
+	Signatures []bundleSignature `json:"signatures,omitempty"`
+}
+
+// NewBundle creates a bundle of the given catalog and policies, created now.
+func NewBundle(catalog ReleaseCatalog, policies ...BundlePolicy) Bundle {
+	return Bundle{
+		Format:   BundleFormat,
+		Created:  time.Now().UTC(),
+		Catalog:  catalog,
+		Policies: policies,
+	}
+}
+
+// WriteBundle writes the bundle, signed by all given keys.
+func WriteBundle(w io.Writer, b Bundle, keys ...ed25519.PrivateKey) error {
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	f := bundleFile{Bundle: data}
+	for _, k := range keys {
+		f.Signatures = append(f.Signatures, bundleSignature{
+			Key:       k.Public().(ed25519.PublicKey),
+			Signature: ed25519.Sign(k, data),
+		})
+	}
+	return json.NewEncoder(w).Encode(f)
+}
+
+// ReadBundle reads a bundle written by WriteBundle and validates it.
+// The bundle must be signed by one of the trusted keys, of which there
+// must be at least one, as its overrides bypass the rules.
+func ReadBundle(r io.Reader, trusted ...ed25519.PublicKey) (Bundle, error) {
+	if len(trusted) == 0 {
+		return Bundle{}, fmt.Errorf("No trusted keys to verify the bundle with")
+	}
+	return readBundle(r, trusted)
+}
+
+// ReadUnsignedBundle reads a bundle written by WriteBundle and validates
+// it, without verifying its signatures. Use it for tests and development
+// only, see ReadBundle.
+func ReadUnsignedBundle(r io.Reader) (Bundle, error) {
+	return readBundle(r, nil)
+}
+
+// readBundle reads and validates a bundle, which must be signed by one of
+// the trusted keys unless there are none.
+func readBundle(r io.Reader, trusted []ed25519.PublicKey) (Bundle, error) {
+	var f bundleFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return Bundle{}, err
+	}
+	if len(trusted) > 0 && !f.signedBy(trusted) {
+		return Bundle{}, fmt.Errorf("Bundle is not signed by a trusted key")
+	}
+	var b Bundle
+	if err := json.Unmarshal(f.Bundle, &b); err != nil {
+		return Bundle{}, err
+	}
+	if err := b.Validate(); err != nil {
+		return Bundle{}, err
+	}
+	return b, nil
+}
+
+// LoadBundle reads the bundle file at the given path, see ReadBundle.
+func LoadBundle(path string, trusted ...ed25519.PublicKey) (Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Bundle{}, err
+	}
+	defer f.Close()
+	return ReadBundle(f, trusted...)
+}
+
+// signedBy returns true when the bundle has a valid signature by one of
+// the given keys.
+func (f bundleFile) signedBy(trusted []ed25519.PublicKey) bool {
+	for _, s := range f.Signatures {
+		for _, k := range trusted {
+			if bytes.Equal(s.Key, k) && ed25519.Verify(k, f.Bundle, s.Signature) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate returns an error when the bundle cannot be used by this
+// version of the library.
+func (b Bundle) Validate() error {
+	if b.Format < 1 || b.Format > BundleFormat {
+		return fmt.Errorf("Unsupported bundle format %d, expected 1 to %d", b.Format, BundleFormat)
+	}
+	if err := b.Catalog.Validate(); err != nil {
+		return err
+	}
+	for _, p := range b.Policies {
+		if _, err := p.Policy(); err != nil {
+			return fmt.Errorf("Invalid policy '%s': %s", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// Options returns the options to check upgrades with the catalog, the
+// overrides and the named policy of the bundle. An empty name selects no
+// policy.
+func (b Bundle) Options(policy string) ([]Option, error) {
+	opts := []Option{WithReleaseCatalog(b.Catalog)}
+	if len(b.Overrides) > 0 {
+		opts = append(opts, WithOverrides(b.Overrides...))
+	}
+	if policy == "" {
+		return opts, nil
+	}
+	for _, bp := range b.Policies {
+		if bp.Name == policy {
+			p, err := bp.Policy()
+			if err != nil {
+				return nil, err
+			}
+			return append(opts, WithPolicy(p)), nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown policy '%s' in bundle", policy)
+}
+
+// Policy returns the policy described by p.
+func (p BundlePolicy) Policy() (Policy, error) {
+	result := Policy{}
+	if p.Profile != "" {
+		if _, err := ParseProfile(string(p.Profile)); err != nil {
+			return Policy{}, err
+		}
+		result = p.Profile.Policy()
+	}
+	result.Soft = result.Soft || p.Soft
+	if p.MaxMinorSkip > 0 {
+		result.MaxMinorSkip = p.MaxMinorSkip
+	}
+	result.DenyByDefault = result.DenyByDefault || p.DenyByDefault
+	result.AllowGAToPreRelease = result.AllowGAToPreRelease || p.AllowGAToPreRelease
+	result.AllowList = append(result.AllowList, p.AllowList...)
+	result.WarningsAsErrors = append(result.WarningsAsErrors, p.WarningsAsErrors...)
+	if len(p.Channels) > 0 {
+		result.Channels = p.Channels
+	}
+	if len(p.Licenses) > 0 {
+		m := LicenseMatrix{}
+		for t, allowed := range result.Licenses {
+			m[t] = allowed
+		}
+		for _, t := range p.Licenses {
+			m[t] = true
+		}
+		result.Licenses = m
+	}
+	for _, w := range p.MaintenanceWindows {
+		window, err := w.window()
+		if err != nil {
+			return Policy{}, err
+		}
+		result.MaintenanceWindows = append(result.MaintenanceWindows, window)
+	}
+	if p.Cooldown != nil {
+		c, err := p.Cooldown.cooldown()
+		if err != nil {
+			return Policy{}, err
+		}
+		result.Cooldown = c
+	}
+	return result, nil
+}
+
+// cooldown parses the cooldown.
+func (c BundleCooldown) cooldown() (Cooldown, error) {
+	var result Cooldown
+	var err error
+	if c.AfterUpgrade != "" {
+		if result.AfterUpgrade, err = time.ParseDuration(c.AfterUpgrade); err != nil {
+			return Cooldown{}, err
+		}
+	}
+	if c.BetweenMinors != "" {
+		if result.BetweenMinors, err = time.ParseDuration(c.BetweenMinors); err != nil {
+			return Cooldown{}, err
+		}
+	}
+	return result, nil
+}
+
+// window parses the maintenance window.
+func (w BundleWindow) window() (MaintenanceWindow, error) {
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return MaintenanceWindow{}, err
+	}
+	location := time.UTC
+	if w.Location != "" {
+		if location, err = time.LoadLocation(w.Location); err != nil {
+			return MaintenanceWindow{}, err
+		}
+	}
+	return ParseMaintenanceWindow(w.Schedule, duration, location)
+}
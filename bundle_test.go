@@ -0,0 +1,152 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBundle(t *testing.T) {
+	catalog := EmbeddedReleaseCatalog()
+	catalog.SkipVersions = []VersionString{"3.12.2"}
+	b := NewBundle(catalog, BundlePolicy{
+		Name:               "prod",
+		Profile:            ProfileProduction,
+		MaintenanceWindows: []BundleWindow{{Schedule: "0 2 * * 6", Duration: "4h"}},
+	}, BundlePolicy{Name: "dev", Profile: ProfileDevelopment})
+	b.Overrides = []Override{{From: "3.10", To: "3.12", Ticket: "OPS-1"}}
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteBundle(f, b, key); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	loaded, err := LoadBundle(path, key.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts, err := loaded.Options("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saturday := WithClock(func() time.Time { return time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC) })
+	if d := Check("3.12.1", "3.12.2", append(opts, saturday)...); d.Rule != RuleSkippedVersion {
+		t.Errorf("Expected the catalog to deny 3.12.2, got %v", d.Err)
+	}
+	if d := Check("3.10.8", "3.12.1", append(opts, saturday)...); d.Outcome() != OutcomeOverridden {
+		t.Errorf("Expected the override to allow 3.12.1, got %v", d.Err)
+	}
+	monday := WithClock(func() time.Time { return time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC) })
+	if d := Check("3.11.8", "3.12.1", append(opts, monday)...); d.Allowed() {
+		t.Error("Expected the maintenance window to deny the upgrade")
+	}
+	if _, err := loaded.Options("staging"); err == nil {
+		t.Error("Expected unknown policy to fail")
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if _, err := LoadBundle(path, other); err == nil {
+		t.Error("Expected bundle signed by another key to fail")
+	}
+	if _, err := LoadBundle(path); err == nil {
+		t.Error("Expected bundle not to load without trusted keys")
+	}
+	f, err = os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := ReadUnsignedBundle(f); err != nil {
+		t.Errorf("Expected bundle to be read without verification, got %s", err)
+	}
+}
+
+func TestBundlePolicyRoundTrip(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	b := NewBundle(EmbeddedReleaseCatalog(), BundlePolicy{
+		Name:         "staging",
+		Profile:      ProfileStaging,
+		Soft:         true,
+		MaxMinorSkip: 2,
+		Cooldown:     &BundleCooldown{AfterUpgrade: "24h", BetweenMinors: "720h"},
+	})
+	if err := WriteBundle(&buf, b, key); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"maxMinorSkip":2`)) ||
+		!bytes.Contains(buf.Bytes(), []byte(`"cooldown":{"afterUpgrade":"24h","betweenMinors":"720h"}`)) {
+		t.Errorf("Expected the limits in the bundle, got %s", buf.Bytes())
+	}
+	loaded, err := ReadBundle(&buf, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := loaded.Policies[0].Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MaxMinorSkip != 2 || p.Cooldown != (Cooldown{AfterUpgrade: 24 * time.Hour, BetweenMinors: 30 * 24 * time.Hour}) {
+		t.Errorf("Expected the limits in the policy, got %+v", p)
+	}
+	if _, err := (BundlePolicy{Name: "bad", Cooldown: &BundleCooldown{AfterUpgrade: "1 day"}}).Policy(); err == nil {
+		t.Error("Expected invalid cooldown to fail")
+	}
+}
+
+func TestReadBundleTampered(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, NewBundle(EmbeddedReleaseCatalog()), key); err != nil {
+		t.Fatal(err)
+	}
+	tampered := bytes.Replace(buf.Bytes(), []byte(`"format":1`), []byte(`"format":1 `), 1)
+	if _, err := ReadBundle(bytes.NewReader(tampered), public); err == nil {
+		t.Error("Expected tampered bundle to fail")
+	}
+	invalid := NewBundle(EmbeddedReleaseCatalog(), BundlePolicy{Name: "bad", MaintenanceWindows: []BundleWindow{{Schedule: "x", Duration: "1h"}}})
+	buf.Reset()
+	if err := WriteBundle(&buf, invalid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadUnsignedBundle(&buf); err == nil {
+		t.Error("Expected invalid policy to fail")
+	}
+}
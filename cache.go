@@ -0,0 +1,218 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CachedChecker caches the decisions of Check in a least-recently-used
+// cache of fixed size. It is safe for concurrent use.
+//
+// Options with side effects (decision recorders, audit sinks, metrics,
+// loggers, tracers) are only invoked when a decision is computed, not
+// when it is served from the cache.
+//
+// Decisions that depend on the time or the state of the deployment are
+// not cached: those with a retryable error, and all decisions of options
+// with maintenance windows, a cooldown, preconditions or a policy
+// resolver. Custom rules must not depend on RuleInput.Time. A new
+// RuleSet of an AtomicRuleSet and a new default policy (see
+// SetDefaultPolicy) take effect immediately.
+//
+// Every returned decision is a copy with its own slices, trace and
+// override, and the time of the check. Only Err is shared between the
+// decisions of the same upgrade, so it must not be modified.
+type CachedChecker struct {
+	mutex   sync.Mutex
+	size    int
+	opts    []Option
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+	stats   CacheStats
+	// generation is incremented when the cache is purged, so decisions
+	// evaluated before are not stored
+	generation uint64
+}
+
+// CacheStats contains statistics of a CachedChecker.
+type CacheStats struct {
+	// Hits is the number of checks served from the cache
+	Hits uint64
+	// Misses is the number of checks that had to be evaluated
+	Misses uint64
+	// Entries is the number of decisions currently in the cache
+	Entries int
+}
+
+// cacheKey identifies a cached decision.
+type cacheKey struct {
+	from, to               VersionString
+	licensed               bool
+	fromLicense, toLicense License
+	// rules and policy are the rule set and default policy the decision
+	// was evaluated with
+	rules  *RuleSet
+	policy *packagePolicy
+}
+
+// cacheEntry is the value of an element in the LRU list.
+type cacheEntry struct {
+	key      cacheKey
+	decision Decision
+}
+
+// NewCachedChecker creates a CachedChecker holding up to `size`
+// decisions, which are evaluated using the given options.
+func NewCachedChecker(size int, opts ...Option) *CachedChecker {
+	if size < 1 {
+		size = 1
+	}
+	return &CachedChecker{
+		size:    size,
+		opts:    opts,
+		entries: make(map[cacheKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Check returns the decision for upgrading from `from` to `to`,
+// without checking licenses.
+func (c *CachedChecker) Check(from, to VersionString) Decision {
+	return c.check(cacheKey{from: from, to: to})
+}
+
+// CheckWithLicense returns the decision for upgrading from `from` to `to`,
+// including the given licenses in the check.
+func (c *CachedChecker) CheckWithLicense(from, to VersionString, fromLicense, toLicense License) Decision {
+	return c.check(cacheKey{from: from, to: to, licensed: true, fromLicense: fromLicense, toLicense: toLicense})
+}
+
+// SetOptions replaces the options used to evaluate decisions.
+// Since that changes the rules, all cached decisions are removed.
+func (c *CachedChecker) SetOptions(opts ...Option) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.opts = opts
+	c.purge()
+}
+
+// Purge removes all cached decisions.
+func (c *CachedChecker) Purge() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.purge()
+}
+
+// Stats returns statistics about the cache.
+func (c *CachedChecker) Stats() CacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	s := c.stats
+	s.Entries = c.lru.Len()
+	return s
+}
+
+// check returns the decision for the given key from the cache, or
+// evaluates and caches it. The decision is evaluated without holding
+// the mutex, so concurrent checks do not wait for each other.
+func (c *CachedChecker) check(key cacheKey) Decision {
+	c.mutex.Lock()
+	o := newOptions(c.opts)
+	key.rules, key.policy = o.ruleSetFor(ParseVersion(key.from)), currentDefaultPolicy()
+	if elem, found := c.entries[key]; found {
+		entry := elem.Value.(*cacheEntry)
+		if x := entry.decision.Override; x == nil || !x.Expired(o.now()) {
+			c.stats.Hits++
+			c.lru.MoveToFront(elem)
+			c.mutex.Unlock()
+			return entry.decision.copy(o.now())
+		}
+		// The override this decision depends on has expired
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+	c.stats.Misses++
+	opts, generation := c.opts, c.generation
+	c.mutex.Unlock()
+
+	if key.licensed {
+		opts = append(append([]Option(nil), opts...), WithLicenses(key.fromLicense, key.toLicense))
+	}
+	d := Check(key.from, key.to, opts...)
+	if !cacheable(o, d) {
+		return d
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generation != generation {
+		// The options changed while evaluating
+		return d
+	}
+	if elem, found := c.entries[key]; found {
+		// A concurrent check evaluated the same decision
+		c.lru.Remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, decision: d.copy(d.Time)})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return d
+}
+
+// cacheable returns true when the given decision, evaluated with the given
+// options, does not depend on the time or the state of the deployment.
+func cacheable(o options, d Decision) bool {
+	if IsRetryable(d.Err) {
+		return false
+	}
+	return len(o.windows) == 0 && o.cooldown.IsZero() && len(o.preconditions) == 0 && o.policyResolver == nil
+}
+
+// copy returns a copy of the decision made at the given time, that does
+// not share its slices, trace and override with d.
+func (d Decision) copy(now time.Time) Decision {
+	d.Time = now
+	d.Warnings = append([]Warning(nil), d.Warnings...)
+	d.UnmetPreconditions = append([]UnmetPrecondition(nil), d.UnmetPreconditions...)
+	d.Notes = append([]UpgradeNote(nil), d.Notes...)
+	d.ResourceHints = append([]ResourceHint(nil), d.ResourceHints...)
+	if d.Override != nil {
+		x := *d.Override
+		x.Rules = append([]RuleID(nil), x.Rules...)
+		d.Override = &x
+	}
+	d.Trace = d.Trace.copy()
+	return d
+}
+
+// purge removes all cached decisions.
+// The mutex must be held.
+func (c *CachedChecker) purge() {
+	c.entries = make(map[cacheKey]*list.Element)
+	c.lru.Init()
+	c.generation++
+}
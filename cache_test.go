@@ -0,0 +1,183 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCachedChecker(t *testing.T) {
+	var recorded decisionList
+	c := NewCachedChecker(2, WithDecisionRecorder(&recorded))
+
+	if d := c.Check("3.10.8", "3.12.1"); d.Allowed() {
+		t.Error("Expected 3.10.8 -> 3.12.1 to be denied")
+	}
+	if d := c.Check("3.10.8", "3.12.1"); d.Allowed() {
+		t.Error("Expected cached 3.10.8 -> 3.12.1 to be denied")
+	}
+	if d := c.CheckWithLicense("3.11.8", "3.12.1", LicenseEnterprise, LicenseCommunity); d.Allowed() {
+		t.Error("Expected Enterprise to Community to be denied")
+	}
+	if d := c.Check("3.11.8", "3.12.1"); !d.Allowed() {
+		t.Error("Expected 3.11.8 -> 3.12.1 without license to be allowed")
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 3 || s.Entries != 2 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+	if len(recorded) != 3 {
+		t.Errorf("Expected 3 recorded decisions, got %d", len(recorded))
+	}
+
+	// The first entry has been evicted
+	c.Check("3.10.8", "3.12.1")
+	if s := c.Stats(); s.Misses != 4 {
+		t.Errorf("Expected evicted entry to be evaluated again, got %+v", s)
+	}
+
+	// Changing the options invalidates the cache
+	c.SetOptions(WithSoft())
+	if d := c.Check("3.10.8", "3.12.1"); !d.Allowed() {
+		t.Error("Expected 3.10.8 -> 3.12.1 to be allowed with soft rules")
+	}
+	if s := c.Stats(); s.Entries != 1 {
+		t.Errorf("Expected 1 entry after SetOptions, got %+v", s)
+	}
+}
+
+func TestCachedCheckerCopiesDecisions(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCachedChecker(2, WithTrace(), WithClock(func() time.Time { return now }))
+	first := c.Check("3.11.8", "3.12.0")
+	if len(first.Warnings) != 1 || first.Trace == nil || len(first.Trace.Rules[0].Conditions) == 0 {
+		t.Fatalf("Expected a warning and a trace with conditions, got %+v", first)
+	}
+	message, rule := first.Warnings[0].Message, first.Trace.Rules[0].Rule
+	first.Warnings[0].Message = "changed"
+	first.Trace.Rules[0].Rule = "changed"
+	first.Trace.Rules[0].Conditions[0].Values["changed"] = "true"
+
+	now = now.Add(time.Hour)
+	second := c.Check("3.11.8", "3.12.0")
+	if c.Stats().Hits != 1 {
+		t.Fatalf("Expected a cache hit, got %+v", c.Stats())
+	}
+	if second.Warnings[0].Message != message || second.Trace.Rules[0].Rule != rule {
+		t.Errorf("Expected the cached decision not to change, got %+v", second)
+	}
+	if _, found := second.Trace.Rules[0].Conditions[0].Values["changed"]; found {
+		t.Error("Expected the cached trace not to change")
+	}
+	if !second.Time.Equal(now) {
+		t.Errorf("Expected the time of the second check, got %s", second.Time)
+	}
+}
+
+func TestCachedCheckerOverrideExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCachedChecker(10,
+		WithClock(func() time.Time { return now }),
+		WithOverrides(Override{From: "3.10", To: "3.12", Expires: now.Add(time.Hour)}))
+
+	if d := c.Check("3.10.8", "3.12.1"); d.Outcome() != OutcomeOverridden {
+		t.Errorf("Expected overridden, got %s", d.Outcome())
+	}
+	now = now.Add(2 * time.Hour)
+	if d := c.Check("3.10.8", "3.12.1"); d.Outcome() != OutcomeDenied {
+		t.Errorf("Expected denied after expiry, got %s", d.Outcome())
+	}
+}
+
+func TestCachedCheckerTimeDependentDecisions(t *testing.T) {
+	now := time.Date(2026, 10, 3, 1, 0, 0, 0, time.UTC)
+	c := NewCachedChecker(10,
+		WithClock(func() time.Time { return now }),
+		WithMaintenanceWindows(MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)))
+
+	if d := c.Check("3.11.8", "3.12.1"); d.Rule != RuleMaintenanceWindow {
+		t.Fatalf("Expected denial outside the window, got %v", d.Err)
+	}
+	now = now.Add(2 * time.Hour)
+	if d := c.Check("3.11.8", "3.12.1"); !d.Allowed() {
+		t.Errorf("Expected the upgrade to be allowed inside the window, got %v", d.Err)
+	}
+	now = now.Add(4 * time.Hour)
+	if d := c.Check("3.11.8", "3.12.1"); d.Allowed() {
+		t.Error("Expected denial after the window")
+	}
+	if s := c.Stats(); s.Hits != 0 || s.Entries != 0 {
+		t.Errorf("Expected nothing to be cached, got %+v", s)
+	}
+}
+
+func TestCachedCheckerRuleSetAndPolicyChanges(t *testing.T) {
+	a := NewAtomicRuleSet(DefaultRuleSet())
+	c := NewCachedChecker(10, WithRuleSet(a))
+	if d := c.Check("3.10.8", "3.12.1"); d.Allowed() {
+		t.Fatal("Expected 3.10.8 -> 3.12.1 to be denied")
+	}
+	a.Store(DefaultRuleSet().WithoutRule(RuleMinorIncrement))
+	if d := c.Check("3.10.8", "3.12.1"); !d.Allowed() {
+		t.Errorf("Expected the new rule set to allow the upgrade, got %v", d.Err)
+	}
+
+	defer SetDefaultPolicy(Policy{})
+	c = NewCachedChecker(10)
+	if d := c.Check("3.10.8", "3.12.1"); d.Allowed() {
+		t.Fatal("Expected 3.10.8 -> 3.12.1 to be denied")
+	}
+	SetDefaultPolicy(Policy{Soft: true})
+	if d := c.Check("3.10.8", "3.12.1"); !d.Allowed() {
+		t.Errorf("Expected the new default policy to allow the upgrade, got %v", d.Err)
+	}
+}
+
+func TestCachedCheckerEvaluatesConcurrently(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	blocking := Rule{
+		ID:      "blocking",
+		Applies: func(in RuleInput) bool { return in.From.Minor() == 10 },
+		Check: func(in RuleInput) error {
+			close(entered)
+			<-release
+			return nil
+		},
+	}
+	c := NewCachedChecker(10, WithRuleSet(NewRuleSet(blocking)))
+	done := make(chan Decision)
+	go func() { done <- c.Check("3.10.8", "3.11.1") }()
+	<-entered
+	finished := make(chan struct{})
+	go func() {
+		c.Check("3.11.8", "3.12.1")
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected a check to complete while another one is evaluated")
+	}
+	close(release)
+	if d := <-done; !d.Allowed() {
+		t.Errorf("Expected the upgrade to be allowed, got %v", d.Err)
+	}
+}
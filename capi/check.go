@@ -0,0 +1,164 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+	"github.com/arangodb/go-upgrade-rules/simulation"
+)
+
+// checkRequest is the JSON input of UpgradeRulesCheck.
+type checkRequest struct {
+	From        upgraderules.VersionString `json:"from"`
+	To          upgraderules.VersionString `json:"to"`
+	FromLicense string                     `json:"fromLicense,omitempty"`
+	ToLicense   string                     `json:"toLicense,omitempty"`
+	Soft        bool                       `json:"soft,omitempty"`
+}
+
+// checkResponse is the JSON output of UpgradeRulesCheck.
+type checkResponse struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// explainResponse is the JSON output of UpgradeRulesExplain.
+type explainResponse struct {
+	checkResponse
+	Trace *upgraderules.Trace `json:"trace,omitempty"`
+}
+
+// planRequest is the JSON input of UpgradeRulesPlan.
+type planRequest struct {
+	checkRequest
+	Versions []upgraderules.VersionString `json:"versions"`
+}
+
+// planResponse is the JSON output of UpgradeRulesPlan.
+type planResponse struct {
+	Found  bool                         `json:"found"`
+	Path   []upgraderules.VersionString `json:"path,omitempty"`
+	Reason string                       `json:"reason,omitempty"`
+}
+
+// check runs the rules for the given JSON request.
+// It implements UpgradeRulesCheck in plain Go, so it is tested without cgo.
+func check(request string) checkResponse {
+	return explain(request, false).checkResponse
+}
+
+// explain runs the rules for the given JSON request, including the trace
+// of the evaluated rules when requested.
+// It implements UpgradeRulesExplain in plain Go, so it is tested without cgo.
+// The request is untrusted input, so it is checked with
+// upgraderules.EvaluateRaw.
+func explain(request string, trace bool) explainResponse {
+	var req checkRequest
+	if err := json.Unmarshal([]byte(request), &req); err != nil {
+		return explainResponse{checkResponse: checkResponse{Reason: "Invalid request: " + err.Error()}}
+	}
+	opts := req.options()
+	if trace {
+		opts = append(opts, upgraderules.WithTrace())
+	}
+	fromLicense, toLicense := req.licenses()
+	d, err := upgraderules.EvaluateRaw(string(req.From), string(req.To), fromLicense, toLicense, opts...)
+	if err != nil {
+		return explainResponse{checkResponse: checkResponse{Reason: err.Error()}}
+	}
+	result := explainResponse{checkResponse: checkResponse{Allowed: true}, Trace: d.Trace}
+	if d.Err != nil {
+		result.checkResponse = checkResponse{Reason: d.Err.Error()}
+	}
+	return result
+}
+
+// plan finds the shortest allowed path of upgrades for the given JSON
+// request with simulation.FindPath.
+// It implements UpgradeRulesPlan in plain Go, so it is tested without cgo.
+// Every version of the request is checked with upgraderules.EvaluateRaw
+// before searching.
+func plan(request string) planResponse {
+	var req planRequest
+	if err := json.Unmarshal([]byte(request), &req); err != nil {
+		return planResponse{Reason: "Invalid request: " + err.Error()}
+	}
+	fromLicense, toLicense := req.licenses()
+	if _, err := upgraderules.EvaluateRaw(string(req.From), string(req.To), fromLicense, toLicense); err != nil {
+		return planResponse{Reason: err.Error()}
+	}
+	for _, v := range req.Versions {
+		if _, err := upgraderules.EvaluateRaw(string(v), string(v), "", ""); err != nil {
+			if ie, ok := err.(*upgraderules.InputError); ok {
+				ie.Field = "versions"
+			}
+			return planResponse{Reason: err.Error()}
+		}
+	}
+	opts := req.options()
+	if fromLicense != "" {
+		// Both licenses were validated by EvaluateRaw
+		from, _ := upgraderules.ParseLicense(fromLicense)
+		to, _ := upgraderules.ParseLicense(toLicense)
+		opts = append(opts, upgraderules.WithLicenses(from, to))
+	}
+	path, found := simulation.FindPath(req.From, req.To, req.Versions, opts...)
+	if !found {
+		return planResponse{Reason: fmt.Sprintf("No allowed path from %s to %s", req.From, req.To)}
+	}
+	return planResponse{Found: true, Path: path}
+}
+
+// licenses returns the licenses of the request, either both empty or
+// both set, where a missing license defaults to community.
+func (req checkRequest) licenses() (string, string) {
+	fromLicense, toLicense := req.FromLicense, req.ToLicense
+	if fromLicense != "" || toLicense != "" {
+		if fromLicense == "" {
+			fromLicense = upgraderules.LicenseCommunity.String()
+		}
+		if toLicense == "" {
+			toLicense = upgraderules.LicenseCommunity.String()
+		}
+	}
+	return fromLicense, toLicense
+}
+
+// options returns the options of the request, other than the licenses.
+func (req checkRequest) options() []upgraderules.Option {
+	var opts []upgraderules.Option
+	if req.Soft {
+		opts = append(opts, upgraderules.WithSoft())
+	}
+	return opts
+}
+
+// encode encodes the given value as JSON.
+func encode(v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return `{"allowed":false,"reason":"Failed to encode response"}`
+	}
+	return string(encoded)
+}
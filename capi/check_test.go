@@ -0,0 +1,84 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		Request  string
+		Expected string
+	}{
+		{`{"from":"3.11.8","to":"3.12.1"}`, `{"allowed":true}`},
+		{`{"from":"3.11.8","to":"3.12.1","fromLicense":"enterprise","toLicense":"enterprise"}`, `{"allowed":true}`},
+		{`{"from":"3.10.8","to":"3.12.1"}`, `{"allowed":false,"reason":"Minor versions may only increment by 1 (from 3.10.8 to 3.12.1)"}`},
+		{`{"from":"3.11.8","to":"3.12.1","fromLicense":"enterprise","toLicense":"community"}`, `{"allowed":false,"reason":"Upgrade from Enterprise to Community edition is not possible (from 3.11.8 enterprise to 3.12.1 community)"}`},
+		{`{"from":"3.11.8","to":"3.12.1","toLicense":"enterprise"}`, `{"allowed":true}`},
+		{`{"from":"3.10.8","to":"3.12.1","soft":true}`, `{"allowed":true}`},
+		{`{"from":"3.11.8","to":"latest"}`, `{"allowed":false,"reason":"Invalid to 'latest': version must have at least a major and minor part"}`},
+		{`{"from":"","to":"3.12.1"}`, `{"allowed":false,"reason":"Invalid from '': version is empty"}`},
+		{`{"from":"3.11.8","to":"3.12.1","fromLicense":"gold"}`, `{"allowed":false,"reason":"Invalid fromLicense 'gold': unknown license"}`},
+		{`{"from":`, `{"allowed":false,"reason":"Invalid request: unexpected end of JSON input"}`},
+	}
+	for _, test := range tests {
+		if s := encode(check(test.Request)); s != test.Expected {
+			t.Errorf("Request %s: expected %s, got %s", test.Request, test.Expected, s)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	r := explain(`{"from":"3.10.8","to":"3.12.1"}`, true)
+	if r.Allowed || r.Reason == "" {
+		t.Errorf("Expected denial, got %+v", r)
+	}
+	if r.Trace == nil || len(r.Trace.Rules) == 0 {
+		t.Fatal("Expected a trace")
+	}
+	if last := r.Trace.Rules[len(r.Trace.Rules)-1]; last.Rule != "minor-increment" || last.Allowed {
+		t.Errorf("Expected the trace to end with the denying rule, got %+v", last)
+	}
+	if r := explain(`{"from":"3.11.8","to":"3.12.1"}`, false); !r.Allowed || r.Trace != nil {
+		t.Errorf("Expected allowed without trace, got %+v", r)
+	}
+	if s := encode(explain(`{"from":"","to":"3.12.1"}`, true)); s != `{"allowed":false,"reason":"Invalid from '': version is empty"}` {
+		t.Errorf("Expected invalid request without trace, got %s", s)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		Request  string
+		Expected string
+	}{
+		{`{"from":"3.10.8","to":"3.12.1","versions":["3.11.0","3.11.8","3.12.0"]}`, `{"found":true,"path":["3.11.0","3.12.1"]}`},
+		{`{"from":"3.10.8","to":"3.12.1","versions":[]}`, `{"found":false,"reason":"No allowed path from 3.10.8 to 3.12.1"}`},
+		{`{"from":"3.10.8","to":"3.12.1","soft":true}`, `{"found":true,"path":["3.12.1"]}`},
+		{`{"from":"3.10.8","to":"3.12.1","versions":["latest"]}`, `{"found":false,"reason":"Invalid versions 'latest': version must have at least a major and minor part"}`},
+		{`{"from":"3.10.8","to":"3.12.1","toLicense":"gold"}`, `{"found":false,"reason":"Invalid toLicense 'gold': unknown license"}`},
+		{`{"from":`, `{"found":false,"reason":"Invalid request: unexpected end of JSON input"}`},
+	}
+	for _, test := range tests {
+		if s := encode(plan(test.Request)); s != test.Expected {
+			t.Errorf("Request %s: expected %s, got %s", test.Request, test.Expected, s)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Command capi exports the upgrade rules as a C shared library.
+// Build it with `make libupgraderules`.
+//
+// All functions take and return JSON encoded, NUL terminated strings.
+// Strings returned by the library must be released with
+// UpgradeRulesFree.
+package main
+
+// #include <stdlib.h>
+import "C"
+
+import "unsafe"
+
+func main() {}
+
+// UpgradeRulesCheck checks if the upgrade described by the given JSON
+// request is allowed.
+// The request looks like:
+//
+//	{"from":"3.11.8","to":"3.12.1","fromLicense":"enterprise","toLicense":"enterprise","soft":false}
+//
+// where the license fields are optional; a missing license defaults to
+// community when the other one is given.
+// The result looks like `{"allowed":false,"reason":"..."}`, where the
+// reason also describes invalid versions or licenses (see
+// upgraderules.EvaluateRaw).
+//
+//export UpgradeRulesCheck
+func UpgradeRulesCheck(request *C.char) *C.char {
+	return toCString(check(C.GoString(request)))
+}
+
+// UpgradeRulesExplain checks the upgrade described by the given JSON
+// request, like UpgradeRulesCheck, and explains the decision.
+// The result looks like `{"allowed":false,"reason":"...","trace":{"rules":[...]}}`,
+// where the trace holds every evaluated rule with its conditions (see
+// upgraderules.Trace). Invalid requests have no trace.
+//
+//export UpgradeRulesExplain
+func UpgradeRulesExplain(request *C.char) *C.char {
+	return toCString(explain(C.GoString(request), true))
+}
+
+// UpgradeRulesPlan finds the shortest sequence of allowed upgrades
+// through the given versions.
+// The request looks like:
+//
+//	{"from":"3.10.8","to":"3.12.1","versions":["3.11.0","3.11.8"],"soft":false}
+//
+// with the optional license fields of UpgradeRulesCheck.
+// The result looks like `{"found":true,"path":["3.11.8","3.12.1"]}`, where
+// the path excludes `from` and ends with `to` (see simulation.FindPath),
+// or `{"found":false,"reason":"..."}`.
+//
+//export UpgradeRulesPlan
+func UpgradeRulesPlan(request *C.char) *C.char {
+	return toCString(plan(C.GoString(request)))
+}
+
+// UpgradeRulesFree releases a string returned by this library.
+//
+//export UpgradeRulesFree
+func UpgradeRulesFree(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+// toCString encodes the given value as JSON into a C string.
+func toCString(v interface{}) *C.char {
+	return C.CString(encode(v))
+}
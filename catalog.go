@@ -0,0 +1,125 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ReleaseCatalogFormat is the newest release catalog format understood by
+// this version of the library.
+const ReleaseCatalogFormat = 1
+
+// embeddedReleaseCatalogPublished is the time the embedded data was last
+// updated.
+var embeddedReleaseCatalogPublished = time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// ReleaseCatalog holds the data about ArangoDB releases that changes
+// between releases of this library. The library embeds a snapshot of it
+// (see EmbeddedReleaseCatalog); long-running programs can use a newer one
+// with WithReleaseCatalog, e.g. one downloaded by the catalog package.
+type ReleaseCatalog struct {
+	// Format of the catalog, at most ReleaseCatalogFormat
+	Format int `json:"format"`
+	// Published is the time the catalog was published. A catalog
+	// replaces another one only when it is published later.
+	Published time.Time `json:"published"`
+	// Releases are the known releases of ArangoDB, see WithReleases
+	Releases []VersionString `json:"releases,omitempty"`
+	// SkipVersions are releases with known defects that must not be
+	// upgraded to, see WithSkipVersions
+	SkipVersions []VersionString `json:"skipVersions,omitempty"`
+	// BreakingChanges are the breaking changes, ordered by version
+	BreakingChanges []BreakingChange `json:"breakingChanges,omitempty"`
+	// ResourceHints are the resource hints, ordered by version
+	ResourceHints []ResourceHint `json:"resourceHints,omitempty"`
+}
+
+// EmbeddedReleaseCatalog returns the catalog compiled into this library.
+func EmbeddedReleaseCatalog() ReleaseCatalog {
+	return ReleaseCatalog{
+		Format:          ReleaseCatalogFormat,
+		Published:       embeddedReleaseCatalogPublished,
+		BreakingChanges: AllBreakingChanges(),
+		ResourceHints:   AllResourceHints(),
+	}
+}
+
+// ReadReleaseCatalog reads a catalog in its JSON representation and
+// validates it.
+func ReadReleaseCatalog(r io.Reader) (ReleaseCatalog, error) {
+	var c ReleaseCatalog
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return ReleaseCatalog{}, err
+	}
+	if err := c.Validate(); err != nil {
+		return ReleaseCatalog{}, err
+	}
+	return c, nil
+}
+
+// Validate returns an error when the catalog cannot be used by this
+// version of the library.
+func (c ReleaseCatalog) Validate() error {
+	if c.Format < 1 || c.Format > ReleaseCatalogFormat {
+		return fmt.Errorf("Unsupported release catalog format %d, expected 1 to %d", c.Format, ReleaseCatalogFormat)
+	}
+	if c.Published.IsZero() {
+		return fmt.Errorf("Release catalog has no publication time")
+	}
+	return nil
+}
+
+// NewerThan returns true when c was published after other.
+func (c ReleaseCatalog) NewerThan(other ReleaseCatalog) bool {
+	return c.Published.After(other.Published)
+}
+
+// BreakingChangesOf returns the breaking changes of the catalog introduced
+// by the minor versions an upgrade from `from` to `to` enters, like
+// BreakingChanges does for the embedded ones.
+func (c ReleaseCatalog) BreakingChangesOf(from, to ParsedVersion) []BreakingChange {
+	var result []BreakingChange
+	for _, b := range c.BreakingChanges {
+		if versionBefore(from, b.Major, b.Minor) && !versionBefore(to, b.Major, b.Minor) {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// WithReleaseCatalog uses the data of the given catalog instead of the
+// embedded one: its resource hints replace the embedded ones, its releases
+// are added to the known releases and its skipped versions are denied.
+func WithReleaseCatalog(c ReleaseCatalog) Option {
+	return func(o *options) {
+		o.catalog = &c
+		if len(c.Releases) > 0 {
+			WithReleases(c.Releases...)(o)
+		}
+		if len(c.SkipVersions) > 0 {
+			WithSkipVersions(c.SkipVersions...)(o)
+		}
+	}
+}
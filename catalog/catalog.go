@@ -0,0 +1,163 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package catalog keeps an upgraderules.ReleaseCatalog up to date between
+// releases of the library, by downloading newer catalogs into a cache
+// directory.
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+const (
+	// FileName is the name of the cached catalog in the cache directory
+	FileName = "catalog.json"
+
+	// maxCatalogSize limits the size of a downloaded catalog
+	maxCatalogSize = 4 << 20
+)
+
+// Updater downloads catalogs from a URL into a cache directory.
+// A catalog is only used when it is valid for this version of the library
+// and published after both the embedded catalog and the cached one, so
+// a stale or rolled back download never replaces newer data.
+// It is safe for concurrent use by a single process.
+type Updater struct {
+	// URL of the catalog in its JSON representation
+	URL string
+	// CacheDir is the directory holding the cached catalog
+	CacheDir string
+	// Client is the HTTP client, http.DefaultClient if nil
+	Client *http.Client
+}
+
+// Load returns the newest usable catalog: the cached one when it is valid
+// and newer than the embedded one, otherwise the embedded one.
+func (u *Updater) Load() upgraderules.ReleaseCatalog {
+	embedded := upgraderules.EmbeddedReleaseCatalog()
+	f, err := os.Open(u.path())
+	if err != nil {
+		return embedded
+	}
+	defer f.Close()
+	cached, err := upgraderules.ReadReleaseCatalog(f)
+	if err != nil || !cached.NewerThan(embedded) {
+		return embedded
+	}
+	return cached
+}
+
+// Option returns an option using the catalog returned by Load.
+func (u *Updater) Option() upgraderules.Option {
+	return upgraderules.WithReleaseCatalog(u.Load())
+}
+
+// Update downloads the catalog and stores it in the cache directory when
+// it is newer than the catalog returned by Load. It returns the newest
+// usable catalog and whether it was updated. Failed downloads caused by
+// the network or a server error (5xx) are retryable, see
+// upgraderules.IsRetryable.
+func (u *Updater) Update(ctx context.Context) (upgraderules.ReleaseCatalog, bool, error) {
+	current := u.Load()
+	data, err := u.download(ctx)
+	if err != nil {
+		return current, false, err
+	}
+	downloaded, err := upgraderules.ReadReleaseCatalog(bytes.NewReader(data))
+	if err != nil {
+		return current, false, fmt.Errorf("Invalid catalog at %s: %w", u.URL, err)
+	}
+	if !downloaded.NewerThan(current) {
+		return current, false, nil
+	}
+	if err := u.store(data); err != nil {
+		return current, false, err
+	}
+	return downloaded, true, nil
+}
+
+// download returns the catalog at the URL.
+func (u *Updater) download(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, upgraderules.MarkRetryable(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("GET %s returned %s", u.URL, resp.Status)
+		if resp.StatusCode >= 500 {
+			return nil, upgraderules.MarkRetryable(err)
+		}
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCatalogSize+1))
+	if err != nil {
+		return nil, upgraderules.MarkRetryable(err)
+	}
+	if len(data) > maxCatalogSize {
+		return nil, fmt.Errorf("Catalog at %s exceeds %d bytes", u.URL, maxCatalogSize)
+	}
+	return data, nil
+}
+
+// store writes the catalog to the cache directory, replacing the cached
+// one atomically so concurrent readers never see a partial file.
+func (u *Updater) store(data []byte) error {
+	if err := os.MkdirAll(u.CacheDir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(u.CacheDir, FileName+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), u.path())
+}
+
+// path returns the path of the cached catalog.
+func (u *Updater) path() string {
+	return filepath.Join(u.CacheDir, FileName)
+}
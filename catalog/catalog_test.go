@@ -0,0 +1,106 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package catalog
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestUpdater(t *testing.T) {
+	body := `{"format":1,"published":"2026-11-01T00:00:00Z","skipVersions":["3.12.2"]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+	u := &Updater{URL: server.URL, CacheDir: filepath.Join(t.TempDir(), "cache")}
+
+	if c := u.Load(); !c.Published.Equal(upgraderules.EmbeddedReleaseCatalog().Published) {
+		t.Errorf("Expected the embedded catalog without cache, got %+v", c)
+	}
+	c, updated, err := u.Update(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated || len(c.SkipVersions) != 1 {
+		t.Errorf("Expected the downloaded catalog, got %+v (updated %t)", c, updated)
+	}
+	if c := u.Load(); len(c.SkipVersions) != 1 {
+		t.Errorf("Expected the cached catalog, got %+v", c)
+	}
+	if d := upgraderules.Check("3.12.1", "3.12.2", u.Option()); d.Rule != upgraderules.RuleSkippedVersion {
+		t.Errorf("Expected the catalog to deny 3.12.2, got %v", d.Err)
+	}
+
+	// An older catalog does not replace the cached one
+	body = `{"format":1,"published":"2026-10-15T00:00:00Z"}`
+	if c, updated, err := u.Update(context.Background()); err != nil || updated || len(c.SkipVersions) != 1 {
+		t.Errorf("Expected the cached catalog to be kept, got %+v (updated %t, %v)", c, updated, err)
+	}
+	// Neither does a catalog in a newer format
+	body = `{"format":99,"published":"2026-12-01T00:00:00Z"}`
+	if _, updated, err := u.Update(context.Background()); err == nil || updated {
+		t.Errorf("Expected an unsupported format to fail, got updated %t, %v", updated, err)
+	}
+	if c := u.Load(); len(c.SkipVersions) != 1 {
+		t.Errorf("Expected the cached catalog, got %+v", c)
+	}
+}
+
+func TestUpdaterIgnoresInvalidCache(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, FileName), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	u := &Updater{URL: "http://127.0.0.1:0", CacheDir: dir}
+	if c := u.Load(); !c.Published.Equal(upgraderules.EmbeddedReleaseCatalog().Published) {
+		t.Errorf("Expected the embedded catalog, got %+v", c)
+	}
+	if _, updated, err := u.Update(context.Background()); err == nil || updated {
+		t.Errorf("Expected the download to fail, got updated %t, %v", updated, err)
+	}
+}
+
+func TestUpdaterRetryableErrors(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	u := &Updater{URL: server.URL, CacheDir: t.TempDir()}
+	if _, _, err := u.Update(context.Background()); err == nil || !upgraderules.IsRetryable(err) {
+		t.Errorf("Expected a server error to be retryable, got %v", err)
+	}
+	status = http.StatusNotFound
+	if _, _, err := u.Update(context.Background()); err == nil || upgraderules.IsRetryable(err) {
+		t.Errorf("Expected a missing catalog not to be retryable, got %v", err)
+	}
+	u.URL = "http://127.0.0.1:0"
+	if _, _, err := u.Update(context.Background()); err == nil || !upgraderules.IsRetryable(err) {
+		t.Errorf("Expected a network error to be retryable, got %v", err)
+	}
+}
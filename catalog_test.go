@@ -0,0 +1,72 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadReleaseCatalog(t *testing.T) {
+	c, err := ReadReleaseCatalog(strings.NewReader(`{"format":1,"published":"2026-11-01T00:00:00Z","releases":["3.12.1","3.12.2"],"skipVersions":["3.12.2"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.NewerThan(EmbeddedReleaseCatalog()) || len(c.Releases) != 2 || len(c.SkipVersions) != 1 {
+		t.Errorf("Unexpected catalog %+v", c)
+	}
+	for _, invalid := range []string{
+		`{"format":2,"published":"2026-11-01T00:00:00Z"}`,
+		`{"format":1}`,
+		`{"format":`,
+	} {
+		if _, err := ReadReleaseCatalog(strings.NewReader(invalid)); err == nil {
+			t.Errorf("Expected %s to be invalid", invalid)
+		}
+	}
+}
+
+func TestWithReleaseCatalog(t *testing.T) {
+	c := ReleaseCatalog{
+		Format:        ReleaseCatalogFormat,
+		Published:     time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		Releases:      []VersionString{"3.12.1", "3.12.2", "3.12.3"},
+		SkipVersions:  []VersionString{"3.12.2"},
+		ResourceHints: []ResourceHint{{Major: 3, Minor: 12, Kind: ResourceDisk, Summary: "More disk"}},
+	}
+	if d := Check("3.12.1", "3.12.2", WithReleaseCatalog(c)); d.Rule != RuleSkippedVersion {
+		t.Errorf("Expected skipped version to be denied, got %v", d.Err)
+	}
+	d := Check("3.11.8", "3.12.1", WithReleaseCatalog(c))
+	if len(d.ResourceHints) != 1 || d.ResourceHints[0].Summary != "More disk" {
+		t.Errorf("Expected the resource hints of the catalog, got %v", d.ResourceHints)
+	}
+	if d := Check("3.6.1", "3.7.1", WithSoft(), WithReleaseCatalog(c)); len(d.ResourceHints) != 0 {
+		t.Errorf("Expected the catalog to replace the embedded hints, got %v", d.ResourceHints)
+	}
+	if d := Check("3.6.1", "3.7.1", WithSoft(), WithReleaseCatalog(EmbeddedReleaseCatalog())); len(d.ResourceHints) != 1 {
+		t.Errorf("Expected the embedded hints, got %v", d.ResourceHints)
+	}
+	if changes := EmbeddedReleaseCatalog().BreakingChangesOf(ParseVersion("3.11.8"), ParseVersion("3.12.1")); len(changes) != 1 {
+		t.Errorf("Expected 1 breaking change, got %v", changes)
+	}
+}
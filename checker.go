@@ -0,0 +1,63 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// Checker decides about upgrades of ArangoDB deployments.
+// It is implemented by the rules (see NewChecker) and by CachedChecker.
+// Code that depends on a Checker can be tested with the fake in the
+// upgraderulestest package.
+type Checker interface {
+	// Check returns the decision for upgrading from `from` to `to`,
+	// without checking licenses.
+	Check(from, to VersionString) Decision
+	// CheckWithLicense returns the decision for upgrading from `from` to `to`,
+	// including the given licenses in the check.
+	CheckWithLicense(from, to VersionString, fromLicense, toLicense License) Decision
+}
+
+var (
+	_ Checker = ruleChecker{}
+	_ Checker = &CachedChecker{}
+)
+
+// NewChecker returns a Checker evaluating the rules with the given options.
+func NewChecker(opts ...Option) Checker {
+	return ruleChecker{opts: opts}
+}
+
+// ruleChecker implements Checker using Check.
+type ruleChecker struct {
+	opts []Option
+}
+
+// Check returns the decision for upgrading from `from` to `to`,
+// without checking licenses.
+func (c ruleChecker) Check(from, to VersionString) Decision {
+	return Check(from, to, c.opts...)
+}
+
+// CheckWithLicense returns the decision for upgrading from `from` to `to`,
+// including the given licenses in the check.
+func (c ruleChecker) CheckWithLicense(from, to VersionString, fromLicense, toLicense License) Decision {
+	o := defaultOptions()
+	o.setLicenses(fromLicense, toLicense)
+	return check(ParseVersion(from), ParseVersion(to), o, c.opts)
+}
@@ -0,0 +1,39 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestNewChecker(t *testing.T) {
+	c := NewChecker(WithSoft())
+	if d := c.Check("3.3.8", "3.5.0"); !d.Allowed() || !d.Soft {
+		t.Errorf("Expected soft 3.3.8 -> 3.5.0 to be allowed, got %s", d.Err)
+	}
+	d := c.CheckWithLicense("3.3.8", "3.5.0", LicenseEnterprise, LicenseCommunity)
+	if d.Allowed() || d.Rule != RuleEditionDowngrade || !d.Soft {
+		t.Errorf("Expected denial by %s, got %s (%s)", RuleEditionDowngrade, d.Outcome(), d.Rule)
+	}
+	if d := c.CheckWithLicense("3.3.8", "3.5.0", LicenseCommunity, LicenseEnterprise); !d.Allowed() {
+		t.Errorf("Expected C->E to be allowed, got %s", d.Err)
+	}
+}
@@ -0,0 +1,185 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package cloudevents publishes upgrade decisions as CloudEvents
+// (version 1.0, structured JSON mode) to an HTTP endpoint or a channel.
+package cloudevents
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+const (
+	// SpecVersion is the CloudEvents specification version of the events
+	SpecVersion = "1.0"
+	// TypePrefix is the prefix of the type of the events.
+	// It is followed by the outcome of the decision, e.g.
+	// "com.arangodb.upgrade.decision.denied".
+	TypePrefix = "com.arangodb.upgrade.decision."
+	// ContentType is the content type of structured mode events
+	ContentType = "application/cloudevents+json"
+)
+
+// Event is a CloudEvent in structured JSON mode.
+type Event struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// Sink delivers events.
+type Sink interface {
+	// Send delivers the given event.
+	Send(ctx context.Context, ev Event) error
+}
+
+// Emitter publishes every decision it records as an Event.
+// It implements upgraderules.AuditSink, so register it using
+// upgraderules.WithAuditSink.
+type Emitter struct {
+	source string
+	sink   Sink
+}
+
+var _ upgraderules.AuditSink = &Emitter{}
+
+// NewEmitter creates an Emitter that sends events with the given
+// source (a URI reference identifying the emitting system) to the
+// given sink.
+func NewEmitter(source string, sink Sink) *Emitter {
+	return &Emitter{
+		source: source,
+		sink:   sink,
+	}
+}
+
+// Record publishes the given decision.
+func (e *Emitter) Record(d upgraderules.Decision) error {
+	ev, err := e.NewEvent(d)
+	if err != nil {
+		return err
+	}
+	return e.sink.Send(context.Background(), ev)
+}
+
+// NewEvent creates the event for the given decision.
+// Its data is the JSON encoding of the decision.
+func (e *Emitter) NewEvent(d upgraderules.Decision) (Event, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return Event{}, err
+	}
+	id, err := newID()
+	if err != nil {
+		return Event{}, err
+	}
+	t := d.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return Event{
+		SpecVersion:     SpecVersion,
+		ID:              id,
+		Source:          e.source,
+		Type:            TypePrefix + string(d.Outcome()),
+		Subject:         fmt.Sprintf("%s->%s", d.From, d.To),
+		Time:            t,
+		DataContentType: "application/json",
+		Data:            data,
+	}, nil
+}
+
+// newID returns a random event ID.
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// HTTPSink sends events to an HTTP endpoint using POST requests.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates a sink that sends events to the given URL.
+// If client is nil, a client with a 10 second timeout is used.
+func NewHTTPSink(url string, client *http.Client) *HTTPSink {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPSink{
+		url:    url,
+		client: client,
+	}
+}
+
+// Send posts the given event. Any response status outside of the
+// 2xx range is considered a failure.
+func (s *HTTPSink) Send(ctx context.Context, ev Event) error {
+	encoded, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ContentType)
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("CloudEvents endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ChannelSink sends events to a channel.
+// Send blocks until the event is received or the context is done.
+type ChannelSink chan<- Event
+
+// Send sends the given event to the channel.
+func (s ChannelSink) Send(ctx context.Context, ev Event) error {
+	select {
+	case s <- ev:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
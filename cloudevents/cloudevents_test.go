@@ -0,0 +1,82 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package cloudevents
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestChannelSink(t *testing.T) {
+	ch := make(chan Event, 1)
+	e := NewEmitter("/operators/eu-1", ChannelSink(ch))
+	upgraderules.Check("3.10.8", "3.12.1", upgraderules.WithAuditSink(e))
+
+	ev := <-ch
+	if ev.SpecVersion != SpecVersion || ev.Source != "/operators/eu-1" || ev.ID == "" {
+		t.Errorf("Unexpected event attributes %+v", ev)
+	}
+	if ev.Type != "com.arangodb.upgrade.decision.denied" {
+		t.Errorf("Unexpected type %s", ev.Type)
+	}
+	if ev.Subject != "3.10.8->3.12.1" {
+		t.Errorf("Unexpected subject %s", ev.Subject)
+	}
+	var data struct {
+		Rule string `json:"rule"`
+	}
+	if err := json.Unmarshal(ev.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Rule != string(upgraderules.RuleMinorIncrement) {
+		t.Errorf("Unexpected data %s", ev.Data)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var received []Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != ContentType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		var ev Event
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, ev)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	e := NewEmitter("/operators/eu-1", NewHTTPSink(srv.URL, nil))
+	if err := e.Record(upgraderules.Check("3.11.8", "3.12.1")); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 1 || received[0].Type != "com.arangodb.upgrade.decision.allowed" {
+		t.Errorf("Unexpected received events %+v", received)
+	}
+}
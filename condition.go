@@ -0,0 +1,81 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// ConditionTypeUpgradeAllowed is the condition type used by NewCondition.
+const ConditionTypeUpgradeAllowed = "UpgradeAllowed"
+
+// ConditionStatus is the status of a condition.
+// It has the same values as metav1.ConditionStatus.
+type ConditionStatus string
+
+const (
+	// ConditionTrue means the upgrade is allowed
+	ConditionTrue ConditionStatus = "True"
+	// ConditionFalse means the upgrade is not allowed
+	ConditionFalse ConditionStatus = "False"
+	// ConditionUnknown means it is not known if the upgrade is allowed
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+const (
+	// ReasonUpgradeAllowed is the condition reason for an allowed upgrade
+	ReasonUpgradeAllowed = "UpgradeAllowed"
+	// ReasonUpgradeNotAllowed is the condition reason for an upgrade that is not allowed
+	ReasonUpgradeNotAllowed = "UpgradeNotAllowed"
+)
+
+// Condition describes the outcome of an upgrade check in the shape of a
+// Kubernetes metav1.Condition, so it can be copied into the status of a
+// custom resource field by field.
+type Condition struct {
+	// Type of the condition, always ConditionTypeUpgradeAllowed
+	Type string `json:"type"`
+	// Status of the condition
+	Status ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the resource the check was based upon
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Reason is a CamelCase machine readable reason
+	Reason string `json:"reason"`
+	// Message is a human readable explanation
+	Message string `json:"message"`
+}
+
+// NewCondition converts the result of one of the Check functions into a
+// Condition for a resource with given generation.
+func NewCondition(err error, observedGeneration int64) Condition {
+	if err != nil {
+		return Condition{
+			Type:               ConditionTypeUpgradeAllowed,
+			Status:             ConditionFalse,
+			ObservedGeneration: observedGeneration,
+			Reason:             ReasonUpgradeNotAllowed,
+			Message:            err.Error(),
+		}
+	}
+	return Condition{
+		Type:               ConditionTypeUpgradeAllowed,
+		Status:             ConditionTrue,
+		ObservedGeneration: observedGeneration,
+		Reason:             ReasonUpgradeAllowed,
+		Message:            "Upgrade is allowed",
+	}
+}
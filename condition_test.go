@@ -0,0 +1,44 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestNewCondition(t *testing.T) {
+	c := NewCondition(CheckUpgradeRules("3.2.1", "3.3.0"), 7)
+	if c.Type != ConditionTypeUpgradeAllowed || c.Status != ConditionTrue || c.Reason != ReasonUpgradeAllowed {
+		t.Errorf("Unexpected condition for allowed upgrade: %+v", c)
+	}
+	if c.ObservedGeneration != 7 {
+		t.Errorf("Expected observed generation 7, got %d", c.ObservedGeneration)
+	}
+
+	err := CheckUpgradeRules("3.2.1", "3.4.0")
+	c = NewCondition(err, 8)
+	if c.Status != ConditionFalse || c.Reason != ReasonUpgradeNotAllowed {
+		t.Errorf("Unexpected condition for denied upgrade: %+v", c)
+	}
+	if c.Message != err.Error() {
+		t.Errorf("Expected message '%s', got '%s'", err, c.Message)
+	}
+}
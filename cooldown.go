@@ -0,0 +1,126 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"time"
+)
+
+// RuleCooldown denies upgrades that follow a previous upgrade too soon,
+// see WithCooldown.
+const RuleCooldown RuleID = "cooldown"
+
+// Cooldown limits how often a deployment is upgraded.
+// A zero duration disables the corresponding limit.
+type Cooldown struct {
+	// AfterUpgrade is the minimum time between any two upgrades
+	AfterUpgrade time.Duration
+	// BetweenMinors is the minimum time between two upgrades that change
+	// the minor (or major) version, e.g. 30 days for at most one minor
+	// upgrade per 30 days
+	BetweenMinors time.Duration
+}
+
+// IsZero returns true when the cooldown has no limits.
+func (c Cooldown) IsZero() bool {
+	return c == Cooldown{}
+}
+
+// CooldownError is the error of a decision for an upgrade that the rules
+// allow, but that follows a previous upgrade too soon. It is retryable,
+// since the upgrade is allowed after the cooldown.
+type CooldownError struct {
+	// Time at which the upgrade was checked
+	Time time.Time
+	// Next is the earliest time at which the upgrade is allowed
+	Next time.Time
+	// Previous is the upgrade that caused the cooldown
+	Previous HistoryEntry
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Licensed is set when the licenses were included in the check
+	Licensed bool
+	// FromLicense is the license being upgraded from (only if Licensed is set)
+	FromLicense License
+	// ToLicense is the license being upgraded to (only if Licensed is set)
+	ToLicense License
+}
+
+// ErrorCodeCooldown is the code of a CooldownError in its JSON representation.
+const ErrorCodeCooldown = "CooldownActive"
+
+// Error describes when the upgrade is allowed.
+func (e *CooldownError) Error() string {
+	return e.reason() + transitionSuffix(e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+}
+
+// reason describes when the upgrade is allowed, without the transition
+// (see Reason).
+func (e *CooldownError) reason() string {
+	return fmt.Sprintf("Upgrade is not allowed before %s, because of the upgrade from %s to %s at %s",
+		e.Next.Format(time.RFC3339), e.Previous.From, e.Previous.To, e.Previous.Time.Format(time.RFC3339))
+}
+
+// Retryable returns true, see IsRetryable.
+func (e *CooldownError) Retryable() bool {
+	return true
+}
+
+// ruleCooldown implements RuleCooldown.
+var ruleCooldown = Rule{
+	ID:      RuleCooldown,
+	Applies: func(in RuleInput) bool { return !in.Cooldown.IsZero() && in.changesVersion() },
+	Check: func(in RuleInput) error {
+		return checkCooldown(in.Cooldown, in.History, TransitionOf(in.From, in.To), in.Time)
+	},
+}
+
+// checkCooldown returns a *CooldownError when an upgrade of the given
+// kind at `now` is too soon after one of the given past upgrades.
+// Failed upgrades are ignored.
+func checkCooldown(c Cooldown, history []HistoryEntry, kind TransitionKind, now time.Time) error {
+	var next time.Time
+	var previous HistoryEntry
+	for _, u := range history {
+		if u.Outcome == UpgradeFailed {
+			continue
+		}
+		limit := c.AfterUpgrade
+		if kind == TransitionMinor || kind == TransitionMajor {
+			if k := TransitionOf(ParseVersion(u.From), ParseVersion(u.To)); (k == TransitionMinor || k == TransitionMajor) && c.BetweenMinors > limit {
+				limit = c.BetweenMinors
+			}
+		}
+		if limit == 0 {
+			continue
+		}
+		if t := u.Time.Add(limit); t.After(now) && t.After(next) {
+			next, previous = t, u
+		}
+	}
+	if next.IsZero() {
+		return nil
+	}
+	return &CooldownError{Time: now, Next: next, Previous: previous}
+}
@@ -0,0 +1,84 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithCooldown(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	history := WithHistory(
+		HistoryEntry{From: "3.10.8", To: "3.11.0", Time: now.AddDate(0, 0, -20)},
+		HistoryEntry{From: "3.11.0", To: "3.11.8", Time: now.Add(-2 * time.Hour)},
+	)
+	cooldown := WithCooldown(Cooldown{AfterUpgrade: 24 * time.Hour, BetweenMinors: 30 * 24 * time.Hour})
+
+	d := Check("3.11.8", "3.12.1", clock, history, cooldown)
+	e, ok := d.Err.(*CooldownError)
+	if d.Rule != RuleCooldown || !ok || !IsRetryable(d.Err) {
+		t.Fatalf("Expected retryable denial by %s, got %s (%v)", RuleCooldown, d.Rule, d.Err)
+	}
+	if !e.Next.Equal(now.AddDate(0, 0, 10)) || e.Previous.To != "3.11.0" {
+		t.Errorf("Expected cooldown of minor upgrade, got %+v", e)
+	}
+	if msg := e.Error(); msg != "Upgrade is not allowed before 2026-10-11T12:00:00Z, because of the upgrade from 3.10.8 to 3.11.0 at 2026-09-11T12:00:00Z (from 3.11.8 to 3.12.1)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	// Patch upgrades only wait for the cooldown after any upgrade
+	d = Check("3.11.8", "3.11.9", clock, history, cooldown)
+	if e, ok := d.Err.(*CooldownError); !ok || !e.Next.Equal(now.Add(22*time.Hour)) {
+		t.Errorf("Expected cooldown after previous upgrade, got %v", d.Err)
+	}
+	now = now.Add(22 * time.Hour)
+	if d := Check("3.11.8", "3.11.9", clock, history, cooldown); !d.Allowed() {
+		t.Errorf("Expected upgrade after cooldown to be allowed, got %s", d.Err)
+	}
+	if d := Check("3.11.8", "3.11.8", clock, history, cooldown); !d.Allowed() {
+		t.Errorf("Expected unchanged version to be allowed, got %s", d.Err)
+	}
+	if d := Check("3.11.8", "3.12.1", clock, history); !d.Allowed() {
+		t.Errorf("Expected no cooldown without WithCooldown, got %s", d.Err)
+	}
+	failed := WithHistory(HistoryEntry{From: "3.11.8", To: "3.11.9", Time: now.Add(-time.Hour), Outcome: UpgradeFailed})
+	if d := Check("3.11.8", "3.11.9", clock, history, failed, cooldown); !d.Allowed() {
+		t.Errorf("Expected failed upgrades not to start a cooldown, got %s", d.Err)
+	}
+}
+
+func TestMergePoliciesCooldown(t *testing.T) {
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org", Policy: Policy{Cooldown: Cooldown{AfterUpgrade: time.Hour, BetweenMinors: 7 * 24 * time.Hour}}},
+		PolicyLayer{Name: "db", Policy: Policy{Cooldown: Cooldown{AfterUpgrade: 24 * time.Hour}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := e.Policy.Cooldown; c.AfterUpgrade != 24*time.Hour || c.BetweenMinors != 7*24*time.Hour {
+		t.Errorf("Expected longest durations, got %+v", c)
+	}
+	if s := e.Sources[PolicyFieldCooldown]; len(s) != 2 {
+		t.Errorf("Expected cooldown from both layers, got %v", s)
+	}
+}
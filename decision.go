@@ -0,0 +1,422 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"time"
+)
+
+// RuleID identifies a rule that can deny an upgrade.
+type RuleID string
+
+const (
+	// RuleMajorVersion denies changing the major version
+	RuleMajorVersion RuleID = "major-version"
+	// RuleMinorIncrement denies changing the minor version by anything else than +1
+	RuleMinorIncrement RuleID = "minor-increment"
+	// RuleMinorDowngrade denies lowering the minor version (soft rules)
+	RuleMinorDowngrade RuleID = "minor-downgrade"
+	// RuleMaxMinorSkip denies increasing the minor version by more than
+	// allowed by WithMaxMinorSkip (soft rules)
+	RuleMaxMinorSkip RuleID = "max-minor-skip"
+	// RuleEditionDowngrade denies going from the Enterprise to the Community edition
+	// (or any license transition not allowed by the LicenseMatrix)
+	RuleEditionDowngrade RuleID = "edition-downgrade"
+)
+
+// Decision is the outcome of checking an upgrade.
+type Decision struct {
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Licensed is set when the licenses were included in the check
+	Licensed bool
+	// FromLicense is the license being upgraded from (only if Licensed is set)
+	FromLicense License
+	// ToLicense is the license being upgraded to (only if Licensed is set)
+	ToLicense License
+	// Soft is set when the soft rules were used
+	Soft bool
+	// Rule is the rule that denied the upgrade (empty if allowed)
+	Rule RuleID
+	// Err describes why the upgrade is not allowed (nil if allowed).
+	// It always identifies the transition: errors that do not describe
+	// it themselves are wrapped in a *TransitionError.
+	Err error
+	// Override is the override that allowed an upgrade that the
+	// version rules would have denied
+	Override *Override
+	// Requester identifies who requested the upgrade (see WithRequester)
+	Requester string
+	// Time is the time at which the decision was made
+	Time time.Time
+	// Trace is the evaluation tree of the decision (only with WithTrace)
+	Trace *Trace
+	// Warnings contains advisories about the upgrade, which do not deny it
+	Warnings []Warning
+	// Impact is the expected impact of the upgrade, see ImpactOf
+	// (zero if not allowed)
+	Impact Impact
+	// UnmetPreconditions contains the preconditions that were not met,
+	// in which case Err is a *PreconditionError (see WithPrecondition)
+	UnmetPreconditions []UnmetPrecondition
+	// Notes contains the operator notes about the upgrade, see WithUpgradeNotes
+	Notes []UpgradeNote
+	// ResourceHints contains the changes of the resource needs introduced
+	// by the minor versions the upgrade enters, see ResourceHints
+	ResourceHints []ResourceHint
+}
+
+// Outcome summarizes a Decision.
+type Outcome string
+
+const (
+	// OutcomeAllowed means the rules allow the upgrade
+	OutcomeAllowed Outcome = "allowed"
+	// OutcomeDenied means the rules do not allow the upgrade
+	OutcomeDenied Outcome = "denied"
+	// OutcomeOverridden means the upgrade is allowed by an override
+	OutcomeOverridden Outcome = "overridden"
+	// OutcomeNoOp means the upgrade is allowed, but changes nothing,
+	// see Decision.IsNoOp
+	OutcomeNoOp Outcome = "no-op"
+)
+
+// Allowed returns true if the upgrade is allowed.
+func (d Decision) Allowed() bool {
+	return d.Err == nil
+}
+
+// Outcome returns the outcome of the decision.
+func (d Decision) Outcome() Outcome {
+	switch {
+	case d.Err != nil:
+		return OutcomeDenied
+	case d.Override != nil:
+		return OutcomeOverridden
+	case d.IsNoOp():
+		return OutcomeNoOp
+	default:
+		return OutcomeAllowed
+	}
+}
+
+// IsNoOp returns true when the decision is about an upgrade that changes
+// nothing: the versions are identical, and so are the licenses if they
+// were included. Reconcilers can skip such upgrades.
+func (d Decision) IsNoOp() bool {
+	return IsNoOp(d.From, d.To) && (!d.Licensed || d.FromLicense == d.ToLicense)
+}
+
+// IsNoOp returns true when upgrading from `from` to `to` does not change
+// the version, e.g. "3.12.1" to "3.12.1". A pre-release and the release
+// of the same patch (e.g. "3.12.0-rc.1" to "3.12.0") are different versions.
+func IsNoOp(from, to VersionString) bool {
+	f, t := ParseVersion(from), ParseVersion(to)
+	return f.major == t.major && f.minor == t.minor && f.sub == t.sub
+}
+
+// DecisionRecorder is notified of every decision made by the Check functions.
+// See WithDecisionRecorder.
+type DecisionRecorder interface {
+	// RecordDecision is called once for every decision.
+	RecordDecision(d Decision)
+}
+
+// AuditSink stores decisions for auditing purposes.
+// See WithAuditSink and the audit package for implementations.
+type AuditSink interface {
+	// Record stores the given decision.
+	Record(d Decision) error
+}
+
+// Metrics receives measurements of rule evaluation.
+// See WithMetrics and the metrics package for a Prometheus implementation.
+type Metrics interface {
+	// ObserveCheck is called once for every check with its decision and
+	// the time it took to evaluate.
+	ObserveCheck(d Decision, duration time.Duration)
+}
+
+// RuleMetrics can be implemented by a Metrics implementation to also
+// receive measurements of every individual rule evaluation.
+type RuleMetrics interface {
+	// ObserveRule is called once for every evaluated rule with its result
+	// and the time it took to evaluate. A nil error means the rule allowed
+	// the upgrade, an *Error means it denied it; any other error means the
+	// rule failed to evaluate.
+	ObserveRule(rule RuleID, err error, duration time.Duration)
+}
+
+// Tracer is informed about the start and end of checks and the rules
+// evaluated by them. See WithTracer and the tracing package for an
+// OpenTelemetry implementation.
+type Tracer interface {
+	// StartCheck is called when a check starts.
+	// It returns the context for the rules of the check and a function
+	// that is called with the decision when the check has finished.
+	StartCheck(ctx context.Context, from, to VersionString) (context.Context, func(Decision))
+	// StartRule is called when the evaluation of a rule starts.
+	// It returns a function that is called with the result of the rule
+	// when its evaluation has finished.
+	StartRule(ctx context.Context, rule RuleID) func(error)
+}
+
+// Logger writes structured log records.
+// When set using WithLogger, one record is written for every decision.
+// See NewSlogLogger for an implementation using log/slog.
+type Logger interface {
+	// Info writes an informational record with given message and key/value pairs.
+	Info(ctx context.Context, msg string, keysAndValues ...interface{})
+	// Warn writes a warning record with given message and key/value pairs.
+	Warn(ctx context.Context, msg string, keysAndValues ...interface{})
+}
+
+// Check evaluates the rules for an upgrade of an ArangoDB deployment
+// from given `from` version to given `to` version.
+// By default the strict rules are used and licenses are not part
+// of the check, see WithSoft and WithLicenses.
+func Check(from, to VersionString, opts ...Option) Decision {
+	return check(ParseVersion(from), ParseVersion(to), defaultOptions(), opts)
+}
+
+// CheckParsed is Check for versions that have already been parsed.
+func CheckParsed(from, to ParsedVersion, opts ...Option) Decision {
+	return check(from, to, defaultOptions(), opts)
+}
+
+// check implements Check with the given options applied on top of base.
+// Without options, and without a trace, an allowed upgrade does not allocate.
+func check(pfrom, pto ParsedVersion, o options, opts []Option) Decision {
+	from, to := pfrom.version, pto.version
+	var policyErr error
+	if len(opts) > 0 {
+		applied := o.apply(opts)
+		if applied.policyResolver != nil {
+			applied, policyErr = resolvePolicy(o, applied, opts, pfrom, pto)
+		}
+		o = applied
+	}
+	var start time.Time
+	if len(o.metrics) > 0 {
+		start = time.Now()
+	}
+	ctx, endCheck := o.ctx, func(Decision) {}
+	if o.tracer != nil {
+		ctx, endCheck = o.tracer.StartCheck(ctx, from, to)
+	}
+	d := Decision{
+		From:        from,
+		To:          to,
+		Licensed:    o.licensed,
+		FromLicense: o.fromLicense,
+		ToLicense:   o.toLicense,
+		Soft:        o.soft,
+		Requester:   o.requester,
+		Time:        o.now(),
+		Warnings:    warnings(pfrom, pto, o.releases),
+	}
+	if o.trace {
+		d.Trace = &Trace{}
+	}
+	var rs *RuleSet
+	if policyErr != nil {
+		// Nothing is evaluated without the policy
+		rs, d.Err = NewRuleSet(), policyErr
+	} else {
+		rs = o.ruleSetFor(pfrom)
+	}
+	in := RuleInput{
+		Context:             ctx,
+		From:                pfrom,
+		To:                  pto,
+		Soft:                o.soft,
+		Licensed:            o.licensed,
+		FromLicense:         o.fromLicense,
+		ToLicense:           o.toLicense,
+		LicenseMatrix:       o.licenseMatrix,
+		MaxMinorSkip:        o.maxMinorSkip,
+		Time:                d.Time,
+		Warnings:            d.Warnings,
+		WarningsAsErrors:    o.promotedWarnings,
+		Intermediates:       o.intermediates,
+		AllowPreRelease:     o.allowPreRelease,
+		AllowGAToPreRelease: o.allowGAToPre,
+		SkipVersions:        o.skipVersions,
+		Architectures:       o.architectures,
+		RequiredFixes:       o.requiredFixes,
+		DenyByDefault:       o.denyByDefault,
+		AllowList:           o.allowList,
+		Channels:            o.channels,
+		MaintenanceWindows:  o.windows,
+		Cooldown:            o.cooldown,
+		History:             o.history,
+		preconditions:       o.preconditions,
+		deployment:          o.deployment,
+	}
+	hooked := len(rs.hooks) > 0
+	if hooked {
+		in = rs.beforeCheck(in)
+	}
+	for _, r := range rs.rules {
+		if !r.applies(in) {
+			continue
+		}
+		in.Trace = d.Trace.startRule(r.ID)
+		t := in.Trace
+		var err error
+		if hooked {
+			err = rs.evaluate(&o, r, in)
+		} else {
+			err = o.evaluate(r, in)
+		}
+		if err == nil {
+			t.finish(nil, false)
+			continue
+		}
+		if r.Overridable {
+			if x := o.findOverride(from, to, r.ID); x != nil {
+				t.finish(err, true)
+				d.Override = x
+				continue
+			}
+		}
+		t.finish(err, false)
+		d.Rule, d.Err = r.ID, err
+		if e, ok := err.(*Error); ok && r.ID == RuleWarningsAsErrors {
+			// The decision names the promoted warning
+			d.Rule = e.Rule
+		}
+		break
+	}
+	if hooked {
+		in.Trace = nil
+		rs.afterCheck(in, d.Rule, d.Err)
+	}
+	if d.Err == nil {
+		d.Impact = ImpactOf(pfrom, pto)
+	}
+	if len(o.notes) > 0 && pfrom.version != pto.version {
+		d.Notes = upgradeNotes(o.notes, pfrom, pto)
+	}
+	if pfrom.major != pto.major || pfrom.minor != pto.minor {
+		base := resourceHints
+		if o.catalog != nil {
+			base = o.catalog.ResourceHints
+		}
+		d.ResourceHints = decisionResourceHints(base, o.resourceHints, pfrom, pto)
+	}
+	if d.Err != nil {
+		d.Err = annotateError(d.Err, d.Rule, from, to, &o)
+		if e, ok := d.Err.(*Error); ok {
+			e.trace = d.Trace
+			if o.messageTemplates != nil {
+				if err := o.messageTemplates.apply(e); err != nil && o.logger != nil {
+					o.logger.Warn(ctx, "Failed to execute message template", "rule", string(e.Rule), "error", err.Error())
+				}
+			}
+		}
+		if e, ok := d.Err.(*PreconditionError); ok {
+			d.UnmetPreconditions = e.Unmet
+		}
+	}
+	endCheck(d)
+	if len(o.metrics) > 0 {
+		duration := time.Since(start)
+		for _, m := range o.metrics {
+			m.ObserveCheck(d, duration)
+		}
+	}
+	if o.logger != nil {
+		logDecision(ctx, o.logger, d)
+	}
+	for _, r := range o.recorders {
+		r.RecordDecision(d)
+	}
+	for _, s := range o.auditSinks {
+		if err := s.Record(d); err != nil && o.logger != nil {
+			o.logger.Warn(ctx, "Failed to record decision in audit sink", "from", string(from), "to", string(to), "error", err.Error())
+		}
+	}
+	return d
+}
+
+// logDecision writes a single record for the given decision.
+func logDecision(ctx context.Context, l Logger, d Decision) {
+	kv := []interface{}{
+		"from", string(d.From),
+		"to", string(d.To),
+		"soft", d.Soft,
+	}
+	if d.Licensed {
+		kv = append(kv, "from_license", d.FromLicense.String(), "to_license", d.ToLicense.String())
+	}
+	if d.Requester != "" {
+		kv = append(kv, "requester", d.Requester)
+	}
+	kv = append(kv, "outcome", string(d.Outcome()))
+	if d.Err != nil {
+		kv = append(kv, "rule", string(d.Rule), "reason", Reason(d.Err))
+		l.Warn(ctx, "Upgrade not allowed", kv...)
+		return
+	}
+	if d.Override != nil {
+		kv = append(kv, "override", d.Override.String())
+		if d.Override.Approver != "" {
+			kv = append(kv, "override_approver", d.Override.Approver)
+		}
+	}
+	l.Info(ctx, "Upgrade allowed", kv...)
+}
+
+// ruleSetFor returns the rules to evaluate for upgrades from the given version.
+func (o *options) ruleSetFor(from ParsedVersion) *RuleSet {
+	if src := o.majorRuleSets[from.major]; src != nil {
+		return src.Snapshot()
+	}
+	if o.ruleSet != nil {
+		return o.ruleSet.Snapshot()
+	}
+	return DefaultRuleSetFor(from.major)
+}
+
+// evaluate runs a single rule.
+func (o *options) evaluate(r Rule, in RuleInput) error {
+	if o.tracer == nil && len(o.ruleMetrics) == 0 {
+		return r.Check(in)
+	}
+	var endRule func(error)
+	if o.tracer != nil {
+		endRule = o.tracer.StartRule(in.Context, r.ID)
+	}
+	start := time.Now()
+	err := r.Check(in)
+	duration := time.Since(start)
+	for _, m := range o.ruleMetrics {
+		m.ObserveRule(r.ID, err, duration)
+	}
+	if endRule != nil {
+		endRule(err)
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type decisionList []Decision
+
+func (l *decisionList) RecordDecision(d Decision) {
+	*l = append(*l, d)
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		From    VersionString
+		To      VersionString
+		Options []Option
+		Rule    RuleID
+	}{
+		{"3.11.8", "3.12.1", nil, ""},
+		{"3.11.8", "4.0.0", nil, RuleMajorVersion},
+		{"3.10.8", "3.12.1", nil, RuleMinorIncrement},
+		{"3.10.8", "3.12.1", []Option{WithSoft()}, ""},
+		{"3.12.1", "3.11.8", []Option{WithSoft()}, RuleMinorDowngrade},
+		{"3.11.8", "3.12.1", []Option{WithLicenses(LicenseEnterprise, LicenseCommunity)}, RuleEditionDowngrade},
+		{"3.11.8", "3.12.1", []Option{WithLicenses(LicenseCommunity, LicenseEnterprise)}, ""},
+	}
+	for _, test := range tests {
+		d := Check(test.From, test.To, test.Options...)
+		if d.Rule != test.Rule {
+			t.Errorf("%s -> %s: expected rule '%s', got '%s'", test.From, test.To, test.Rule, d.Rule)
+		}
+		if d.Allowed() != (test.Rule == "") {
+			t.Errorf("%s -> %s: expected allowed=%v, got %v (%v)", test.From, test.To, test.Rule == "", d.Allowed(), d.Err)
+		}
+	}
+}
+
+func TestDecisionRecorder(t *testing.T) {
+	var recorded decisionList
+	CheckUpgradeRulesWithLicense("3.11.8", "3.12.1", LicenseEnterprise, LicenseEnterprise, WithDecisionRecorder(&recorded))
+	CheckSoftUpgradeRules("3.12.1", "3.10.8", WithDecisionRecorder(&recorded), WithOverrides(Override{From: "3.12", To: "3.10"}))
+	if len(recorded) != 2 {
+		t.Fatalf("Expected 2 decisions, got %d", len(recorded))
+	}
+	if d := recorded[0]; !d.Allowed() || !d.Licensed || d.FromLicense != LicenseEnterprise || d.Soft {
+		t.Errorf("Unexpected first decision %+v", d)
+	}
+	if d := recorded[1]; !d.Allowed() || d.Override == nil || !d.Soft || d.Licensed {
+		t.Errorf("Unexpected second decision %+v", d)
+	}
+}
+
+type metricsList []Decision
+
+func (l *metricsList) ObserveCheck(d Decision, duration time.Duration) {
+	*l = append(*l, d)
+}
+
+func TestDecisionOutcome(t *testing.T) {
+	var observed metricsList
+	m := WithMetrics(&observed)
+	Check("3.11.8", "3.12.1", m)
+	Check("3.10.8", "3.12.1", m)
+	Check("3.10.8", "3.12.1", m, WithOverrides(Override{From: "3.10", To: "3.12"}))
+	expected := []Outcome{OutcomeAllowed, OutcomeDenied, OutcomeOverridden}
+	if len(observed) != len(expected) {
+		t.Fatalf("Expected %d observed checks, got %d", len(expected), len(observed))
+	}
+	for i, d := range observed {
+		if d.Outcome() != expected[i] {
+			t.Errorf("Check %d: expected outcome %s, got %s", i, expected[i], d.Outcome())
+		}
+	}
+}
+
+type ruleTracer struct {
+	checks int
+	rules  []RuleID
+}
+
+type tracerKey struct{}
+
+func (t *ruleTracer) StartCheck(ctx context.Context, from, to VersionString) (context.Context, func(Decision)) {
+	return context.WithValue(ctx, tracerKey{}, "check"), func(Decision) { t.checks++ }
+}
+
+func (t *ruleTracer) StartRule(ctx context.Context, rule RuleID) func(error) {
+	if ctx.Value(tracerKey{}) != "check" {
+		panic("rule started without check context")
+	}
+	return func(error) { t.rules = append(t.rules, rule) }
+}
+
+func TestTracer(t *testing.T) {
+	var tracer ruleTracer
+	Check("3.12.1", "3.10.8", WithSoft(), WithLicenses(LicenseCommunity, LicenseCommunity), WithTracer(&tracer))
+	if tracer.checks != 1 {
+		t.Errorf("Expected 1 check, got %d", tracer.checks)
+	}
+	expected := []RuleID{RuleEditionDowngrade, RuleMajorVersion, RuleMinorDowngrade}
+	if len(tracer.rules) != len(expected) {
+		t.Fatalf("Expected rules %v, got %v", expected, tracer.rules)
+	}
+	for i, r := range expected {
+		if tracer.rules[i] != r {
+			t.Errorf("Expected rule %d to be %s, got %s", i, r, tracer.rules[i])
+		}
+	}
+}
+
+func TestIsNoOp(t *testing.T) {
+	tests := []struct {
+		From, To VersionString
+		NoOp     bool
+	}{
+		{"3.12.1", "3.12.1", true},
+		{"3.12.1", "3.12.2", false},
+		{"3.12.0-rc.1", "3.12.0", false},
+		{"3.12.0-rc.1", "3.12.0-rc.1", true},
+	}
+	for _, test := range tests {
+		if noOp := IsNoOp(test.From, test.To); noOp != test.NoOp {
+			t.Errorf("IsNoOp(%s, %s): expected %t, got %t", test.From, test.To, test.NoOp, noOp)
+		}
+	}
+}
+
+func TestDecisionNoOp(t *testing.T) {
+	if d := Check("3.12.1", "3.12.1"); !d.IsNoOp() || d.Outcome() != OutcomeNoOp || !d.Allowed() {
+		t.Errorf("Expected allowed no-op, got %s", d.Outcome())
+	}
+	if d := Check("3.12.1", "3.12.1", WithLicenses(LicenseCommunity, LicenseEnterprise)); d.IsNoOp() || d.Outcome() != OutcomeAllowed {
+		t.Errorf("Expected license change not to be a no-op, got %s", d.Outcome())
+	}
+	if d := Check("3.12.0-rc.1", "3.12.0"); d.IsNoOp() || d.Outcome() != OutcomeAllowed {
+		t.Errorf("Expected rc -> GA not to be a no-op, got %s", d.Outcome())
+	}
+}
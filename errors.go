@@ -0,0 +1,286 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Error is the error returned when a rule does not allow an upgrade.
+// Such errors are terminal: checking the same upgrade again will give
+// the same result.
+type Error struct {
+	// Rule is the rule that denied the upgrade
+	Rule RuleID
+	// Message describes why the upgrade is not allowed
+	Message string
+	// MessageID identifies Message independent of its language, see Catalog
+	MessageID MessageID
+	// Args holds the values of the placeholders in the message, if any
+	Args []interface{}
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Licensed is set when the licenses were included in the check
+	Licensed bool
+	// FromLicense is the license being upgraded from (only if Licensed is set)
+	FromLicense License
+	// ToLicense is the license being upgraded to (only if Licensed is set)
+	ToLicense License
+
+	// cause holds the details of the denial, see Unwrap
+	cause error
+	// templated is set when Message comes from a MessageTemplates,
+	// which decides itself whether to include the transition
+	templated bool
+	// trace is the trace of the decision, see Format
+	trace *Trace
+}
+
+// ErrorCodeUpgradeNotAllowed is the code of an Error in its JSON representation.
+const ErrorCodeUpgradeNotAllowed = "UpgradeNotAllowed"
+
+// newCausedError creates a new Error for the given rule, with the English
+// message for the given message ID formatted with the given arguments.
+// The cause holds the details of the denial, which callers retrieve with
+// errors.As (see Error.Unwrap).
+func newCausedError(cause error, rule RuleID, id MessageID, args ...interface{}) error {
+	e := &Error{
+		Rule:      rule,
+		Message:   catalogEnglish[id],
+		MessageID: id,
+		cause:     cause,
+	}
+	if len(args) > 0 {
+		e.Message, e.Args = fmt.Sprintf(e.Message, args...), args
+	}
+	return e
+}
+
+// Error returns the message of the error, followed by the transition
+// that was checked, e.g. "Minor versions may only increment by 1 (from
+// 3.10.8 to 3.12.1)", so the error identifies the upgrade on its own.
+// Messages of a MessageTemplates are returned unchanged.
+func (e *Error) Error() string {
+	if e.templated {
+		return e.Message
+	}
+	return e.Message + transitionSuffix(e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+}
+
+// Reason returns the message of the given error without the transition
+// that Error appends, for output that shows the transition anyway, e.g.
+// a report with columns for the versions. It returns an empty string
+// for a nil error.
+func Reason(err error) string {
+	switch e := err.(type) {
+	case nil:
+		return ""
+	case *Error:
+		return e.Message
+	case interface{ reason() string }:
+		return e.reason()
+	}
+	return err.Error()
+}
+
+// transitionSuffix describes the transition of an error, see Error.Error.
+// It is empty when the versions are not known.
+func transitionSuffix(from, to VersionString, licensed bool, fromLicense, toLicense License) string {
+	switch {
+	case from == "" && to == "":
+		return ""
+	case licensed:
+		return fmt.Sprintf(" (from %s %s to %s %s)", from, fromLicense, to, toLicense)
+	default:
+		return fmt.Sprintf(" (from %s to %s)", from, to)
+	}
+}
+
+// TransitionError adds the checked transition to an error that does not
+// describe it itself, e.g. the error of a custom rule, so every error of a
+// decision identifies the upgrade on its own.
+type TransitionError struct {
+	// Err is the error without the transition
+	Err error
+	// Rule is the rule that returned the error (empty if it was not
+	// returned by a rule)
+	Rule RuleID
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Licensed is set when the licenses were included in the check
+	Licensed bool
+	// FromLicense is the license being upgraded from (only if Licensed is set)
+	FromLicense License
+	// ToLicense is the license being upgraded to (only if Licensed is set)
+	ToLicense License
+}
+
+// Error returns the message of the error, followed by the transition.
+func (e *TransitionError) Error() string {
+	return e.Err.Error() + transitionSuffix(e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+}
+
+// reason returns the message of the error without the transition, see Reason.
+func (e *TransitionError) reason() string {
+	return Reason(e.Err)
+}
+
+// Unwrap returns the error without the transition.
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// annotateError returns a copy of the given error of a check, which
+// describes the transition of the check. The error is copied, so errors
+// returned by rules are never modified. Rule is the rule that returned
+// the error, if any.
+func annotateError(err error, rule RuleID, from, to VersionString, o *options) error {
+	switch e := err.(type) {
+	case *Error:
+		c := *e
+		c.From, c.To = from, to
+		c.Licensed, c.FromLicense, c.ToLicense = o.licensed, o.fromLicense, o.toLicense
+		return &c
+	case *MaintenanceWindowError:
+		c := *e
+		c.From, c.To = from, to
+		c.Licensed, c.FromLicense, c.ToLicense = o.licensed, o.fromLicense, o.toLicense
+		return &c
+	case *CooldownError:
+		c := *e
+		c.From, c.To = from, to
+		c.Licensed, c.FromLicense, c.ToLicense = o.licensed, o.fromLicense, o.toLicense
+		return &c
+	case *PreconditionError:
+		c := *e
+		c.From, c.To = from, to
+		c.Licensed, c.FromLicense, c.ToLicense = o.licensed, o.fromLicense, o.toLicense
+		return &c
+	default:
+		return &TransitionError{Err: err, Rule: rule, From: from, To: to, Licensed: o.licensed, FromLicense: o.fromLicense, ToLicense: o.toLicense}
+	}
+}
+
+// Unwrap returns the details of the denial, e.g. a *MinorSkipError for
+// RuleMinorIncrement, so they can be retrieved with errors.As to build
+// messages with full context. It returns nil for custom rules.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// MajorVersionError details a denial by RuleMajorVersion.
+type MajorVersionError struct {
+	From VersionString
+	To   VersionString
+}
+
+// Error describes the change of major version.
+func (e *MajorVersionError) Error() string {
+	return fmt.Sprintf("Upgrade from %s to %s changes the major version", e.From, e.To)
+}
+
+// MinorSkipError details a denial by RuleMinorIncrement or RuleMaxMinorSkip.
+type MinorSkipError struct {
+	From VersionString
+	To   VersionString
+	// MaxSkip is the largest allowed increase of the minor version
+	MaxSkip int
+}
+
+// Error describes the skipped minor versions.
+func (e *MinorSkipError) Error() string {
+	return fmt.Sprintf("Upgrade from %s to %s increments the minor version by more than %d", e.From, e.To, e.MaxSkip)
+}
+
+// DowngradeError details a denial by RuleMinorDowngrade or RuleGAToPreRelease.
+type DowngradeError struct {
+	From VersionString
+	To   VersionString
+}
+
+// Error describes the downgrade.
+func (e *DowngradeError) Error() string {
+	return fmt.Sprintf("Downgrade from %s to %s is not possible", e.From, e.To)
+}
+
+// LicenseTransitionError details a denial by RuleEditionDowngrade.
+type LicenseTransitionError struct {
+	From License
+	To   License
+}
+
+// Error describes the license transition.
+func (e *LicenseTransitionError) Error() string {
+	return fmt.Sprintf("Changing the license from %s to %s is not allowed", e.From, e.To)
+}
+
+// IsRetryable returns true if the given error is caused by a temporary
+// condition, such that the same check may succeed when tried again later.
+// An error is retryable when it, or an error it wraps (via an
+// `Unwrap() error` method), has a `Retryable() bool` method returning true.
+// All other errors, including all policy denials (*Error) and invalid
+// input, are terminal.
+func IsRetryable(err error) bool {
+	for err != nil {
+		if r, ok := err.(interface{ Retryable() bool }); ok {
+			return r.Retryable()
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// MarkRetryable returns the given error marked as retryable (see
+// IsRetryable), for sources such as an ImageResolver to report temporary
+// failures, e.g. network errors or server errors (5xx). It returns nil for
+// a nil error.
+func MarkRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err}
+}
+
+// markNetworkError marks the given error retryable when it is caused by a
+// network failure (a net.Error), see MarkRetryable.
+func markNetworkError(err error) error {
+	var ne net.Error
+	if errors.As(err, &ne) && !IsRetryable(err) {
+		return MarkRetryable(err)
+	}
+	return err
+}
+
+// IsTerminal returns true if the given error is not nil and not retryable.
+// Reconcile loops should not requeue on terminal errors.
+func IsTerminal(err error) bool {
+	return err != nil && !IsRetryable(err)
+}
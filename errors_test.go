@@ -0,0 +1,188 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type temporaryError struct {
+	retryable bool
+}
+
+func (e temporaryError) Error() string   { return "temporary" }
+func (e temporaryError) Retryable() bool { return e.retryable }
+
+type wrappedError struct {
+	err error
+}
+
+func (e wrappedError) Error() string { return "wrapped: " + e.err.Error() }
+func (e wrappedError) Unwrap() error { return e.err }
+
+func TestIsRetryable(t *testing.T) {
+	_, parseErr := ParseLicense("gold")
+	tests := []struct {
+		Err       error
+		Retryable bool
+		Terminal  bool
+	}{
+		{nil, false, false},
+		{CheckUpgradeRules("3.1.0", "3.3.0"), false, true},
+		{CheckUpgradeRulesWithLicense("3.1.0", "3.1.1", LicenseEnterprise, LicenseCommunity), false, true},
+		{parseErr, false, true},
+		{fmt.Errorf("other"), false, true},
+		{temporaryError{true}, true, false},
+		{temporaryError{false}, false, true},
+		{wrappedError{temporaryError{true}}, true, false},
+		{wrappedError{fmt.Errorf("other")}, false, true},
+	}
+	for _, test := range tests {
+		if r := IsRetryable(test.Err); r != test.Retryable {
+			t.Errorf("IsRetryable(%v): expected %v, got %v", test.Err, test.Retryable, r)
+		}
+		if r := IsTerminal(test.Err); r != test.Terminal {
+			t.Errorf("IsTerminal(%v): expected %v, got %v", test.Err, test.Terminal, r)
+		}
+	}
+}
+
+func TestErrorRule(t *testing.T) {
+	err := CheckSoftUpgradeRules("3.3.0", "3.1.0")
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Expected *Error, got %T", err)
+	}
+	if e.Rule != RuleMinorDowngrade {
+		t.Errorf("Expected rule %s, got %s", RuleMinorDowngrade, e.Rule)
+	}
+}
+
+func TestErrorsAs(t *testing.T) {
+	err := CheckUpgradeRules("3.10.8", "3.12.1")
+	var skip *MinorSkipError
+	if !errors.As(err, &skip) || skip.From != "3.10.8" || skip.To != "3.12.1" || skip.MaxSkip != 1 {
+		t.Errorf("Expected *MinorSkipError, got %#v", skip)
+	}
+	err = CheckSoftUpgradeRules("3.10.8", "3.13.1", WithMaxMinorSkip(2))
+	if !errors.As(err, &skip) || skip.MaxSkip != 2 {
+		t.Errorf("Expected *MinorSkipError with maximum 2, got %#v", skip)
+	}
+	err = CheckUpgradeRulesWithLicense("3.11.8", "3.12.1", LicenseEnterprise, LicenseCommunity)
+	var license *LicenseTransitionError
+	if !errors.As(err, &license) || license.From != LicenseEnterprise || license.To != LicenseCommunity {
+		t.Errorf("Expected *LicenseTransitionError, got %#v", license)
+	}
+	if IsRetryable(err) {
+		t.Error("Expected denial to be terminal")
+	}
+	tests := []struct {
+		Err    error
+		Target interface{}
+	}{
+		{CheckUpgradeRules("3.12.1", "4.0.0"), new(*MajorVersionError)},
+		{CheckSoftUpgradeRules("3.12.1", "3.11.8"), new(*DowngradeError)},
+		{Check("3.12.0", "3.12.0-rc.1", WithAllowPreRelease()).Err, new(*DowngradeError)},
+		{Check("3.11.8", "3.12.0-rc.1").Err, new(*PreReleaseError)},
+		{Check("3.11.8", "3.12.1", WithSkipVersions("3.12.1")).Err, new(*SkippedVersionError)},
+		{Check("3.9.8", "3.9.9", WithArchitectures(ArchitectureARM64)).Err, new(*ArchitectureError)},
+		{Check("3.11.8", "3.12.0-rc.1", WithChannels(ChannelGA)).Err, new(*ChannelError)},
+		{Check("3.11.8", "3.12.1", WithDenyByDefault()).Err, new(*AllowListError)},
+		{Check("3.6.1", "3.8.0", WithSoft(), WithMandatoryIntermediates(Intermediate{Before: "3.7", Since: "3.8", Via: "3.7"})).Err, new(*IntermediateError)},
+		{Check("3.11.8", "3.12.0", WithWarningsAsErrors(WarningFirstRelease)).Err, new(*WarningError)},
+	}
+	for i, test := range tests {
+		if !errors.As(test.Err, test.Target) {
+			t.Errorf("%d: expected %T in %v", i, test.Target, test.Err)
+		}
+	}
+}
+
+func TestErrorIdentifiesTransition(t *testing.T) {
+	tests := []struct {
+		Err      error
+		Expected string
+	}{
+		{CheckUpgradeRules("3.10.8", "3.12.1"), "Minor versions may only increment by 1 (from 3.10.8 to 3.12.1)"},
+		{CheckUpgradeRulesWithLicense("3.11.8", "3.12.1", LicenseEnterprise, LicenseCommunity), "Upgrade from Enterprise to Community edition is not possible (from 3.11.8 enterprise to 3.12.1 community)"},
+	}
+	for _, test := range tests {
+		if msg := test.Err.Error(); msg != test.Expected {
+			t.Errorf("Expected %q, got %q", test.Expected, msg)
+		}
+		if reason := Reason(test.Err); reason != test.Err.(*Error).Message {
+			t.Errorf("Expected reason %q, got %q", test.Err.(*Error).Message, reason)
+		}
+	}
+	d := Check("3.11.8", "3.12.1", WithMaintenanceWindows(MustParseMaintenanceWindow("0 2 * * 6", time.Hour, nil)),
+		WithClock(func() time.Time { return time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC) }))
+	if reason := Reason(d.Err); reason != "Upgrade is outside the maintenance windows, next window starts at 2024-06-08T02:00:00Z" {
+		t.Errorf("Unexpected reason %q", reason)
+	}
+	if e := d.Err.(*MaintenanceWindowError); e.From != "3.11.8" || e.To != "3.12.1" {
+		t.Errorf("Expected the versions in the error, got %+v", e)
+	}
+	d = Check("3.11.8", "3.12.1", WithLicenses(LicenseEnterprise, LicenseEnterprise),
+		WithCooldown(Cooldown{AfterUpgrade: time.Hour}),
+		WithHistory(HistoryEntry{From: "3.11.7", To: "3.11.8", Time: time.Date(2024, 6, 3, 11, 30, 0, 0, time.UTC)}),
+		WithClock(func() time.Time { return time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC) }))
+	if msg := d.Err.Error(); msg != Reason(d.Err)+" (from 3.11.8 enterprise to 3.12.1 enterprise)" {
+		t.Errorf("Expected the licenses in the error, got %q", msg)
+	}
+	if Reason(nil) != "" || Reason(fmt.Errorf("custom")) != "custom" {
+		t.Error("Unexpected reason of nil or custom error")
+	}
+}
+
+func TestCustomRuleErrorIdentifiesTransition(t *testing.T) {
+	custom := errors.New("No upgrades today")
+	rs := NewRuleSet(Rule{ID: "deny-all", Check: func(in RuleInput) error { return custom }})
+	d := Check("3.11.8", "3.12.1", WithRuleSet(rs), WithLicenses(LicenseCommunity, LicenseCommunity))
+	if msg := d.Err.Error(); msg != "No upgrades today (from 3.11.8 community to 3.12.1 community)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if !errors.Is(d.Err, custom) || Reason(d.Err) != "No upgrades today" {
+		t.Errorf("Expected the custom error to be wrapped, got %#v", d.Err)
+	}
+	var te *TransitionError
+	if !errors.As(d.Err, &te) || te.From != "3.11.8" || te.To != "3.12.1" || !te.Licensed {
+		t.Errorf("Expected a TransitionError, got %#v", d.Err)
+	}
+}
+
+func TestRuleErrorIsNotModified(t *testing.T) {
+	shared := newCausedError(nil, RuleMinorIncrement, MessageMinorIncrementTooLarge).(*Error)
+	rs := NewRuleSet(Rule{ID: RuleMinorIncrement, Check: func(in RuleInput) error { return shared }})
+	first := Check("3.11.8", "3.12.1", WithRuleSet(rs))
+	second := Check("3.10.8", "3.12.2", WithRuleSet(rs))
+	if shared.From != "" || shared.To != "" {
+		t.Errorf("Expected the error of the rule not to be modified, got %+v", shared)
+	}
+	if e := first.Err.(*Error); e.From != "3.11.8" || e.To != "3.12.1" {
+		t.Errorf("Expected the versions of the first check, got %+v", e)
+	}
+	if e := second.Err.(*Error); e.From != "3.10.8" || e.To != "3.12.2" {
+		t.Errorf("Expected the versions of the second check, got %+v", e)
+	}
+}
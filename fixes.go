@@ -0,0 +1,101 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RuleRequiredFix denies upgrades to versions that do not contain a
+// required fix, see WithRequiredFixes.
+const RuleRequiredFix RuleID = "required-fix"
+
+// RequiredFix is a fix that the version being upgraded to must contain.
+// Since fixes are backported, a fix is contained in a different patch
+// release of every minor version.
+type RequiredFix struct {
+	// Issue identifies the fixed issue, e.g. "BTS-1234"
+	Issue string `json:"issue"`
+	// FixedIn holds the first patch release of every minor version that
+	// contains the fix, e.g. "3.11.9" and "3.12.2". Minor versions after
+	// the newest one listed contain the fix, other minor versions do not.
+	FixedIn []VersionString `json:"fixedIn"`
+}
+
+// ContainedIn returns true when the given version contains the fix.
+func (f RequiredFix) ContainedIn(v ParsedVersion) bool {
+	var newest ParsedVersion
+	for i, x := range f.FixedIn {
+		fixed := ParseVersion(x)
+		if fixed.major == v.major && fixed.minor == v.minor {
+			return !versionLess(v, fixed)
+		}
+		if i == 0 || versionLess(newest, fixed) {
+			newest = fixed
+		}
+	}
+	return len(f.FixedIn) > 0 && versionBefore(newest, v.major, v.minor)
+}
+
+// WithRequiredFixes denies upgrades to versions that do not contain all
+// given fixes with RuleRequiredFix, which can be overridden.
+func WithRequiredFixes(fixes ...RequiredFix) Option {
+	return func(o *options) {
+		o.requiredFixes = append(append([]RequiredFix(nil), o.requiredFixes...), fixes...)
+	}
+}
+
+// RequiredFixError details a denial by RuleRequiredFix.
+type RequiredFixError struct {
+	// Version is the version being upgraded to
+	Version VersionString
+	// Issue identifies the missing fix
+	Issue string
+	// FixedIn holds the versions containing the fix, sorted
+	FixedIn []VersionString
+}
+
+// Error describes the missing fix.
+func (e *RequiredFixError) Error() string {
+	return fmt.Sprintf("Version %s does not contain the fix for %s", e.Version, e.Issue)
+}
+
+// ruleRequiredFix implements RuleRequiredFix.
+var ruleRequiredFix = Rule{
+	ID:          RuleRequiredFix,
+	Applies:     func(in RuleInput) bool { return len(in.RequiredFixes) > 0 && in.changesVersion() },
+	Check:       func(in RuleInput) error { return checkRequiredFixes(in.RequiredFixes, in.To) },
+	Overridable: true,
+}
+
+// checkRequiredFixes implements RuleRequiredFix.
+func checkRequiredFixes(fixes []RequiredFix, to ParsedVersion) error {
+	for _, f := range fixes {
+		if !f.ContainedIn(to) {
+			fixedIn := append([]VersionString(nil), f.FixedIn...)
+			sort.Slice(fixedIn, func(i, j int) bool { return compareVersions(fixedIn[i], fixedIn[j]) < 0 })
+			cause := &RequiredFixError{Version: to.version, Issue: f.Issue, FixedIn: fixedIn}
+			return newCausedError(cause, RuleRequiredFix, MessageFixMissing, string(to.version), f.Issue)
+		}
+	}
+	return nil
+}
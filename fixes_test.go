@@ -0,0 +1,72 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRequiredFixContainedIn(t *testing.T) {
+	fix := RequiredFix{Issue: "BTS-1234", FixedIn: []VersionString{"3.12.2", "3.11.9"}}
+	tests := map[VersionString]bool{
+		"3.11.8":  false,
+		"3.11.9":  true,
+		"3.11.12": true,
+		"3.12.1":  false,
+		"3.12.2":  true,
+		"3.13.0":  true,
+		"4.0.0":   true,
+		"3.10.14": false,
+	}
+	for v, expected := range tests {
+		if got := fix.ContainedIn(ParseVersion(v)); got != expected {
+			t.Errorf("%s: expected %t, got %t", v, expected, got)
+		}
+	}
+	if (RequiredFix{Issue: "BTS-1"}).ContainedIn(ParseVersion("3.12.1")) {
+		t.Error("Expected a fix without versions not to be contained")
+	}
+}
+
+func TestWithRequiredFixes(t *testing.T) {
+	fix := WithRequiredFixes(RequiredFix{Issue: "BTS-1234", FixedIn: []VersionString{"3.12.2", "3.11.9"}})
+	if d := Check("3.11.8", "3.11.9", fix); !d.Allowed() {
+		t.Errorf("Expected the backport to be accepted, got %v", d.Err)
+	}
+	d := Check("3.11.8", "3.12.1", fix)
+	if d.Rule != RuleRequiredFix {
+		t.Fatalf("Expected %s, got %q", RuleRequiredFix, d.Rule)
+	}
+	var fixErr *RequiredFixError
+	if !errors.As(d.Err, &fixErr) || fixErr.Issue != "BTS-1234" || fixErr.FixedIn[0] != "3.11.9" {
+		t.Errorf("Unexpected error details %+v", fixErr)
+	}
+	if Reason(d.Err) != "Version 3.12.1 does not contain the fix for BTS-1234" {
+		t.Errorf("Unexpected reason '%s'", Reason(d.Err))
+	}
+	if d := Check("3.11.8", "3.12.1", fix, WithOverrides(Override{From: "3.11", To: "3.12", Rules: []RuleID{RuleRequiredFix}})); d.Outcome() != OutcomeOverridden {
+		t.Errorf("Expected the override to allow the upgrade, got %v", d.Err)
+	}
+	if d := Check("3.11.8", "3.11.8", fix); !d.Allowed() {
+		t.Errorf("Expected a no-op to be allowed, got %v", d.Err)
+	}
+}
@@ -0,0 +1,174 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Format implements fmt.Formatter, following the convention of
+// github.com/pkg/errors: %s and %v print the message (see Error), %q
+// prints it quoted and %+v also prints the rule and, when the decision
+// was traced (see WithTrace), the evaluation of all rules.
+func (e *Error) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		fmt.Fprintf(w, "\nrule: %s", e.Rule)
+		writeTrace(w, e.trace)
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// wrapped error with its details.
+func (e *TransitionError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		fmt.Fprintf(w, "\ncause: %+v", e.Err)
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// rule and each unmet precondition on its own line.
+func (e *PreconditionError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		fmt.Fprintf(w, "\nrule: %s", RulePreconditions)
+		for _, u := range e.Unmet {
+			fmt.Fprintf(w, "\nunmet %s: %v", u.Name, u.Err)
+		}
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// rule and the start of the next maintenance window.
+func (e *MaintenanceWindowError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		fmt.Fprintf(w, "\nrule: %s", RuleMaintenanceWindow)
+		if e.Next.IsZero() {
+			io.WriteString(w, "\nnext: never")
+		} else {
+			fmt.Fprintf(w, "\nnext: %s", e.Next.Format(time.RFC3339))
+		}
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// rule, the earliest time the upgrade is allowed and the previous upgrade.
+func (e *CooldownError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		fmt.Fprintf(w, "\nrule: %s\nnext: %s\nprevious: %s -> %s at %s", RuleCooldown, e.Next.Format(time.RFC3339),
+			e.Previous.From, e.Previous.To, e.Previous.Time.Format(time.RFC3339))
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// versions.
+func (e *MajorVersionError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		writeVersions(w, e.From, e.To)
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// versions and the largest allowed increase of the minor version.
+func (e *MinorSkipError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		writeVersions(w, e.From, e.To)
+		fmt.Fprintf(w, "\nmax skip: %d", e.MaxSkip)
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// versions.
+func (e *DowngradeError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		writeVersions(w, e.From, e.To)
+	})
+}
+
+// Format implements fmt.Formatter like Error.Format, %+v also prints the
+// licenses.
+func (e *LicenseTransitionError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e, func(w io.Writer) {
+		fmt.Fprintf(w, "\nfrom license: %s\nto license: %s", e.From, e.To)
+	})
+}
+
+// formatError implements fmt.Formatter for the errors of this package:
+// %s and %v print the message of err, %q prints it quoted and %+v also
+// lets detail print the details, each line after a newline.
+func formatError(s fmt.State, verb rune, err error, detail func(w io.Writer)) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, err.Error())
+		if s.Flag('+') {
+			detail(s)
+		}
+	case 's':
+		io.WriteString(s, err.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", err.Error())
+	}
+}
+
+// writeVersions writes the given versions, each after a newline.
+func writeVersions(w io.Writer, from, to VersionString) {
+	fmt.Fprintf(w, "\nfrom: %s\nto: %s", from, to)
+}
+
+// Format implements fmt.Formatter: %s and %v print the transition and its
+// outcome, e.g. "3.10.8 -> 3.12.1: denied (minor-increment): Minor versions
+// may only increment by 1", %q prints it quoted and %+v also prints the
+// trace of the decision (see WithTrace).
+func (d Decision) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v', 's':
+		io.WriteString(s, d.summary())
+		if verb == 'v' && s.Flag('+') {
+			writeTrace(s, d.Trace)
+		}
+	case 'q':
+		fmt.Fprintf(s, "%q", d.summary())
+	}
+}
+
+// summary describes the transition and its outcome, see Format.
+func (d Decision) summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s -> %s: %s", d.From, d.To, d.Outcome())
+	if d.Err != nil {
+		if d.Rule != "" {
+			fmt.Fprintf(&b, " (%s)", d.Rule)
+		}
+		fmt.Fprintf(&b, ": %s", Reason(d.Err))
+	} else if x := d.Override; x != nil && x.Ticket != "" {
+		fmt.Fprintf(&b, " (%s)", x.Ticket)
+	}
+	return b.String()
+}
+
+// writeTrace writes the lines of the given trace, if any, each after a
+// newline.
+func writeTrace(w io.Writer, t *Trace) {
+	if lines := strings.TrimSuffix(t.String(), "\n"); lines != "" {
+		io.WriteString(w, "\n"+lines)
+	}
+}
@@ -0,0 +1,114 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorFormat(t *testing.T) {
+	err := CheckUpgradeRules("3.10.8", "3.12.1", WithTrace())
+	if s := fmt.Sprintf("%v", err); s != "Minor versions may only increment by 1 (from 3.10.8 to 3.12.1)" {
+		t.Errorf("Unexpected %%v %q", s)
+	}
+	if s := fmt.Sprintf("%s", err); s != err.Error() {
+		t.Errorf("Unexpected %%s %q", s)
+	}
+	if s := fmt.Sprintf("%q", err); s != `"Minor versions may only increment by 1 (from 3.10.8 to 3.12.1)"` {
+		t.Errorf("Unexpected %%q %q", s)
+	}
+	s := fmt.Sprintf("%+v", err)
+	for _, expected := range []string{
+		"Minor versions may only increment by 1 (from 3.10.8 to 3.12.1)\nrule: minor-increment\n",
+		"rule major-version: allowed\n",
+		"rule minor-increment: denied (Minor versions may only increment by 1)\n",
+		"  minor version increments by 1 [from.minor=10, to.minor=12]: false",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Expected %q in\n%s", expected, s)
+		}
+	}
+	if s := fmt.Sprintf("%+v", CheckUpgradeRules("3.10.8", "3.12.1")); s != "Minor versions may only increment by 1 (from 3.10.8 to 3.12.1)\nrule: minor-increment" {
+		t.Errorf("Unexpected %%+v without trace %q", s)
+	}
+}
+
+func TestDecisionFormat(t *testing.T) {
+	tests := []struct {
+		Decision Decision
+		Expected string
+	}{
+		{Check("3.11.8", "3.12.1"), "3.11.8 -> 3.12.1: allowed"},
+		{Check("3.10.8", "3.12.1"), "3.10.8 -> 3.12.1: denied (minor-increment): Minor versions may only increment by 1"},
+		{Check("3.10.8", "3.12.1", WithOverrides(Override{From: "3.10", To: "3.12", Ticket: "OPS-1"})), "3.10.8 -> 3.12.1: overridden (OPS-1)"},
+	}
+	for _, test := range tests {
+		if s := fmt.Sprintf("%v", test.Decision); s != test.Expected {
+			t.Errorf("Expected %q, got %q", test.Expected, s)
+		}
+	}
+	if s := fmt.Sprintf("%q", Check("3.10.8", "3.12.1")); s != `"3.10.8 -> 3.12.1: denied (minor-increment): Minor versions may only increment by 1"` {
+		t.Errorf("Unexpected %%q %s", s)
+	}
+	s := fmt.Sprintf("%+v", Check("3.10.8", "3.12.1", WithTrace()))
+	if !strings.HasPrefix(s, "3.10.8 -> 3.12.1: denied (minor-increment): Minor versions may only increment by 1\nrule major-version: allowed\n") {
+		t.Errorf("Unexpected %%+v\n%s", s)
+	}
+}
+
+func TestDetailedErrorFormat(t *testing.T) {
+	next := time.Date(2024, 6, 8, 2, 0, 0, 0, time.UTC)
+	tests := []struct {
+		Err    error
+		Detail string
+	}{
+		{&PreconditionError{Unmet: []UnmetPrecondition{{Name: "backup", Err: errors.New("No backup")}}}, "\nrule: preconditions\nunmet backup: No backup"},
+		{&MaintenanceWindowError{Next: next}, "\nrule: maintenance-window\nnext: 2024-06-08T02:00:00Z"},
+		{&MaintenanceWindowError{}, "\nrule: maintenance-window\nnext: never"},
+		{&CooldownError{Next: next, Previous: HistoryEntry{From: "3.11.7", To: "3.11.8", Time: next.Add(-time.Hour)}},
+			"\nrule: cooldown\nnext: 2024-06-08T02:00:00Z\nprevious: 3.11.7 -> 3.11.8 at 2024-06-08T01:00:00Z"},
+		{&MajorVersionError{From: "3.12.1", To: "4.0.0"}, "\nfrom: 3.12.1\nto: 4.0.0"},
+		{&MinorSkipError{From: "3.10.8", To: "3.12.1", MaxSkip: 1}, "\nfrom: 3.10.8\nto: 3.12.1\nmax skip: 1"},
+		{&DowngradeError{From: "3.12.1", To: "3.11.8"}, "\nfrom: 3.12.1\nto: 3.11.8"},
+		{&LicenseTransitionError{From: LicenseEnterprise, To: LicenseCommunity}, "\nfrom license: enterprise\nto license: community"},
+		{&TransitionError{Err: &DowngradeError{From: "3.12.1", To: "3.11.8"}, From: "3.12.1", To: "3.11.8"}, "\ncause: Downgrade from 3.12.1 to 3.11.8 is not possible\nfrom: 3.12.1\nto: 3.11.8"},
+	}
+	for _, test := range tests {
+		msg := test.Err.Error()
+		if s := fmt.Sprintf("%v", test.Err); s != msg {
+			t.Errorf("Unexpected %%v %q", s)
+		}
+		if s := fmt.Sprintf("%s", test.Err); s != msg {
+			t.Errorf("Unexpected %%s %q", s)
+		}
+		if s := fmt.Sprintf("%q", test.Err); s != strconv.Quote(msg) {
+			t.Errorf("Unexpected %%q %s", s)
+		}
+		if s := fmt.Sprintf("%+v", test.Err); s != msg+test.Detail {
+			t.Errorf("Expected %%+v %q, got %q", msg+test.Detail, s)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/arangodb/go-upgrade-rules
+
+go 1.21
+
+require (
+	github.com/Masterminds/semver/v3 v3.2.1
+	github.com/arangodb/go-driver v1.6.2
+	github.com/blang/semver/v4 v4.0.0
+	github.com/prometheus/client_golang v1.19.0
+	go.opentelemetry.io/otel v1.24.0
+	go.opentelemetry.io/otel/sdk v1.24.0
+	go.opentelemetry.io/otel/trace v1.24.0
+	k8s.io/api v0.29.3
+	k8s.io/apimachinery v0.29.3
+	k8s.io/client-go v0.29.3
+	sigs.k8s.io/controller-runtime v0.17.2
+)
@@ -0,0 +1,55 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExportGraph returns a Graphviz (DOT) directed graph of the permitted
+// upgrades between the given versions, using the given options.
+// Upgrades that are only permitted by an override are drawn dashed.
+// Upgrades from a version to itself are left out.
+func ExportGraph(versions []VersionString, opts ...Option) (dot string) {
+	m := NewMatrix(versions, opts...)
+	var sb strings.Builder
+	sb.WriteString("digraph upgrades {\n")
+	for _, v := range m.Versions {
+		fmt.Fprintf(&sb, "\t%s;\n", strconv.Quote(string(v)))
+	}
+	for i, from := range m.Versions {
+		for j, to := range m.Versions {
+			if i == j {
+				continue
+			}
+			switch m.Decisions[i][j].Outcome() {
+			case OutcomeAllowed:
+				fmt.Fprintf(&sb, "\t%s -> %s;\n", strconv.Quote(string(from)), strconv.Quote(string(to)))
+			case OutcomeOverridden:
+				fmt.Fprintf(&sb, "\t%s -> %s [style=dashed];\n", strconv.Quote(string(from)), strconv.Quote(string(to)))
+			}
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
@@ -0,0 +1,42 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestExportGraph(t *testing.T) {
+	versions := []VersionString{"3.10.8", "3.11.8", "3.12.1"}
+	dot := ExportGraph(versions, WithOverrides(Override{From: "3.10", To: "3.12"}))
+	expected := `digraph upgrades {
+	"3.10.8";
+	"3.11.8";
+	"3.12.1";
+	"3.10.8" -> "3.11.8";
+	"3.10.8" -> "3.12.1" [style=dashed];
+	"3.11.8" -> "3.12.1";
+}
+`
+	if dot != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, dot)
+	}
+}
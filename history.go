@@ -0,0 +1,227 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// UpgradeOutcome is the result of an upgrade that was performed.
+type UpgradeOutcome string
+
+const (
+	// UpgradeSucceeded means the deployment runs the new version
+	UpgradeSucceeded UpgradeOutcome = "succeeded"
+	// UpgradeFailed means the upgrade did not complete and the deployment
+	// still runs the old version
+	UpgradeFailed UpgradeOutcome = "failed"
+	// UpgradeRolledBack means the upgrade completed, but the deployment was
+	// rolled back to the old version afterwards
+	UpgradeRolledBack UpgradeOutcome = "rolled-back"
+)
+
+// HistoryEntry is a single transition in an UpgradeHistory.
+type HistoryEntry struct {
+	// From is the version before the upgrade
+	From VersionString `json:"from"`
+	// To is the version the upgrade went to
+	To VersionString `json:"to"`
+	// Time at which the upgrade was done
+	Time time.Time `json:"time"`
+	// Outcome of the upgrade
+	Outcome UpgradeOutcome `json:"outcome"`
+	// Requester is the user or system that requested the upgrade, if known
+	Requester string `json:"requester,omitempty"`
+}
+
+// HistoryEntryOf returns the entry for the upgrade allowed by the given
+// decision, with the given outcome. The time is the time of the decision.
+func HistoryEntryOf(d Decision, outcome UpgradeOutcome) HistoryEntry {
+	return HistoryEntry{From: d.From, To: d.To, Time: d.Time, Outcome: outcome, Requester: d.Requester}
+}
+
+// UpgradeHistory holds the upgrades of a deployment in order, for the
+// cooldown (see WithUpgradeHistory), rollback validation and audits.
+type UpgradeHistory struct {
+	// Entries holds the upgrades, oldest first
+	Entries []HistoryEntry `json:"entries"`
+}
+
+// Append returns a copy of the history with the given entries added.
+func (h UpgradeHistory) Append(entries ...HistoryEntry) UpgradeHistory {
+	return UpgradeHistory{Entries: append(append([]HistoryEntry(nil), h.Entries...), entries...)}
+}
+
+// PastUpgrades returns the upgrades that were performed, i.e. that
+// succeeded or were rolled back afterwards.
+func (h UpgradeHistory) PastUpgrades() []HistoryEntry {
+	var result []HistoryEntry
+	for _, e := range h.Entries {
+		if e.Outcome == UpgradeSucceeded || e.Outcome == UpgradeRolledBack {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// Current returns the version the deployment runs after the last
+// upgrade, or false when the history has no performed upgrade.
+func (h UpgradeHistory) Current() (VersionString, bool) {
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		switch e := h.Entries[i]; e.Outcome {
+		case UpgradeSucceeded:
+			return e.To, true
+		case UpgradeRolledBack:
+			return e.From, true
+		}
+	}
+	return "", false
+}
+
+// HasRun returns true when the deployment ran the given version according
+// to the history, e.g. to validate that a rollback returns to a version
+// that is known to work with the data.
+func (h UpgradeHistory) HasRun(v VersionString) bool {
+	for _, e := range h.Entries {
+		if e.From == v || (e.To == v && e.Outcome != UpgradeFailed) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithUpgradeHistory adds the entries of the history to the upgrades
+// considered by the cooldown, see WithHistory.
+func WithUpgradeHistory(h UpgradeHistory) Option {
+	return WithHistory(h.Entries...)
+}
+
+// HistoryStore persists the upgrade histories of deployments.
+// Implementations must be safe for concurrent use.
+type HistoryStore interface {
+	// Load returns the history of the given deployment, which is empty
+	// for an unknown deployment.
+	Load(ctx context.Context, deployment string) (UpgradeHistory, error)
+	// Append adds an entry to the history of the given deployment.
+	Append(ctx context.Context, deployment string, e HistoryEntry) error
+}
+
+// MemoryHistoryStore is a HistoryStore that keeps the histories in memory,
+// e.g. for tests. The zero value is ready to use.
+type MemoryHistoryStore struct {
+	mutex     sync.Mutex
+	histories map[string]UpgradeHistory
+}
+
+var _ HistoryStore = &MemoryHistoryStore{}
+
+// Load returns the history of the given deployment.
+func (s *MemoryHistoryStore) Load(ctx context.Context, deployment string) (UpgradeHistory, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.histories[deployment].Append(), nil
+}
+
+// Append adds an entry to the history of the given deployment.
+func (s *MemoryHistoryStore) Append(ctx context.Context, deployment string, e HistoryEntry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.histories == nil {
+		s.histories = make(map[string]UpgradeHistory)
+	}
+	s.histories[deployment] = s.histories[deployment].Append(e)
+	return nil
+}
+
+// FileHistoryStore is a HistoryStore that keeps the history of every
+// deployment in a file of the given directory, with one line of JSON
+// per entry.
+type FileHistoryStore struct {
+	mutex sync.Mutex
+	dir   string
+}
+
+var _ HistoryStore = &FileHistoryStore{}
+
+// NewFileHistoryStore creates a FileHistoryStore in the given directory,
+// which is created when it does not exist.
+func NewFileHistoryStore(dir string) (*FileHistoryStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileHistoryStore{dir: dir}, nil
+}
+
+// Load reads the history of the given deployment.
+func (s *FileHistoryStore) Load(ctx context.Context, deployment string) (UpgradeHistory, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	f, err := os.Open(s.path(deployment))
+	if os.IsNotExist(err) {
+		return UpgradeHistory{}, nil
+	} else if err != nil {
+		return UpgradeHistory{}, err
+	}
+	defer f.Close()
+	var h UpgradeHistory
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		var e HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return UpgradeHistory{}, fmt.Errorf("Invalid history entry in line %d of %s: %s", lineNo, f.Name(), err)
+		}
+		h.Entries = append(h.Entries, e)
+	}
+	return h, scanner.Err()
+}
+
+// Append adds an entry to the history file of the given deployment.
+func (s *FileHistoryStore) Append(ctx context.Context, deployment string, e HistoryEntry) error {
+	encoded, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	f, err := os.OpenFile(s.path(deployment), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(encoded, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// path returns the path of the history file of the given deployment.
+// The name is escaped, so it cannot refer to a file outside the directory.
+func (s *FileHistoryStore) path(deployment string) string {
+	return filepath.Join(s.dir, url.PathEscape(deployment)+".jsonl")
+}
@@ -0,0 +1,100 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testHistory(now time.Time) UpgradeHistory {
+	return UpgradeHistory{}.Append(
+		HistoryEntry{From: "3.10.8", To: "3.11.1", Time: now.Add(-72 * time.Hour), Outcome: UpgradeSucceeded},
+		HistoryEntry{From: "3.11.1", To: "3.12.0", Time: now.Add(-48 * time.Hour), Outcome: UpgradeFailed},
+		HistoryEntry{From: "3.11.1", To: "3.12.1", Time: now.Add(-time.Hour), Outcome: UpgradeSucceeded, Requester: "alice"},
+	)
+}
+
+func TestUpgradeHistory(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	h := testHistory(now)
+	if v, ok := h.Current(); !ok || v != "3.12.1" {
+		t.Errorf("Expected current version 3.12.1, got %s (%v)", v, ok)
+	}
+	if upgrades := h.PastUpgrades(); len(upgrades) != 2 || upgrades[1].To != "3.12.1" {
+		t.Errorf("Expected the failed upgrade to be skipped, got %v", upgrades)
+	}
+	if !h.HasRun("3.11.1") || h.HasRun("3.12.0") || h.HasRun("3.9.0") {
+		t.Error("Unexpected versions run by the deployment")
+	}
+	rolledBack := h.Append(HistoryEntry{From: "3.12.1", To: "3.12.2", Time: now, Outcome: UpgradeRolledBack})
+	if v, _ := rolledBack.Current(); v != "3.12.1" {
+		t.Errorf("Expected current version 3.12.1 after rollback, got %s", v)
+	}
+	if len(h.Entries) != 3 {
+		t.Errorf("Append must not modify the history, got %d entries", len(h.Entries))
+	}
+	if _, ok := (UpgradeHistory{}).Current(); ok {
+		t.Error("Expected no current version for an empty history")
+	}
+}
+
+func TestWithUpgradeHistory(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	d := Check("3.12.1", "3.12.3", clock, WithCooldown(Cooldown{AfterUpgrade: 24 * time.Hour}), WithUpgradeHistory(testHistory(now)))
+	if d.Rule != RuleCooldown {
+		t.Errorf("Expected rule '%s', got '%s' (%v)", RuleCooldown, d.Rule, d.Err)
+	}
+}
+
+func TestHistoryStores(t *testing.T) {
+	fileStore, err := NewFileHistoryStore(filepath.Join(t.TempDir(), "histories"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	for name, store := range map[string]HistoryStore{"memory": &MemoryHistoryStore{}, "file": fileStore} {
+		if h, err := store.Load(ctx, "prod/eu"); err != nil || len(h.Entries) != 0 {
+			t.Errorf("%s: expected empty history, got %v (%v)", name, h, err)
+		}
+		expected := testHistory(now)
+		for _, e := range expected.Entries {
+			if err := store.Append(ctx, "prod/eu", e); err != nil {
+				t.Fatalf("%s: append failed: %s", name, err)
+			}
+		}
+		if err := store.Append(ctx, "staging", expected.Entries[0]); err != nil {
+			t.Fatalf("%s: append failed: %s", name, err)
+		}
+		h, err := store.Load(ctx, "prod/eu")
+		if err != nil {
+			t.Fatalf("%s: load failed: %s", name, err)
+		}
+		if !reflect.DeepEqual(h, expected) {
+			t.Errorf("%s: expected %v, got %v", name, expected, h)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// Hooks are called around the evaluation of the rules of a RuleSet, for
+// cross-cutting concerns like caching, metrics, auditing or modifying the
+// input in tests, see RuleSet.WithHooks. Every function is optional.
+// Hooks are called concurrently by concurrent checks.
+type Hooks struct {
+	// BeforeCheck is called before the rules of a check are evaluated.
+	// It may modify the input.
+	BeforeCheck func(in *RuleInput)
+	// AfterCheck is called after the rules of a check were evaluated, with
+	// the rule that denied the upgrade and its error (empty and nil when
+	// the rules allow it).
+	AfterCheck func(in RuleInput, rule RuleID, err error)
+	// BeforeRule is called before a rule is evaluated. It may modify the
+	// input of the rule. When it returns true, the rule is not evaluated
+	// and the returned error is its result, e.g. a cached one.
+	BeforeRule func(r Rule, in *RuleInput) (handled bool, err error)
+	// AfterRule is called after a rule was evaluated and returns the
+	// result to use, usually the given error.
+	AfterRule func(r Rule, in RuleInput, err error) error
+}
+
+// WithHooks returns a copy of the RuleSet that calls the given hooks, in
+// addition to those of s. Before hooks are called in the order they were
+// added, after hooks in reverse order, so hooks nest like middleware.
+func (s *RuleSet) WithHooks(hooks ...Hooks) *RuleSet {
+	return &RuleSet{rules: s.rules, hooks: append(s.Hooks(), hooks...)}
+}
+
+// Hooks returns a copy of the hooks of the RuleSet.
+func (s *RuleSet) Hooks() []Hooks {
+	return append([]Hooks(nil), s.hooks...)
+}
+
+// beforeCheck calls the BeforeCheck hooks and returns the input to use.
+func (s *RuleSet) beforeCheck(in RuleInput) RuleInput {
+	for _, h := range s.hooks {
+		if h.BeforeCheck != nil {
+			h.BeforeCheck(&in)
+		}
+	}
+	return in
+}
+
+// afterCheck calls the AfterCheck hooks.
+func (s *RuleSet) afterCheck(in RuleInput, rule RuleID, err error) {
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		if h := s.hooks[i]; h.AfterCheck != nil {
+			h.AfterCheck(in, rule, err)
+		}
+	}
+}
+
+// evaluate evaluates a single rule, calling the rule hooks around it.
+// The input only escapes to the heap here, so checks without hooks do
+// not allocate.
+func (s *RuleSet) evaluate(o *options, r Rule, in RuleInput) error {
+	var err error
+	handled := false
+	for _, h := range s.hooks {
+		if h.BeforeRule != nil {
+			if handled, err = h.BeforeRule(r, &in); handled {
+				break
+			}
+		}
+	}
+	if !handled {
+		err = o.evaluate(r, in)
+	}
+	for i := len(s.hooks) - 1; i >= 0; i-- {
+		if h := s.hooks[i]; h.AfterRule != nil {
+			err = h.AfterRule(r, in, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,95 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRuleSetHooks(t *testing.T) {
+	var calls []string
+	outer := Hooks{
+		BeforeCheck: func(in *RuleInput) { calls = append(calls, "before check "+string(in.To.Version())) },
+		AfterCheck:  func(in RuleInput, rule RuleID, err error) { calls = append(calls, "after check "+string(rule)) },
+		BeforeRule: func(r Rule, in *RuleInput) (bool, error) {
+			calls = append(calls, "before "+string(r.ID))
+			return false, nil
+		},
+		AfterRule: func(r Rule, in RuleInput, err error) error {
+			calls = append(calls, "after "+string(r.ID))
+			return err
+		},
+	}
+	inner := Hooks{
+		AfterRule: func(r Rule, in RuleInput, err error) error {
+			calls = append(calls, "inner after "+string(r.ID))
+			return err
+		},
+	}
+	rs := NewRuleSet(ruleMajorVersion, ruleMinorIncrement).WithHooks(outer).WithHooks(inner)
+	d := Check("3.10.8", "3.12.1", WithRuleSet(rs))
+	if d.Rule != RuleMinorIncrement {
+		t.Errorf("Expected %s, got %s", RuleMinorIncrement, d.Rule)
+	}
+	expected := []string{
+		"before check 3.12.1",
+		"before major-version", "inner after major-version", "after major-version",
+		"before minor-increment", "inner after minor-increment", "after minor-increment",
+		"after check minor-increment",
+	}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, calls)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+			break
+		}
+	}
+	if len(DefaultRuleSet().Hooks()) != 0 || len(rs.Hooks()) != 2 {
+		t.Error("Expected WithHooks to copy the rule set")
+	}
+}
+
+func TestRuleSetHooksReplaceResults(t *testing.T) {
+	denied := errors.New("cached denial")
+	rs := DefaultRuleSet().WithHooks(Hooks{
+		BeforeCheck: func(in *RuleInput) { in.Soft = true },
+		BeforeRule: func(r Rule, in *RuleInput) (bool, error) {
+			if r.ID == RuleMajorVersion {
+				return true, denied
+			}
+			return false, nil
+		},
+	})
+	if d := Check("3.10.8", "3.12.1", WithRuleSet(rs)); !errors.Is(d.Err, denied) {
+		t.Errorf("Expected the result of the hook, got %v", d.Err)
+	}
+	rs = DefaultRuleSet().WithHooks(Hooks{BeforeCheck: func(in *RuleInput) { in.Soft = true }})
+	if d := Check("3.10.8", "3.12.1", WithRuleSet(rs)); !d.Allowed() {
+		t.Errorf("Expected the modified input to select the soft rules, got %v", d.Err)
+	}
+	rs = DefaultRuleSet().WithHooks(Hooks{AfterRule: func(r Rule, in RuleInput, err error) error { return nil }})
+	if d := Check("3.10.8", "4.0.0", WithRuleSet(rs)); !d.Allowed() {
+		t.Errorf("Expected the hook to allow the upgrade, got %v", d.Err)
+	}
+}
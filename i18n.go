@@ -0,0 +1,215 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MessageID identifies the human readable message of an Error,
+// independent of its language.
+type MessageID string
+
+const (
+	// MessageMajorVersionDifferent is used by RuleMajorVersion
+	MessageMajorVersionDifferent MessageID = "major-version-different"
+	// MessageMinorIncrementTooLarge is used by RuleMinorIncrement
+	MessageMinorIncrementTooLarge MessageID = "minor-increment-too-large"
+	// MessageDowngradeNotPossible is used by RuleMinorDowngrade
+	MessageDowngradeNotPossible MessageID = "downgrade-not-possible"
+	// MessageEditionDowngradeNotPossible is used by RuleEditionDowngrade
+	MessageEditionDowngradeNotPossible MessageID = "edition-downgrade-not-possible"
+	// MessageLicenseTransitionNotAllowed is used by RuleEditionDowngrade
+	// when a LicenseMatrix denies a transition other than Enterprise to Community
+	MessageLicenseTransitionNotAllowed MessageID = "license-transition-not-allowed"
+	// MessageChannelNotAllowed is used by RuleChannel
+	MessageChannelNotAllowed MessageID = "channel-not-allowed"
+	// MessageTransitionNotAllowListed is used by RuleAllowList
+	MessageTransitionNotAllowListed MessageID = "transition-not-allow-listed"
+	// MessageSourcePatchTooOld is used by RuleMinimumPatch, with the
+	// required version and the minor version being upgraded to as arguments
+	MessageSourcePatchTooOld MessageID = "source-patch-too-old"
+	// MessageIntermediateRequired is used by RuleMandatoryIntermediate,
+	// with the intermediate version as argument
+	MessageIntermediateRequired MessageID = "intermediate-required"
+	// MessageVersionSkipped is used by RuleSkippedVersion, with the
+	// target version as argument
+	MessageVersionSkipped MessageID = "version-skipped"
+	// MessagePreReleaseTarget is used by RulePreReleaseTarget, with the
+	// target version as argument
+	MessagePreReleaseTarget MessageID = "pre-release-target"
+	// MessageMinorSkipTooLarge is used by RuleMaxMinorSkip, with the
+	// maximum increase as argument
+	MessageMinorSkipTooLarge MessageID = "minor-skip-too-large"
+	// MessageGAToPreRelease is used by RuleGAToPreRelease, with both
+	// versions as arguments
+	MessageGAToPreRelease MessageID = "ga-to-pre-release"
+	// MessageNoBinaries is used by RuleArchitecture, with the target
+	// version and the architecture as arguments
+	MessageNoBinaries MessageID = "no-binaries"
+	// MessageFixMissing is used by RuleRequiredFix, with the target
+	// version and the issue as arguments
+	MessageFixMissing MessageID = "fix-missing"
+	// MessagePreReleaseSourceWarning is used for WarningPreReleaseSource,
+	// with the source version as argument
+	MessagePreReleaseSourceWarning MessageID = "pre-release-source-warning"
+	// MessagePreReleaseTargetWarning is used for WarningPreReleaseTarget,
+	// with the target version as argument
+	MessagePreReleaseTargetWarning MessageID = "pre-release-target-warning"
+	// MessageFirstReleaseWarning is used for WarningFirstRelease, with the
+	// target version and its major and minor version as arguments
+	MessageFirstReleaseWarning MessageID = "first-release-warning"
+	// MessageFirstReleaseLaterPatchWarning is used for WarningFirstRelease
+	// when a later patch release is known, with the arguments of
+	// MessageFirstReleaseWarning followed by the later release
+	MessageFirstReleaseLaterPatchWarning MessageID = "first-release-later-patch-warning"
+)
+
+// Catalog holds the messages of a single language.
+// Messages of errors with arguments are fmt format strings, which can
+// use explicit argument indexes (e.g. %[2]s) to change the word order.
+type Catalog map[MessageID]string
+
+var (
+	// catalogEnglish is the default catalog, used for Error.Message
+	catalogEnglish = Catalog{
+		MessageMajorVersionDifferent:       "Major versions are different",
+		MessageMinorIncrementTooLarge:      "Minor versions may only increment by 1",
+		MessageDowngradeNotPossible:        "Downgrade is not possible",
+		MessageEditionDowngradeNotPossible: "Upgrade from Enterprise to Community edition is not possible",
+		MessageLicenseTransitionNotAllowed: "Changing the license is not allowed",
+		MessageChannelNotAllowed:           "The release channel of the target version is not allowed",
+		MessageTransitionNotAllowListed:    "Upgrade is not in the list of allowed upgrades",
+		MessageSourcePatchTooOld:           "Version %s or later is required before upgrading to %s",
+		MessageIntermediateRequired:        "Upgrade must pass through version %s",
+		MessageVersionSkipped:              "Version %s is embargoed",
+		MessagePreReleaseTarget:            "Version %s is a pre-release",
+		MessageMinorSkipTooLarge:           "Minor versions may only increment by up to %d",
+		MessageGAToPreRelease:              "Going back from %s to its pre-release %s is not allowed",
+		MessageNoBinaries:                  "Version %s has no official %s binaries",
+		MessageFixMissing:                  "Version %s does not contain the fix for %s",
+
+		MessagePreReleaseSourceWarning:       "Version %s is a pre-release, upgrades from pre-releases are not supported",
+		MessagePreReleaseTargetWarning:       "Version %s is a pre-release, which is not meant for production",
+		MessageFirstReleaseWarning:           "Version %s is the first release of %d.%d, consider waiting for the first patch release",
+		MessageFirstReleaseLaterPatchWarning: "Version %s is the first release of %d.%d, consider upgrading to %s instead",
+	}
+	// catalogs holds the built-in catalogs by language
+	catalogs = map[string]Catalog{
+		"en": catalogEnglish,
+		"de": {
+			MessageMajorVersionDifferent:       "Die Hauptversionen sind unterschiedlich",
+			MessageMinorIncrementTooLarge:      "Die Nebenversion darf nur um 1 erhöht werden",
+			MessageDowngradeNotPossible:        "Ein Downgrade ist nicht möglich",
+			MessageEditionDowngradeNotPossible: "Ein Wechsel von der Enterprise Edition zur Community Edition ist nicht möglich",
+			MessageLicenseTransitionNotAllowed: "Ein Wechsel der Lizenz ist nicht erlaubt",
+			MessageChannelNotAllowed:           "Der Release-Kanal der Zielversion ist nicht erlaubt",
+			MessageTransitionNotAllowListed:    "Das Upgrade ist nicht in der Liste der erlaubten Upgrades",
+			MessageSourcePatchTooOld:           "Vor einem Upgrade auf %[2]s ist Version %[1]s oder neuer erforderlich",
+			MessageIntermediateRequired:        "Das Upgrade muss über Version %s erfolgen",
+			MessageVersionSkipped:              "Version %s ist gesperrt",
+			MessagePreReleaseTarget:            "Version %s ist eine Vorabversion",
+			MessageMinorSkipTooLarge:           "Die Nebenversion darf nur um bis zu %d erhöht werden",
+			MessageGAToPreRelease:              "Der Wechsel von %s zurück auf die Vorabversion %s ist nicht erlaubt",
+			MessageNoBinaries:                  "Für Version %s gibt es keine offiziellen %s-Binärdateien",
+			MessageFixMissing:                  "Version %s enthält die Korrektur für %s nicht",
+
+			MessagePreReleaseSourceWarning:       "Version %s ist eine Vorabversion, Upgrades von Vorabversionen werden nicht unterstützt",
+			MessagePreReleaseTargetWarning:       "Version %s ist eine Vorabversion, die nicht für den Produktivbetrieb gedacht ist",
+			MessageFirstReleaseWarning:           "Version %s ist das erste Release von %d.%d, warten Sie besser auf das erste Patch-Release",
+			MessageFirstReleaseLaterPatchWarning: "Version %s ist das erste Release von %d.%d, führen Sie besser ein Upgrade auf %s durch",
+		},
+		"ja": {
+			MessageMajorVersionDifferent:       "メジャーバージョンが異なります",
+			MessageMinorIncrementTooLarge:      "マイナーバージョンは1つずつしか上げられません",
+			MessageDowngradeNotPossible:        "ダウングレードはできません",
+			MessageEditionDowngradeNotPossible: "Enterprise エディションから Community エディションへの変更はできません",
+			MessageLicenseTransitionNotAllowed: "ライセンスの変更は許可されていません",
+			MessageChannelNotAllowed:           "ターゲットバージョンのリリースチャネルは許可されていません",
+			MessageTransitionNotAllowListed:    "このアップグレードは許可リストに含まれていません",
+			MessageSourcePatchTooOld:           "%[2]s へアップグレードする前に、バージョン %[1]s 以降が必要です",
+			MessageIntermediateRequired:        "アップグレードはバージョン %s を経由する必要があります",
+			MessageVersionSkipped:              "バージョン %s は使用禁止です",
+			MessagePreReleaseTarget:            "バージョン %s はプレリリースです",
+			MessageMinorSkipTooLarge:           "マイナーバージョンは最大%dつまでしか上げられません",
+			MessageGAToPreRelease:              "%s からプレリリース %s に戻すことはできません",
+			MessageNoBinaries:                  "バージョン %s には公式の %s バイナリがありません",
+			MessageFixMissing:                  "バージョン %s には %s の修正が含まれていません",
+
+			MessagePreReleaseSourceWarning:       "バージョン %s はプレリリースです。プレリリースからのアップグレードはサポートされていません",
+			MessagePreReleaseTargetWarning:       "バージョン %s はプレリリースであり、本番環境向けではありません",
+			MessageFirstReleaseWarning:           "バージョン %s は %d.%d の最初のリリースです。最初のパッチリリースを待つことを検討してください",
+			MessageFirstReleaseLaterPatchWarning: "バージョン %s は %d.%d の最初のリリースです。代わりに %s へのアップグレードを検討してください",
+		},
+	}
+)
+
+// CatalogFor returns a copy of the built-in catalog for the given language,
+// e.g. "de" or "ja-JP". Regions are ignored. When there is no catalog for
+// the language, the English catalog is returned.
+func CatalogFor(lang string) Catalog {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	c, found := catalogs[lang]
+	if !found {
+		c = catalogEnglish
+	}
+	result := make(Catalog, len(c))
+	for id, msg := range c {
+		result[id] = msg
+	}
+	return result
+}
+
+// Message returns the message for the given error in the language of the
+// catalog. If the error is not an *Error (or does not wrap one), or the
+// catalog has no message for it, err.Error() is returned.
+func (c Catalog) Message(err error) string {
+	if err == nil {
+		return ""
+	}
+	for e := err; e != nil; {
+		if x, ok := e.(*Error); ok {
+			if msg, found := c[x.MessageID]; found && x.MessageID != "" {
+				if len(x.Args) > 0 {
+					return fmt.Sprintf(msg, x.Args...)
+				}
+				return msg
+			}
+			break
+		}
+		u, ok := e.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		e = u.Unwrap()
+	}
+	return err.Error()
+}
+
+// Localize returns the reason of the given error in the given language,
+// see CatalogFor and Catalog.Message.
+func Localize(err error, lang string) string {
+	return CatalogFor(lang).Message(err)
+}
@@ -0,0 +1,80 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLocalize(t *testing.T) {
+	err := CheckUpgradeRules("3.3.8", "3.5.0")
+	tests := map[string]string{
+		"":      "Minor versions may only increment by 1",
+		"en":    "Minor versions may only increment by 1",
+		"fr":    "Minor versions may only increment by 1",
+		"de":    "Die Nebenversion darf nur um 1 erhöht werden",
+		"de-AT": "Die Nebenversion darf nur um 1 erhöht werden",
+		"ja_JP": "マイナーバージョンは1つずつしか上げられません",
+	}
+	for lang, expected := range tests {
+		if msg := Localize(err, lang); msg != expected {
+			t.Errorf("Localize(%q) = %q, expected %q", lang, msg, expected)
+		}
+	}
+	if msg := Localize(errors.New("custom"), "de"); msg != "custom" {
+		t.Errorf("Expected message of non *Error to be kept, got %q", msg)
+	}
+	if msg := Localize(retryableError{err}, "de"); msg != tests["de"] {
+		t.Errorf("Expected wrapped error to be localized, got %q", msg)
+	}
+	if msg := Localize(nil, "de"); msg != "" {
+		t.Errorf("Expected empty message for nil, got %q", msg)
+	}
+}
+
+func TestLocalizePromotedWarning(t *testing.T) {
+	d := Check("3.11.8", "3.12.0", WithWarningsAsErrors(WarningFirstRelease))
+	if msg := Localize(d.Err, "de"); msg != "Version 3.12.0 ist das erste Release von 3.12, warten Sie besser auf das erste Patch-Release" {
+		t.Errorf("Expected the promoted warning to be localized, got %q", msg)
+	}
+	d = Check("3.11.8", "3.12.0", WithWarningsAsErrors(WarningFirstRelease), WithReleases("3.12.0", "3.12.1"))
+	if msg := Localize(d.Err, "en"); msg != d.Warnings[0].Message {
+		t.Errorf("Expected the English message of the warning, got %q", msg)
+	}
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	for lang, c := range catalogs {
+		for id := range catalogEnglish {
+			if c[id] == "" {
+				t.Errorf("Catalog %s has no message for %s", lang, id)
+			}
+		}
+	}
+}
+
+func TestCatalogForReturnsCopy(t *testing.T) {
+	CatalogFor("de")[MessageDowngradeNotPossible] = "changed"
+	if msg := CatalogFor("de")[MessageDowngradeNotPossible]; msg == "changed" {
+		t.Error("CatalogFor must return a copy")
+	}
+}
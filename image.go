@@ -0,0 +1,235 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Image is a reference to a Docker image of ArangoDB, such as
+// "arangodb/enterprise:3.12.1".
+type Image struct {
+	// Registry is the host of the registry, e.g. "ghcr.io".
+	// It is empty for images on Docker Hub without an explicit registry.
+	Registry string
+	// Repository is the path of the image in the registry,
+	// e.g. "arangodb/enterprise" or "arangodb".
+	Repository string
+	// Tag is the tag of the image, e.g. "3.12.1". It may be empty.
+	Tag string
+	// Digest is the digest of the image, e.g. "sha256:...". It may be empty.
+	Digest string
+}
+
+// ResolvedImage is the result of resolving an Image.
+type ResolvedImage struct {
+	// Version of ArangoDB contained in the image
+	Version VersionString
+	// License of the edition contained in the image, if Licensed is set
+	License License
+	// Licensed is set when the edition of the image is known
+	Licensed bool
+	// Digest of the image manifest, e.g. "sha256:...", if known
+	Digest string
+}
+
+// ImageResolver determines the version and edition of images whose
+// tag is not a version, e.g. by inspecting the image in its registry.
+// See the registry package for an implementation.
+type ImageResolver interface {
+	// ResolveImage returns the version and edition of the given image.
+	// CheckImageUpgrade passes images in their mirror (see
+	// WithImageMirrors), so resolvers do not map mirrors themselves.
+	// Network failures, and errors marked with MarkRetryable, make the
+	// check retryable (see IsRetryable).
+	ResolveImage(ctx context.Context, img Image) (ResolvedImage, error)
+}
+
+// ImageResolverFunc is a function implementing ImageResolver.
+type ImageResolverFunc func(ctx context.Context, img Image) (ResolvedImage, error)
+
+// ResolveImage calls f.
+func (f ImageResolverFunc) ResolveImage(ctx context.Context, img Image) (ResolvedImage, error) {
+	return f(ctx, img)
+}
+
+// imageEditions maps the names of the ArangoDB images (the last
+// element of the repository) to the license of the edition they contain.
+var imageEditions = map[string]License{
+	"arangodb":           LicenseCommunity,
+	"arangodb-preview":   LicenseCommunity,
+	"enterprise":         LicenseEnterprise,
+	"enterprise-preview": LicenseEnterprise,
+}
+
+// tagEditions maps suffixes of tags, as in "3.12.1-enterprise", to the
+// license of the edition the image contains.
+var tagEditions = map[string]License{
+	"-community":  LicenseCommunity,
+	"-enterprise": LicenseEnterprise,
+}
+
+// EditionInferrer returns the license of the edition contained in the
+// given image, or false when it does not know the image.
+// Use it for private mirrors that rename the ArangoDB images.
+type EditionInferrer func(img Image) (License, bool)
+
+// ParseImage splits a reference of the form
+// [registry/]repository[:tag][@digest] into its parts.
+// The first element of the path is taken as registry when it contains
+// a '.' or a ':', or is "localhost", as Docker does.
+func ParseImage(ref string) (Image, error) {
+	var img Image
+	rest := ref
+	if i := strings.IndexByte(rest, '@'); i >= 0 {
+		rest, img.Digest = rest[:i], rest[i+1:]
+	}
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		if first := rest[:i]; strings.ContainsAny(first, ".:") || first == "localhost" {
+			img.Registry, rest = first, rest[i+1:]
+		}
+	}
+	if i := strings.LastIndexByte(rest, ':'); i >= 0 && !strings.Contains(rest[i:], "/") {
+		rest, img.Tag = rest[:i], rest[i+1:]
+	}
+	img.Repository = rest
+	if img.Repository == "" || strings.HasPrefix(img.Repository, "/") || strings.HasSuffix(img.Repository, "/") {
+		return Image{}, &InputError{Field: "image", Value: truncateRaw(ref), Reason: "image has no repository"}
+	}
+	return img, nil
+}
+
+// Name returns the last element of the repository, e.g. "enterprise".
+func (i Image) Name() string {
+	return i.Repository[strings.LastIndexByte(i.Repository, '/')+1:]
+}
+
+// Version returns the tag as version, without an edition suffix
+// such as "-enterprise".
+func (i Image) Version() VersionString {
+	for suffix := range tagEditions {
+		if strings.HasSuffix(i.Tag, suffix) {
+			return VersionString(strings.TrimSuffix(i.Tag, suffix))
+		}
+	}
+	return VersionString(i.Tag)
+}
+
+// License returns the license of the edition contained in the image,
+// or false when the image is not a known ArangoDB image.
+// It is inferred from the edition suffix of the tag (e.g. "-enterprise"),
+// or else from the name of the image (e.g. "arangodb/enterprise").
+func (i Image) License() (License, bool) {
+	for suffix, l := range tagEditions {
+		if strings.HasSuffix(i.Tag, suffix) {
+			return l, true
+		}
+	}
+	l, found := imageEditions[i.Name()]
+	return l, found
+}
+
+// String returns the image reference.
+func (i Image) String() string {
+	s := i.Repository
+	if i.Registry != "" {
+		s = i.Registry + "/" + s
+	}
+	if i.Tag != "" {
+		s += ":" + i.Tag
+	}
+	if i.Digest != "" {
+		s += "@" + i.Digest
+	}
+	return s
+}
+
+// CheckImageUpgrade checks an upgrade from one ArangoDB image to another,
+// e.g. from "arangodb/arangodb:3.11.8" to "arangodb/enterprise:3.12.1".
+// The versions are taken from the tags and the licenses from the
+// editions of the images, so the checks include the licenses.
+//
+// The edition of an image is inferred by the EditionInferrer set by
+// WithEditionInferrer and otherwise by Image.License of its upstream
+// image (see WithImageMirrors).
+// When the tag of an image is not a version, the image is resolved
+// using the resolver set by WithImageResolver. Without a resolver, images
+// that cannot be parsed, are not ArangoDB images or do not have a version
+// as tag result in an *InputError.
+func CheckImageUpgrade(fromImage, toImage string, opts ...Option) (Decision, error) {
+	o := newOptions(opts)
+	from, err := resolveImageForCheck(o, "fromImage", fromImage)
+	if err != nil {
+		return Decision{}, err
+	}
+	to, err := resolveImageForCheck(o, "toImage", toImage)
+	if err != nil {
+		return Decision{}, err
+	}
+	base := defaultOptions()
+	base.setLicenses(from.License, to.License)
+	return check(ParseVersion(from.Version), ParseVersion(to.Version), base, opts), nil
+}
+
+// resolveImageForCheck parses the given image reference and determines
+// its version and license, returning an *InputError for the given field
+// when it cannot be checked.
+func resolveImageForCheck(o options, field, ref string) (ResolvedImage, error) {
+	invalid := func(reason string) error {
+		return &InputError{Field: field, Value: truncateRaw(ref), Reason: reason}
+	}
+	img, err := ParseImage(ref)
+	if err != nil {
+		return ResolvedImage{}, invalid(err.(*InputError).Reason)
+	}
+	l, found := o.imageMirrors.ToUpstream(img).License()
+	if o.editionInferrer != nil {
+		if il, ifound := o.editionInferrer(img); ifound {
+			l, found = il, true
+		}
+	}
+	result := ResolvedImage{Version: img.Version(), License: l, Licensed: found, Digest: img.Digest}
+	if img.Tag == "" || validateRawVersion(field, string(img.Version())) != nil {
+		if o.imageResolver == nil {
+			if img.Tag == "" {
+				return ResolvedImage{}, invalid("image has no tag")
+			}
+			return ResolvedImage{}, invalid("tag is not a version")
+		}
+		resolved, err := o.imageResolver.ResolveImage(o.ctx, o.imageMirrors.ToMirror(img))
+		if err != nil {
+			return ResolvedImage{}, markNetworkError(fmt.Errorf("Failed to resolve image %s: %w", truncateRaw(ref), err))
+		}
+		if !resolved.Licensed {
+			resolved.License, resolved.Licensed = l, found
+		}
+		if validateRawVersion(field, string(resolved.Version)) != nil {
+			return ResolvedImage{}, invalid(fmt.Sprintf("image has invalid version '%s'", truncateRaw(string(resolved.Version))))
+		}
+		result = resolved
+	}
+	if !result.Licensed {
+		return ResolvedImage{}, invalid("not an ArangoDB image")
+	}
+	return result, nil
+}
@@ -0,0 +1,197 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestParseImage(t *testing.T) {
+	tests := []struct {
+		Ref      string
+		Expected Image
+	}{
+		{"arangodb/arangodb:3.11.8", Image{Repository: "arangodb/arangodb", Tag: "3.11.8"}},
+		{"arangodb:3.11.8", Image{Repository: "arangodb", Tag: "3.11.8"}},
+		{"arangodb/enterprise", Image{Repository: "arangodb/enterprise"}},
+		{"docker.io/arangodb/enterprise:3.12.1", Image{Registry: "docker.io", Repository: "arangodb/enterprise", Tag: "3.12.1"}},
+		{"localhost:5000/arangodb/enterprise:3.12.1@sha256:abc", Image{Registry: "localhost:5000", Repository: "arangodb/enterprise", Tag: "3.12.1", Digest: "sha256:abc"}},
+		{"registry.example.com/mirror/enterprise@sha256:abc", Image{Registry: "registry.example.com", Repository: "mirror/enterprise", Digest: "sha256:abc"}},
+	}
+	for _, test := range tests {
+		img, err := ParseImage(test.Ref)
+		if err != nil {
+			t.Errorf("ParseImage(%q) failed: %s", test.Ref, err)
+		} else if img != test.Expected {
+			t.Errorf("ParseImage(%q): expected %+v, got %+v", test.Ref, test.Expected, img)
+		} else if img.String() != test.Ref {
+			t.Errorf("Expected %q, got %q", test.Ref, img.String())
+		}
+	}
+	for _, ref := range []string{"", ":3.11.8", "docker.io/", "@sha256:abc"} {
+		if _, err := ParseImage(ref); err == nil {
+			t.Errorf("ParseImage(%q) should fail", ref)
+		}
+	}
+}
+
+func TestImageLicense(t *testing.T) {
+	tests := []struct {
+		Ref     string
+		License License
+		Found   bool
+	}{
+		{"arangodb/arangodb:3.11.8", LicenseCommunity, true},
+		{"arangodb:3.11.8", LicenseCommunity, true},
+		{"arangodb/enterprise:3.12.1", LicenseEnterprise, true},
+		{"arangodb/enterprise-preview:3.12.0-rc.1", LicenseEnterprise, true},
+		{"arangodb/kube-arangodb:1.2.40", LicenseCommunity, false},
+		{"arangodb/arangodb:3.12.1-enterprise", LicenseEnterprise, true},
+		{"registry.example.com/db:3.11.8-community", LicenseCommunity, true},
+		{"registry.example.com/db:3.11.8", LicenseCommunity, false},
+	}
+	for _, test := range tests {
+		img, _ := ParseImage(test.Ref)
+		l, found := img.License()
+		if l != test.License || found != test.Found {
+			t.Errorf("%s: expected %s, %t, got %s, %t", test.Ref, test.License, test.Found, l, found)
+		}
+	}
+}
+
+func TestCheckImageUpgrade(t *testing.T) {
+	d, err := CheckImageUpgrade("arangodb/arangodb:3.11.8", "arangodb/enterprise:3.12.1")
+	if err != nil || !d.Allowed() {
+		t.Errorf("Expected community 3.11.8 -> enterprise 3.12.1 to be allowed, got %v, %v", d.Err, err)
+	}
+	if !d.Licensed || d.FromLicense != LicenseCommunity || d.ToLicense != LicenseEnterprise || d.From != "3.11.8" || d.To != "3.12.1" {
+		t.Errorf("Unexpected decision %+v", d)
+	}
+	d, err = CheckImageUpgrade("arangodb/enterprise:3.11.8", "arangodb/arangodb:3.12.1")
+	if err != nil || d.Rule != RuleEditionDowngrade {
+		t.Errorf("Expected denial by %s, got %q, %v", RuleEditionDowngrade, d.Rule, err)
+	}
+	d, err = CheckImageUpgrade("arangodb/enterprise:3.10.8", "arangodb/enterprise:3.12.1", WithSoft())
+	if err != nil || !d.Allowed() || !d.Soft {
+		t.Errorf("Expected soft check to be allowed, got %v, %v", d.Err, err)
+	}
+}
+
+func TestCheckImageUpgradeInvalidInput(t *testing.T) {
+	tests := []struct {
+		From, To string
+		Field    string
+	}{
+		{"", "arangodb/enterprise:3.12.1", "fromImage"},
+		{"nginx:1.25", "arangodb/enterprise:3.12.1", "fromImage"},
+		{"arangodb/enterprise:3.11.8", "arangodb/enterprise", "toImage"},
+		{"arangodb/enterprise:3.11.8", "arangodb/enterprise:latest", "toImage"},
+	}
+	for _, test := range tests {
+		_, err := CheckImageUpgrade(test.From, test.To)
+		if e, ok := err.(*InputError); !ok || e.Field != test.Field {
+			t.Errorf("CheckImageUpgrade(%q, %q) should return an *InputError for %s, got %v", test.From, test.To, test.Field, err)
+		}
+	}
+}
+
+func TestCheckImageUpgradeWithResolver(t *testing.T) {
+	notFound := errors.New("not found")
+	var resolved []string
+	resolver := WithImageResolver(ImageResolverFunc(func(ctx context.Context, img Image) (ResolvedImage, error) {
+		resolved = append(resolved, img.String())
+		switch img.String() {
+		case "arangodb/enterprise:latest":
+			return ResolvedImage{Version: "3.12.1", Digest: "sha256:abc"}, nil
+		case "registry.example.com/db@sha256:def":
+			return ResolvedImage{Version: "3.11.8", License: LicenseEnterprise, Licensed: true}, nil
+		case "arangodb/enterprise:custom":
+			return ResolvedImage{Version: "unknown"}, nil
+		case "arangodb/enterprise:unreachable":
+			return ResolvedImage{}, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		case "arangodb/enterprise:unavailable":
+			return ResolvedImage{}, MarkRetryable(errors.New("503 Service Unavailable"))
+		}
+		return ResolvedImage{}, notFound
+	}))
+	d, err := CheckImageUpgrade("registry.example.com/db@sha256:def", "arangodb/enterprise:latest", resolver)
+	if err != nil || !d.Allowed() || d.From != "3.11.8" || d.To != "3.12.1" || d.FromLicense != LicenseEnterprise || d.ToLicense != LicenseEnterprise {
+		t.Errorf("Unexpected decision %+v, %v", d, err)
+	}
+	if len(resolved) != 2 {
+		t.Errorf("Expected both images to be resolved, got %v", resolved)
+	}
+	if _, err := CheckImageUpgrade("arangodb/enterprise:3.11.8", "arangodb/enterprise:missing", resolver); !errors.Is(err, notFound) || IsRetryable(err) {
+		t.Errorf("Expected terminal resolver error to be returned, got %#v", err)
+	}
+	for _, tag := range []string{"unreachable", "unavailable"} {
+		if _, err := CheckImageUpgrade("arangodb/enterprise:3.11.8", "arangodb/enterprise:"+tag, resolver); !IsRetryable(err) {
+			t.Errorf("Expected retryable error for %s, got %#v", tag, err)
+		}
+	}
+	if _, err := CheckImageUpgrade("arangodb/enterprise:3.11.8", "arangodb/enterprise:custom", resolver); err == nil {
+		t.Error("Expected invalid resolved version to fail")
+	}
+	resolved = nil
+	if _, err := CheckImageUpgrade("arangodb/enterprise:3.11.8", "arangodb/enterprise:3.12.1", resolver); err != nil || len(resolved) != 0 {
+		t.Errorf("Expected images with version tags not to be resolved, got %v, %v", resolved, err)
+	}
+}
+
+func TestImageVersion(t *testing.T) {
+	for ref, expected := range map[string]VersionString{
+		"arangodb/enterprise:3.12.1":                "3.12.1",
+		"arangodb/enterprise:3.12.0-rc.1":           "3.12.0-rc.1",
+		"registry.example.com/db:3.12.1-enterprise": "3.12.1",
+		"arangodb/enterprise":                       "",
+	} {
+		img, _ := ParseImage(ref)
+		if v := img.Version(); v != expected {
+			t.Errorf("%s: expected version %q, got %q", ref, expected, v)
+		}
+	}
+}
+
+func TestCheckImageUpgradeWithEditionInferrer(t *testing.T) {
+	inferrer := WithEditionInferrer(func(img Image) (License, bool) {
+		switch img.Repository {
+		case "mirror/arangodb-ee":
+			return LicenseEnterprise, true
+		case "mirror/arangodb-ce":
+			return LicenseCommunity, true
+		}
+		return LicenseCommunity, false
+	})
+	d, err := CheckImageUpgrade("registry.example.com/mirror/arangodb-ee:3.11.8", "registry.example.com/mirror/arangodb-ce:3.12.1", inferrer)
+	if err != nil || d.Rule != RuleEditionDowngrade {
+		t.Errorf("Expected denial by %s, got %q, %v", RuleEditionDowngrade, d.Rule, err)
+	}
+	d, err = CheckImageUpgrade("arangodb/arangodb:3.11.8-enterprise", "arangodb/enterprise:3.12.1", inferrer)
+	if err != nil || !d.Allowed() || d.FromLicense != LicenseEnterprise || d.From != "3.11.8" {
+		t.Errorf("Expected fallback to the tag suffix, got %+v, %v", d, err)
+	}
+	if _, err := CheckImageUpgrade("registry.example.com/mirror/other:3.11.8", "arangodb/enterprise:3.12.1", inferrer); err == nil {
+		t.Error("Expected unknown image to fail")
+	}
+}
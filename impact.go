@@ -0,0 +1,85 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// Impact describes the expected effect of an upgrade on a deployment,
+// so schedulers can estimate the downtime it needs.
+type Impact struct {
+	// RollingRestart is set when the servers can be upgraded one at a
+	// time, while the deployment stays available
+	RollingRestart bool `json:"rollingRestart"`
+	// AutoUpgrade is set when the database files must be converted by
+	// starting the servers with --database.auto-upgrade
+	AutoUpgrade bool `json:"autoUpgrade"`
+	// IndexRebuild is set when (some) indexes are rebuilt after the upgrade
+	IndexRebuild bool `json:"indexRebuild"`
+}
+
+// IsZero returns true when the upgrade has no impact, which is the case
+// when the version does not change.
+func (i Impact) IsZero() bool {
+	return i == Impact{}
+}
+
+// impactChange adds to the impact of upgrades into a minor version.
+// Its RollingRestart field is not used.
+type impactChange struct {
+	major, minor int
+	impact       Impact
+}
+
+// impactChanges is the embedded table of minor versions whose upgrade
+// has more impact than the transition kind implies.
+var impactChanges = []impactChange{
+	// 3.12 changed the sort order of numbers in persistent indexes
+	{major: 3, minor: 12, impact: Impact{IndexRebuild: true}},
+}
+
+// ImpactOf returns the expected impact of an upgrade from `from` to `to`.
+// Patch upgrades only need a rolling restart. Minor upgrades also need
+// the auto-upgrade phase. Major upgrades cannot be done by a rolling
+// restart. Upgrades into versions listed in an embedded table can have
+// more impact, e.g. index rebuilds.
+func ImpactOf(from, to ParsedVersion) Impact {
+	var i Impact
+	switch TransitionOf(from, to) {
+	case TransitionNone:
+		return i
+	case TransitionPatch:
+		i.RollingRestart = true
+	case TransitionMinor:
+		i.RollingRestart, i.AutoUpgrade = true, true
+	case TransitionMajor:
+		i.AutoUpgrade = true
+	}
+	for _, c := range impactChanges {
+		if versionBefore(from, c.major, c.minor) && !versionBefore(to, c.major, c.minor) {
+			i.AutoUpgrade = i.AutoUpgrade || c.impact.AutoUpgrade
+			i.IndexRebuild = i.IndexRebuild || c.impact.IndexRebuild
+		}
+	}
+	return i
+}
+
+// versionBefore returns true when v is a version before major.minor.
+func versionBefore(v ParsedVersion, major, minor int) bool {
+	return v.major < major || v.major == major && v.minor < minor
+}
@@ -0,0 +1,55 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestImpactOf(t *testing.T) {
+	tests := []struct {
+		From, To VersionString
+		Expected Impact
+	}{
+		{"3.11.8", "3.11.8", Impact{}},
+		{"3.11.8", "3.11.9", Impact{RollingRestart: true}},
+		{"3.10.8", "3.11.0", Impact{RollingRestart: true, AutoUpgrade: true}},
+		{"3.11.8", "3.12.1", Impact{RollingRestart: true, AutoUpgrade: true, IndexRebuild: true}},
+		{"3.10.8", "3.12.1", Impact{RollingRestart: true, AutoUpgrade: true, IndexRebuild: true}},
+		{"3.12.1", "3.12.4", Impact{RollingRestart: true}},
+		{"3.12.4", "4.0.0", Impact{AutoUpgrade: true}},
+		{"3.11.8", "4.0.0", Impact{AutoUpgrade: true, IndexRebuild: true}},
+	}
+	for _, test := range tests {
+		if i := ImpactOf(ParseVersion(test.From), ParseVersion(test.To)); i != test.Expected {
+			t.Errorf("%s -> %s: expected %+v, got %+v", test.From, test.To, test.Expected, i)
+		}
+	}
+}
+
+func TestDecisionImpact(t *testing.T) {
+	if d := Check("3.11.8", "3.11.9"); d.Impact != (Impact{RollingRestart: true}) {
+		t.Errorf("Unexpected impact %+v", d.Impact)
+	}
+	if d := Check("3.10.8", "3.12.1"); !d.Impact.IsZero() {
+		t.Errorf("Expected no impact for a denied upgrade, got %+v", d.Impact)
+	}
+}
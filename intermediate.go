@@ -0,0 +1,96 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+)
+
+// RuleMandatoryIntermediate denies upgrades that skip a version every
+// upgrade path must pass through, see WithMandatoryIntermediates.
+const RuleMandatoryIntermediate RuleID = "mandatory-intermediate"
+
+// Intermediate requires upgrades from versions before minor version
+// Before to versions of minor version Since or later to pass through
+// minor version Via, e.g. {Before: "3.6", Since: "3.8", Via: "3.7"}.
+// Only the major and minor components of the versions are used.
+type Intermediate struct {
+	// Before is the first minor version that is not affected as source
+	Before VersionString
+	// Since is the first minor version that is affected as target
+	Since VersionString
+	// Via is the minor version that must be passed; planners stop at
+	// its latest patch release
+	Via VersionString
+}
+
+// requiredFor returns true when an upgrade from `from` to `to` skips Via.
+func (i Intermediate) requiredFor(from, to ParsedVersion) bool {
+	before, since := ParseVersion(i.Before), ParseVersion(i.Since)
+	return versionBefore(from, before.major, before.minor) && !versionBefore(to, since.major, since.minor)
+}
+
+// WithMandatoryIntermediates adds intermediate versions that upgrades
+// must pass through. Upgrades skipping one are denied by
+// RuleMandatoryIntermediate, which can be overridden.
+func WithMandatoryIntermediates(intermediates ...Intermediate) Option {
+	return func(o *options) {
+		o.intermediates = append(append([]Intermediate(nil), o.intermediates...), intermediates...)
+	}
+}
+
+// MandatoryIntermediates returns the intermediate versions configured by
+// the given options, for planners that must stop at them.
+func MandatoryIntermediates(opts ...Option) []Intermediate {
+	return append([]Intermediate(nil), newOptions(opts).intermediates...)
+}
+
+// IntermediateError details a denial by RuleMandatoryIntermediate.
+type IntermediateError struct {
+	From VersionString
+	To   VersionString
+	// Via is the minor version the upgrade must pass through
+	Via VersionString
+}
+
+// Error describes the skipped intermediate version.
+func (e *IntermediateError) Error() string {
+	return fmt.Sprintf("Upgrade from %s to %s must pass through version %s", e.From, e.To, e.Via)
+}
+
+// ruleMandatoryIntermediate implements RuleMandatoryIntermediate.
+var ruleMandatoryIntermediate = Rule{
+	ID:          RuleMandatoryIntermediate,
+	Applies:     func(in RuleInput) bool { return len(in.Intermediates) > 0 },
+	Check:       func(in RuleInput) error { return checkIntermediates(in.Intermediates, in.From, in.To) },
+	Overridable: true,
+}
+
+// checkIntermediates implements RuleMandatoryIntermediate.
+func checkIntermediates(intermediates []Intermediate, from, to ParsedVersion) error {
+	for _, i := range intermediates {
+		if i.requiredFor(from, to) {
+			cause := &IntermediateError{From: from.version, To: to.version, Via: i.Via}
+			return newCausedError(cause, RuleMandatoryIntermediate, MessageIntermediateRequired, string(i.Via))
+		}
+	}
+	return nil
+}
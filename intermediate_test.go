@@ -0,0 +1,62 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestWithMandatoryIntermediates(t *testing.T) {
+	opts := []Option{WithSoft(), WithMandatoryIntermediates(Intermediate{Before: "3.6", Since: "3.8", Via: "3.7"})}
+	tests := []struct {
+		From, To VersionString
+		Rule     RuleID
+	}{
+		{"3.5.4", "3.8.0", RuleMandatoryIntermediate},
+		{"3.5.4", "3.9.1", RuleMandatoryIntermediate},
+		{"3.5.4", "3.7.2", ""},
+		{"3.6.1", "3.8.0", ""},
+		{"3.7.2", "3.8.0", ""},
+	}
+	for _, test := range tests {
+		if d := Check(test.From, test.To, opts...); d.Rule != test.Rule {
+			t.Errorf("%s -> %s: expected rule %q, got %q", test.From, test.To, test.Rule, d.Rule)
+		}
+	}
+	d := Check("3.5.4", "3.8.0", opts...)
+	if msg := d.Err.Error(); msg != "Upgrade must pass through version 3.7 (from 3.5.4 to 3.8.0)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	d = Check("3.5.4", "3.8.0", append(opts, WithOverrides(Override{From: "3.5", To: "3.8"}))...)
+	if d.Outcome() != OutcomeOverridden {
+		t.Errorf("Expected override, got %s", d.Outcome())
+	}
+}
+
+func TestMandatoryIntermediates(t *testing.T) {
+	i := Intermediate{Before: "3.6", Since: "3.8", Via: "3.7"}
+	if len(MandatoryIntermediates()) != 0 {
+		t.Error("Expected no intermediates by default")
+	}
+	if x := MandatoryIntermediates(WithSoft(), WithMandatoryIntermediates(i)); len(x) != 1 || x[0] != i {
+		t.Errorf("Unexpected intermediates %v", x)
+	}
+}
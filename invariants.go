@@ -0,0 +1,203 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"time"
+)
+
+// ViolationKind identifies the invariant broken by a Violation.
+type ViolationKind string
+
+const (
+	// ViolationInvalidRule means a rule has no ID or no Check function
+	ViolationInvalidRule ViolationKind = "invalid-rule"
+	// ViolationDuplicateRule means two rules have the same ID
+	ViolationDuplicateRule ViolationKind = "duplicate-rule"
+	// ViolationPanic means a rule panicked
+	ViolationPanic ViolationKind = "panic"
+	// ViolationNondeterministic means an upgrade is both allowed and denied
+	// when evaluated repeatedly
+	ViolationNondeterministic ViolationKind = "nondeterministic"
+	// ViolationSoftStricter means the soft rules deny an upgrade that the
+	// strict rules allow
+	ViolationSoftStricter ViolationKind = "soft-stricter"
+	// ViolationDowngradeOnly means a downgrade is allowed, but upgrading
+	// back again is not
+	ViolationDowngradeOnly ViolationKind = "downgrade-only"
+	// ViolationExpiredOverride means an override has expired
+	ViolationExpiredOverride ViolationKind = "expired-override"
+)
+
+// Violation is a broken invariant found by VerifyInvariants.
+type Violation struct {
+	Kind ViolationKind
+	// Rule is the rule involved, if any
+	Rule RuleID
+	// From and To describe the upgrade involved, if any
+	From VersionString
+	To   VersionString
+	// Message describes the violation
+	Message string
+}
+
+// String returns a human readable description of the violation.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Kind, v.Message)
+}
+
+// invariantVersions are the versions VerifyInvariants evaluates the rules for.
+var invariantVersions = func() []ParsedVersion {
+	var result []ParsedVersion
+	for major := 3; major <= 4; major++ {
+		for minor := 0; minor <= 3; minor++ {
+			for patch := 0; patch <= 1; patch++ {
+				result = append(result, ParseVersion(VersionString(fmt.Sprintf("%d.%d.%d", major, minor, patch))))
+			}
+		}
+	}
+	return result
+}()
+
+// VerifyInvariants checks structural properties of the given rule set
+// and returns the violations found, or nil if there are none:
+//
+//   - every rule has an ID and a Check function, and IDs are unique
+//   - rules do not panic, and evaluate an upgrade the same way every time
+//   - the soft rules allow every upgrade the strict rules allow
+//   - when a downgrade is allowed, upgrading back again (with the same
+//     licenses) is allowed too
+//   - none of the overrides given with WithOverrides has expired,
+//     according to the clock given with WithClock
+//
+// The rules are evaluated for a fixed set of 3.x and 4.x versions, with
+// and without all combinations of licenses. It is meant to be run once,
+// e.g. at startup, against a customer supplied rule set.
+func VerifyInvariants(s *RuleSet, opts ...Option) []Violation {
+	o := newOptions(opts)
+	var result []Violation
+	seen := make(map[RuleID]bool)
+	var valid []Rule
+	for i, r := range s.rules {
+		switch {
+		case r.ID == "":
+			result = append(result, Violation{Kind: ViolationInvalidRule, Message: fmt.Sprintf("Rule %d has no ID", i)})
+			continue
+		case r.Check == nil:
+			result = append(result, Violation{Kind: ViolationInvalidRule, Rule: r.ID, Message: fmt.Sprintf("Rule %s has no Check function", r.ID)})
+			continue
+		case seen[r.ID]:
+			result = append(result, Violation{Kind: ViolationDuplicateRule, Rule: r.ID, Message: fmt.Sprintf("Rule %s is defined more than once", r.ID)})
+		}
+		seen[r.ID] = true
+		valid = append(valid, r)
+	}
+	reported := make(map[Violation]bool)
+	report := func(v Violation) {
+		if !reported[v] {
+			reported[v] = true
+			result = append(result, v)
+		}
+	}
+	licenses := [][2]License{
+		{LicenseCommunity, LicenseCommunity},
+		{LicenseCommunity, LicenseEnterprise},
+		{LicenseEnterprise, LicenseCommunity},
+		{LicenseEnterprise, LicenseEnterprise},
+	}
+	for _, from := range invariantVersions {
+		for _, to := range invariantVersions {
+			for l := -1; l < len(licenses); l++ {
+				in := RuleInput{Context: o.ctx, From: from, To: to}
+				if l >= 0 {
+					in.Licensed, in.FromLicense, in.ToLicense = true, licenses[l][0], licenses[l][1]
+				}
+				strict := verifyUpgrade(valid, in, report)
+				in.Soft = true
+				soft := verifyUpgrade(valid, in, report)
+				if strict == "" && soft != "" {
+					report(Violation{Kind: ViolationSoftStricter, Rule: soft, From: from.version, To: to.version,
+						Message: fmt.Sprintf("Soft rules deny %s -> %s, which the strict rules allow", from, to)})
+				}
+				for _, soft := range []bool{false, true} {
+					in.Soft = soft
+					if to.version.CompareTo(from.version) >= 0 || verifyUpgrade(valid, in, report) != "" {
+						continue
+					}
+					back := in
+					back.From, back.To = to, from
+					if rule := verifyUpgrade(valid, back, report); rule != "" {
+						report(Violation{Kind: ViolationDowngradeOnly, Rule: rule, From: from.version, To: to.version,
+							Message: fmt.Sprintf("Downgrade %s -> %s is allowed, but %s -> %s is denied", from, to, to, from)})
+					}
+				}
+			}
+		}
+	}
+	now := o.now()
+	for _, x := range o.overrides {
+		if x.Expired(now) {
+			result = append(result, Violation{Kind: ViolationExpiredOverride, From: x.From, To: x.To,
+				Message: fmt.Sprintf("Override %s expired at %s", x, x.Expires.Format(time.RFC3339))})
+		}
+	}
+	return result
+}
+
+// verifyUpgrade evaluates the given rules twice and returns the rule
+// denying the upgrade, or an empty ID when the rules allow it.
+// Panics and differences between both evaluations are reported.
+func verifyUpgrade(rules []Rule, in RuleInput, report func(Violation)) RuleID {
+	for _, r := range rules {
+		first, panicked := verifyRule(r, in, report)
+		if panicked {
+			continue
+		}
+		second, panicked := verifyRule(r, in, report)
+		if panicked {
+			continue
+		}
+		if first != second {
+			report(Violation{Kind: ViolationNondeterministic, Rule: r.ID, From: in.From.version, To: in.To.version,
+				Message: fmt.Sprintf("Rule %s both allows and denies %s -> %s", r.ID, in.From, in.To)})
+		}
+		if first {
+			return r.ID
+		}
+	}
+	return ""
+}
+
+// verifyRule evaluates a single rule and returns true when it denies the upgrade.
+func verifyRule(r Rule, in RuleInput, report func(Violation)) (denied, panicked bool) {
+	defer func() {
+		if p := recover(); p != nil {
+			report(Violation{Kind: ViolationPanic, Rule: r.ID, From: in.From.version, To: in.To.version,
+				Message: fmt.Sprintf("Rule %s panicked for %s -> %s: %v", r.ID, in.From, in.To, p)})
+			denied, panicked = false, true
+		}
+	}()
+	if !r.applies(in) {
+		return false, false
+	}
+	return r.Check(in) != nil, false
+}
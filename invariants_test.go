@@ -0,0 +1,108 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyInvariantsDefaultRuleSet(t *testing.T) {
+	if violations := VerifyInvariants(DefaultRuleSet()); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+}
+
+func TestVerifyInvariants(t *testing.T) {
+	calls := 0
+	flaky := Rule{
+		ID: "flaky",
+		Check: func(in RuleInput) error {
+			calls++
+			if calls%2 == 0 {
+				return errors.New("Denied every other time")
+			}
+			return nil
+		},
+	}
+	softOnlyPatch := Rule{
+		ID:      "soft-only-patch",
+		Applies: func(in RuleInput) bool { return in.Soft },
+		Check: func(in RuleInput) error {
+			if in.From.Minor() != in.To.Minor() {
+				return errors.New("Soft rules only allow patch upgrades")
+			}
+			return nil
+		},
+	}
+	noPatchUpgrade := Rule{
+		ID: "no-patch-upgrade",
+		Check: func(in RuleInput) error {
+			if in.From.Minor() == in.To.Minor() && in.From.Sub() < in.To.Sub() {
+				return errors.New("Patch upgrades are not allowed")
+			}
+			return nil
+		},
+	}
+	panicking := Rule{
+		ID:    "panic",
+		Check: func(in RuleInput) error { panic("boom") },
+	}
+	tests := []struct {
+		Rule Rule
+		Kind ViolationKind
+	}{
+		{Rule{ID: "no-check"}, ViolationInvalidRule},
+		{Rule{Check: checkMajorVersion}, ViolationInvalidRule},
+		{ruleMajorVersion, ViolationDuplicateRule},
+		{flaky, ViolationNondeterministic},
+		{softOnlyPatch, ViolationSoftStricter},
+		{noPatchUpgrade, ViolationDowngradeOnly},
+		{panicking, ViolationPanic},
+	}
+	for _, test := range tests {
+		violations := VerifyInvariants(NewRuleSet(ruleMajorVersion, test.Rule))
+		if len(violations) == 0 {
+			t.Errorf("Expected %s violation for rule '%s', got none", test.Kind, test.Rule.ID)
+			continue
+		}
+		for _, v := range violations {
+			if v.Kind != test.Kind {
+				t.Errorf("Expected only %s violations for rule '%s', got %s", test.Kind, test.Rule.ID, v)
+			}
+		}
+	}
+}
+
+func TestVerifyInvariantsExpiredOverride(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	violations := VerifyInvariants(DefaultRuleSet(),
+		WithClock(func() time.Time { return now }),
+		WithOverrides(
+			Override{From: "3.9", To: "3.11", Expires: now.Add(-time.Hour)},
+			Override{From: "3.10", To: "3.12", Expires: now.Add(time.Hour)},
+			Override{From: "3.8", To: "3.10"},
+		))
+	if len(violations) != 1 || violations[0].Kind != ViolationExpiredOverride || violations[0].From != "3.9" {
+		t.Errorf("Expected a single expired override violation, got %v", violations)
+	}
+}
@@ -0,0 +1,232 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// MarshalJSON encodes the license as its name.
+func (l License) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON decodes a license from its name.
+func (l *License) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	parsed, err := ParseLicense(name)
+	if err != nil {
+		return err
+	}
+	*l = parsed
+	return nil
+}
+
+// errorCodeOther is the code used in the JSON representation of
+// errors that are not an *Error.
+const errorCodeOther = "Error"
+
+// errorJSON is the JSON representation of the errors of a decision.
+// Next and Unmet are only set for the errors that have them.
+type errorJSON struct {
+	Code        string                  `json:"code"`
+	Rule        RuleID                  `json:"rule,omitempty"`
+	Message     string                  `json:"message"`
+	From        VersionString           `json:"from,omitempty"`
+	To          VersionString           `json:"to,omitempty"`
+	FromLicense *License                `json:"fromLicense,omitempty"`
+	ToLicense   *License                `json:"toLicense,omitempty"`
+	Next        *time.Time              `json:"next,omitempty"`
+	Unmet       []unmetPreconditionJSON `json:"unmet,omitempty"`
+}
+
+// unmetPreconditionJSON is the JSON representation of an UnmetPrecondition.
+type unmetPreconditionJSON struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// newErrorJSON returns the JSON representation of the given error with
+// the given code and rule, and the transition. The message is the reason
+// of the error, without the transition (see Reason).
+func newErrorJSON(code string, rule RuleID, err error, from, to VersionString, licensed bool, fromLicense, toLicense License) errorJSON {
+	v := errorJSON{
+		Code:    code,
+		Rule:    rule,
+		Message: Reason(err),
+		From:    from,
+		To:      to,
+	}
+	if licensed {
+		v.FromLicense, v.ToLicense = &fromLicense, &toLicense
+	}
+	return v
+}
+
+// MarshalJSON encodes the error as an object with code, rule,
+// message, versions and licenses.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newErrorJSON(ErrorCodeUpgradeNotAllowed, e.Rule, e, e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense))
+}
+
+// MarshalJSON encodes the error like Error.MarshalJSON, with the code
+// "Error" and the message of the wrapped error.
+func (e *TransitionError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newErrorJSON(errorCodeOther, e.Rule, e.Err, e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense))
+}
+
+// MarshalJSON encodes the error like Error.MarshalJSON, followed by the
+// unmet preconditions.
+func (e *PreconditionError) MarshalJSON() ([]byte, error) {
+	v := newErrorJSON(ErrorCodePreconditionsNotMet, RulePreconditions, e, e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+	v.Unmet = make([]unmetPreconditionJSON, 0, len(e.Unmet))
+	for _, u := range e.Unmet {
+		v.Unmet = append(v.Unmet, unmetPreconditionJSON{Name: u.Name, Reason: u.Err.Error()})
+	}
+	return json.Marshal(v)
+}
+
+// MarshalJSON encodes the error like Error.MarshalJSON, followed by the
+// start of the next window (omitted if there is none).
+func (e *MaintenanceWindowError) MarshalJSON() ([]byte, error) {
+	v := newErrorJSON(ErrorCodeOutsideMaintenanceWindow, RuleMaintenanceWindow, e, e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+	if !e.Next.IsZero() {
+		v.Next = &e.Next
+	}
+	return json.Marshal(v)
+}
+
+// MarshalJSON encodes the error like Error.MarshalJSON, followed by the
+// earliest time at which the upgrade is allowed.
+func (e *CooldownError) MarshalJSON() ([]byte, error) {
+	v := newErrorJSON(ErrorCodeCooldown, RuleCooldown, e, e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+	v.Next = &e.Next
+	return json.Marshal(v)
+}
+
+// overrideJSON is the JSON representation of an Override.
+type overrideJSON struct {
+	From     VersionString `json:"from"`
+	To       VersionString `json:"to"`
+	Ticket   string        `json:"ticket,omitempty"`
+	Expires  *time.Time    `json:"expires,omitempty"`
+	Rules    []RuleID      `json:"rules,omitempty"`
+	Approver string        `json:"approver,omitempty"`
+}
+
+// MarshalJSON encodes the override, omitting the expiry time when
+// it never expires.
+func (o Override) MarshalJSON() ([]byte, error) {
+	v := overrideJSON{
+		From:     o.From,
+		To:       o.To,
+		Ticket:   o.Ticket,
+		Rules:    o.Rules,
+		Approver: o.Approver,
+	}
+	if !o.Expires.IsZero() {
+		v.Expires = &o.Expires
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON decodes an override.
+func (o *Override) UnmarshalJSON(data []byte) error {
+	var v overrideJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*o = Override{
+		From:     v.From,
+		To:       v.To,
+		Ticket:   v.Ticket,
+		Rules:    v.Rules,
+		Approver: v.Approver,
+	}
+	if v.Expires != nil {
+		o.Expires = *v.Expires
+	}
+	return nil
+}
+
+// decisionJSON is the JSON representation of a Decision.
+type decisionJSON struct {
+	From          VersionString  `json:"from"`
+	To            VersionString  `json:"to"`
+	FromLicense   *License       `json:"fromLicense,omitempty"`
+	ToLicense     *License       `json:"toLicense,omitempty"`
+	Soft          bool           `json:"soft"`
+	Allowed       bool           `json:"allowed"`
+	Outcome       Outcome        `json:"outcome"`
+	Rule          RuleID         `json:"rule,omitempty"`
+	Error         interface{}    `json:"error,omitempty"`
+	Override      *Override      `json:"override,omitempty"`
+	Requester     string         `json:"requester,omitempty"`
+	Time          *time.Time     `json:"time,omitempty"`
+	Trace         *Trace         `json:"trace,omitempty"`
+	Warnings      []Warning      `json:"warnings,omitempty"`
+	Impact        *Impact        `json:"impact,omitempty"`
+	Links         []Link         `json:"links,omitempty"`
+	Notes         []UpgradeNote  `json:"notes,omitempty"`
+	ResourceHints []ResourceHint `json:"resourceHints,omitempty"`
+}
+
+// MarshalJSON encodes the decision.
+// The error is encoded using its own MarshalJSON method if it has one,
+// otherwise as an object with only a message.
+func (d Decision) MarshalJSON() ([]byte, error) {
+	v := decisionJSON{
+		From:          d.From,
+		To:            d.To,
+		Soft:          d.Soft,
+		Allowed:       d.Allowed(),
+		Outcome:       d.Outcome(),
+		Rule:          d.Rule,
+		Override:      d.Override,
+		Requester:     d.Requester,
+		Trace:         d.Trace,
+		Warnings:      d.Warnings,
+		Links:         d.Links(),
+		Notes:         d.Notes,
+		ResourceHints: d.ResourceHints,
+	}
+	if d.Licensed {
+		v.FromLicense, v.ToLicense = &d.FromLicense, &d.ToLicense
+	}
+	if d.Err != nil {
+		if m, ok := d.Err.(json.Marshaler); ok {
+			v.Error = m
+		} else {
+			v.Error = errorJSON{Code: errorCodeOther, Message: d.Err.Error()}
+		}
+	}
+	if !d.Time.IsZero() {
+		v.Time = &d.Time
+	}
+	if !d.Impact.IsZero() {
+		v.Impact = &d.Impact
+	}
+	return json.Marshal(v)
+}
@@ -0,0 +1,193 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLicenseJSON(t *testing.T) {
+	encoded, err := json.Marshal([]License{LicenseCommunity, LicenseEnterprise})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `["community","enterprise"]` {
+		t.Errorf("Unexpected encoding %s", encoded)
+	}
+	var decoded []License
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[0] != LicenseCommunity || decoded[1] != LicenseEnterprise {
+		t.Errorf("Unexpected decoded licenses %v", decoded)
+	}
+	if err := json.Unmarshal([]byte(`"gold"`), &decoded[0]); err == nil {
+		t.Error("Expected unknown license to fail")
+	}
+}
+
+func TestErrorJSON(t *testing.T) {
+	err := CheckUpgradeRulesWithLicense("3.10.8", "3.12.1", LicenseEnterprise, LicenseEnterprise)
+	encoded, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	expected := `{"code":"UpgradeNotAllowed","rule":"minor-increment","message":"Minor versions may only increment by 1","from":"3.10.8","to":"3.12.1","fromLicense":"enterprise","toLicense":"enterprise"}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+}
+
+func TestDecisionJSON(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	notes := func(v string) string {
+		return `{"title":"Release notes ` + v + `","url":"https://docs.arangodb.com/` + v + `/release-notes/version-` + v + `/whats-new-in-` + strings.Replace(v, ".", "-", 1) + `/"},` +
+			`{"title":"Incompatible changes in ` + v + `","url":"https://docs.arangodb.com/` + v + `/release-notes/version-` + v + `/incompatible-changes-in-` + strings.Replace(v, ".", "-", 1) + `/"},`
+	}
+	guide := func(v, target string) string {
+		return `{"title":"Upgrading","url":"https://docs.arangodb.com/` + v + `/operations/upgrading/"},` +
+			`{"title":"Changelog ` + target + `","url":"https://github.com/arangodb/arangodb/blob/v` + target + `/CHANGELOG"}`
+	}
+	tests := []struct {
+		Decision Decision
+		Expected string
+	}{
+		{
+			Check("3.11.8", "3.12.1", clock),
+			`{"from":"3.11.8","to":"3.12.1","soft":false,"allowed":true,"outcome":"allowed","time":"2026-10-01T12:00:00Z",` +
+				`"impact":{"rollingRestart":true,"autoUpgrade":true,"indexRebuild":true},"links":[` + notes("3.12") + guide("3.12", "3.12.1") + `]}`,
+		},
+		{
+			Check("3.12.1", "3.11.8", clock, WithSoft(), WithLicenses(LicenseCommunity, LicenseEnterprise), WithRequester("alice")),
+			`{"from":"3.12.1","to":"3.11.8","fromLicense":"community","toLicense":"enterprise","soft":true,"allowed":false,"outcome":"denied","rule":"minor-downgrade",` +
+				`"error":{"code":"UpgradeNotAllowed","rule":"minor-downgrade","message":"Downgrade is not possible","from":"3.12.1","to":"3.11.8","fromLicense":"community","toLicense":"enterprise"},` +
+				`"requester":"alice","time":"2026-10-01T12:00:00Z","links":[` + notes("3.11") + guide("3.11", "3.11.8") + `]}`,
+		},
+		{
+			Check("3.10.8", "3.12.1", clock, WithOverrides(Override{From: "3.10", To: "3.12", Ticket: "OPS-1", Expires: now.Add(time.Hour)})),
+			`{"from":"3.10.8","to":"3.12.1","soft":false,"allowed":true,"outcome":"overridden","override":{"from":"3.10","to":"3.12","ticket":"OPS-1","expires":"2026-10-01T13:00:00Z"},"time":"2026-10-01T12:00:00Z",` +
+				`"impact":{"rollingRestart":true,"autoUpgrade":true,"indexRebuild":true},"links":[` + notes("3.11") + notes("3.12") + guide("3.12", "3.12.1") + `]}`,
+		},
+		{
+			Decision{From: "3.11.8", To: "3.12.1", Err: errors.New("custom")},
+			`{"from":"3.11.8","to":"3.12.1","soft":false,"allowed":false,"outcome":"denied","error":{"code":"Error","message":"custom"},` +
+				`"links":[` + notes("3.12") + guide("3.12", "3.12.1") + `]}`,
+		},
+	}
+	for _, test := range tests {
+		encoded, err := json.Marshal(test.Decision)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != test.Expected {
+			t.Errorf("Expected\n%s\ngot\n%s", test.Expected, encoded)
+		}
+	}
+}
+
+func TestDecisionErrorJSON(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	tests := []struct {
+		Decision Decision
+		Expected string
+	}{
+		{
+			Check("3.11.8", "3.12.1", clock, WithMaintenanceWindows(MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil))),
+			`{"code":"OutsideMaintenanceWindow","rule":"maintenance-window","message":"Upgrade is outside the maintenance windows, next window starts at 2026-10-03T02:00:00Z",` +
+				`"from":"3.11.8","to":"3.12.1","next":"2026-10-03T02:00:00Z"}`,
+		},
+		{
+			Check("3.11.8", "3.12.1", clock, WithLicenses(LicenseEnterprise, LicenseEnterprise),
+				WithMaintenanceWindows(MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil))),
+			`{"code":"OutsideMaintenanceWindow","rule":"maintenance-window","message":"Upgrade is outside the maintenance windows, next window starts at 2026-10-03T02:00:00Z",` +
+				`"from":"3.11.8","to":"3.12.1","fromLicense":"enterprise","toLicense":"enterprise","next":"2026-10-03T02:00:00Z"}`,
+		},
+		{
+			Check("3.11.8", "3.12.1", clock, WithMaintenanceWindows(MaintenanceWindow{})),
+			`{"code":"OutsideMaintenanceWindow","rule":"maintenance-window","message":"Upgrade is outside the maintenance windows, which never occur",` +
+				`"from":"3.11.8","to":"3.12.1"}`,
+		},
+		{
+			Check("3.11.8", "3.12.1", clock, WithCooldown(Cooldown{AfterUpgrade: 24 * time.Hour}),
+				WithHistory(HistoryEntry{From: "3.11.7", To: "3.11.8", Time: now.Add(-time.Hour)})),
+			`{"code":"CooldownActive","rule":"cooldown","message":"Upgrade is not allowed before 2026-10-02T11:00:00Z, because of the upgrade from 3.11.7 to 3.11.8 at 2026-10-01T11:00:00Z",` +
+				`"from":"3.11.8","to":"3.12.1","next":"2026-10-02T11:00:00Z"}`,
+		},
+		{
+			Check("3.11.8", "3.12.1", clock, WithLicenses(LicenseCommunity, LicenseCommunity), WithCooldown(Cooldown{AfterUpgrade: 24 * time.Hour}),
+				WithHistory(HistoryEntry{From: "3.11.7", To: "3.11.8", Time: now.Add(-time.Hour)})),
+			`{"code":"CooldownActive","rule":"cooldown","message":"Upgrade is not allowed before 2026-10-02T11:00:00Z, because of the upgrade from 3.11.7 to 3.11.8 at 2026-10-01T11:00:00Z",` +
+				`"from":"3.11.8","to":"3.12.1","fromLicense":"community","toLicense":"community","next":"2026-10-02T11:00:00Z"}`,
+		},
+		{
+			Check("3.11.8", "3.12.1", clock, WithLicenses(LicenseEnterprise, LicenseEnterprise), WithPrecondition(&testPrecondition{name: "backup", err: errors.New("No backup")})),
+			`{"code":"PreconditionsNotMet","rule":"preconditions","message":"Preconditions not met: backup: No backup",` +
+				`"from":"3.11.8","to":"3.12.1","fromLicense":"enterprise","toLicense":"enterprise","unmet":[{"name":"backup","reason":"No backup"}]}`,
+		},
+		{
+			Check("3.11.1", "3.12.1", clock, WithLicenses(LicenseCommunity, LicenseCommunity), WithRuleSet(NewRuleSet(Rule{
+				ID:    "deny-all",
+				Check: func(in RuleInput) error { return errors.New("nope") },
+			}))),
+			`{"code":"Error","rule":"deny-all","message":"nope","from":"3.11.1","to":"3.12.1","fromLicense":"community","toLicense":"community"}`,
+		},
+	}
+	for _, test := range tests {
+		encoded, err := json.Marshal(test.Decision.Err)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(encoded) != test.Expected {
+			t.Errorf("Expected\n%s\ngot\n%s", test.Expected, encoded)
+		}
+		var decision struct {
+			Error json.RawMessage `json:"error"`
+		}
+		if encoded, err = json.Marshal(test.Decision); err != nil {
+			t.Fatal(err)
+		} else if err := json.Unmarshal(encoded, &decision); err != nil || string(decision.Error) != test.Expected {
+			t.Errorf("Expected decision error\n%s\ngot\n%s", test.Expected, decision.Error)
+		}
+	}
+}
+
+func TestOverrideJSON(t *testing.T) {
+	o := Override{From: "3.10", To: "3.12", Ticket: "OPS-1", Expires: time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		Rules: []RuleID{RuleMinorIncrement}, Approver: "bob"}
+	encoded, err := json.Marshal(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Override
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.From != o.From || decoded.To != o.To || decoded.Ticket != o.Ticket || !decoded.Expires.Equal(o.Expires) ||
+		decoded.Approver != o.Approver || len(decoded.Rules) != 1 || decoded.Rules[0] != RuleMinorIncrement {
+		t.Errorf("Expected %+v, got %+v", o, decoded)
+	}
+}
@@ -0,0 +1,94 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package admission provides a controller-runtime admission handler
+// that validates version changes of resources against the upgrade rules.
+//
+// Mounting it in an operator takes a few lines:
+//
+//	handler := admission.NewHandler(extractVersion)
+//	mgr.GetWebhookServer().Register("/validate-upgrade", &webhook.Admission{Handler: handler})
+package admission
+
+import (
+	"context"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// VersionFunc extracts the ArangoDB version and license from a raw
+// (JSON encoded) resource.
+// An empty version means that the resource does not (yet) specify a
+// version, in which case the change is not checked.
+type VersionFunc func(raw []byte) (upgraderules.VersionString, upgraderules.License, error)
+
+// Handler is an admission.Handler that denies updates of resources
+// that change the ArangoDB version or license in a way that the
+// upgrade rules do not allow.
+type Handler struct {
+	versions VersionFunc
+	opts     []upgraderules.Option
+}
+
+var _ admission.Handler = &Handler{}
+
+// NewHandler creates a Handler that uses the given function to find the
+// version and license of the old and new resource.
+// The given options are passed to upgraderules.Check.
+func NewHandler(versions VersionFunc, opts ...upgraderules.Option) *Handler {
+	return &Handler{
+		versions: versions,
+		opts:     opts,
+	}
+}
+
+// Handle validates the given admission request.
+// Only updates are checked, all other operations are allowed.
+func (h *Handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+	fromVersion, fromLicense, err := h.versions(req.OldObject.Raw)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	toVersion, toLicense, err := h.versions(req.Object.Raw)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if fromVersion == "" || toVersion == "" {
+		return admission.Allowed("")
+	}
+	if fromVersion == toVersion && fromLicense == toLicense {
+		return admission.Allowed("")
+	}
+	// The request decides the context and licenses, so they come last.
+	opts := make([]upgraderules.Option, 0, len(h.opts)+2)
+	opts = append(opts, h.opts...)
+	opts = append(opts, upgraderules.WithContext(ctx), upgraderules.WithLicenses(fromLicense, toLicense))
+	if d := upgraderules.Check(fromVersion, toVersion, opts...); !d.Allowed() {
+		return admission.Denied(d.Err.Error())
+	}
+	return admission.Allowed("")
+}
@@ -0,0 +1,134 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+type testResource struct {
+	Version upgraderules.VersionString `json:"version"`
+	License string                     `json:"license"`
+}
+
+func extractTestResource(raw []byte) (upgraderules.VersionString, upgraderules.License, error) {
+	var r testResource
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return "", upgraderules.LicenseCommunity, err
+	}
+	if r.License == "" {
+		return r.Version, upgraderules.LicenseCommunity, nil
+	}
+	l, err := upgraderules.ParseLicense(r.License)
+	return r.Version, l, err
+}
+
+func updateRequest(t *testing.T, from, to testResource) admission.Request {
+	oldRaw, err := json.Marshal(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newRaw, err := json.Marshal(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			OldObject: runtime.RawExtension{Raw: oldRaw},
+			Object:    runtime.RawExtension{Raw: newRaw},
+		},
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(extractTestResource)
+	tests := []struct {
+		From    testResource
+		To      testResource
+		Allowed bool
+	}{
+		{testResource{"3.11.8", ""}, testResource{"3.12.1", ""}, true},
+		{testResource{"3.10.8", ""}, testResource{"3.12.1", ""}, false},
+		{testResource{"3.11.8", "enterprise"}, testResource{"3.11.8", "community"}, false},
+		{testResource{"3.11.8", "community"}, testResource{"3.11.8", "enterprise"}, true},
+		{testResource{"", ""}, testResource{"3.12.1", ""}, true},
+	}
+	for _, test := range tests {
+		resp := h.Handle(context.Background(), updateRequest(t, test.From, test.To))
+		if resp.Allowed != test.Allowed {
+			t.Errorf("%+v -> %+v: expected allowed=%v, got %v", test.From, test.To, test.Allowed, resp.Allowed)
+		}
+	}
+
+	resp := h.Handle(context.Background(), admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			OldObject: runtime.RawExtension{Raw: []byte("{")},
+			Object:    runtime.RawExtension{Raw: []byte("{}")},
+		},
+	})
+	if resp.Allowed {
+		t.Error("Expected invalid resource to be rejected")
+	}
+}
+
+func TestHandlerIgnoresCreate(t *testing.T) {
+	h := NewHandler(extractTestResource)
+	req := updateRequest(t, testResource{"3.10.8", ""}, testResource{"3.12.1", ""})
+	req.Operation = admissionv1.Create
+	if resp := h.Handle(context.Background(), req); !resp.Allowed {
+		t.Error("Expected create to be allowed")
+	}
+}
+
+type testContextKey struct{}
+
+func TestHandlerRequestOptionsComeLast(t *testing.T) {
+	var seen interface{}
+	rs := upgraderules.NewRuleSet(append([]upgraderules.Rule{{
+		ID: "context",
+		Check: func(in upgraderules.RuleInput) error {
+			seen = in.Context.Value(testContextKey{})
+			return nil
+		},
+	}}, upgraderules.DefaultRuleSet().Rules()...)...)
+	h := NewHandler(extractTestResource,
+		upgraderules.WithRuleSet(rs),
+		upgraderules.WithContext(context.Background()),
+		upgraderules.WithLicenses(upgraderules.LicenseCommunity, upgraderules.LicenseCommunity))
+	ctx := context.WithValue(context.Background(), testContextKey{}, "request")
+	req := updateRequest(t, testResource{"3.11.8", "enterprise"}, testResource{"3.11.9", "community"})
+	if resp := h.Handle(ctx, req); resp.Allowed {
+		t.Error("Expected the licenses of the request to be checked")
+	}
+	if seen != "request" {
+		t.Errorf("Expected the context of the request, got %v", seen)
+	}
+}
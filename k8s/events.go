@@ -0,0 +1,63 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package k8s contains Kubernetes integrations for the upgrade rules.
+// It is a separate package so the rules themselves do not depend on
+// the Kubernetes client libraries.
+package k8s
+
+import (
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// NewEventRecorder returns a DecisionRecorder that records every decision
+// as an Event on the given object (typically an ArangoDeployment).
+// Allowed upgrades result in Normal events, denied upgrades in Warning events.
+func NewEventRecorder(recorder record.EventRecorder, object runtime.Object) upgraderules.DecisionRecorder {
+	return &eventRecorder{
+		recorder: recorder,
+		object:   object,
+	}
+}
+
+type eventRecorder struct {
+	recorder record.EventRecorder
+	object   runtime.Object
+}
+
+// RecordDecision records the given decision as an Event.
+func (r *eventRecorder) RecordDecision(d upgraderules.Decision) {
+	if d.Allowed() {
+		msg := fmt.Sprintf("Upgrade from %s to %s is allowed", d.From, d.To)
+		if d.Override != nil {
+			msg = fmt.Sprintf("%s by override %s", msg, d.Override)
+		}
+		r.recorder.Event(r.object, core.EventTypeNormal, upgraderules.ReasonUpgradeAllowed, msg)
+	} else {
+		msg := fmt.Sprintf("Upgrade from %s to %s is not allowed (%s): %s", d.From, d.To, d.Rule, upgraderules.Reason(d.Err))
+		r.recorder.Event(r.object, core.EventTypeWarning, upgraderules.ReasonUpgradeNotAllowed, msg)
+	}
+}
@@ -0,0 +1,43 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package k8s
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestEventRecorder(t *testing.T) {
+	fake := record.NewFakeRecorder(10)
+	r := upgraderules.WithDecisionRecorder(NewEventRecorder(fake, nil))
+
+	upgraderules.Check("3.11.8", "3.12.1", r)
+	if ev := <-fake.Events; ev != "Normal UpgradeAllowed Upgrade from 3.11.8 to 3.12.1 is allowed" {
+		t.Errorf("Unexpected event '%s'", ev)
+	}
+	upgraderules.Check("3.10.8", "3.12.1", r)
+	if ev := <-fake.Events; ev != "Warning UpgradeNotAllowed Upgrade from 3.10.8 to 3.12.1 is not allowed (minor-increment): Minor versions may only increment by 1" {
+		t.Errorf("Unexpected event '%s'", ev)
+	}
+}
@@ -0,0 +1,132 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies the receiver into out.
+func (in *UpgradePolicy) DeepCopyInto(out *UpgradePolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy returns a copy of the receiver.
+func (in *UpgradePolicy) DeepCopy() *UpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a copy of the receiver as a runtime.Object.
+func (in *UpgradePolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *UpgradePolicyList) DeepCopyInto(out *UpgradePolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		out.Items = make([]UpgradePolicy, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy returns a copy of the receiver.
+func (in *UpgradePolicyList) DeepCopy() *UpgradePolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject returns a copy of the receiver as a runtime.Object.
+func (in *UpgradePolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *UpgradePolicySpec) DeepCopyInto(out *UpgradePolicySpec) {
+	*out = *in
+	if in.Channels != nil {
+		out.Channels = append(out.Channels[:0:0], in.Channels...)
+	}
+	if in.MaintenanceWindows != nil {
+		out.MaintenanceWindows = append(out.MaintenanceWindows[:0:0], in.MaintenanceWindows...)
+	}
+	if in.Exceptions != nil {
+		out.Exceptions = make([]Exception, len(in.Exceptions))
+		for i := range in.Exceptions {
+			in.Exceptions[i].DeepCopyInto(&out.Exceptions[i])
+		}
+	}
+}
+
+// DeepCopy returns a copy of the receiver.
+func (in *UpgradePolicySpec) DeepCopy() *UpgradePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *Exception) DeepCopyInto(out *Exception) {
+	*out = *in
+	if in.Expires != nil {
+		out.Expires = new(metav1.Time)
+		in.Expires.DeepCopyInto(out.Expires)
+	}
+	if in.Rules != nil {
+		out.Rules = append(out.Rules[:0:0], in.Rules...)
+	}
+}
+
+// DeepCopy returns a copy of the receiver.
+func (in *Exception) DeepCopy() *Exception {
+	if in == nil {
+		return nil
+	}
+	out := new(Exception)
+	in.DeepCopyInto(out)
+	return out
+}
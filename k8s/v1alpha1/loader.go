@@ -0,0 +1,86 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Policy returns the policy described by the spec. Its RuleSet is the
+// DefaultRuleSet, which enforces the fields of the spec.
+func (s UpgradePolicySpec) Policy() (upgraderules.Policy, error) {
+	bp := upgraderules.BundlePolicy{
+		Profile:      s.Profile,
+		Soft:         s.Soft,
+		MaxMinorSkip: s.MaxMinorSkip,
+		Channels:     s.Channels,
+	}
+	for _, w := range s.MaintenanceWindows {
+		bp.MaintenanceWindows = append(bp.MaintenanceWindows, upgraderules.BundleWindow{
+			Schedule: w.Schedule,
+			Duration: w.Duration.Duration.String(),
+			Location: w.Location,
+		})
+	}
+	return bp.Policy()
+}
+
+// Overrides returns the exceptions of the spec as overrides.
+func (s UpgradePolicySpec) Overrides() ([]upgraderules.Override, error) {
+	var result []upgraderules.Override
+	for i, e := range s.Exceptions {
+		if e.From == "" || e.To == "" {
+			return nil, fmt.Errorf("Exception %d needs a from and to version", i)
+		}
+		o := upgraderules.Override{
+			From:     e.From,
+			To:       e.To,
+			Ticket:   e.Ticket,
+			Rules:    append([]upgraderules.RuleID(nil), e.Rules...),
+			Approver: e.Approver,
+		}
+		if e.Expires != nil {
+			o.Expires = e.Expires.Time
+		}
+		result = append(result, o)
+	}
+	return result, nil
+}
+
+// Options returns the options to check upgrades under the policy,
+// including its exceptions (see upgraderules.WithOverrides).
+func (p *UpgradePolicy) Options() ([]upgraderules.Option, error) {
+	policy, err := p.Spec.Policy()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid UpgradePolicy '%s': %s", p.Name, err)
+	}
+	overrides, err := p.Spec.Overrides()
+	if err != nil {
+		return nil, fmt.Errorf("Invalid UpgradePolicy '%s': %s", p.Name, err)
+	}
+	opts := policy.Options()
+	if len(overrides) > 0 {
+		opts = append(opts, upgraderules.WithOverrides(overrides...))
+	}
+	return opts, nil
+}
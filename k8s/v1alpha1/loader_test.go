@@ -0,0 +1,104 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+const testPolicy = `{
+	"apiVersion": "upgrade.arangodb.com/v1alpha1",
+	"kind": "UpgradePolicy",
+	"metadata": {"name": "prod", "namespace": "db"},
+	"spec": {
+		"profile": "production",
+		"channels": ["ga", "pre-release"],
+		"maintenanceWindows": [{"schedule": "0 2 * * 6", "duration": "4h"}],
+		"exceptions": [{"from": "3.10", "to": "3.12", "ticket": "OPS-1", "expires": "2024-07-01T00:00:00Z"}]
+	}
+}`
+
+func TestUpgradePolicyOptions(t *testing.T) {
+	var p UpgradePolicy
+	if err := json.Unmarshal([]byte(testPolicy), &p); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := p.Options()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saturday := upgraderules.WithClock(func() time.Time { return time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC) })
+	monday := upgraderules.WithClock(func() time.Time { return time.Date(2024, 6, 10, 3, 0, 0, 0, time.UTC) })
+	tests := []struct {
+		From, To upgraderules.VersionString
+		Clock    upgraderules.Option
+		Allowed  bool
+	}{
+		{"3.11.8", "3.12.1", saturday, true},
+		{"3.11.8", "3.12.1", monday, false},
+		{"3.11.8", "3.12.0-rc.1", saturday, true},
+		{"3.10.8", "3.12.1", saturday, true},
+		{"3.9.8", "3.11.1", saturday, false},
+	}
+	for _, test := range tests {
+		d := upgraderules.Check(test.From, test.To, append(opts, test.Clock)...)
+		if d.Allowed() != test.Allowed {
+			t.Errorf("%s -> %s: expected allowed=%t, got %s", test.From, test.To, test.Allowed, d.Err)
+		}
+	}
+}
+
+func TestUpgradePolicyInvalid(t *testing.T) {
+	for name, spec := range map[string]UpgradePolicySpec{
+		"profile":   {Profile: "qa"},
+		"window":    {MaintenanceWindows: []MaintenanceWindow{{Schedule: "every day"}}},
+		"exception": {Exceptions: []Exception{{From: "3.10"}}},
+	} {
+		p := UpgradePolicy{Spec: spec}
+		p.Name = name
+		if _, err := p.Options(); err == nil {
+			t.Errorf("Expected invalid %s to fail", name)
+		}
+	}
+}
+
+func TestUpgradePolicyDeepCopy(t *testing.T) {
+	var p UpgradePolicy
+	if err := json.Unmarshal([]byte(testPolicy), &p); err != nil {
+		t.Fatal(err)
+	}
+	c := p.DeepCopyObject().(*UpgradePolicy)
+	c.Spec.Channels[0] = upgraderules.ChannelPreRelease
+	c.Spec.Exceptions[0].Expires.Time = time.Time{}
+	if p.Spec.Channels[0] != upgraderules.ChannelGA || p.Spec.Exceptions[0].Expires.IsZero() {
+		t.Error("Expected the copy not to share the spec")
+	}
+	s := runtime.NewScheme()
+	if err := AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+}
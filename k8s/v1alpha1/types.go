@@ -0,0 +1,92 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// UpgradePolicy is the policy for upgrades of the deployments in its
+// namespace, see UpgradePolicySpec.Policy.
+type UpgradePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec UpgradePolicySpec `json:"spec"`
+}
+
+// UpgradePolicyList is a list of UpgradePolicy resources.
+type UpgradePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []UpgradePolicy `json:"items"`
+}
+
+// UpgradePolicySpec holds the fields of an UpgradePolicy.
+type UpgradePolicySpec struct {
+	// Profile is the profile the policy is based on, if any.
+	// The other fields refine the policy of the profile.
+	Profile upgraderules.Profile `json:"profile,omitempty"`
+	// Soft selects the soft rules
+	Soft bool `json:"soft,omitempty"`
+	// Channels holds the allowed release channels, those of the profile
+	// when empty
+	Channels []upgraderules.Channel `json:"channels,omitempty"`
+	// MaxMinorSkip limits the increase of the minor version allowed by
+	// the soft rules, that of the profile when 0
+	MaxMinorSkip int `json:"maxMinorSkip,omitempty"`
+	// MaintenanceWindows holds the windows in which upgrades are allowed
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// Exceptions holds the upgrades that are allowed even if the rules
+	// deny them
+	Exceptions []Exception `json:"exceptions,omitempty"`
+}
+
+// MaintenanceWindow is a window in which upgrades are allowed, see
+// upgraderules.ParseMaintenanceWindow.
+type MaintenanceWindow struct {
+	// Schedule is a cron expression of the start of the window, e.g. "0 2 * * 6"
+	Schedule string `json:"schedule"`
+	// Duration of the window, e.g. "2h"
+	Duration metav1.Duration `json:"duration"`
+	// Location is the name of the time zone of the schedule, UTC if empty
+	Location string `json:"location,omitempty"`
+}
+
+// Exception allows an upgrade even if the rules deny it, see
+// upgraderules.Override.
+type Exception struct {
+	// From is the version being upgraded from (e.g. "3.9" or "3.9.4")
+	From upgraderules.VersionString `json:"from"`
+	// To is the version being upgraded to (e.g. "3.11" or "3.11.0")
+	To upgraderules.VersionString `json:"to"`
+	// Ticket is a reference explaining why the exception exists
+	Ticket string `json:"ticket,omitempty"`
+	// Expires is the time after which the exception is no longer honored
+	Expires *metav1.Time `json:"expires,omitempty"`
+	// Rules limits the exception to the given rules
+	Rules []upgraderules.RuleID `json:"rules,omitempty"`
+	// Approver identifies who approved the exception
+	Approver string `json:"approver,omitempty"`
+}
@@ -0,0 +1,112 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Link is a reference to documentation about an upgrade.
+type Link struct {
+	// Title describes the linked document
+	Title string `json:"title"`
+	// URL of the document
+	URL string `json:"url"`
+}
+
+// linkTemplate generates a link for a minor version. The placeholders
+// {major}, {minor} and {version} are replaced in Title and URL.
+type linkTemplate struct {
+	// since is the first minor version ({major, minor}) using the template
+	since [2]int
+	// target is set when only the target minor version is linked,
+	// otherwise every minor version the upgrade passes is linked
+	target bool
+	title  string
+	url    string
+}
+
+// linkTemplates is the template table of links, the last template
+// that applies to a minor version with the same title wins.
+var linkTemplates = []linkTemplate{
+	{since: [2]int{3, 0}, title: "Release notes {major}.{minor}", url: "https://www.arangodb.com/docs/{major}.{minor}/release-notes-new-features{major}{minor}.html"},
+	{since: [2]int{3, 0}, title: "Incompatible changes in {major}.{minor}", url: "https://www.arangodb.com/docs/{major}.{minor}/release-notes-upgrading-changes{major}{minor}.html"},
+	{since: [2]int{3, 0}, target: true, title: "Upgrading", url: "https://www.arangodb.com/docs/{major}.{minor}/upgrading.html"},
+	{since: [2]int{3, 10}, title: "Release notes {major}.{minor}", url: "https://docs.arangodb.com/{major}.{minor}/release-notes/version-{major}.{minor}/whats-new-in-{major}-{minor}/"},
+	{since: [2]int{3, 10}, title: "Incompatible changes in {major}.{minor}", url: "https://docs.arangodb.com/{major}.{minor}/release-notes/version-{major}.{minor}/incompatible-changes-in-{major}-{minor}/"},
+	{since: [2]int{3, 10}, target: true, title: "Upgrading", url: "https://docs.arangodb.com/{major}.{minor}/operations/upgrading/"},
+}
+
+// changelogURL is the template of the link to the changelog of a release.
+const changelogURL = "https://github.com/arangodb/arangodb/blob/v{version}/CHANGELOG"
+
+// Links returns references to the release notes and upgrade guide of
+// the minor versions an upgrade from `from` to `to` passes, and to the
+// changelog of the target version. It returns nil when the version does
+// not change.
+func Links(from, to ParsedVersion) []Link {
+	if from.version == to.version {
+		return nil
+	}
+	var links []Link
+	if from.major == to.major && from.minor < to.minor {
+		for minor := from.minor + 1; minor <= to.minor; minor++ {
+			links = appendLinks(links, to.major, minor, minor == to.minor)
+		}
+	} else {
+		links = appendLinks(links, to.major, to.minor, true)
+	}
+	links = append(links, Link{
+		Title: "Changelog " + string(to.version),
+		URL:   strings.Replace(changelogURL, "{version}", string(to.version), -1),
+	})
+	return links
+}
+
+// appendLinks appends the links of the given minor version to links.
+func appendLinks(links []Link, major, minor int, target bool) []Link {
+	r := strings.NewReplacer("{major}", strconv.Itoa(major), "{minor}", strconv.Itoa(minor))
+	var selected []linkTemplate
+	for _, t := range linkTemplates {
+		if major < t.since[0] || major == t.since[0] && minor < t.since[1] || t.target && !target {
+			continue
+		}
+		replaced := false
+		for i, s := range selected {
+			if s.title == t.title {
+				selected[i], replaced = t, true
+			}
+		}
+		if !replaced {
+			selected = append(selected, t)
+		}
+	}
+	for _, t := range selected {
+		links = append(links, Link{Title: r.Replace(t.title), URL: r.Replace(t.url)})
+	}
+	return links
+}
+
+// Links returns references to the documentation of the upgrade, see Links.
+func (d Decision) Links() []Link {
+	return Links(ParseVersion(d.From), ParseVersion(d.To))
+}
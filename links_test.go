@@ -0,0 +1,73 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestLinks(t *testing.T) {
+	if links := Links(ParseVersion("3.11.8"), ParseVersion("3.11.8")); links != nil {
+		t.Errorf("Expected no links for unchanged version, got %v", links)
+	}
+	links := Links(ParseVersion("3.9.5"), ParseVersion("3.10.2"))
+	expected := []Link{
+		{Title: "Release notes 3.10", URL: "https://docs.arangodb.com/3.10/release-notes/version-3.10/whats-new-in-3-10/"},
+		{Title: "Incompatible changes in 3.10", URL: "https://docs.arangodb.com/3.10/release-notes/version-3.10/incompatible-changes-in-3-10/"},
+		{Title: "Upgrading", URL: "https://docs.arangodb.com/3.10/operations/upgrading/"},
+		{Title: "Changelog 3.10.2", URL: "https://github.com/arangodb/arangodb/blob/v3.10.2/CHANGELOG"},
+	}
+	if len(links) != len(expected) {
+		t.Fatalf("Expected %d links, got %v", len(expected), links)
+	}
+	for i, l := range links {
+		if l != expected[i] {
+			t.Errorf("Link %d: expected %+v, got %+v", i, expected[i], l)
+		}
+	}
+}
+
+func TestLinksBefore310(t *testing.T) {
+	links := Links(ParseVersion("3.7.2"), ParseVersion("3.9.1"))
+	titles := []string{"Release notes 3.8", "Incompatible changes in 3.8", "Release notes 3.9", "Incompatible changes in 3.9", "Upgrading", "Changelog 3.9.1"}
+	if len(links) != len(titles) {
+		t.Fatalf("Expected %d links, got %v", len(titles), links)
+	}
+	for i, l := range links {
+		if l.Title != titles[i] {
+			t.Errorf("Link %d: expected %s, got %s", i, titles[i], l.Title)
+		}
+	}
+	if u := links[2].URL; u != "https://www.arangodb.com/docs/3.9/release-notes-new-features39.html" {
+		t.Errorf("Unexpected URL %s", u)
+	}
+	if u := links[4].URL; u != "https://www.arangodb.com/docs/3.9/upgrading.html" {
+		t.Errorf("Unexpected URL %s", u)
+	}
+}
+
+func TestLinksPatch(t *testing.T) {
+	d := Check("3.12.1", "3.12.4")
+	links := d.Links()
+	if len(links) != 4 || links[3].Title != "Changelog 3.12.4" {
+		t.Errorf("Unexpected links %v", links)
+	}
+}
@@ -0,0 +1,299 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuleMaintenanceWindow denies upgrades outside the maintenance windows
+// given with WithMaintenanceWindows.
+const RuleMaintenanceWindow RuleID = "maintenance-window"
+
+// MaintenanceWindow is a recurring period of time in which upgrades are
+// allowed. It starts at every time matching a cron schedule and lasts for
+// a fixed duration.
+type MaintenanceWindow struct {
+	spec     string
+	duration time.Duration
+	location *time.Location
+	fields   [5]cronField
+}
+
+// cronField is the set of values matched by a field of a cron schedule.
+type cronField struct {
+	values [61]bool
+	// any is set for '*', which matters for day-of-month & day-of-week
+	any bool
+}
+
+// cronRanges holds the minimum and maximum value of every cron field.
+var cronRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+// ParseMaintenanceWindow parses a maintenance window that starts at every
+// time matching the given cron schedule (minute, hour, day of month, month,
+// day of week; supporting '*', lists, ranges and steps) and lasts for the
+// given duration. The schedule is evaluated in the given location, or UTC
+// when nil. E.g. "0 2 * * 6" with 4 hours is every Saturday 02:00 - 06:00.
+func ParseMaintenanceWindow(spec string, duration time.Duration, location *time.Location) (MaintenanceWindow, error) {
+	if location == nil {
+		location = time.UTC
+	}
+	w := MaintenanceWindow{spec: spec, duration: duration, location: location}
+	if duration <= 0 || duration > 7*24*time.Hour {
+		return MaintenanceWindow{}, fmt.Errorf("Invalid maintenance window duration %s, expected between 0 and 7 days", duration)
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != 5 {
+		return MaintenanceWindow{}, fmt.Errorf("Invalid maintenance window schedule '%s', expected 5 fields", spec)
+	}
+	for i, part := range parts {
+		f, err := parseCronField(part, cronRanges[i][0], cronRanges[i][1])
+		if err != nil {
+			return MaintenanceWindow{}, fmt.Errorf("Invalid maintenance window schedule '%s': %s", spec, err)
+		}
+		w.fields[i] = f
+	}
+	// Sunday is both 0 and 7
+	if w.fields[4].values[7] {
+		w.fields[4].values[0] = true
+	}
+	return w, nil
+}
+
+// parseCronField parses a single field of a cron schedule.
+func parseCronField(s string, min, max int) (cronField, error) {
+	var f cronField
+	f.any = s == "*"
+	for _, item := range strings.Split(s, ",") {
+		rng, step := item, 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			n, err := strconv.Atoi(item[idx+1:])
+			if err != nil || n <= 0 {
+				return cronField{}, fmt.Errorf("invalid step in '%s'", item)
+			}
+			rng, step = item[:idx], n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return cronField{}, fmt.Errorf("invalid value '%s'", item)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return cronField{}, fmt.Errorf("invalid value '%s'", item)
+				}
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return cronField{}, fmt.Errorf("value '%s' out of range %d-%d", item, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			f.values[v] = true
+		}
+	}
+	return f, nil
+}
+
+// MustParseMaintenanceWindow is ParseMaintenanceWindow, panicking on
+// invalid input. It is intended for windows that are constants of the program.
+func MustParseMaintenanceWindow(spec string, duration time.Duration, location *time.Location) MaintenanceWindow {
+	w, err := ParseMaintenanceWindow(spec, duration, location)
+	if err != nil {
+		panic(err)
+	}
+	return w
+}
+
+// String returns the schedule and duration of the window.
+func (w MaintenanceWindow) String() string {
+	return fmt.Sprintf("%s for %s", w.spec, w.duration)
+}
+
+// loc returns the location of the schedule, UTC for the zero value.
+func (w MaintenanceWindow) loc() *time.Location {
+	if w.location == nil {
+		return time.UTC
+	}
+	return w.location
+}
+
+// Contains returns true if the given time is inside the window.
+// The zero value never contains a time.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	t = t.In(w.loc())
+	// Look for a start in (t - duration, t]
+	start := t.Truncate(time.Minute)
+	for s := start; t.Sub(s) < w.duration; s = s.Add(-time.Minute) {
+		if w.matches(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the first time at or after t that is inside the window.
+// It returns false when the schedule never matches (e.g. February 30th).
+func (w MaintenanceWindow) Next(t time.Time) (time.Time, bool) {
+	if w.Contains(t) {
+		return t, true
+	}
+	t = t.In(w.loc())
+	s := t.Truncate(time.Minute)
+	if s.Before(t) {
+		s = s.Add(time.Minute)
+	}
+	limit := s.AddDate(5, 0, 0)
+	for s.Before(limit) {
+		if !w.matchesDay(s) {
+			s = time.Date(s.Year(), s.Month(), s.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if w.matches(s) {
+			return s, true
+		}
+		s = s.Add(time.Minute)
+	}
+	return time.Time{}, false
+}
+
+// matchesDay returns true if the day of t matches the schedule.
+func (w MaintenanceWindow) matchesDay(t time.Time) bool {
+	dom, month, dow := w.fields[2], w.fields[3], w.fields[4]
+	if !month.values[int(t.Month())] {
+		return false
+	}
+	domMatch, dowMatch := dom.values[t.Day()], dow.values[int(t.Weekday())]
+	if !dom.any && !dow.any {
+		// Like cron, a restricted day of month and day of week match either
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// matches returns true if t (truncated to minutes) is a start of the window.
+func (w MaintenanceWindow) matches(t time.Time) bool {
+	return w.fields[0].values[t.Minute()] && w.fields[1].values[t.Hour()] && w.matchesDay(t)
+}
+
+// MaintenanceWindowError is the error of a decision for an upgrade that
+// the rules allow, but that was checked outside all maintenance windows.
+// It is retryable, since the upgrade is allowed in the next window.
+type MaintenanceWindowError struct {
+	// Time at which the upgrade was checked
+	Time time.Time
+	// Next is the start of the next maintenance window (zero if there is none)
+	Next time.Time
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Licensed is set when the licenses were included in the check
+	Licensed bool
+	// FromLicense is the license being upgraded from (only if Licensed is set)
+	FromLicense License
+	// ToLicense is the license being upgraded to (only if Licensed is set)
+	ToLicense License
+}
+
+// ErrorCodeOutsideMaintenanceWindow is the code of a MaintenanceWindowError
+// in its JSON representation.
+const ErrorCodeOutsideMaintenanceWindow = "OutsideMaintenanceWindow"
+
+// Error describes when the next maintenance window starts.
+func (e *MaintenanceWindowError) Error() string {
+	return e.reason() + transitionSuffix(e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+}
+
+// reason describes when the next maintenance window starts, without the
+// transition (see Reason).
+func (e *MaintenanceWindowError) reason() string {
+	if e.Next.IsZero() {
+		return "Upgrade is outside the maintenance windows, which never occur"
+	}
+	return fmt.Sprintf("Upgrade is outside the maintenance windows, next window starts at %s", e.Next.Format(time.RFC3339))
+}
+
+// Retryable returns true, see IsRetryable.
+func (e *MaintenanceWindowError) Retryable() bool {
+	return true
+}
+
+// ruleMaintenanceWindow implements RuleMaintenanceWindow.
+var ruleMaintenanceWindow = Rule{
+	ID:      RuleMaintenanceWindow,
+	Applies: func(in RuleInput) bool { return len(in.MaintenanceWindows) > 0 && in.changesVersion() },
+	Check:   func(in RuleInput) error { return checkMaintenanceWindows(in.MaintenanceWindows, in.Time) },
+}
+
+// checkMaintenanceWindows returns a *MaintenanceWindowError when now is
+// not inside a window of every given group.
+func checkMaintenanceWindows(groups [][]MaintenanceWindow, now time.Time) error {
+	next, ok := nextInAllGroups(groups, now)
+	if ok && next.Equal(now) {
+		return nil
+	}
+	return &MaintenanceWindowError{Time: now, Next: next}
+}
+
+// maxWindowSearchSteps limits the search for a time inside a window of
+// every group, since windows of different groups may never overlap.
+const maxWindowSearchSteps = 1000
+
+// nextInAllGroups returns the earliest time at or after t that is inside
+// a window of every given group.
+func nextInAllGroups(groups [][]MaintenanceWindow, t time.Time) (time.Time, bool) {
+	for i := 0; i < maxWindowSearchSteps; i++ {
+		latest := t
+		for _, g := range groups {
+			n, ok := nextInGroup(g, t)
+			if !ok {
+				return time.Time{}, false
+			}
+			if n.After(latest) {
+				latest = n
+			}
+		}
+		if latest.Equal(t) {
+			return t, true
+		}
+		t = latest
+	}
+	return time.Time{}, false
+}
+
+// nextInGroup returns the earliest time at or after t that is inside one
+// of the given windows.
+func nextInGroup(windows []MaintenanceWindow, t time.Time) (time.Time, bool) {
+	var next time.Time
+	for _, w := range windows {
+		if n, ok := w.Next(t); ok && (next.IsZero() || n.Before(next)) {
+			next = n
+		}
+	}
+	return next, !next.IsZero()
+}
@@ -0,0 +1,153 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceWindow(t *testing.T) {
+	// Saturdays 02:00 - 06:00 UTC
+	w := MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)
+	sat := func(h, m int) time.Time { return time.Date(2024, 6, 1, h, m, 0, 0, time.UTC) }
+	tests := map[time.Time]bool{
+		sat(1, 59):                 false,
+		sat(2, 0):                  true,
+		sat(5, 59):                 true,
+		sat(6, 0):                  false,
+		sat(2, 0).AddDate(0, 0, 1): false,
+	}
+	for tm, expected := range tests {
+		if w.Contains(tm) != expected {
+			t.Errorf("Contains(%s) = %v, expected %v", tm, !expected, expected)
+		}
+	}
+	next, ok := w.Next(sat(6, 0))
+	if expected := sat(2, 0).AddDate(0, 0, 7); !ok || !next.Equal(expected) {
+		t.Errorf("Expected next window at %s, got %s", expected, next)
+	}
+	if next, _ := w.Next(sat(3, 0)); !next.Equal(sat(3, 0)) {
+		t.Errorf("Expected time inside window to be returned, got %s", next)
+	}
+}
+
+func TestMaintenanceWindowSchedules(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("No time zone data: %s", err)
+	}
+	// Every 15 minutes on weekdays in Berlin
+	w := MustParseMaintenanceWindow("*/15 22-23 * * 1-5", 5*time.Minute, berlin)
+	if !w.Contains(time.Date(2024, 6, 3, 20, 47, 0, 0, time.UTC)) {
+		t.Error("Expected Monday 22:47 Berlin to be inside the window")
+	}
+	if w.Contains(time.Date(2024, 6, 3, 20, 51, 0, 0, time.UTC)) {
+		t.Error("Expected Monday 22:51 Berlin to be outside the window")
+	}
+	// Day of month and day of week match either, like cron
+	w = MustParseMaintenanceWindow("0 0 13 * 5", time.Hour, nil)
+	next, _ := w.Next(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if expected := time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC); !next.Equal(expected) {
+		t.Errorf("Expected next window at %s, got %s", expected, next)
+	}
+	if _, ok := MustParseMaintenanceWindow("0 0 30 2 *", time.Hour, nil).Next(time.Now()); ok {
+		t.Error("February 30th should never occur")
+	}
+}
+
+func TestParseMaintenanceWindowInvalid(t *testing.T) {
+	for _, spec := range []string{"", "0 2 * *", "60 2 * * *", "0 2 * * 8", "0 2-1 * * *", "*/0 2 * * *", "a 2 * * *"} {
+		if _, err := ParseMaintenanceWindow(spec, time.Hour, nil); err == nil {
+			t.Errorf("ParseMaintenanceWindow(%q) should fail", spec)
+		}
+	}
+	if _, err := ParseMaintenanceWindow("0 2 * * *", 0, nil); err == nil {
+		t.Error("Zero duration should fail")
+	}
+}
+
+func TestZeroMaintenanceWindow(t *testing.T) {
+	var w MaintenanceWindow
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	if w.Contains(now) {
+		t.Error("Zero window should not contain any time")
+	}
+	if _, ok := w.Next(now); ok {
+		t.Error("Zero window should have no next time")
+	}
+	d := Check("3.11.8", "3.12.1", WithClock(func() time.Time { return now }), WithMaintenanceWindows(w))
+	var e *MaintenanceWindowError
+	if d.Rule != RuleMaintenanceWindow || !errors.As(d.Err, &e) || !e.Next.IsZero() {
+		t.Errorf("Expected denial without next window, got %v", d.Err)
+	}
+}
+
+func TestWithMaintenanceWindows(t *testing.T) {
+	w := MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	d := Check("3.11.8", "3.11.9", clock, WithMaintenanceWindows(w))
+	if d.Allowed() || d.Rule != RuleMaintenanceWindow || !IsRetryable(d.Err) {
+		t.Fatalf("Expected denial by %s, got %s (%s)", RuleMaintenanceWindow, d.Outcome(), d.Rule)
+	}
+	e, ok := d.Err.(*MaintenanceWindowError)
+	if !ok || !e.Next.Equal(time.Date(2024, 6, 8, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected error %v", d.Err)
+	}
+	if msg := d.Err.Error(); msg != "Upgrade is outside the maintenance windows, next window starts at 2024-06-08T02:00:00Z (from 3.11.8 to 3.11.9)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	// Rule denials take precedence, unchanged versions are always allowed
+	if d := Check("3.11.8", "3.13.0", clock, WithMaintenanceWindows(w)); d.Rule != RuleMinorIncrement {
+		t.Errorf("Expected denial by %s, got %s", RuleMinorIncrement, d.Rule)
+	}
+	if d := Check("3.11.8", "3.11.8", clock, WithMaintenanceWindows(w)); !d.Allowed() {
+		t.Errorf("Expected unchanged version to be allowed, got %s", d.Err)
+	}
+	now = time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC)
+	if d := Check("3.11.8", "3.11.9", clock, WithMaintenanceWindows(w)); !d.Allowed() {
+		t.Errorf("Expected upgrade inside window to be allowed, got %s", d.Err)
+	}
+}
+
+func TestWithMaintenanceWindowsRequiresEveryUse(t *testing.T) {
+	// Saturdays 02:00 - 06:00 and every day 05:00 - 07:00 overlap from 05:00 to 06:00
+	saturday := MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)
+	early := MustParseMaintenanceWindow("0 5 * * *", 2*time.Hour, nil)
+	now := time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC)
+	d := Check("3.11.8", "3.11.9", WithClock(func() time.Time { return now }), WithMaintenanceWindows(saturday), WithMaintenanceWindows(early))
+	e, ok := d.Err.(*MaintenanceWindowError)
+	if !ok || !e.Next.Equal(time.Date(2024, 6, 8, 5, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Unexpected error %v", d.Err)
+	}
+	now = e.Next
+	if d := Check("3.11.8", "3.11.9", WithClock(func() time.Time { return now }), WithMaintenanceWindows(saturday), WithMaintenanceWindows(early)); !d.Allowed() {
+		t.Errorf("Expected upgrade inside both windows to be allowed, got %s", d.Err)
+	}
+	// Windows that never overlap
+	sunday := MustParseMaintenanceWindow("0 2 * * 0", time.Hour, nil)
+	d = Check("3.11.8", "3.11.9", WithClock(func() time.Time { return now }), WithMaintenanceWindows(saturday), WithMaintenanceWindows(sunday))
+	if e, ok := d.Err.(*MaintenanceWindowError); !ok || !e.Next.IsZero() {
+		t.Errorf("Expected error without next window, got %v", d.Err)
+	}
+}
@@ -0,0 +1,122 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Matrix holds the decisions for upgrading between every pair of a set
+// of versions.
+type Matrix struct {
+	// Versions in the order of the rows and columns
+	Versions []VersionString
+	// Decisions holds the decision for upgrading from Versions[i]
+	// to Versions[j] at Decisions[i][j].
+	Decisions [][]Decision
+}
+
+// NewMatrix checks upgrading between every pair of the given versions,
+// using the given options.
+func NewMatrix(versions []VersionString, opts ...Option) Matrix {
+	m := Matrix{
+		Versions:  append([]VersionString(nil), versions...),
+		Decisions: make([][]Decision, len(versions)),
+	}
+	parsed := make([]ParsedVersion, len(versions))
+	for i, v := range versions {
+		parsed[i] = ParseVersion(v)
+	}
+	for i, from := range parsed {
+		m.Decisions[i] = make([]Decision, len(versions))
+		for j, to := range parsed {
+			m.Decisions[i][j] = CheckParsed(from, to, opts...)
+		}
+	}
+	return m
+}
+
+// matrixCell returns the symbol used for a decision in a rendered matrix.
+func matrixCell(d Decision) string {
+	switch d.Outcome() {
+	case OutcomeAllowed, OutcomeNoOp:
+		return "✓"
+	case OutcomeOverridden:
+		return "✓*"
+	default:
+		return "✗"
+	}
+}
+
+const matrixLegend = "✓ = allowed, ✓* = allowed by override, ✗ = not allowed"
+
+// WriteMarkdown renders the matrix as a Markdown table, with the source
+// versions as rows and the target versions as columns.
+func (m Matrix) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("| from \\ to |")
+	for _, v := range m.Versions {
+		fmt.Fprintf(&sb, " %s |", v)
+	}
+	sb.WriteString("\n|---|")
+	for range m.Versions {
+		sb.WriteString("---|")
+	}
+	sb.WriteString("\n")
+	for i, from := range m.Versions {
+		fmt.Fprintf(&sb, "| **%s** |", from)
+		for j := range m.Versions {
+			fmt.Fprintf(&sb, " %s |", matrixCell(m.Decisions[i][j]))
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "\n%s\n", matrixLegend)
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+var matrixHTMLTemplate = template.Must(template.New("matrix").Funcs(template.FuncMap{
+	"cell":   matrixCell,
+	"reason": Reason,
+}).Parse(`<table class="upgrade-matrix">
+<thead>
+<tr><th>from \ to</th>{{range .Versions}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{range $i, $from := .Versions}}<tr><th>{{$from}}</th>{{range index $.Decisions $i}}<td class="{{.Outcome}}"{{if .Err}} title="{{reason .Err}}"{{end}}>{{cell .}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+<p>{{.Legend}}</p>
+`))
+
+// WriteHTML renders the matrix as an HTML table, with the source
+// versions as rows and the target versions as columns.
+// Every cell has the outcome of its decision as class and, when the
+// upgrade is not allowed, the reason as title.
+func (m Matrix) WriteHTML(w io.Writer) error {
+	return matrixHTMLTemplate.Execute(w, struct {
+		Matrix
+		Legend string
+	}{m, matrixLegend})
+}
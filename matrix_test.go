@@ -0,0 +1,79 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewMatrix(t *testing.T) {
+	versions := []VersionString{"3.10.8", "3.11.8", "3.12.1"}
+	m := NewMatrix(versions)
+	expected := [][]bool{
+		{true, true, false},
+		{false, true, true},
+		{false, false, true},
+	}
+	for i := range versions {
+		for j := range versions {
+			if m.Decisions[i][j].Allowed() != expected[i][j] {
+				t.Errorf("%s -> %s: expected allowed=%v", versions[i], versions[j], expected[i][j])
+			}
+		}
+	}
+}
+
+func TestMatrixMarkdown(t *testing.T) {
+	versions := []VersionString{"3.10.8", "3.11.8", "3.12.1"}
+	m := NewMatrix(versions, WithOverrides(Override{From: "3.10", To: "3.12"}))
+	var buf bytes.Buffer
+	if err := m.WriteMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "| from \\ to | 3.10.8 | 3.11.8 | 3.12.1 |\n" +
+		"|---|---|---|---|\n" +
+		"| **3.10.8** | ✓ | ✓ | ✓* |\n" +
+		"| **3.11.8** | ✗ | ✓ | ✓ |\n" +
+		"| **3.12.1** | ✗ | ✗ | ✓ |\n" +
+		"\n" + matrixLegend + "\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestMatrixHTML(t *testing.T) {
+	m := NewMatrix([]VersionString{"3.10.8", "3.12.1"})
+	var buf bytes.Buffer
+	if err := m.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	s := buf.String()
+	for _, expected := range []string{
+		"<th>from \\ to</th><th>3.10.8</th><th>3.12.1</th>",
+		`<tr><th>3.10.8</th><td class="no-op">✓</td><td class="denied" title="Minor versions may only increment by 1">✗</td></tr>`,
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Expected HTML to contain %s, got:\n%s", expected, s)
+		}
+	}
+}
@@ -0,0 +1,612 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// PolicyField identifies a field of a Policy when merging policies.
+type PolicyField string
+
+const (
+	// PolicyFieldRuleSet is Policy.RuleSet
+	PolicyFieldRuleSet PolicyField = "ruleSet"
+	// PolicyFieldSoft is Policy.Soft
+	PolicyFieldSoft PolicyField = "soft"
+	// PolicyFieldMaxMinorSkip is Policy.MaxMinorSkip
+	PolicyFieldMaxMinorSkip PolicyField = "maxMinorSkip"
+	// PolicyFieldLicenses is Policy.Licenses
+	PolicyFieldLicenses PolicyField = "licenses"
+	// PolicyFieldAllowList is Policy.DenyByDefault together with Policy.AllowList
+	PolicyFieldAllowList PolicyField = "allowList"
+	// PolicyFieldChannels is Policy.Channels
+	PolicyFieldChannels PolicyField = "channels"
+	// PolicyFieldAllowGAToPreRelease is Policy.AllowGAToPreRelease
+	PolicyFieldAllowGAToPreRelease PolicyField = "allowGAToPreRelease"
+	// PolicyFieldMaintenanceWindows is Policy.MaintenanceWindows
+	PolicyFieldMaintenanceWindows PolicyField = "maintenanceWindows"
+	// PolicyFieldWarningsAsErrors is Policy.WarningsAsErrors
+	PolicyFieldWarningsAsErrors PolicyField = "warningsAsErrors"
+	// PolicyFieldCooldown is Policy.Cooldown
+	PolicyFieldCooldown PolicyField = "cooldown"
+	// PolicyFieldPreconditions is Policy.Preconditions
+	PolicyFieldPreconditions PolicyField = "preconditions"
+)
+
+// policyFields holds all fields in the order of Policy.
+var policyFields = []PolicyField{
+	PolicyFieldRuleSet,
+	PolicyFieldSoft,
+	PolicyFieldMaxMinorSkip,
+	PolicyFieldLicenses,
+	PolicyFieldAllowList,
+	PolicyFieldChannels,
+	PolicyFieldAllowGAToPreRelease,
+	PolicyFieldMaintenanceWindows,
+	PolicyFieldWarningsAsErrors,
+	PolicyFieldCooldown,
+	PolicyFieldPreconditions,
+}
+
+// PolicyLayer is a policy with its place in a hierarchy, e.g. the
+// organization default, an environment or a single deployment.
+type PolicyLayer struct {
+	// Name identifies the layer in the effective policy
+	Name string
+	// Policy of the layer
+	Policy Policy
+	// Replace marks the fields in which this layer replaces the result of
+	// the previous layers, instead of being merged with it. Use it to
+	// relax a field, e.g. to allow the soft rules in development.
+	Replace []PolicyField
+}
+
+// replaces returns true if the layer replaces the given field.
+func (l PolicyLayer) replaces(f PolicyField) bool {
+	for _, x := range l.Replace {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectivePolicy is the result of merging policy layers.
+type EffectivePolicy struct {
+	// Policy is the merged policy
+	Policy Policy
+	// Sources holds, by field, the names of the layers that determined
+	// the value of the field, in order
+	Sources map[PolicyField][]string
+}
+
+// MergePolicies merges the given layers, from the most general to the most
+// specific, into a single policy. Unless a layer replaces a field, the
+// stricter value wins:
+//
+//   - the rules of all rule sets are evaluated (a nil RuleSet counts as
+//     DefaultRuleSet), in the order of the layers; when multiple layers have
+//     a rule with the same ID, an upgrade denied by any of them is denied.
+//     The rule sets are merged for every check, so a layer can replace its
+//     rules with an AtomicRuleSet
+//   - the soft rules are only used when all layers use them
+//   - the smallest limit of the minor version increase applies
+//   - a license transition must be allowed by all license matrices
+//   - with an allow-list in multiple layers, an upgrade must be in all of them
+//   - the release channel must be allowed by all layers, where a layer
+//     without channels only allows generally available releases
+//   - going back from a release to its pre-release is only allowed when
+//     all layers allow it
+//   - an upgrade must be inside a maintenance window of all layers
+//   - the warnings promoted to errors by any layer deny an upgrade
+//   - the longest cooldown durations apply
+//   - the preconditions of all layers must be met; a precondition that is
+//     in multiple layers (by name) applies to the transitions of all of them
+//
+// It returns an error when the layers have no release channel in common.
+func MergePolicies(layers ...PolicyLayer) (EffectivePolicy, error) {
+	result := EffectivePolicy{Sources: make(map[PolicyField][]string)}
+	var windows [][]MaintenanceWindow
+	for i, l := range layers {
+		p := l.Policy
+		for _, f := range policyFields {
+			if i == 0 || l.replaces(f) {
+				result.Sources[f] = nil
+			}
+		}
+		source := func(f PolicyField) {
+			result.Sources[f] = append(result.Sources[f], l.Name)
+		}
+		if i == 0 || l.replaces(PolicyFieldRuleSet) {
+			result.Policy.RuleSet = p.RuleSet
+			source(PolicyFieldRuleSet)
+		} else if merged, changed := mergeRuleSets(result.Policy.RuleSet, p.RuleSet); changed {
+			result.Policy.RuleSet = merged
+			source(PolicyFieldRuleSet)
+		}
+		if i == 0 || l.replaces(PolicyFieldSoft) {
+			result.Policy.Soft = p.Soft
+			source(PolicyFieldSoft)
+		} else if result.Policy.Soft && !p.Soft {
+			result.Policy.Soft = false
+			result.Sources[PolicyFieldSoft] = []string{l.Name}
+		}
+		if i == 0 || l.replaces(PolicyFieldMaxMinorSkip) {
+			result.Policy.MaxMinorSkip = p.MaxMinorSkip
+			source(PolicyFieldMaxMinorSkip)
+		} else if p.MaxMinorSkip > 0 && (result.Policy.MaxMinorSkip == 0 || p.MaxMinorSkip < result.Policy.MaxMinorSkip) {
+			result.Policy.MaxMinorSkip = p.MaxMinorSkip
+			result.Sources[PolicyFieldMaxMinorSkip] = []string{l.Name}
+		}
+		if i == 0 || l.replaces(PolicyFieldLicenses) {
+			result.Policy.Licenses = p.Licenses
+			source(PolicyFieldLicenses)
+		} else if p.Licenses != nil {
+			result.Policy.Licenses = intersectLicenseMatrices(result.Policy.Licenses, p.Licenses)
+			source(PolicyFieldLicenses)
+		}
+		if i == 0 || l.replaces(PolicyFieldAllowList) {
+			result.Policy.DenyByDefault, result.Policy.AllowList = p.DenyByDefault, p.AllowList
+			source(PolicyFieldAllowList)
+		} else if p.DenyByDefault {
+			if result.Policy.DenyByDefault {
+				result.Policy.AllowList = intersectTransitions(result.Policy.AllowList, p.AllowList)
+			} else {
+				result.Policy.DenyByDefault, result.Policy.AllowList = true, p.AllowList
+			}
+			source(PolicyFieldAllowList)
+		}
+		if i == 0 || l.replaces(PolicyFieldChannels) {
+			result.Policy.Channels = p.Channels
+			source(PolicyFieldChannels)
+		} else if len(p.Channels) > 0 {
+			current := result.Policy.Channels
+			if len(current) == 0 {
+				// No channels means generally available releases only
+				current = []Channel{ChannelGA}
+			}
+			common := intersectChannels(current, p.Channels)
+			if len(common) == 0 {
+				return EffectivePolicy{}, fmt.Errorf("Policy layer '%s' has no release channel in common with the layers before it", l.Name)
+			}
+			result.Policy.Channels = common
+			source(PolicyFieldChannels)
+		}
+		if i == 0 || l.replaces(PolicyFieldAllowGAToPreRelease) {
+			result.Policy.AllowGAToPreRelease = p.AllowGAToPreRelease
+			source(PolicyFieldAllowGAToPreRelease)
+		} else if result.Policy.AllowGAToPreRelease && !p.AllowGAToPreRelease {
+			result.Policy.AllowGAToPreRelease = false
+			result.Sources[PolicyFieldAllowGAToPreRelease] = []string{l.Name}
+		}
+		if i == 0 || l.replaces(PolicyFieldMaintenanceWindows) {
+			windows = nil
+		}
+		if len(p.MaintenanceWindows) > 0 || i == 0 || l.replaces(PolicyFieldMaintenanceWindows) {
+			if len(p.MaintenanceWindows) > 0 {
+				windows = append(windows, p.MaintenanceWindows)
+			}
+			source(PolicyFieldMaintenanceWindows)
+		}
+		if i == 0 || l.replaces(PolicyFieldWarningsAsErrors) {
+			result.Policy.WarningsAsErrors = p.WarningsAsErrors
+			source(PolicyFieldWarningsAsErrors)
+		} else if merged := mergeWarningCodes(result.Policy.WarningsAsErrors, p.WarningsAsErrors); len(merged) > len(result.Policy.WarningsAsErrors) {
+			result.Policy.WarningsAsErrors = merged
+			source(PolicyFieldWarningsAsErrors)
+		}
+		if i == 0 || l.replaces(PolicyFieldCooldown) {
+			result.Policy.Cooldown = p.Cooldown
+			source(PolicyFieldCooldown)
+		} else if c := &result.Policy.Cooldown; p.Cooldown.AfterUpgrade > c.AfterUpgrade || p.Cooldown.BetweenMinors > c.BetweenMinors {
+			if p.Cooldown.AfterUpgrade > c.AfterUpgrade {
+				c.AfterUpgrade = p.Cooldown.AfterUpgrade
+			}
+			if p.Cooldown.BetweenMinors > c.BetweenMinors {
+				c.BetweenMinors = p.Cooldown.BetweenMinors
+			}
+			source(PolicyFieldCooldown)
+		}
+		if i == 0 || l.replaces(PolicyFieldPreconditions) {
+			result.Policy.Preconditions = nil
+		}
+		if len(p.Preconditions) > 0 || i == 0 || l.replaces(PolicyFieldPreconditions) {
+			result.Policy.Preconditions = mergePreconditions(result.Policy.Preconditions, p.Preconditions)
+			source(PolicyFieldPreconditions)
+		}
+	}
+	if len(windows) > 0 {
+		result.Policy.MaintenanceWindows, result.Policy.moreWindows = windows[0], windows[1:]
+	}
+	return result, nil
+}
+
+// mergeRuleSets returns a rule set that evaluates the rules of a followed
+// by those of b, see mergeSnapshots. Nil counts as DefaultRuleSet. Both
+// sources are kept and merged when a check takes a snapshot, so replacing
+// the rules of an AtomicRuleSet also changes the merged rules. It returns
+// false when b is a RuleSet that adds nothing to the rules of a.
+func mergeRuleSets(a, b RuleSetSource) (RuleSetSource, bool) {
+	if b == nil && a == nil {
+		return nil, false
+	}
+	if a == nil {
+		a = DefaultRuleSet()
+	}
+	if b == nil {
+		b = DefaultRuleSet()
+	}
+	if rb, ok := b.(*RuleSet); ok {
+		if _, changed := mergeSnapshots(a.Snapshot(), rb); !changed {
+			return a, false
+		}
+	}
+	l := &layeredRuleSet{}
+	if la, ok := a.(*layeredRuleSet); ok {
+		l.layers = append(l.layers, la.layers...)
+	} else {
+		l.layers = append(l.layers, a)
+	}
+	l.layers = append(l.layers, b)
+	return l, true
+}
+
+// layeredRuleSet merges the rule sets of policy layers, see MergePolicies.
+type layeredRuleSet struct {
+	layers []RuleSetSource
+	last   atomic.Value // *layeredSnapshot
+}
+
+// layeredSnapshot holds the merged rules of the snapshots of the layers.
+type layeredSnapshot struct {
+	snapshots []*RuleSet
+	merged    *RuleSet
+}
+
+// Snapshot returns the merged rules of the current rule sets of the layers.
+// The result is reused until one of the layers returns another RuleSet.
+func (l *layeredRuleSet) Snapshot() *RuleSet {
+	snapshots := make([]*RuleSet, len(l.layers))
+	for i, s := range l.layers {
+		snapshots[i] = s.Snapshot()
+	}
+	if last, ok := l.last.Load().(*layeredSnapshot); ok && sameSnapshots(last.snapshots, snapshots) {
+		return last.merged
+	}
+	merged := snapshots[0]
+	for _, s := range snapshots[1:] {
+		merged, _ = mergeSnapshots(merged, s)
+	}
+	l.last.Store(&layeredSnapshot{snapshots: snapshots, merged: merged})
+	return merged
+}
+
+// sameSnapshots returns true when a and b hold the same rule sets.
+func sameSnapshots(a, b []*RuleSet) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+// mergeSnapshots returns the rules of a followed by those of b that are not
+// in a. When both have a rule with the same ID, the rule of a is replaced by
+// one that denies an upgrade when either of them does, unless both are the
+// same built-in rule. It returns false when the result has the rules of a.
+func mergeSnapshots(a, b *RuleSet) (*RuleSet, bool) {
+	rules := a.Rules()
+	changed := len(b.hooks) > 0
+	for _, r := range b.rules {
+		i := indexOfRule(rules, r.ID)
+		switch {
+		case i < 0:
+			rules = append(rules, r)
+			changed = true
+		case !isBuiltinRule(rules[i]) || !isBuiltinRule(r):
+			rules[i] = strictestRule(rules[i], r)
+			changed = true
+		}
+	}
+	if !changed {
+		return a, false
+	}
+	return &RuleSet{rules: rules, hooks: append(a.Hooks(), b.hooks...)}, true
+}
+
+// indexOfRule returns the index of the rule with given ID, or -1.
+func indexOfRule(rules []Rule, id RuleID) int {
+	for i, r := range rules {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// isBuiltinRule returns true when r is the built-in rule with its ID.
+// The built-in rules use functions without captured variables, so
+// comparing the functions identifies them.
+func isBuiltinRule(r Rule) bool {
+	builtin, found := defaultRuleSet.Rule(r.ID)
+	for _, s := range majorRuleSets {
+		if found {
+			break
+		}
+		builtin, found = s.Rule(r.ID)
+	}
+	return found && r.Overridable == builtin.Overridable &&
+		funcPointer(r.Check) == funcPointer(builtin.Check) &&
+		funcPointer(r.Applies) == funcPointer(builtin.Applies)
+}
+
+// funcPointer returns the code pointer of f, or 0 for nil.
+func funcPointer(f interface{}) uintptr {
+	v := reflect.ValueOf(f)
+	if v.IsNil() {
+		return 0
+	}
+	return v.Pointer()
+}
+
+// strictestRule returns a rule with the ID of a that denies an upgrade when
+// a or b does. An Override only allows the upgrade when both rules are
+// overridable.
+func strictestRule(a, b Rule) Rule {
+	return Rule{
+		ID:      a.ID,
+		Applies: func(in RuleInput) bool { return a.applies(in) || b.applies(in) },
+		Check: func(in RuleInput) error {
+			if a.applies(in) {
+				if err := a.Check(in); err != nil {
+					return err
+				}
+			}
+			if b.applies(in) {
+				return b.Check(in)
+			}
+			return nil
+		},
+		Overridable: a.Overridable && b.Overridable,
+	}
+}
+
+// intersectLicenseMatrices returns the transitions allowed by both a and b.
+// A nil matrix allows the built-in transitions.
+func intersectLicenseMatrices(a, b LicenseMatrix) LicenseMatrix {
+	result := LicenseMatrix{}
+	for _, from := range []License{LicenseCommunity, LicenseEnterprise} {
+		for _, to := range []License{LicenseCommunity, LicenseEnterprise} {
+			if from != to && a.Allows(from, to) && b.Allows(from, to) {
+				result[LicenseTransition{From: from, To: to}] = true
+			}
+		}
+	}
+	return result
+}
+
+// intersectTransitions returns the transitions that are in both a and b.
+func intersectTransitions(a, b []Transition) []Transition {
+	result := []Transition{}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				result = append(result, x)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// intersectChannels returns the channels that are in both a and b.
+func intersectChannels(a, b []Channel) []Channel {
+	var result []Channel
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				result = append(result, x)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// mergePreconditions adds the preconditions of b to a. A precondition with
+// the name of one in a extends the transitions it applies to.
+func mergePreconditions(a, b []PolicyPrecondition) []PolicyPrecondition {
+	result := append([]PolicyPrecondition(nil), a...)
+	for _, y := range b {
+		found := false
+		for i, x := range result {
+			if x.Precondition.Name() != y.Precondition.Name() {
+				continue
+			}
+			found = true
+			if len(x.Transitions) > 0 && len(y.Transitions) > 0 {
+				result[i].Transitions = mergeTransitionKinds(x.Transitions, y.Transitions)
+			} else {
+				result[i].Transitions = nil
+			}
+			break
+		}
+		if !found {
+			result = append(result, y)
+		}
+	}
+	return result
+}
+
+// mergeTransitionKinds returns the kinds that are in a or b.
+func mergeTransitionKinds(a, b []TransitionKind) []TransitionKind {
+	result := append([]TransitionKind(nil), a...)
+	for _, y := range b {
+		found := false
+		for _, x := range a {
+			if x == y {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, y)
+		}
+	}
+	return result
+}
+
+// mergeWarningCodes returns the codes that are in a or b.
+func mergeWarningCodes(a, b []WarningCode) []WarningCode {
+	result := append([]WarningCode(nil), a...)
+	for _, y := range b {
+		found := false
+		for _, x := range a {
+			if x == y {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, y)
+		}
+	}
+	return result
+}
+
+// String describes every field of the effective policy with the layers
+// that determined it, one field per line. It is intended for debugging.
+func (e EffectivePolicy) String() string {
+	var b strings.Builder
+	p := e.Policy
+	for _, f := range policyFields {
+		var value string
+		switch f {
+		case PolicyFieldRuleSet:
+			rs := DefaultRuleSet()
+			if p.RuleSet != nil {
+				rs = p.RuleSet.Snapshot()
+			}
+			ids := make([]string, 0, len(rs.rules))
+			for _, r := range rs.rules {
+				ids = append(ids, string(r.ID))
+			}
+			value = strings.Join(ids, ", ")
+		case PolicyFieldSoft:
+			value = fmt.Sprintf("%t", p.Soft)
+		case PolicyFieldMaxMinorSkip:
+			value = fmt.Sprintf("%d", p.MaxMinorSkip)
+		case PolicyFieldLicenses:
+			value = "default"
+			if p.Licenses != nil {
+				var allowed []string
+				for t, ok := range p.Licenses {
+					if ok {
+						allowed = append(allowed, t.From.String()+"->"+t.To.String())
+					}
+				}
+				sort.Strings(allowed)
+				value = "[" + strings.Join(allowed, ", ") + "]"
+			}
+		case PolicyFieldAllowList:
+			value = "off"
+			if p.DenyByDefault {
+				allowed := make([]string, 0, len(p.AllowList))
+				for _, t := range p.AllowList {
+					allowed = append(allowed, t.String())
+				}
+				value = "[" + strings.Join(allowed, ", ") + "]"
+			}
+		case PolicyFieldChannels:
+			value = string(ChannelGA)
+			if len(p.Channels) > 0 {
+				channels := make([]string, 0, len(p.Channels))
+				for _, c := range p.Channels {
+					channels = append(channels, string(c))
+				}
+				value = strings.Join(channels, ", ")
+			}
+		case PolicyFieldAllowGAToPreRelease:
+			value = fmt.Sprintf("%t", p.AllowGAToPreRelease)
+		case PolicyFieldMaintenanceWindows:
+			value = "always"
+			if len(p.MaintenanceWindows) > 0 {
+				groups := []string{windowsString(p.MaintenanceWindows)}
+				for _, windows := range p.moreWindows {
+					groups = append(groups, windowsString(windows))
+				}
+				value = strings.Join(groups, " and ")
+			}
+		case PolicyFieldWarningsAsErrors:
+			value = "none"
+			if len(p.WarningsAsErrors) > 0 {
+				codes := make([]string, 0, len(p.WarningsAsErrors))
+				for _, c := range p.WarningsAsErrors {
+					codes = append(codes, string(c))
+				}
+				value = strings.Join(codes, ", ")
+			}
+		case PolicyFieldCooldown:
+			value = "none"
+			if !p.Cooldown.IsZero() {
+				value = fmt.Sprintf("%s after any upgrade, %s between minor upgrades", p.Cooldown.AfterUpgrade, p.Cooldown.BetweenMinors)
+			}
+		case PolicyFieldPreconditions:
+			value = "none"
+			if len(p.Preconditions) > 0 {
+				names := make([]string, 0, len(p.Preconditions))
+				for _, x := range p.Preconditions {
+					name := x.Precondition.Name()
+					if len(x.Transitions) > 0 {
+						kinds := make([]string, 0, len(x.Transitions))
+						for _, k := range x.Transitions {
+							kinds = append(kinds, string(k))
+						}
+						name += " (" + strings.Join(kinds, ", ") + ")"
+					}
+					names = append(names, name)
+				}
+				value = strings.Join(names, ", ")
+			}
+		}
+		fmt.Fprintf(&b, "%s: %s", f, value)
+		if sources := e.Sources[f]; len(sources) > 0 {
+			fmt.Fprintf(&b, " (from %s)", strings.Join(sources, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// windowsString describes a group of maintenance windows.
+func windowsString(windows []MaintenanceWindow) string {
+	s := make([]string, 0, len(windows))
+	for _, w := range windows {
+		s = append(s, w.String())
+	}
+	return "[" + strings.Join(s, ", ") + "]"
+}
@@ -0,0 +1,244 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMergePoliciesStricterWins(t *testing.T) {
+	backup := &testPrecondition{name: "backup"}
+	health := &testPrecondition{name: "health"}
+	org := PolicyLayer{Name: "org", Policy: Policy{
+		Soft:          true,
+		Channels:      []Channel{ChannelGA, ChannelPreRelease},
+		Preconditions: []PolicyPrecondition{{Precondition: backup, Transitions: []TransitionKind{TransitionMajor}}},
+	}}
+	prod := PolicyLayer{Name: "production", Policy: Policy{
+		Channels:      []Channel{ChannelGA},
+		Licenses:      LicenseMatrix{},
+		Preconditions: []PolicyPrecondition{{Precondition: backup, Transitions: []TransitionKind{TransitionMinor}}, {Precondition: health}},
+	}}
+	e, err := MergePolicies(org, prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := e.Policy
+	if p.Soft {
+		t.Error("Expected strict rules")
+	}
+	if len(p.Channels) != 1 || p.Channels[0] != ChannelGA {
+		t.Errorf("Expected GA channel only, got %v", p.Channels)
+	}
+	if p.Licenses.Allows(LicenseCommunity, LicenseEnterprise) {
+		t.Error("Expected no license transitions")
+	}
+	if len(p.Preconditions) != 2 || len(p.Preconditions[0].Transitions) != 2 || p.Preconditions[1].Precondition != health {
+		t.Errorf("Unexpected preconditions %+v", p.Preconditions)
+	}
+	if s := e.Sources[PolicyFieldSoft]; len(s) != 1 || s[0] != "production" {
+		t.Errorf("Expected soft from production, got %v", s)
+	}
+	if s := e.Sources[PolicyFieldChannels]; len(s) != 2 {
+		t.Errorf("Expected channels from both layers, got %v", s)
+	}
+}
+
+func TestMergePoliciesReplace(t *testing.T) {
+	org := PolicyLayer{Name: "org", Policy: Policy{Channels: []Channel{ChannelGA}}}
+	dev := PolicyLayer{Name: "dev", Policy: Policy{Soft: true, Channels: []Channel{ChannelPreRelease}},
+		Replace: []PolicyField{PolicyFieldSoft, PolicyFieldChannels}}
+	e, err := MergePolicies(org, dev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Policy.Soft || len(e.Policy.Channels) != 1 || e.Policy.Channels[0] != ChannelPreRelease {
+		t.Errorf("Expected dev to replace soft and channels, got %+v", e.Policy)
+	}
+	if s := e.Sources[PolicyFieldChannels]; len(s) != 1 || s[0] != "dev" {
+		t.Errorf("Expected channels from dev, got %v", s)
+	}
+	if _, err := MergePolicies(org, PolicyLayer{Name: "dev", Policy: dev.Policy}); err == nil {
+		t.Error("Expected error for layers without common channel")
+	}
+}
+
+func TestMergePoliciesGAOnlyLayer(t *testing.T) {
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org"},
+		PolicyLayer{Name: "team", Policy: Policy{Channels: []Channel{ChannelGA, ChannelPreRelease}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.Policy.Channels) != 1 || e.Policy.Channels[0] != ChannelGA {
+		t.Errorf("Expected GA channel only, got %v", e.Policy.Channels)
+	}
+	if d := e.Policy.Check("3.11.8", "3.12.0-rc.1"); d.Allowed() {
+		t.Error("Expected the pre-release layer not to loosen the GA-only layer")
+	}
+	if _, err := MergePolicies(PolicyLayer{Name: "org"}, PolicyLayer{Name: "team", Policy: Policy{Channels: []Channel{ChannelPreRelease}}}); err == nil {
+		t.Error("Expected error for a pre-release layer on top of a GA-only layer")
+	}
+}
+
+func TestMergePoliciesRuleSets(t *testing.T) {
+	custom := Rule{ID: "custom", Check: func(RuleInput) error { return nil }}
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org"},
+		PolicyLayer{Name: "team", Policy: Policy{RuleSet: NewRuleSet(ruleMajorVersion, custom)}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := e.Policy.RuleSet.Snapshot().Rules()
+	if len(rules) != len(defaultRuleSet.rules)+1 || rules[len(rules)-1].ID != "custom" {
+		t.Errorf("Expected default rules plus custom, got %d rules", len(rules))
+	}
+	e, err = MergePolicies(PolicyLayer{Name: "org"}, PolicyLayer{Name: "team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Policy.RuleSet != nil {
+		t.Error("Expected default rule set")
+	}
+}
+
+func TestMergePoliciesRuleSetsSameID(t *testing.T) {
+	allow := Rule{ID: "custom", Check: func(RuleInput) error { return nil }}
+	deny := Rule{ID: "custom", Check: func(RuleInput) error { return errors.New("Denied by team") }}
+	for _, order := range [][]Rule{{allow, deny}, {deny, allow}} {
+		e, err := MergePolicies(
+			PolicyLayer{Name: "org", Policy: Policy{RuleSet: DefaultRuleSet().WithRule(order[0])}},
+			PolicyLayer{Name: "team", Policy: Policy{RuleSet: DefaultRuleSet().WithRule(order[1])}},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(e.Policy.RuleSet.Snapshot().Rules()) != len(defaultRuleSet.rules)+1 {
+			t.Errorf("Expected the rules with the same ID to be merged, got %d rules", len(e.Policy.RuleSet.Snapshot().Rules()))
+		}
+		if d := e.Policy.Check("3.11.1", "3.11.2"); d.Rule != "custom" {
+			t.Errorf("Expected denial by custom, got %q", d.Rule)
+		}
+	}
+}
+
+func TestMergePoliciesAtomicRuleSet(t *testing.T) {
+	a := NewAtomicRuleSet(DefaultRuleSet())
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org"},
+		PolicyLayer{Name: "team", Policy: Policy{RuleSet: a}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := e.Policy.Check("3.11.1", "3.11.2"); !d.Allowed() {
+		t.Fatalf("Expected allowed, got %s", d.Err)
+	}
+	a.Store(DefaultRuleSet().WithRule(Rule{ID: "freeze", Check: func(RuleInput) error { return errors.New("Frozen") }}))
+	if d := e.Policy.Check("3.11.1", "3.11.2"); d.Rule != "freeze" {
+		t.Errorf("Expected the stored rules to apply, got %q", d.Rule)
+	}
+}
+
+func TestMergePoliciesAllowListAndWindows(t *testing.T) {
+	saturday := MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)
+	early := MustParseMaintenanceWindow("0 5 * * *", 2*time.Hour, nil)
+	a := Transition{From: "3.11.8", To: "3.12.1"}
+	b := Transition{From: "3.11.8", To: "3.12.2"}
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org", Policy: Policy{DenyByDefault: true, AllowList: []Transition{a, b}, MaintenanceWindows: []MaintenanceWindow{saturday}}},
+		PolicyLayer{Name: "db", Policy: Policy{DenyByDefault: true, AllowList: []Transition{b}, MaintenanceWindows: []MaintenanceWindow{early}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := WithClock(func() time.Time { return time.Date(2024, 6, 8, 5, 30, 0, 0, time.UTC) })
+	if d := e.Policy.Check(b.From, b.To, clock); !d.Allowed() {
+		t.Errorf("Expected %s to be allowed, got %s", b, d.Err)
+	}
+	if d := e.Policy.Check(a.From, a.To, clock); d.Rule != RuleAllowList {
+		t.Errorf("Expected %s to be denied by %s, got %q", a, RuleAllowList, d.Rule)
+	}
+	clock = WithClock(func() time.Time { return time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC) })
+	if d := e.Policy.Check(b.From, b.To, clock); d.Rule != RuleMaintenanceWindow {
+		t.Errorf("Expected denial by %s outside the early window, got %q", RuleMaintenanceWindow, d.Rule)
+	}
+}
+
+func TestEffectivePolicyString(t *testing.T) {
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org", Policy: Policy{Channels: []Channel{ChannelGA}}},
+		PolicyLayer{Name: "db", Policy: Policy{Preconditions: []PolicyPrecondition{{Precondition: &testPrecondition{name: "backup"}, Transitions: []TransitionKind{TransitionMinor}}}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := e.String()
+	for _, expected := range []string{
+		"ruleSet: edition-downgrade, major-version, minor-increment, minor-downgrade, max-minor-skip, minimum-patch, " +
+			"warnings-as-errors, mandatory-intermediate, pre-release-target, ga-to-pre-release, skipped-version, architecture, " +
+			"required-fix, allow-list, channel, maintenance-window, cooldown, preconditions (from org)\n",
+		"soft: false (from org)\n",
+		"maxMinorSkip: 0 (from org)\n",
+		"licenses: default (from org)\n",
+		"allowList: off (from org)\n",
+		"channels: ga (from org)\n",
+		"allowGAToPreRelease: false (from org)\n",
+		"maintenanceWindows: always (from org)\n",
+		"preconditions: backup (minor) (from org, db)\n",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Expected %q in\n%s", expected, s)
+		}
+	}
+	e, err = MergePolicies(PolicyLayer{Name: "org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := e.String(); !strings.Contains(s, "channels: ga (from org)\n") {
+		t.Errorf("Expected a policy without channels to show GA only, got\n%s", s)
+	}
+}
+
+func TestMergePoliciesMaxMinorSkip(t *testing.T) {
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org", Policy: Policy{Soft: true, MaxMinorSkip: 3}},
+		PolicyLayer{Name: "env", Policy: Policy{Soft: true}},
+		PolicyLayer{Name: "db", Policy: Policy{Soft: true, MaxMinorSkip: 2}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Policy.MaxMinorSkip != 2 {
+		t.Errorf("Expected the smallest limit, got %d", e.Policy.MaxMinorSkip)
+	}
+	if s := e.Sources[PolicyFieldMaxMinorSkip]; len(s) != 1 || s[0] != "db" {
+		t.Errorf("Expected limit from db, got %v", s)
+	}
+	if d := e.Policy.Check("3.10.8", "3.13.0"); d.Rule != RuleMaxMinorSkip {
+		t.Errorf("Expected %s, got %q", RuleMaxMinorSkip, d.Rule)
+	}
+}
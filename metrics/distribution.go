@@ -0,0 +1,97 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/arangodb/go-upgrade-rules/simulation"
+)
+
+// DistributionCollector exports the version distribution of a fleet
+// (see simulation.AnalyzeDistribution) as Prometheus gauges.
+// Register it with a Prometheus registry and call Set whenever the
+// distribution is recomputed.
+type DistributionCollector struct {
+	deployments    *prometheus.GaugeVec
+	endOfLife      prometheus.Gauge
+	endOfLifeRatio prometheus.Gauge
+	medianDistance prometheus.Gauge
+}
+
+var _ prometheus.Collector = &DistributionCollector{}
+
+// NewDistributionCollector creates a new DistributionCollector.
+func NewDistributionCollector() *DistributionCollector {
+	return &DistributionCollector{
+		deployments: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "fleet_deployments",
+			Help:      "Number of deployments by version",
+		}, []string{"version"}),
+		endOfLife: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "fleet_end_of_life_deployments",
+			Help:      "Number of deployments on an end of life version",
+		}),
+		endOfLifeRatio: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "fleet_end_of_life_ratio",
+			Help:      "Fraction of deployments on an end of life version",
+		}),
+		medianDistance: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "fleet_median_minor_distance",
+			Help:      "Median number of minor versions the deployments are behind the latest version",
+		}),
+	}
+}
+
+// Set replaces the exported values by those of the given distribution.
+func (c *DistributionCollector) Set(d simulation.Distribution) {
+	c.deployments.Reset()
+	for v, n := range d.Versions {
+		c.deployments.WithLabelValues(string(v)).Set(float64(n))
+	}
+	c.endOfLife.Set(float64(d.EndOfLife))
+	c.endOfLifeRatio.Set(d.EndOfLifePercent / 100)
+	c.medianDistance.Set(d.MedianMinorDistance)
+}
+
+// Describe implements prometheus.Collector.
+func (c *DistributionCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.deployments.Describe(ch)
+	c.endOfLife.Describe(ch)
+	c.endOfLifeRatio.Describe(ch)
+	c.medianDistance.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *DistributionCollector) Collect(ch chan<- prometheus.Metric) {
+	c.deployments.Collect(ch)
+	c.endOfLife.Collect(ch)
+	c.endOfLifeRatio.Collect(ch)
+	c.medianDistance.Collect(ch)
+}
@@ -0,0 +1,54 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+	"github.com/arangodb/go-upgrade-rules/simulation"
+)
+
+func TestDistributionCollector(t *testing.T) {
+	c := NewDistributionCollector()
+	fleet := []upgraderules.DeploymentInfo{{From: "3.12.1"}, {From: "3.11.8"}, {From: "3.11.8"}, {From: "3.9.5"}}
+	c.Set(simulation.AnalyzeDistribution(fleet, simulation.DistributionSpec{Latest: "3.12.1", EndOfLife: []upgraderules.VersionString{"3.9"}}))
+	if n := testutil.ToFloat64(c.deployments.WithLabelValues("3.11.8")); n != 2 {
+		t.Errorf("Expected 2 deployments on 3.11.8, got %v", n)
+	}
+	if n := testutil.ToFloat64(c.endOfLife); n != 1 {
+		t.Errorf("Expected 1 end of life deployment, got %v", n)
+	}
+	if n := testutil.ToFloat64(c.endOfLifeRatio); n != 0.25 {
+		t.Errorf("Expected end of life ratio 0.25, got %v", n)
+	}
+	if n := testutil.ToFloat64(c.medianDistance); n != 1 {
+		t.Errorf("Expected median distance 1, got %v", n)
+	}
+
+	// Versions no longer in use are removed
+	c.Set(simulation.AnalyzeDistribution(fleet[:1], simulation.DistributionSpec{Latest: "3.12.1"}))
+	if n := testutil.CollectAndCount(c.deployments); n != 1 {
+		t.Errorf("Expected 1 version, got %d", n)
+	}
+}
@@ -0,0 +1,133 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package metrics exports Prometheus metrics about upgrade rule evaluation.
+// It is a separate package so the rules themselves do not depend on the
+// Prometheus client library.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+const (
+	namespace = "arangodb"
+	subsystem = "upgrade_rules"
+)
+
+// Values of the result label of the rule metrics
+const (
+	resultAllowed = "allowed"
+	resultDenied  = "denied"
+	resultError   = "error"
+)
+
+// Collector implements upgraderules.Metrics, upgraderules.RuleMetrics
+// and prometheus.Collector.
+// Register it with a Prometheus registry and pass it to the checks
+// using upgraderules.WithMetrics.
+type Collector struct {
+	checks       *prometheus.CounterVec
+	duration     prometheus.Histogram
+	rules        *prometheus.CounterVec
+	ruleDuration *prometheus.HistogramVec
+}
+
+var (
+	_ upgraderules.Metrics     = &Collector{}
+	_ upgraderules.RuleMetrics = &Collector{}
+	_ prometheus.Collector     = &Collector{}
+)
+
+// durationBuckets are the histogram buckets used for evaluation times.
+// Built-in rules take nanoseconds, custom rules backed by remote
+// services can take seconds.
+var durationBuckets = []float64{.000001, .00001, .0001, .001, .01, .1, 1, 10}
+
+// NewCollector creates a new Collector.
+func NewCollector() *Collector {
+	return &Collector{
+		checks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "checks_total",
+			Help:      "Number of upgrade checks by outcome and denying rule",
+		}, []string{"outcome", "rule"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "check_duration_seconds",
+			Help:      "Time it took to evaluate an upgrade check",
+			Buckets:   durationBuckets,
+		}),
+		rules: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_evaluations_total",
+			Help:      "Number of rule evaluations by rule and result (allowed, denied, error)",
+		}, []string{"rule", "result"}),
+		ruleDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "rule_duration_seconds",
+			Help:      "Time it took to evaluate a rule",
+			Buckets:   durationBuckets,
+		}, []string{"rule"}),
+	}
+}
+
+// ObserveCheck records a single check.
+func (c *Collector) ObserveCheck(d upgraderules.Decision, duration time.Duration) {
+	c.checks.WithLabelValues(string(d.Outcome()), string(d.Rule)).Inc()
+	c.duration.Observe(duration.Seconds())
+}
+
+// ObserveRule records a single rule evaluation.
+func (c *Collector) ObserveRule(rule upgraderules.RuleID, err error, duration time.Duration) {
+	result := resultAllowed
+	if err != nil {
+		result = resultError
+		if _, ok := err.(*upgraderules.Error); ok {
+			result = resultDenied
+		}
+	}
+	c.rules.WithLabelValues(string(rule), result).Inc()
+	c.ruleDuration.WithLabelValues(string(rule)).Observe(duration.Seconds())
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.checks.Describe(ch)
+	c.duration.Describe(ch)
+	c.rules.Describe(ch)
+	c.ruleDuration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.checks.Collect(ch)
+	c.duration.Collect(ch)
+	c.rules.Collect(ch)
+	c.ruleDuration.Collect(ch)
+}
@@ -0,0 +1,79 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	m := upgraderules.WithMetrics(c)
+	upgraderules.Check("3.11.8", "3.12.1", m)
+	upgraderules.Check("3.11.8", "3.12.2", m)
+	upgraderules.Check("3.10.8", "3.12.1", m)
+	upgraderules.Check("3.10.8", "3.12.1", m, upgraderules.WithOverrides(upgraderules.Override{From: "3.10", To: "3.12"}))
+
+	tests := []struct {
+		Outcome upgraderules.Outcome
+		Rule    upgraderules.RuleID
+		Count   float64
+	}{
+		{upgraderules.OutcomeAllowed, "", 2},
+		{upgraderules.OutcomeDenied, upgraderules.RuleMinorIncrement, 1},
+		{upgraderules.OutcomeOverridden, "", 1},
+	}
+	for _, test := range tests {
+		if n := testutil.ToFloat64(c.checks.WithLabelValues(string(test.Outcome), string(test.Rule))); n != test.Count {
+			t.Errorf("Expected %v checks with outcome %s, got %v", test.Count, test.Outcome, n)
+		}
+	}
+}
+
+func TestCollectorRules(t *testing.T) {
+	c := NewCollector()
+	m := upgraderules.WithMetrics(c)
+	upgraderules.Check("3.11.8", "3.12.1", m)
+	upgraderules.Check("3.10.8", "3.12.1", m)
+	c.ObserveRule("custom", errors.New("timeout"), time.Second)
+
+	tests := []struct {
+		Rule   upgraderules.RuleID
+		Result string
+		Count  float64
+	}{
+		{upgraderules.RuleMajorVersion, resultAllowed, 2},
+		{upgraderules.RuleMinorIncrement, resultAllowed, 1},
+		{upgraderules.RuleMinorIncrement, resultDenied, 1},
+		{"custom", resultError, 1},
+	}
+	for _, test := range tests {
+		if n := testutil.ToFloat64(c.rules.WithLabelValues(string(test.Rule), test.Result)); n != test.Count {
+			t.Errorf("Expected %v evaluations of %s with result %s, got %v", test.Count, test.Rule, test.Result, n)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// RuleMinimumPatch denies upgrades into a minor version from a version
+// that is older than the minimum required for that minor version.
+const RuleMinimumPatch RuleID = "minimum-patch"
+
+// minimumSource is the oldest version from which upgrades into a minor
+// version are possible.
+type minimumSource struct {
+	major, minor int
+	required     ParsedVersion
+}
+
+// minimumSources is the table of minor versions that require the
+// deployment to be on a minimum patch release of an earlier minor version,
+// e.g. {major: 3, minor: 4, required: ParseVersion("3.3.23")} when 3.3.23
+// or later is required before upgrading to 3.4.
+var minimumSources = []minimumSource{}
+
+// ruleMinimumPatch implements RuleMinimumPatch.
+// It applies to the strict and the soft rules.
+var ruleMinimumPatch = Rule{
+	ID:          RuleMinimumPatch,
+	Check:       checkMinimumPatch,
+	Overridable: true,
+}
+
+// MinimumSourceVersion returns the oldest version from which an upgrade
+// to the given version is possible, or false when there is none.
+func MinimumSourceVersion(to ParsedVersion) (VersionString, bool) {
+	var result ParsedVersion
+	found := false
+	for _, m := range minimumSources {
+		if !versionBefore(to, m.major, m.minor) && (!found || versionLess(result, m.required)) {
+			result, found = m.required, true
+		}
+	}
+	return result.version, found
+}
+
+// MinimumPatchError details a denial by RuleMinimumPatch.
+type MinimumPatchError struct {
+	From VersionString
+	To   VersionString
+	// Required is the oldest version from which the upgrade is possible
+	Required VersionString
+}
+
+// Error describes the required version.
+func (e *MinimumPatchError) Error() string {
+	return fmt.Sprintf("Upgrade from %s to %s requires version %s or later", e.From, e.To, e.Required)
+}
+
+// checkMinimumPatch implements RuleMinimumPatch.
+func checkMinimumPatch(in RuleInput) error {
+	for _, m := range minimumSources {
+		if !versionBefore(in.From, m.major, m.minor) || versionBefore(in.To, m.major, m.minor) {
+			continue
+		}
+		ok := !versionLess(in.From, m.required)
+		if in.Trace != nil {
+			in.Trace.Condition("source is at least the required version", ok, "from", in.From.version, "required", m.required.version)
+		}
+		if !ok {
+			cause := &MinimumPatchError{From: in.From.version, To: in.To.version, Required: m.required.version}
+			return newCausedError(cause, RuleMinimumPatch, MessageSourcePatchTooOld, string(m.required.version), strconv.Itoa(m.major)+"."+strconv.Itoa(m.minor))
+		}
+	}
+	return nil
+}
+
+// versionLess returns true when a is an older version than b, comparing
+// the major, minor and patch versions. A missing patch counts as 0.
+func versionLess(a, b ParsedVersion) bool {
+	if a.major != b.major {
+		return a.major < b.major
+	}
+	if a.minor != b.minor {
+		return a.minor < b.minor
+	}
+	pa, _ := a.Patch()
+	pb, _ := b.Patch()
+	return pa < pb
+}
@@ -0,0 +1,90 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+// setMinimumSources replaces the table of minimum source versions and
+// returns a function restoring it.
+func setMinimumSources(table []minimumSource) func() {
+	saved := minimumSources
+	minimumSources = table
+	return func() { minimumSources = saved }
+}
+
+func TestMinimumPatch(t *testing.T) {
+	defer setMinimumSources([]minimumSource{{major: 3, minor: 4, required: ParseVersion("3.3.23")}})()
+	tests := []struct {
+		From, To VersionString
+		Soft     bool
+		Rule     RuleID
+	}{
+		{"3.3.23", "3.4.0", false, ""},
+		{"3.3.24", "3.4.0", false, ""},
+		{"3.3.8", "3.4.0", false, RuleMinimumPatch},
+		{"3.3.rc7", "3.4.0", false, RuleMinimumPatch},
+		{"3.2.1", "3.4.8", true, RuleMinimumPatch},
+		{"3.3.8", "3.3.9", false, ""},
+		{"3.4.0", "3.5.1", false, ""},
+		// The minor version rules are evaluated first
+		{"3.2.1", "3.4.8", false, RuleMinorIncrement},
+	}
+	for _, test := range tests {
+		var opts []Option
+		if test.Soft {
+			opts = append(opts, WithSoft())
+		}
+		if d := Check(test.From, test.To, opts...); d.Rule != test.Rule {
+			t.Errorf("%s -> %s: expected rule %q, got %q", test.From, test.To, test.Rule, d.Rule)
+		}
+	}
+	err := CheckUpgradeRules("3.3.8", "3.4.0")
+	if err == nil || err.Error() != "Version 3.3.23 or later is required before upgrading to 3.4 (from 3.3.8 to 3.4.0)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if msg := Localize(err, "de"); msg != "Vor einem Upgrade auf 3.4 ist Version 3.3.23 oder neuer erforderlich" {
+		t.Errorf("Unexpected German message %q", msg)
+	}
+	if d := Check("3.3.8", "3.4.0", WithOverrides(Override{From: "3.3", To: "3.4"})); !d.Allowed() {
+		t.Errorf("Expected override to allow the upgrade, got %s", d.Err)
+	}
+}
+
+func TestMinimumSourceVersion(t *testing.T) {
+	defer setMinimumSources([]minimumSource{
+		{major: 3, minor: 4, required: ParseVersion("3.3.23")},
+		{major: 3, minor: 6, required: ParseVersion("3.5.4")},
+	})()
+	tests := map[VersionString]VersionString{
+		"3.3.9": "",
+		"3.4.0": "3.3.23",
+		"3.5.2": "3.3.23",
+		"3.6.1": "3.5.4",
+	}
+	for to, expected := range tests {
+		v, found := MinimumSourceVersion(ParseVersion(to))
+		if v != expected || found != (expected != "") {
+			t.Errorf("MinimumSourceVersion(%s): expected %q, got %q, %t", to, expected, v, found)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dockerHubRegistry is the registry of images without an explicit registry.
+const dockerHubRegistry = "docker.io"
+
+// ImageMirror maps an upstream image repository to an internal mirror,
+// e.g. in air-gapped environments. Both are repository prefixes
+// including the registry, such as "docker.io/arangodb" and
+// "registry.internal/mirror/arangodb". Images on Docker Hub without an
+// explicit registry match the registry "docker.io".
+type ImageMirror struct {
+	Upstream string `json:"upstream"`
+	Mirror   string `json:"mirror"`
+}
+
+// ImageMirrors is a list of mirrors. When several mirrors match an
+// image, the one with the longest prefix is used.
+type ImageMirrors []ImageMirror
+
+// ParseImageMirror parses a mirror of the form "upstream=mirror",
+// e.g. "docker.io/arangodb=registry.internal/mirror/arangodb".
+func ParseImageMirror(s string) (ImageMirror, error) {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ImageMirror{}, fmt.Errorf("Invalid image mirror '%s', expected upstream=mirror", s)
+	}
+	return ImageMirror{Upstream: strings.TrimSuffix(parts[0], "/"), Mirror: strings.TrimSuffix(parts[1], "/")}, nil
+}
+
+// ToMirror returns the image in the mirror of its upstream repository,
+// or the image itself when it is not mirrored.
+func (m ImageMirrors) ToMirror(img Image) Image {
+	return m.replace(img, func(x ImageMirror) (string, string) { return x.Upstream, x.Mirror })
+}
+
+// ToUpstream returns the upstream image of an image in a mirror,
+// or the image itself when it is not in a mirror.
+func (m ImageMirrors) ToUpstream(img Image) Image {
+	return m.replace(img, func(x ImageMirror) (string, string) { return x.Mirror, x.Upstream })
+}
+
+// replace replaces the longest matching prefix `from` of the image path
+// by `to`, as returned by the given function for every mirror.
+func (m ImageMirrors) replace(img Image, fromTo func(ImageMirror) (string, string)) Image {
+	registry := img.Registry
+	if registry == "" {
+		registry = dockerHubRegistry
+	}
+	path := registry + "/" + img.Repository
+	best, replacement := "", ""
+	for _, x := range m {
+		from, to := fromTo(x)
+		if len(from) > len(best) && (path == from || strings.HasPrefix(path, from+"/")) {
+			best, replacement = from, to
+		}
+	}
+	if best == "" {
+		return img
+	}
+	result := img
+	path = replacement + path[len(best):]
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		result.Registry, result.Repository = path[:i], path[i+1:]
+	} else {
+		result.Registry, result.Repository = "", path
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseImageMirror(t *testing.T) {
+	m, err := ParseImageMirror("docker.io/arangodb=registry.internal/mirror/arangodb/")
+	if err != nil || m != (ImageMirror{Upstream: "docker.io/arangodb", Mirror: "registry.internal/mirror/arangodb"}) {
+		t.Errorf("Unexpected mirror %+v, %v", m, err)
+	}
+	for _, s := range []string{"", "docker.io/arangodb", "=registry.internal", "docker.io/arangodb="} {
+		if _, err := ParseImageMirror(s); err == nil {
+			t.Errorf("ParseImageMirror(%q) should fail", s)
+		}
+	}
+}
+
+func TestImageMirrors(t *testing.T) {
+	mirrors := ImageMirrors{
+		{Upstream: "docker.io/arangodb", Mirror: "registry.internal/mirror/arangodb"},
+		{Upstream: "docker.io/arangodb/enterprise", Mirror: "registry.internal/ee"},
+		{Upstream: "ghcr.io/arangodb", Mirror: "localhost:5000"},
+	}
+	tests := []struct {
+		Upstream, Mirror string
+	}{
+		{"arangodb/arangodb:3.11.8", "registry.internal/mirror/arangodb/arangodb:3.11.8"},
+		{"docker.io/arangodb/arangodb:3.11.8", "registry.internal/mirror/arangodb/arangodb:3.11.8"},
+		{"arangodb/enterprise:3.12.1@sha256:abc", "registry.internal/ee:3.12.1@sha256:abc"},
+		{"ghcr.io/arangodb/enterprise:3.12.1", "localhost:5000/enterprise:3.12.1"},
+	}
+	for _, test := range tests {
+		upstream, _ := ParseImage(test.Upstream)
+		mirror, _ := ParseImage(test.Mirror)
+		if m := mirrors.ToMirror(upstream); m != mirror {
+			t.Errorf("ToMirror(%s): expected %s, got %s", test.Upstream, test.Mirror, m)
+		}
+		// Images on Docker Hub get an explicit registry
+		if u := mirrors.ToUpstream(mirror); u.Repository != upstream.Repository || u.Tag != upstream.Tag || u.Digest != upstream.Digest {
+			t.Errorf("ToUpstream(%s): expected %s, got %s", test.Mirror, test.Upstream, u)
+		}
+	}
+	for _, ref := range []string{"arangodb-fork/arangodb:3.11.8", "quay.io/arangodb/arangodb:3.11.8"} {
+		img, _ := ParseImage(ref)
+		if m := mirrors.ToMirror(img); m != img {
+			t.Errorf("Expected %s not to be mirrored, got %s", ref, m)
+		}
+	}
+}
+
+func TestCheckImageUpgradeWithMirrors(t *testing.T) {
+	mirror, _ := ParseImageMirror("docker.io/arangodb=registry.internal/mirror")
+	d, err := CheckImageUpgrade("registry.internal/mirror/enterprise:3.11.8", "registry.internal/mirror/arangodb:3.12.1", WithImageMirrors(mirror))
+	if err != nil || d.Rule != RuleEditionDowngrade {
+		t.Errorf("Expected denial by %s, got %q, %v", RuleEditionDowngrade, d.Rule, err)
+	}
+	var resolved Image
+	resolver := ImageResolverFunc(func(ctx context.Context, img Image) (ResolvedImage, error) {
+		resolved = img
+		return ResolvedImage{Version: "3.12.1"}, nil
+	})
+	d, err = CheckImageUpgrade("arangodb/enterprise:3.11.8", "arangodb/enterprise:latest", WithImageMirrors(mirror), WithImageResolver(resolver))
+	if err != nil || !d.Allowed() || resolved.String() != "registry.internal/mirror/enterprise:latest" {
+		t.Errorf("Expected image to be resolved in the mirror, got %s, %v, %v", resolved, d.Err, err)
+	}
+}
@@ -0,0 +1,70 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// UpgradeNote is an operator note about a transition, e.g. from 3.11 to
+// 3.12: "replication contexts are rebuilt, expect longer failover".
+// Notes are attached to the decisions of the upgrades passing the
+// transition, see WithUpgradeNotes.
+type UpgradeNote struct {
+	// From is the version being upgraded from. Without patch component
+	// (e.g. "3.11"), it matches all patch releases of the minor version.
+	From VersionString `json:"from"`
+	// To is the version being upgraded to, matched like From
+	To VersionString `json:"to"`
+	// Note describes what operators should expect or do
+	Note string `json:"note"`
+}
+
+// WithUpgradeNotes attaches the given notes to the decisions of the
+// upgrades they apply to (see UpgradeNote.AppliesTo), allowed or not.
+func WithUpgradeNotes(notes ...UpgradeNote) Option {
+	return func(o *options) {
+		o.notes = append(append([]UpgradeNote(nil), o.notes...), notes...)
+	}
+}
+
+// AppliesTo returns true when an upgrade from `from` to `to` passes the
+// transition of the note. Besides matching From and To, an upgrade
+// passes a transition between minor versions when it jumps over it,
+// e.g. 3.10.8 to 3.12.1 passes 3.11 to 3.12.
+func (n UpgradeNote) AppliesTo(from, to ParsedVersion) bool {
+	if versionMatches(n.From, from.version) && versionMatches(n.To, to.version) {
+		return true
+	}
+	nf, nt := ParseVersion(n.From), ParseVersion(n.To)
+	if nf.sub != "" || nt.sub != "" || nf.major != nt.major || from.major != nf.major || to.major != nf.major {
+		return false
+	}
+	return from.minor <= nf.minor && nf.minor < nt.minor && nt.minor <= to.minor
+}
+
+// upgradeNotes returns the notes that apply to an upgrade from `from`
+// to `to`, in the order they were given.
+func upgradeNotes(notes []UpgradeNote, from, to ParsedVersion) []UpgradeNote {
+	var result []UpgradeNote
+	for _, n := range notes {
+		if n.AppliesTo(from, to) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
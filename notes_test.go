@@ -0,0 +1,77 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeNoteAppliesTo(t *testing.T) {
+	minor := UpgradeNote{From: "3.11", To: "3.12", Note: "minor"}
+	patch := UpgradeNote{From: "3.12.3", To: "3.12.4", Note: "patch"}
+	tests := []struct {
+		Note     UpgradeNote
+		From, To VersionString
+		Applies  bool
+	}{
+		{minor, "3.11.8", "3.12.1", true},
+		{minor, "3.10.8", "3.12.1", true},
+		{minor, "3.10.8", "3.13.0", true},
+		{minor, "3.10.8", "3.11.8", false},
+		{minor, "3.12.1", "3.12.2", false},
+		{minor, "3.12.1", "3.11.8", false},
+		{patch, "3.12.3", "3.12.4", true},
+		{patch, "3.12.2", "3.12.4", false},
+		{patch, "3.11.8", "3.12.4", false},
+	}
+	for _, test := range tests {
+		if applies := test.Note.AppliesTo(ParseVersion(test.From), ParseVersion(test.To)); applies != test.Applies {
+			t.Errorf("%s: %s -> %s: expected %t, got %t", test.Note.Note, test.From, test.To, test.Applies, applies)
+		}
+	}
+}
+
+func TestWithUpgradeNotes(t *testing.T) {
+	notes := WithUpgradeNotes(
+		UpgradeNote{From: "3.11", To: "3.12", Note: "Replication contexts are rebuilt, expect longer failover"},
+		UpgradeNote{From: "3.10", To: "3.11", Note: "Indexes are rebuilt"},
+	)
+	d := Check("3.10.8", "3.12.1", notes, WithSoft())
+	if len(d.Notes) != 2 || d.Notes[0].From != "3.11" || d.Notes[1].From != "3.10" {
+		t.Errorf("Unexpected notes %v", d.Notes)
+	}
+	d = Check("3.10.8", "3.12.1", notes)
+	if d.Allowed() || len(d.Notes) != 2 {
+		t.Errorf("Expected notes on a denied upgrade, got %v", d.Notes)
+	}
+	if d := Check("3.12.1", "3.12.1", notes); d.Notes != nil {
+		t.Errorf("Expected no notes, got %v", d.Notes)
+	}
+	encoded, err := json.Marshal(Check("3.11.8", "3.12.1", notes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(encoded), `"notes":[{"from":"3.11","to":"3.12","note":"Replication contexts are rebuilt, expect longer failover"}]`) {
+		t.Errorf("Expected notes in %s", encoded)
+	}
+}
@@ -0,0 +1,413 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"time"
+)
+
+// Option customizes the behavior of the Check functions.
+type Option func(*options)
+
+// options holds the configuration built from a list of Option's.
+// Options copy the slices they change instead of appending to them, so
+// they never modify the slices of the caller or of another options value
+// sharing them (see apply).
+type options struct {
+	soft             bool
+	maxMinorSkip     int
+	licensed         bool
+	fromLicense      License
+	toLicense        License
+	ruleSet          RuleSetSource
+	majorRuleSets    map[int]RuleSetSource
+	messageTemplates *MessageTemplates
+	licenseMatrix    LicenseMatrix
+	channels         []Channel
+	intermediates    []Intermediate
+	skipVersions     []VersionString
+	notes            []UpgradeNote
+	lts              []VersionString
+	architectures    []Architecture
+	requiredFixes    []RequiredFix
+	resourceHints    []ResourceHint
+	catalog          *ReleaseCatalog
+	allowPreRelease  bool
+	allowGAToPre     bool
+	releases         []ParsedVersion
+	promotedWarnings []WarningCode
+	denyByDefault    bool
+	allowList        []Transition
+	preconditions    []attachedPrecondition
+	deployment       DeploymentInfo
+	windows          [][]MaintenanceWindow
+	cooldown         Cooldown
+	history          []HistoryEntry
+	overrides        []Override
+	now              func() time.Time
+	recorders        []DecisionRecorder
+	metrics          []Metrics
+	ruleMetrics      []RuleMetrics
+	tracer           Tracer
+	logger           Logger
+	auditSinks       []AuditSink
+	requester        string
+	trace            bool
+	parallelism      int
+	ctx              context.Context
+	imageResolver    ImageResolver
+	editionInferrer  EditionInferrer
+	imageMirrors     ImageMirrors
+	policyResolver   PolicyResolver
+}
+
+// setLicenses includes the given licenses in the check.
+func (o *options) setLicenses(fromLicense, toLicense License) {
+	o.licensed = true
+	o.fromLicense = fromLicense
+	o.toLicense = toLicense
+}
+
+// newOptions builds the configuration for the given options.
+func newOptions(opts []Option) options {
+	return defaultOptions().apply(opts)
+}
+
+// defaultOptions returns the configuration used when no options are
+// given, which includes the default policy (see SetDefaultPolicy).
+func defaultOptions() options {
+	o := options{
+		now: time.Now,
+		ctx: context.Background(),
+	}
+	if opts := defaultPolicyOptions(); len(opts) > 0 {
+		o = o.apply(opts)
+	}
+	return o
+}
+
+// apply returns a copy of o with the given options applied.
+// The copy escapes to the heap, so callers on the allocation-free
+// path only call it when there are options.
+func (o options) apply(opts []Option) options {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithSoft selects the soft rules, which allow to jump more than
+// one minor version.
+func WithSoft() Option {
+	return func(o *options) {
+		o.soft = true
+	}
+}
+
+// WithMaxMinorSkip limits the soft rules to increase the minor version
+// by at most n, e.g. 2 allows 3.10 to 3.12 but not 3.10 to 3.13.
+// Larger jumps are denied by RuleMaxMinorSkip, which can be overridden.
+// It has no effect on the strict rules, or when n is 0.
+func WithMaxMinorSkip(n int) Option {
+	return func(o *options) {
+		o.maxMinorSkip = n
+	}
+}
+
+// WithRuleSet evaluates the rules of the given source instead of the
+// built-in rules (see DefaultRuleSetFor). The source is asked for its
+// RuleSet once per check, so an *AtomicRuleSet can be updated while
+// checks are running. Options such as WithChannels are enforced by
+// rules of DefaultRuleSet, so they only have an effect when the source
+// has those rules.
+func WithRuleSet(s RuleSetSource) Option {
+	return func(o *options) {
+		o.ruleSet = s
+	}
+}
+
+// WithMajorRuleSet evaluates the rules of the given source for upgrades
+// from the given major version, instead of those given with WithRuleSet
+// or the built-in rules of that major version (see DefaultRuleSetFor).
+func WithMajorRuleSet(major int, s RuleSetSource) Option {
+	return func(o *options) {
+		m := make(map[int]RuleSetSource, len(o.majorRuleSets)+1)
+		for k, v := range o.majorRuleSets {
+			m[k] = v
+		}
+		m[major] = s
+		o.majorRuleSets = m
+	}
+}
+
+// WithMessageTemplates replaces the message of errors returned by rules
+// that have a template. The message ID of such errors is kept, so
+// Catalog.Message still returns the built-in translation.
+func WithMessageTemplates(t *MessageTemplates) Option {
+	return func(o *options) {
+		o.messageTemplates = t
+	}
+}
+
+// WithLicenseMatrix replaces the built-in license transitions of
+// RuleEditionDowngrade by the given matrix. It only has an effect when
+// licenses are part of the check (see WithLicenses).
+func WithLicenseMatrix(m LicenseMatrix) Option {
+	return func(o *options) {
+		o.licenseMatrix = m
+	}
+}
+
+// WithChannels only allows upgrades to versions of the given release
+// channels. Upgrades to other channels are denied by RuleChannel.
+// The channels replace the default of only allowing upgrades to
+// generally available releases (see RulePreReleaseTarget).
+func WithChannels(channels ...Channel) Option {
+	return func(o *options) {
+		o.channels = append(append([]Channel(nil), o.channels...), channels...)
+	}
+}
+
+// WithDenyByDefault only allows upgrades between the exact version pairs
+// given, which must also be allowed by the rules. All other upgrades that
+// change the version are denied by RuleAllowList, which cannot be
+// overridden. Multiple uses add to the list of allowed upgrades.
+func WithDenyByDefault(allowed ...Transition) Option {
+	return func(o *options) {
+		o.denyByDefault = true
+		o.allowList = append(append([]Transition(nil), o.allowList...), allowed...)
+	}
+}
+
+// WithPrecondition requires the given precondition to be met for upgrades
+// of the given kinds. Without kinds, it applies to every upgrade that
+// changes the version. Preconditions are only evaluated when the rules
+// allow the upgrade; unmet preconditions deny it with a *PreconditionError.
+func WithPrecondition(p Precondition, kinds ...TransitionKind) Option {
+	return func(o *options) {
+		o.preconditions = append(append([]attachedPrecondition(nil), o.preconditions...),
+			attachedPrecondition{precondition: p, transitions: append([]TransitionKind(nil), kinds...)})
+	}
+}
+
+// WithDeployment describes the deployment being upgraded to preconditions.
+// The versions, licenses and transition of the given info are replaced by
+// those of the check.
+func WithDeployment(info DeploymentInfo) Option {
+	return func(o *options) {
+		o.deployment = info
+	}
+}
+
+// WithMaintenanceWindows only allows upgrades inside one of the given
+// windows, at the time given by the clock (see WithClock). Upgrades that
+// the rules allow outside the windows are denied by RuleMaintenanceWindow
+// with a *MaintenanceWindowError holding the start of the next window.
+// Every use adds a requirement: the upgrade must be inside one of the
+// windows of each use, e.g. of an organization and of a deployment.
+func WithMaintenanceWindows(windows ...MaintenanceWindow) Option {
+	return func(o *options) {
+		o.windows = append(append([][]MaintenanceWindow(nil), o.windows...), append([]MaintenanceWindow(nil), windows...))
+	}
+}
+
+// WithCooldown denies upgrades that follow one of the upgrades given
+// with WithHistory too soon, at the time given by the clock (see
+// WithClock). Such upgrades are denied by RuleCooldown with a
+// *CooldownError holding the earliest time the upgrade is allowed.
+func WithCooldown(c Cooldown) Option {
+	return func(o *options) {
+		o.cooldown = c
+	}
+}
+
+// WithHistory adds upgrades of the deployment that have been done before,
+// see WithCooldown. Entries without an outcome count as succeeded, failed
+// upgrades are ignored.
+func WithHistory(upgrades ...HistoryEntry) Option {
+	return func(o *options) {
+		o.history = append(append([]HistoryEntry(nil), o.history...), upgrades...)
+	}
+}
+
+// WithReleases sets the known releases of ArangoDB, which refines the
+// warnings of a check, e.g. WarningFirstRelease is only given when a
+// later patch release exists. Multiple uses add to the known releases.
+func WithReleases(versions ...VersionString) Option {
+	return func(o *options) {
+		releases := make([]ParsedVersion, 0, len(o.releases)+len(versions))
+		releases = append(releases, o.releases...)
+		for _, v := range versions {
+			releases = append(releases, ParseVersion(v))
+		}
+		o.releases = releases
+	}
+}
+
+// WithWarningsAsErrors denies upgrades that the rules allow, but that
+// have a warning with one of the given codes. The rule of such a decision
+// is the code of the warning, e.g. RuleID(WarningFirstRelease).
+func WithWarningsAsErrors(codes ...WarningCode) Option {
+	return func(o *options) {
+		o.promotedWarnings = append(append([]WarningCode(nil), o.promotedWarnings...), codes...)
+	}
+}
+
+// WithLicenses includes the given licenses of the deployment before
+// and after the upgrade in the check.
+func WithLicenses(fromLicense, toLicense License) Option {
+	return func(o *options) {
+		o.setLicenses(fromLicense, toLicense)
+	}
+}
+
+// WithOverrides forces the upgrades matched by the given overrides
+// to be allowed, even when the version rules deny them.
+// Expired overrides are ignored.
+func WithOverrides(overrides ...Override) Option {
+	return func(o *options) {
+		o.overrides = append(append([]Override(nil), o.overrides...), overrides...)
+	}
+}
+
+// WithClock sets the function used to get the current time.
+// It defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithDecisionRecorder adds a recorder that is called with every
+// decision made.
+func WithDecisionRecorder(r DecisionRecorder) Option {
+	return func(o *options) {
+		o.recorders = append(append([]DecisionRecorder(nil), o.recorders...), r)
+	}
+}
+
+// WithMetrics adds a Metrics implementation that is informed about
+// every check. If it also implements RuleMetrics, it is informed about
+// every rule evaluation as well.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) {
+		o.metrics = append(append([]Metrics(nil), o.metrics...), m)
+		if rm, ok := m.(RuleMetrics); ok {
+			o.ruleMetrics = append(append([]RuleMetrics(nil), o.ruleMetrics...), rm)
+		}
+	}
+}
+
+// WithTracer sets the Tracer that is informed about every check and the
+// rules evaluated by it.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// WithLogger sets the Logger that receives a record for every decision.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithAuditSink adds a sink that records every decision.
+// Failures to record a decision are reported to the Logger (if any).
+func WithAuditSink(s AuditSink) Option {
+	return func(o *options) {
+		o.auditSinks = append(append([]AuditSink(nil), o.auditSinks...), s)
+	}
+}
+
+// WithRequester sets the identity of the user or system requesting
+// the upgrade. It is included in the decision for auditing.
+func WithRequester(requester string) Option {
+	return func(o *options) {
+		o.requester = requester
+	}
+}
+
+// WithTrace makes Check include the full evaluation tree of the
+// rules in the decision. This is intended for debugging.
+func WithTrace() Option {
+	return func(o *options) {
+		o.trace = true
+	}
+}
+
+// WithParallelism sets the maximum number of checks CheckMany evaluates
+// concurrently. It has no effect on other functions.
+func WithParallelism(n int) Option {
+	return func(o *options) {
+		o.parallelism = n
+	}
+}
+
+// WithContext sets the context of a check. It is passed to the Tracer and Logger.
+// It defaults to context.Background().
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// WithImageResolver sets the resolver used by CheckImageUpgrade for
+// images whose tag is not a version, e.g. "latest" or a digest.
+func WithImageResolver(r ImageResolver) Option {
+	return func(o *options) {
+		o.imageResolver = r
+	}
+}
+
+// WithEditionInferrer sets the function used by CheckImageUpgrade to
+// infer the edition of an image before falling back to Image.License.
+func WithEditionInferrer(f EditionInferrer) Option {
+	return func(o *options) {
+		o.editionInferrer = f
+	}
+}
+
+// WithImageMirrors sets the mirrors of image repositories used by
+// CheckImageUpgrade. Images in a mirror are checked as their upstream
+// image, and images are resolved in their mirror.
+func WithImageMirrors(mirrors ...ImageMirror) Option {
+	return func(o *options) {
+		o.imageMirrors = append(append(ImageMirrors(nil), o.imageMirrors...), mirrors...)
+	}
+}
+
+// findOverride returns the first active override for an upgrade
+// from `from` to `to` that covers the given rule, or nil if there is none.
+func (o *options) findOverride(from, to VersionString, rule RuleID) *Override {
+	if len(o.overrides) == 0 {
+		return nil
+	}
+	now := o.now()
+	for i, x := range o.overrides {
+		if x.Matches(from, to) && x.Covers(rule) && !x.Expired(now) {
+			return &o.overrides[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptionsCopySlices(t *testing.T) {
+	saturday := MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)
+	sunday := MustParseMaintenanceWindow("0 2 * * 0", 4*time.Hour, nil)
+	windows := []MaintenanceWindow{saturday}
+	o := newOptions([]Option{WithMaintenanceWindows(windows...)})
+	windows[0] = sunday
+	if o.windows[0][0].String() != saturday.String() {
+		t.Error("Expected the windows of the caller to be copied")
+	}
+
+	base := newOptions([]Option{WithChannels(ChannelGA), WithChannels(ChannelPreRelease), WithChannels(ChannelGA)})
+	a := base.apply([]Option{WithChannels("a")})
+	b := base.apply([]Option{WithChannels("b")})
+	if a.channels[3] != "a" || b.channels[3] != "b" || len(base.channels) != 3 {
+		t.Errorf("Expected options not to share appended slices, got %v, %v", a.channels, b.channels)
+	}
+}
@@ -0,0 +1,194 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AnnotationAllow is the annotation used to force one or more upgrades.
+	// Its value is a comma separated list of `<from>-><to>[:<ticket>]`
+	// entries, e.g. "3.9->3.11:ticket-1234".
+	// A version with only major & minor matches all patch versions of that minor,
+	// a version with a patch part only matches that exact version.
+	AnnotationAllow = "upgrade.arangodb.com/allow"
+	// AnnotationAllowUntil is the annotation that holds the expiry time of
+	// the overrides in AnnotationAllow in RFC 3339 format.
+	// Without it, the overrides do not expire.
+	AnnotationAllowUntil = "upgrade.arangodb.com/allow-until"
+	// AnnotationAllowRules is the annotation that limits the overrides in
+	// AnnotationAllow to a comma separated list of rule IDs.
+	// Without it, the overrides bypass all overridable rules.
+	AnnotationAllowRules = "upgrade.arangodb.com/allow-rules"
+	// AnnotationAllowApprover is the annotation that holds who approved
+	// the overrides in AnnotationAllow.
+	AnnotationAllowApprover = "upgrade.arangodb.com/allow-approver"
+)
+
+// Override forces an upgrade to be allowed, even if the version rules
+// would deny it.
+type Override struct {
+	// From is the version being upgraded from (e.g. "3.9" or "3.9.4")
+	From VersionString
+	// To is the version being upgraded to (e.g. "3.11" or "3.11.0")
+	To VersionString
+	// Ticket is a reference explaining why the override exists
+	Ticket string
+	// Expires is the time after which the override is no longer honored.
+	// The zero time means the override never expires.
+	Expires time.Time
+	// Rules limits the override to the given rules.
+	// When empty, it bypasses all overridable rules.
+	Rules []RuleID
+	// Approver identifies who approved the override
+	Approver string
+}
+
+// Matches returns true if the override applies to an upgrade from
+// `from` to `to`.
+func (o Override) Matches(from, to VersionString) bool {
+	return versionMatches(o.From, from) && versionMatches(o.To, to)
+}
+
+// Covers returns true if the override applies to the given rule.
+// Only overridable rules can be bypassed by an override.
+func (o Override) Covers(rule RuleID) bool {
+	if len(o.Rules) == 0 {
+		return true
+	}
+	for _, r := range o.Rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Expired returns true if the override is no longer valid at the given time.
+func (o Override) Expired(now time.Time) bool {
+	return !o.Expires.IsZero() && now.After(o.Expires)
+}
+
+// String returns the override in the format used by AnnotationAllow.
+func (o Override) String() string {
+	if o.Ticket == "" {
+		return fmt.Sprintf("%s->%s", o.From, o.To)
+	}
+	return fmt.Sprintf("%s->%s:%s", o.From, o.To, o.Ticket)
+}
+
+// ParseOverride parses a single `<from>-><to>[:<ticket>]` entry.
+func ParseOverride(value string) (Override, error) {
+	value = strings.TrimSpace(value)
+	transition, ticket := value, ""
+	if idx := strings.Index(value, ":"); idx >= 0 {
+		transition, ticket = value[:idx], strings.TrimSpace(value[idx+1:])
+	}
+	parts := strings.Split(transition, "->")
+	if len(parts) != 2 {
+		return Override{}, fmt.Errorf("Invalid override '%s', expected <from>-><to>[:<ticket>]", value)
+	}
+	from, to := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if err := validateVersionPattern(from); err != nil {
+		return Override{}, fmt.Errorf("Invalid override '%s': %s", value, err)
+	}
+	if err := validateVersionPattern(to); err != nil {
+		return Override{}, fmt.Errorf("Invalid override '%s': %s", value, err)
+	}
+	return Override{
+		From:   VersionString(from),
+		To:     VersionString(to),
+		Ticket: ticket,
+	}, nil
+}
+
+// ParseOverrideAnnotations parses the overrides found in the given
+// annotations (see AnnotationAllow and AnnotationAllowUntil).
+// If there are no override annotations, nil is returned.
+func ParseOverrideAnnotations(annotations map[string]string) ([]Override, error) {
+	value, found := annotations[AnnotationAllow]
+	if !found {
+		return nil, nil
+	}
+	var expires time.Time
+	if until, found := annotations[AnnotationAllowUntil]; found {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(until))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid value for annotation %s: %s", AnnotationAllowUntil, err)
+		}
+		expires = t
+	}
+	var rules []RuleID
+	if value, found := annotations[AnnotationAllowRules]; found {
+		for _, r := range strings.Split(value, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				rules = append(rules, RuleID(r))
+			}
+		}
+	}
+	approver := strings.TrimSpace(annotations[AnnotationAllowApprover])
+	var result []Override
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		o, err := ParseOverride(entry)
+		if err != nil {
+			return nil, err
+		}
+		o.Expires = expires
+		o.Rules = rules
+		o.Approver = approver
+		result = append(result, o)
+	}
+	return result, nil
+}
+
+// validateVersionPattern checks that the given string is a version
+// with a numeric major & minor part and an optional patch part.
+func validateVersionPattern(s string) error {
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) < 2 {
+		return fmt.Errorf("Version '%s' must have at least a major and minor part", s)
+	}
+	for _, p := range parts[:2] {
+		if _, err := strconv.Atoi(p); err != nil {
+			return fmt.Errorf("Version '%s' must have a numeric major and minor part", s)
+		}
+	}
+	if len(parts) == 3 && parts[2] == "" {
+		return fmt.Errorf("Version '%s' has an empty patch part", s)
+	}
+	return nil
+}
+
+// versionMatches returns true if the given version matches the given
+// pattern. A pattern without patch part matches all patch versions.
+func versionMatches(pattern, v VersionString) bool {
+	if pattern.Sub() == "" {
+		return pattern.Major() == v.Major() && pattern.Minor() == v.Minor()
+	}
+	return pattern == v
+}
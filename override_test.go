@@ -0,0 +1,162 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOverride(t *testing.T) {
+	tests := []struct {
+		Value  string
+		Valid  bool
+		From   VersionString
+		To     VersionString
+		Ticket string
+	}{
+		{"3.9->3.11:ticket-1234", true, "3.9", "3.11", "ticket-1234"},
+		{" 3.9.4 -> 3.11.0 ", true, "3.9.4", "3.11.0", ""},
+		{"3.9->3.11:", true, "3.9", "3.11", ""},
+		{"3.9", false, "", "", ""},
+		{"3->3.11", false, "", "", ""},
+		{"3.x->3.11", false, "", "", ""},
+		{"3.9.->3.11", false, "", "", ""},
+		{"3.9->3.10->3.11", false, "", "", ""},
+	}
+	for _, test := range tests {
+		o, err := ParseOverride(test.Value)
+		if !test.Valid {
+			if err == nil {
+				t.Errorf("'%s' should be invalid, got %v", test.Value, o)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("'%s' should be valid, got %s", test.Value, err)
+		} else if o.From != test.From || o.To != test.To || o.Ticket != test.Ticket {
+			t.Errorf("'%s' parsed into unexpected %+v", test.Value, o)
+		}
+	}
+}
+
+func TestParseOverrideAnnotations(t *testing.T) {
+	overrides, err := ParseOverrideAnnotations(map[string]string{"foo": "bar"})
+	if err != nil || overrides != nil {
+		t.Errorf("Expected no overrides, got %v, %v", overrides, err)
+	}
+
+	overrides, err = ParseOverrideAnnotations(map[string]string{
+		AnnotationAllow:      "3.9->3.11:ticket-1234, 3.11.2->3.12.0",
+		AnnotationAllowUntil: "2026-11-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("Expected valid annotations, got %s", err)
+	}
+	if len(overrides) != 2 {
+		t.Fatalf("Expected 2 overrides, got %d", len(overrides))
+	}
+	expires := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	for _, o := range overrides {
+		if !o.Expires.Equal(expires) {
+			t.Errorf("Expected %s to expire at %s, got %s", o, expires, o.Expires)
+		}
+	}
+
+	if _, err := ParseOverrideAnnotations(map[string]string{
+		AnnotationAllow:      "3.9->3.11",
+		AnnotationAllowUntil: "tomorrow",
+	}); err == nil {
+		t.Error("Expected invalid expiry to fail")
+	}
+}
+
+func TestOverrideMatches(t *testing.T) {
+	minor := Override{From: "3.9", To: "3.11"}
+	exact := Override{From: "3.9.4", To: "3.11.0"}
+	tests := []struct {
+		Override Override
+		From     VersionString
+		To       VersionString
+		Matches  bool
+	}{
+		{minor, "3.9.0", "3.11.5", true},
+		{minor, "3.9.4", "3.11.0", true},
+		{minor, "3.10.0", "3.11.0", false},
+		{minor, "3.9.0", "3.12.0", false},
+		{exact, "3.9.4", "3.11.0", true},
+		{exact, "3.9.5", "3.11.0", false},
+		{exact, "3.9.4", "3.11.1", false},
+	}
+	for _, test := range tests {
+		if m := test.Override.Matches(test.From, test.To); m != test.Matches {
+			t.Errorf("%s matching %s -> %s: expected %v, got %v", test.Override, test.From, test.To, test.Matches, m)
+		}
+	}
+}
+
+func TestCheckWithOverrides(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	active := Override{From: "3.9", To: "3.11", Expires: now.Add(time.Hour)}
+	expired := Override{From: "3.9", To: "3.11", Expires: now.Add(-time.Hour)}
+
+	if err := CheckUpgradeRules("3.9.1", "3.11.0", clock, WithOverrides(active)); err != nil {
+		t.Errorf("Override should allow upgrade, got %s", err)
+	}
+	if err := CheckSoftUpgradeRules("3.11.0", "3.9.1", clock, WithOverrides(Override{From: "3.11", To: "3.9"})); err != nil {
+		t.Errorf("Override should allow downgrade, got %s", err)
+	}
+	if err := CheckUpgradeRules("3.9.1", "3.11.0", clock, WithOverrides(expired)); err == nil {
+		t.Error("Expired override should not allow upgrade")
+	}
+	if err := CheckUpgradeRulesWithLicense("3.9.1", "3.11.0", LicenseEnterprise, LicenseCommunity, clock, WithOverrides(active)); err == nil {
+		t.Error("Override should not allow Enterprise to Community edition change")
+	}
+}
+
+func TestOverrideRules(t *testing.T) {
+	scoped := Override{From: "3.9", To: "3.11", Rules: []RuleID{RuleMinorIncrement}, Ticket: "OPS-1", Approver: "bob"}
+	d := Check("3.9.1", "3.11.0", WithOverrides(scoped))
+	if !d.Allowed() || d.Override == nil || d.Override.Approver != "bob" {
+		t.Errorf("Scoped override should allow upgrade, got %v", d.Err)
+	}
+	if d := Check("3.11.0", "3.9.1", WithSoft(), WithOverrides(Override{From: "3.11", To: "3.9", Rules: []RuleID{RuleMinorIncrement}})); d.Allowed() {
+		t.Error("Override scoped to another rule should not allow downgrade")
+	}
+	if !scoped.Covers(RuleMinorIncrement) || scoped.Covers(RuleMajorVersion) || !(Override{}).Covers(RuleMajorVersion) {
+		t.Error("Unexpected result of Covers")
+	}
+}
+
+func TestParseOverrideAnnotationsRulesAndApprover(t *testing.T) {
+	overrides, err := ParseOverrideAnnotations(map[string]string{
+		AnnotationAllow:         "3.9->3.11:OPS-1",
+		AnnotationAllowRules:    "minor-increment, major-version",
+		AnnotationAllowApprover: "bob",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(overrides) != 1 || overrides[0].Approver != "bob" || len(overrides[0].Rules) != 2 || overrides[0].Rules[1] != RuleMajorVersion {
+		t.Errorf("Unexpected overrides %+v", overrides)
+	}
+}
@@ -0,0 +1,96 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+)
+
+// RuleArchitecture denies upgrades to versions without official binaries
+// for an architecture of the deployment, see WithArchitectures.
+const RuleArchitecture RuleID = "architecture"
+
+// Architecture is a CPU architecture ArangoDB binaries are built for,
+// named like GOARCH.
+type Architecture string
+
+const (
+	// ArchitectureAMD64 is x86-64
+	ArchitectureAMD64 Architecture = "amd64"
+	// ArchitectureARM64 is 64-bit ARM (aarch64)
+	ArchitectureARM64 Architecture = "arm64"
+)
+
+// architectureSince holds the first minor version ({major, minor}) with
+// official binaries and images of both editions, by architecture.
+var architectureSince = map[Architecture][2]int{
+	ArchitectureAMD64: {0, 0},
+	ArchitectureARM64: {3, 10},
+}
+
+// HasBinaries returns true when the given version has official binaries
+// for the given architecture. It returns false for unknown architectures.
+func HasBinaries(v ParsedVersion, arch Architecture) bool {
+	since, found := architectureSince[arch]
+	return found && !versionBefore(v, since[0], since[1])
+}
+
+// WithArchitectures sets the architectures of the nodes of the deployment.
+// Upgrades to versions without official binaries for one of them are
+// denied by RuleArchitecture, which can be overridden (e.g. for custom
+// builds).
+func WithArchitectures(archs ...Architecture) Option {
+	return func(o *options) {
+		o.architectures = append(append([]Architecture(nil), o.architectures...), archs...)
+	}
+}
+
+// ArchitectureError details a denial by RuleArchitecture.
+type ArchitectureError struct {
+	// Version is the version being upgraded to
+	Version VersionString
+	// Architecture is the architecture without official binaries
+	Architecture Architecture
+}
+
+// Error describes the missing binaries.
+func (e *ArchitectureError) Error() string {
+	return fmt.Sprintf("Version %s has no official %s binaries", e.Version, e.Architecture)
+}
+
+// ruleArchitecture implements RuleArchitecture.
+var ruleArchitecture = Rule{
+	ID:          RuleArchitecture,
+	Applies:     func(in RuleInput) bool { return len(in.Architectures) > 0 && in.changesVersion() },
+	Check:       func(in RuleInput) error { return checkArchitectures(in.Architectures, in.To) },
+	Overridable: true,
+}
+
+// checkArchitectures implements RuleArchitecture.
+func checkArchitectures(archs []Architecture, to ParsedVersion) error {
+	for _, a := range archs {
+		if !HasBinaries(to, a) {
+			cause := &ArchitectureError{Version: to.version, Architecture: a}
+			return newCausedError(cause, RuleArchitecture, MessageNoBinaries, string(to.version), string(a))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestHasBinaries(t *testing.T) {
+	tests := []struct {
+		Version  VersionString
+		Arch     Architecture
+		Expected bool
+	}{
+		{"3.9.10", ArchitectureAMD64, true},
+		{"3.9.10", ArchitectureARM64, false},
+		{"3.10.0", ArchitectureARM64, true},
+		{"3.12.1", ArchitectureARM64, true},
+		{"3.12.1", "ppc64le", false},
+	}
+	for _, test := range tests {
+		if has := HasBinaries(ParseVersion(test.Version), test.Arch); has != test.Expected {
+			t.Errorf("%s on %s: expected %t, got %t", test.Version, test.Arch, test.Expected, has)
+		}
+	}
+}
+
+func TestWithArchitectures(t *testing.T) {
+	arm := WithArchitectures(ArchitectureAMD64, ArchitectureARM64)
+	if d := Check("3.9.10", "3.10.1", arm); !d.Allowed() {
+		t.Errorf("Expected upgrade to 3.10 to be allowed, got %s", d.Err)
+	}
+	d := Check("3.9.9", "3.9.10", arm)
+	if d.Rule != RuleArchitecture || d.Err.Error() != "Version 3.9.10 has no official arm64 binaries (from 3.9.9 to 3.9.10)" {
+		t.Errorf("Expected %s, got %s (%v)", RuleArchitecture, d.Rule, d.Err)
+	}
+	if d := Check("3.9.9", "3.9.10", WithArchitectures(ArchitectureAMD64)); !d.Allowed() {
+		t.Errorf("Expected amd64 upgrade to be allowed, got %s", d.Err)
+	}
+	if d := Check("3.9.9", "3.9.9", arm); !d.Allowed() {
+		t.Errorf("Expected no-op to be allowed, got %s", d.Err)
+	}
+	d = Check("3.9.9", "3.9.10", arm, WithOverrides(Override{From: "3.9", To: "3.9", Rules: []RuleID{RuleArchitecture}}))
+	if d.Outcome() != OutcomeOverridden {
+		t.Errorf("Expected override, got %s", d.Outcome())
+	}
+}
@@ -0,0 +1,258 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// RuleChannel denies upgrades to versions of a release channel that is
+// not allowed, see WithChannels.
+const RuleChannel RuleID = "channel"
+
+// Channel is the release channel of a version.
+type Channel string
+
+const (
+	// ChannelGA contains the generally available releases, e.g. 3.12.4
+	ChannelGA Channel = "ga"
+	// ChannelPreRelease contains the alpha, beta, milestone and release
+	// candidate versions, e.g. 3.12.0-rc.1 or 3.12.rc7
+	ChannelPreRelease Channel = "pre-release"
+)
+
+// ChannelOf returns the release channel of the given version.
+func ChannelOf(v ParsedVersion) Channel {
+	if v.IsPreRelease() {
+		return ChannelPreRelease
+	}
+	return ChannelGA
+}
+
+// ruleChannel implements RuleChannel.
+var ruleChannel = Rule{
+	ID:      RuleChannel,
+	Applies: func(in RuleInput) bool { return len(in.Channels) > 0 && in.changesVersion() },
+	Check:   func(in RuleInput) error { return checkChannel(in.Channels, in.To) },
+}
+
+// checkChannel implements RuleChannel.
+func checkChannel(channels []Channel, to ParsedVersion) error {
+	c := ChannelOf(to)
+	for _, x := range channels {
+		if x == c {
+			return nil
+		}
+	}
+	return newCausedError(&ChannelError{To: to.version, Channel: c, Allowed: channels}, RuleChannel, MessageChannelNotAllowed)
+}
+
+// ChannelError details a denial by RuleChannel.
+type ChannelError struct {
+	// To is the version being upgraded to
+	To VersionString
+	// Channel is the release channel of To
+	Channel Channel
+	// Allowed holds the allowed release channels
+	Allowed []Channel
+}
+
+// Error describes the channel that is not allowed.
+func (e *ChannelError) Error() string {
+	return fmt.Sprintf("Version %s is in release channel '%s', which is not allowed", e.To, e.Channel)
+}
+
+// LicenseTransition is a change of license during an upgrade.
+type LicenseTransition struct {
+	From License `json:"from"`
+	To   License `json:"to"`
+}
+
+// LicenseMatrix holds the license transitions that are allowed, in
+// addition to keeping the license, which is always allowed.
+// A nil matrix allows every transition except Enterprise to Community.
+type LicenseMatrix map[LicenseTransition]bool
+
+// Allows returns true if the matrix allows changing the license from
+// `from` to `to`.
+func (m LicenseMatrix) Allows(from, to License) bool {
+	if from == to {
+		return true
+	}
+	if m == nil {
+		return from != LicenseEnterprise
+	}
+	return m[LicenseTransition{From: from, To: to}]
+}
+
+// Policy bundles everything an organization decides about upgrades into
+// a single value, so it can be managed as one artifact instead of a set
+// of options at every call site. The zero value is equivalent to calling
+// Check without options.
+type Policy struct {
+	// RuleSet holds the rules to evaluate (DefaultRuleSet when nil). It must
+	// contain the policy rules of DefaultRuleSet to enforce the other fields.
+	RuleSet RuleSetSource
+	// Soft selects the soft rules, see WithSoft
+	Soft bool
+	// MaxMinorSkip limits the increase of the minor version allowed by the
+	// soft rules, unlimited when 0 (see WithMaxMinorSkip)
+	MaxMinorSkip int
+	// Licenses holds the allowed license transitions, see WithLicenseMatrix
+	Licenses LicenseMatrix
+	// DenyByDefault only allows the upgrades in AllowList, see WithDenyByDefault
+	DenyByDefault bool
+	// AllowList holds the allowed upgrades when DenyByDefault is set
+	AllowList []Transition
+	// Channels holds the allowed release channels of the target version,
+	// only generally available releases are allowed when empty (see
+	// WithChannels and RulePreReleaseTarget)
+	Channels []Channel
+	// AllowGAToPreRelease allows going back from a release to a
+	// pre-release of the same patch version, see WithAllowGAToPreRelease
+	AllowGAToPreRelease bool
+	// MaintenanceWindows holds the windows in which upgrades are allowed,
+	// upgrades are allowed at any time when empty (see WithMaintenanceWindows)
+	MaintenanceWindows []MaintenanceWindow
+	// WarningsAsErrors holds the codes of warnings that deny an upgrade,
+	// see WithWarningsAsErrors
+	WarningsAsErrors []WarningCode
+	// Cooldown limits how often a deployment is upgraded, see WithCooldown
+	Cooldown Cooldown
+	// Preconditions holds the preconditions that must be met before an
+	// upgrade, see WithPrecondition
+	Preconditions []PolicyPrecondition
+
+	// moreWindows holds the windows of other layers of a merged policy,
+	// an upgrade must be inside a window of every layer (see MergePolicies)
+	moreWindows [][]MaintenanceWindow
+}
+
+// PolicyPrecondition is a precondition of a Policy, that applies to the
+// given transition kinds (or all transitions that change the version
+// when empty).
+type PolicyPrecondition struct {
+	Precondition Precondition
+	Transitions  []TransitionKind
+}
+
+// Options returns the options that implement the policy.
+func (p Policy) Options() []Option {
+	var opts []Option
+	if p.RuleSet != nil {
+		opts = append(opts, WithRuleSet(p.RuleSet))
+	}
+	if p.Soft {
+		opts = append(opts, WithSoft())
+	}
+	if p.MaxMinorSkip > 0 {
+		opts = append(opts, WithMaxMinorSkip(p.MaxMinorSkip))
+	}
+	if p.Licenses != nil {
+		opts = append(opts, WithLicenseMatrix(p.Licenses))
+	}
+	if p.DenyByDefault {
+		opts = append(opts, WithDenyByDefault(p.AllowList...))
+	}
+	if len(p.Channels) > 0 {
+		opts = append(opts, WithChannels(p.Channels...))
+	}
+	if p.AllowGAToPreRelease {
+		opts = append(opts, WithAllowGAToPreRelease())
+	}
+	if len(p.MaintenanceWindows) > 0 {
+		opts = append(opts, WithMaintenanceWindows(p.MaintenanceWindows...))
+	}
+	for _, windows := range p.moreWindows {
+		opts = append(opts, WithMaintenanceWindows(windows...))
+	}
+	if len(p.WarningsAsErrors) > 0 {
+		opts = append(opts, WithWarningsAsErrors(p.WarningsAsErrors...))
+	}
+	if !p.Cooldown.IsZero() {
+		opts = append(opts, WithCooldown(p.Cooldown))
+	}
+	for _, x := range p.Preconditions {
+		opts = append(opts, WithPrecondition(x.Precondition, x.Transitions...))
+	}
+	return opts
+}
+
+// Check evaluates an upgrade from `from` to `to` under the policy.
+// The given options (e.g. WithLicenses, WithDeployment or WithOverrides)
+// are applied after those of the policy.
+func (p Policy) Check(from, to VersionString, opts ...Option) Decision {
+	return Check(from, to, append(p.Options(), opts...)...)
+}
+
+// defaultPolicy holds the *packagePolicy set with SetDefaultPolicy.
+var defaultPolicy atomic.Value
+
+// packagePolicy is a policy with its options, so they are only built once.
+type packagePolicy struct {
+	policy Policy
+	opts   []Option
+}
+
+// SetDefaultPolicy sets the policy applied by all checks before the options
+// given to them, e.g. once at startup, so the package-level check
+// functions can be called without options. Options given to a check
+// refine the default policy. The policy must not be modified afterwards.
+// It is safe to call concurrently with checks; checks that already
+// started keep using the previous default policy.
+func SetDefaultPolicy(p Policy) {
+	defaultPolicy.Store(&packagePolicy{policy: p, opts: p.Options()})
+}
+
+// DefaultPolicy returns the policy set with SetDefaultPolicy, or the zero
+// Policy when none is set.
+func DefaultPolicy() Policy {
+	if p, ok := defaultPolicy.Load().(*packagePolicy); ok {
+		return p.policy
+	}
+	return Policy{}
+}
+
+// defaultPolicyOptions returns the options of the default policy.
+func defaultPolicyOptions() []Option {
+	if p := currentDefaultPolicy(); p != nil {
+		return p.opts
+	}
+	return nil
+}
+
+// currentDefaultPolicy returns the policy set with SetDefaultPolicy, or nil.
+// Every call of SetDefaultPolicy stores a new pointer.
+func currentDefaultPolicy() *packagePolicy {
+	p, _ := defaultPolicy.Load().(*packagePolicy)
+	return p
+}
+
+// WithPolicy applies all options of the given policy.
+func WithPolicy(p Policy) Option {
+	opts := p.Options()
+	return func(o *options) {
+		for _, opt := range opts {
+			opt(o)
+		}
+	}
+}
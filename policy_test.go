@@ -0,0 +1,180 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChannelOf(t *testing.T) {
+	tests := map[VersionString]Channel{
+		"3.12.4":      ChannelGA,
+		"3.12.0-rc.1": ChannelPreRelease,
+		"3.2.rc7":     ChannelPreRelease,
+		"4.0.0-devel": ChannelPreRelease,
+		"3.11.0":      ChannelGA,
+	}
+	for v, expected := range tests {
+		if c := ChannelOf(ParseVersion(v)); c != expected {
+			t.Errorf("ChannelOf(%s): expected %s, got %s", v, expected, c)
+		}
+	}
+}
+
+func TestLicenseMatrixAllows(t *testing.T) {
+	var builtin LicenseMatrix
+	if !builtin.Allows(LicenseCommunity, LicenseEnterprise) || builtin.Allows(LicenseEnterprise, LicenseCommunity) {
+		t.Error("Nil matrix must only deny Enterprise to Community")
+	}
+	m := LicenseMatrix{{From: LicenseEnterprise, To: LicenseCommunity}: true}
+	if !m.Allows(LicenseEnterprise, LicenseCommunity) || m.Allows(LicenseCommunity, LicenseEnterprise) {
+		t.Error("Matrix must only allow its transitions")
+	}
+	if !m.Allows(LicenseCommunity, LicenseCommunity) {
+		t.Error("Keeping the license must always be allowed")
+	}
+}
+
+func TestPolicyZeroValue(t *testing.T) {
+	var p Policy
+	for _, pair := range [][2]VersionString{{"3.11.8", "3.12.1"}, {"3.10.8", "3.12.1"}, {"3.12.1", "3.12.0-rc.1"}} {
+		expected := Check(pair[0], pair[1])
+		d := p.Check(pair[0], pair[1])
+		if d.Rule != expected.Rule || d.Allowed() != expected.Allowed() {
+			t.Errorf("%s -> %s: expected %s, got %s", pair[0], pair[1], expected.Outcome(), d.Outcome())
+		}
+	}
+}
+
+func TestPolicyLicenses(t *testing.T) {
+	p := Policy{Licenses: LicenseMatrix{{From: LicenseEnterprise, To: LicenseCommunity}: true}}
+	if d := p.Check("3.11.8", "3.12.1", WithLicenses(LicenseEnterprise, LicenseCommunity)); !d.Allowed() {
+		t.Errorf("Expected E->C to be allowed by the matrix, got %s", d.Err)
+	}
+	d := p.Check("3.11.8", "3.12.1", WithLicenses(LicenseCommunity, LicenseEnterprise), WithTrace())
+	if d.Rule != RuleEditionDowngrade {
+		t.Fatalf("Expected C->E to be denied by %s, got %s", RuleEditionDowngrade, d.Rule)
+	}
+	if e := d.Err.(*Error); e.MessageID != MessageLicenseTransitionNotAllowed {
+		t.Errorf("Expected message %s, got %s", MessageLicenseTransitionNotAllowed, e.MessageID)
+	}
+	if c := d.Trace.Rules[0].Conditions[0]; c.Description != "license transition is in the license matrix" {
+		t.Errorf("Unexpected condition %q", c.Description)
+	}
+}
+
+func TestPolicyChannels(t *testing.T) {
+	p := Policy{Channels: []Channel{ChannelGA}}
+	if d := p.Check("3.11.8", "3.12.1"); !d.Allowed() {
+		t.Errorf("Expected GA target to be allowed, got %s", d.Err)
+	}
+	d := p.Check("3.11.8", "3.12.0-rc.1")
+	if d.Rule != RuleChannel || d.Err == nil {
+		t.Fatalf("Expected pre-release target to be denied by %s, got %s", RuleChannel, d.Rule)
+	}
+	if e := d.Err.(*Error); e.From != "3.11.8" || e.To != "3.12.0-rc.1" {
+		t.Errorf("Expected versions in error, got %+v", e)
+	}
+	if d := p.Check("3.12.0-rc.1", "3.12.0-rc.1"); !d.Allowed() {
+		t.Errorf("Expected unchanged version to be allowed, got %s", d.Err)
+	}
+	// The version rules are evaluated first
+	if d := p.Check("3.10.8", "3.12.0-rc.1"); d.Rule != RuleMinorIncrement {
+		t.Errorf("Expected %s, got %s", RuleMinorIncrement, d.Rule)
+	}
+}
+
+func TestPolicyBundle(t *testing.T) {
+	saturday := time.Date(2026, 10, 3, 3, 0, 0, 0, time.UTC)
+	backup := &testPrecondition{name: "backup", err: errors.New("No backup")}
+	p := Policy{
+		RuleSet:            DefaultRuleSet(),
+		Soft:               true,
+		MaintenanceWindows: []MaintenanceWindow{MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)},
+		Preconditions:      []PolicyPrecondition{{Precondition: backup, Transitions: []TransitionKind{TransitionMajor}}},
+	}
+	d := p.Check("3.10.8", "3.12.1", WithClock(func() time.Time { return saturday }))
+	if !d.Allowed() || !d.Soft {
+		t.Errorf("Expected soft allowed decision, got %+v", d)
+	}
+	if len(backup.infos) != 0 {
+		t.Error("Expected precondition not to be evaluated for a minor upgrade")
+	}
+	if d := p.Check("3.10.8", "3.12.1", WithClock(func() time.Time { return saturday.Add(4 * time.Hour) })); d.Rule != RuleMaintenanceWindow {
+		t.Errorf("Expected %s, got %s", RuleMaintenanceWindow, d.Rule)
+	}
+	p.RuleSet = DefaultRuleSet().WithoutRule(RuleMajorVersion).WithoutRule(RuleMinorDowngrade)
+	if d := p.Check("3.12.1", "4.0.0", WithClock(func() time.Time { return saturday })); len(d.UnmetPreconditions) != 1 {
+		t.Errorf("Expected unmet precondition, got %+v", d)
+	}
+}
+
+func TestWithPolicy(t *testing.T) {
+	p := Policy{Soft: true, Channels: []Channel{ChannelGA}}
+	if d := Check("3.10.8", "3.12.1", WithPolicy(p)); !d.Allowed() {
+		t.Errorf("Expected soft rules, got %s", d.Err)
+	}
+	if d := Check("3.10.8", "3.12.0-rc.1", WithPolicy(p)); d.Rule != RuleChannel {
+		t.Errorf("Expected %s, got %s", RuleChannel, d.Rule)
+	}
+}
+
+func TestSetDefaultPolicy(t *testing.T) {
+	defer SetDefaultPolicy(Policy{})
+	if err := CheckUpgradeRules("3.10.8", "3.12.1"); err == nil {
+		t.Fatal("Expected strict rules without a default policy")
+	}
+	SetDefaultPolicy(Policy{Soft: true, Channels: []Channel{ChannelGA}})
+	if !DefaultPolicy().Soft {
+		t.Errorf("Expected the default policy, got %+v", DefaultPolicy())
+	}
+	if err := CheckUpgradeRules("3.10.8", "3.12.1"); err != nil {
+		t.Errorf("Expected the default policy to allow the upgrade, got %s", err)
+	}
+	if d := Check("3.12.1", "3.13.0-rc.1", WithChannels(ChannelPreRelease)); !d.Allowed() {
+		t.Errorf("Expected options to refine the default policy, got %v", d.Err)
+	}
+	SetDefaultPolicy(Policy{})
+	if err := CheckUpgradeRules("3.10.8", "3.12.1"); err == nil {
+		t.Error("Expected strict rules after resetting the default policy")
+	}
+}
+
+func TestSetDefaultPolicyConcurrent(t *testing.T) {
+	defer SetDefaultPolicy(Policy{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				CheckUpgradeRules("3.10.8", "3.12.1")
+			}
+		}()
+	}
+	for j := 0; j < 100; j++ {
+		SetDefaultPolicy(Policy{Soft: j%2 == 0})
+	}
+	wg.Wait()
+}
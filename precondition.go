@@ -0,0 +1,210 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// TransitionKind classifies an upgrade by the most significant part of
+// the version that changes.
+type TransitionKind string
+
+const (
+	// TransitionNone means the version does not change
+	TransitionNone TransitionKind = "none"
+	// TransitionPatch means only the part after the minor version changes
+	TransitionPatch TransitionKind = "patch"
+	// TransitionMinor means the minor version changes
+	TransitionMinor TransitionKind = "minor"
+	// TransitionMajor means the major version changes
+	TransitionMajor TransitionKind = "major"
+)
+
+// TransitionOf returns the kind of an upgrade from `from` to `to`.
+func TransitionOf(from, to ParsedVersion) TransitionKind {
+	switch {
+	case from.major != to.major:
+		return TransitionMajor
+	case from.minor != to.minor:
+		return TransitionMinor
+	case from.sub != to.sub:
+		return TransitionPatch
+	default:
+		return TransitionNone
+	}
+}
+
+// DeploymentInfo describes the deployment being upgraded, as input for
+// preconditions. The versions, licenses and transition are set by the
+// check, the other fields come from WithDeployment.
+type DeploymentInfo struct {
+	// Name identifies the deployment
+	Name string
+	// Namespace of the deployment, if any
+	Namespace string
+	// Labels of the deployment
+	Labels map[string]string
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Licensed is set when the licenses are part of the check
+	Licensed bool
+	// FromLicense is the license being upgraded from (only if Licensed is set)
+	FromLicense License
+	// ToLicense is the license being upgraded to (only if Licensed is set)
+	ToLicense License
+	// Transition is the kind of the upgrade
+	Transition TransitionKind
+}
+
+// Precondition is a requirement on the deployment (rather than on the
+// versions) that must be met before an upgrade is allowed, such as the
+// existence of a recent backup.
+type Precondition interface {
+	// Name identifies the precondition in results.
+	Name() string
+	// Evaluate returns an error describing why the precondition is not met
+	// for the given deployment, or nil when it is met.
+	Evaluate(ctx context.Context, info DeploymentInfo) error
+}
+
+// UnmetPrecondition is a precondition that was not met by a check.
+type UnmetPrecondition struct {
+	// Name of the precondition
+	Name string
+	// Err describes why the precondition is not met
+	Err error
+}
+
+// PreconditionError is the error of a decision when the rules allow an
+// upgrade, but one or more preconditions are not met.
+// Since preconditions describe the state of a deployment, which usually
+// changes over time, such errors are retryable.
+type PreconditionError struct {
+	// Unmet contains the unmet preconditions, in order of evaluation
+	Unmet []UnmetPrecondition
+	// From is the version being upgraded from
+	From VersionString
+	// To is the version being upgraded to
+	To VersionString
+	// Licensed is set when the licenses were included in the check
+	Licensed bool
+	// FromLicense is the license being upgraded from (only if Licensed is set)
+	FromLicense License
+	// ToLicense is the license being upgraded to (only if Licensed is set)
+	ToLicense License
+}
+
+// ErrorCodePreconditionsNotMet is the code of a PreconditionError in its JSON representation.
+const ErrorCodePreconditionsNotMet = "PreconditionsNotMet"
+
+// Error lists the unmet preconditions.
+func (e *PreconditionError) Error() string {
+	return e.reason() + transitionSuffix(e.From, e.To, e.Licensed, e.FromLicense, e.ToLicense)
+}
+
+// reason lists the unmet preconditions, without the transition (see Reason).
+func (e *PreconditionError) reason() string {
+	parts := make([]string, 0, len(e.Unmet))
+	for _, u := range e.Unmet {
+		parts = append(parts, u.Name+": "+u.Err.Error())
+	}
+	return "Preconditions not met: " + strings.Join(parts, "; ")
+}
+
+// Retryable returns true, see IsRetryable.
+func (e *PreconditionError) Retryable() bool {
+	return true
+}
+
+// As finds the first error of an unmet precondition that matches target,
+// so errors.As retrieves the details of unmet preconditions.
+func (e *PreconditionError) As(target interface{}) bool {
+	for _, u := range e.Unmet {
+		if errors.As(u.Err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// RulePreconditions denies upgrades when a precondition is not met, see
+// WithPrecondition. It is evaluated after all other built-in rules, since
+// preconditions usually inspect the deployment.
+const RulePreconditions RuleID = "preconditions"
+
+// rulePreconditions implements RulePreconditions.
+var rulePreconditions = Rule{
+	ID:      RulePreconditions,
+	Applies: func(in RuleInput) bool { return len(in.preconditions) > 0 },
+	Check:   checkPreconditions,
+}
+
+// checkPreconditions implements RulePreconditions.
+func checkPreconditions(in RuleInput) error {
+	info := in.deployment
+	info.From, info.To = in.From.version, in.To.version
+	info.Licensed, info.FromLicense, info.ToLicense = in.Licensed, in.FromLicense, in.ToLicense
+	info.Transition = TransitionOf(in.From, in.To)
+	if unmet := evaluatePreconditions(in.Context, in.preconditions, info); len(unmet) > 0 {
+		return &PreconditionError{Unmet: unmet, From: info.From, To: info.To}
+	}
+	return nil
+}
+
+// attachedPrecondition is a precondition with the transitions it applies to.
+type attachedPrecondition struct {
+	precondition Precondition
+	// transitions the precondition applies to, all when empty
+	transitions []TransitionKind
+}
+
+// appliesTo returns true when the precondition must be evaluated for the given transition.
+func (p attachedPrecondition) appliesTo(kind TransitionKind) bool {
+	if len(p.transitions) == 0 {
+		return kind != TransitionNone
+	}
+	for _, t := range p.transitions {
+		if t == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluatePreconditions evaluates the preconditions that apply to the
+// given deployment and returns the unmet ones.
+func evaluatePreconditions(ctx context.Context, preconditions []attachedPrecondition, info DeploymentInfo) []UnmetPrecondition {
+	var unmet []UnmetPrecondition
+	for _, p := range preconditions {
+		if !p.appliesTo(info.Transition) {
+			continue
+		}
+		if err := p.precondition.Evaluate(ctx, info); err != nil {
+			unmet = append(unmet, UnmetPrecondition{Name: p.precondition.Name(), Err: err})
+		}
+	}
+	return unmet
+}
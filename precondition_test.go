@@ -0,0 +1,108 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// testPrecondition is a Precondition returning a fixed error and
+// recording the deployments it was evaluated for.
+type testPrecondition struct {
+	name  string
+	err   error
+	infos []DeploymentInfo
+}
+
+func (p *testPrecondition) Name() string { return p.name }
+
+func (p *testPrecondition) Evaluate(ctx context.Context, info DeploymentInfo) error {
+	p.infos = append(p.infos, info)
+	return p.err
+}
+
+func TestTransitionOf(t *testing.T) {
+	tests := map[[2]string]TransitionKind{
+		{"3.11.8", "3.11.8"}: TransitionNone,
+		{"3.11.8", "3.11.9"}: TransitionPatch,
+		{"3.11.8", "3.12.0"}: TransitionMinor,
+		{"3.12.0", "3.11.8"}: TransitionMinor,
+		{"3.11.8", "4.0.0"}:  TransitionMajor,
+	}
+	for versions, expected := range tests {
+		if kind := TransitionOf(ParseVersion(VersionString(versions[0])), ParseVersion(VersionString(versions[1]))); kind != expected {
+			t.Errorf("TransitionOf(%s, %s) = %s, expected %s", versions[0], versions[1], kind, expected)
+		}
+	}
+}
+
+func TestWithPrecondition(t *testing.T) {
+	backup := &testPrecondition{name: "backup", err: errors.New("No backup in the last 24h")}
+	health := &testPrecondition{name: "health"}
+	opts := []Option{
+		WithPrecondition(backup, TransitionMinor, TransitionMajor),
+		WithPrecondition(health),
+		WithDeployment(DeploymentInfo{Name: "db", Namespace: "prod"}),
+		WithLicenses(LicenseEnterprise, LicenseEnterprise),
+	}
+	// Patch upgrades only require health
+	if d := Check("3.11.8", "3.11.9", opts...); !d.Allowed() || len(backup.infos) != 0 || len(health.infos) != 1 {
+		t.Errorf("Expected patch upgrade to be allowed without backup, got %v", d.Err)
+	}
+	d := Check("3.11.8", "3.12.1", opts...)
+	if d.Allowed() || d.Rule != RulePreconditions || len(d.UnmetPreconditions) != 1 || d.UnmetPreconditions[0].Name != "backup" {
+		t.Fatalf("Expected denial by unmet backup precondition, got %s (%s): %v", d.Outcome(), d.Rule, d.Err)
+	}
+	if !IsRetryable(d.Err) {
+		t.Error("Unmet preconditions should be retryable")
+	}
+	if msg := d.Err.Error(); msg != "Preconditions not met: backup: No backup in the last 24h (from 3.11.8 enterprise to 3.12.1 enterprise)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	info := backup.infos[0]
+	if info.Name != "db" || info.Namespace != "prod" || info.From != "3.11.8" || info.To != "3.12.1" ||
+		info.Transition != TransitionMinor || !info.Licensed || info.FromLicense != LicenseEnterprise {
+		t.Errorf("Unexpected deployment info %+v", info)
+	}
+	// Preconditions are not evaluated when the rules deny the upgrade
+	if d := Check("3.11.8", "3.13.0", opts...); d.Rule != RuleMinorIncrement || len(backup.infos) != 1 {
+		t.Errorf("Expected denial by rule without evaluating preconditions, got %s", d.Rule)
+	}
+	// Nor when the version does not change
+	if d := Check("3.11.8", "3.11.8", opts...); !d.Allowed() || len(health.infos) != 2 {
+		t.Errorf("Expected same version to be allowed without preconditions, got %v", d.Err)
+	}
+}
+
+func TestPreconditionErrorJSON(t *testing.T) {
+	err := &PreconditionError{Unmet: []UnmetPrecondition{{Name: "backup", Err: errors.New("No backup")}}}
+	encoded, jsonErr := json.Marshal(err)
+	if jsonErr != nil {
+		t.Fatalf("Marshal failed: %s", jsonErr)
+	}
+	expected := `{"code":"PreconditionsNotMet","rule":"preconditions","message":"Preconditions not met: backup: No backup","unmet":[{"name":"backup","reason":"No backup"}]}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+}
@@ -0,0 +1,133 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package preconditions contains ready-made implementations of
+// upgraderules.Precondition.
+package preconditions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Backup describes a single backup of a deployment.
+type Backup struct {
+	// ID identifies the backup
+	ID string
+	// Version is the version of the deployment the backup was taken from
+	Version upgraderules.VersionString
+	// Time at which the backup was taken
+	Time time.Time
+	// Restorable is set when the backup is complete and consistent
+	Restorable bool
+}
+
+// BackupLister lists the backups of a deployment.
+type BackupLister interface {
+	// ListBackups returns the backups of the given deployment.
+	ListBackups(ctx context.Context, info upgraderules.DeploymentInfo) ([]Backup, error)
+}
+
+// BackupListerFunc is a function implementing BackupLister.
+type BackupListerFunc func(ctx context.Context, info upgraderules.DeploymentInfo) ([]Backup, error)
+
+// ListBackups calls f.
+func (f BackupListerFunc) ListBackups(ctx context.Context, info upgraderules.DeploymentInfo) ([]Backup, error) {
+	return f(ctx, info)
+}
+
+// NewDriverBackupLister returns a BackupLister listing the hot backups of
+// a deployment using the go-driver backup API. A backup is restorable
+// when it is available and not potentially inconsistent.
+func NewDriverBackupLister(c driver.ClientBackup) BackupLister {
+	return BackupListerFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) ([]Backup, error) {
+		list, err := c.List(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]Backup, 0, len(list))
+		for id, meta := range list {
+			result = append(result, Backup{
+				ID:         string(id),
+				Version:    upgraderules.VersionString(meta.Version),
+				Time:       meta.DateTime,
+				Restorable: meta.Available && !meta.PotentiallyInconsistent,
+			})
+		}
+		return result, nil
+	})
+}
+
+// BackupExists is a precondition requiring a restorable backup, taken
+// from the version being upgraded from, that is not older than MaxAge.
+// Attach it to the transitions that need it, e.g.
+//
+//	upgraderules.WithPrecondition(p, upgraderules.TransitionMinor, upgraderules.TransitionMajor)
+type BackupExists struct {
+	lister BackupLister
+	maxAge time.Duration
+	now    func() time.Time
+}
+
+var _ upgraderules.Precondition = &BackupExists{}
+
+// NewBackupExists creates a BackupExists precondition using the given
+// lister. The clock defaults to time.Now when nil.
+func NewBackupExists(lister BackupLister, maxAge time.Duration, now func() time.Time) *BackupExists {
+	if now == nil {
+		now = time.Now
+	}
+	return &BackupExists{
+		lister: lister,
+		maxAge: maxAge,
+		now:    now,
+	}
+}
+
+// Name returns "backup-exists".
+func (p *BackupExists) Name() string {
+	return "backup-exists"
+}
+
+// Evaluate returns an error when there is no recent, restorable backup
+// of the version being upgraded from. Backups without version are
+// assumed to match.
+func (p *BackupExists) Evaluate(ctx context.Context, info upgraderules.DeploymentInfo) error {
+	backups, err := p.lister.ListBackups(ctx, info)
+	if err != nil {
+		return upgraderules.PreconditionUnknownError(fmt.Errorf("Failed to list backups: %s", err))
+	}
+	oldest := p.now().Add(-p.maxAge)
+	for _, b := range backups {
+		if !b.Restorable || b.Time.Before(oldest) {
+			continue
+		}
+		if b.Version != "" && (b.Version.Major() != info.From.Major() || b.Version.Minor() != info.From.Minor()) {
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("No restorable backup of version %s taken within the last %s", info.From, p.maxAge)
+}
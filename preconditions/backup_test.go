@@ -0,0 +1,91 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+type backupClient map[driver.BackupID]driver.BackupMeta
+
+func (c backupClient) List(ctx context.Context, opt *driver.BackupListOptions) (map[driver.BackupID]driver.BackupMeta, error) {
+	if c == nil {
+		return nil, errors.New("connection refused")
+	}
+	return c, nil
+}
+
+func TestBackupExists(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	info := upgraderules.DeploymentInfo{From: "3.11.8", To: "3.12.1"}
+	tests := []struct {
+		Name    string
+		Backups backupClient
+		Met     bool
+	}{
+		{"recent", backupClient{"a": {Version: "3.11.8", DateTime: now.Add(-time.Hour), Available: true}}, true},
+		{"other patch", backupClient{"a": {Version: "3.11.7", DateTime: now.Add(-time.Hour), Available: true}}, true},
+		{"too old", backupClient{"a": {Version: "3.11.8", DateTime: now.Add(-25 * time.Hour), Available: true}}, false},
+		{"unavailable", backupClient{"a": {Version: "3.11.8", DateTime: now.Add(-time.Hour)}}, false},
+		{"inconsistent", backupClient{"a": {Version: "3.11.8", DateTime: now.Add(-time.Hour), Available: true, PotentiallyInconsistent: true}}, false},
+		{"other minor", backupClient{"a": {Version: "3.10.8", DateTime: now.Add(-time.Hour), Available: true}}, false},
+		{"none", backupClient{}, false},
+		{"list fails", nil, false},
+	}
+	for _, test := range tests {
+		p := NewBackupExists(NewDriverBackupLister(test.Backups), 24*time.Hour, clock)
+		err := p.Evaluate(context.Background(), info)
+		if test.Met && err != nil {
+			t.Errorf("%s: expected precondition to be met, got %s", test.Name, err)
+		} else if !test.Met && err == nil {
+			t.Errorf("%s: expected precondition not to be met", test.Name)
+		}
+	}
+}
+
+func TestBackupExistsWithCheck(t *testing.T) {
+	p := NewBackupExists(BackupListerFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) ([]Backup, error) {
+		return nil, nil
+	}), time.Hour, nil)
+	opt := upgraderules.WithPrecondition(p, upgraderules.TransitionMinor, upgraderules.TransitionMajor)
+	if d := upgraderules.Check("3.11.8", "3.11.9", opt); !d.Allowed() {
+		t.Errorf("Expected patch upgrade to be allowed without backup, got %s", d.Err)
+	}
+	d := upgraderules.Check("3.11.8", "3.12.1", opt)
+	if d.Allowed() || len(d.UnmetPreconditions) != 1 || d.UnmetPreconditions[0].Name != "backup-exists" {
+		t.Errorf("Expected minor upgrade to require a backup, got %v", d.Err)
+	}
+}
+
+func TestBackupExistsUnknown(t *testing.T) {
+	p := NewBackupExists(NewDriverBackupLister(backupClient(nil)), time.Hour, nil)
+	if err := p.Evaluate(context.Background(), upgraderules.DeploymentInfo{}); !upgraderules.IsPreconditionUnknown(err) {
+		t.Errorf("Expected unknown precondition when listing fails, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// DiskUsage is the disk usage of the data volume of a single member.
+type DiskUsage struct {
+	// Member identifies the member (e.g. its short name)
+	Member string
+	// TotalBytes is the size of the volume
+	TotalBytes uint64
+	// FreeBytes is the free space on the volume
+	FreeBytes uint64
+}
+
+// DiskUsageSource provides the disk usage of the members of a deployment.
+type DiskUsageSource interface {
+	// DiskUsage returns the current disk usage of all members of the given deployment.
+	DiskUsage(ctx context.Context, info upgraderules.DeploymentInfo) ([]DiskUsage, error)
+}
+
+// DiskUsageSourceFunc is a function implementing DiskUsageSource.
+type DiskUsageSourceFunc func(ctx context.Context, info upgraderules.DeploymentInfo) ([]DiskUsage, error)
+
+// DiskUsage calls f.
+func (f DiskUsageSourceFunc) DiskUsage(ctx context.Context, info upgraderules.DeploymentInfo) ([]DiskUsage, error) {
+	return f(ctx, info)
+}
+
+// DiskHeadroom is a precondition requiring a minimum fraction of free
+// disk space on every member, since compactions and format migrations
+// during an upgrade need headroom.
+type DiskHeadroom struct {
+	source        DiskUsageSource
+	minFree       float64
+	perTransition map[upgraderules.TransitionKind]float64
+}
+
+var _ upgraderules.Precondition = &DiskHeadroom{}
+
+// NewDiskHeadroom creates a DiskHeadroom precondition requiring at least
+// the given fraction (0..1) of free space on every member.
+func NewDiskHeadroom(source DiskUsageSource, minFree float64) *DiskHeadroom {
+	return &DiskHeadroom{
+		source:  source,
+		minFree: minFree,
+	}
+}
+
+// WithTransitionThreshold returns a copy of the precondition that requires
+// the given fraction of free space for upgrades of the given kind.
+func (p *DiskHeadroom) WithTransitionThreshold(kind upgraderules.TransitionKind, minFree float64) *DiskHeadroom {
+	result := *p
+	result.perTransition = make(map[upgraderules.TransitionKind]float64, len(p.perTransition)+1)
+	for k, v := range p.perTransition {
+		result.perTransition[k] = v
+	}
+	result.perTransition[kind] = minFree
+	return &result
+}
+
+// Name returns "disk-headroom".
+func (p *DiskHeadroom) Name() string {
+	return "disk-headroom"
+}
+
+// Threshold returns the fraction of free space required for upgrades of the given kind.
+func (p *DiskHeadroom) Threshold(kind upgraderules.TransitionKind) float64 {
+	if v, found := p.perTransition[kind]; found {
+		return v
+	}
+	return p.minFree
+}
+
+// Evaluate returns an error listing the members with too little free
+// space, or nil when all members have enough.
+func (p *DiskHeadroom) Evaluate(ctx context.Context, info upgraderules.DeploymentInfo) error {
+	usage, err := p.source.DiskUsage(ctx, info)
+	if err != nil {
+		return upgraderules.PreconditionUnknownError(fmt.Errorf("Failed to get disk usage: %s", err))
+	}
+	threshold := p.Threshold(info.Transition)
+	var short []string
+	for _, u := range usage {
+		free := 0.0
+		if u.TotalBytes > 0 {
+			free = float64(u.FreeBytes) / float64(u.TotalBytes)
+		}
+		if free < threshold {
+			short = append(short, fmt.Sprintf("%s (%.0f%% free)", u.Member, free*100))
+		}
+	}
+	if len(short) > 0 {
+		return fmt.Errorf("Less than %.0f%% free disk space on %s", threshold*100, strings.Join(short, ", "))
+	}
+	return nil
+}
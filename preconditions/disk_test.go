@@ -0,0 +1,55 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"context"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestDiskHeadroom(t *testing.T) {
+	usage := []DiskUsage{
+		{Member: "DBServer0001", TotalBytes: 100, FreeBytes: 40},
+		{Member: "DBServer0002", TotalBytes: 100, FreeBytes: 25},
+	}
+	source := DiskUsageSourceFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) ([]DiskUsage, error) {
+		return usage, nil
+	})
+	p := NewDiskHeadroom(source, 0.2)
+	strict := p.WithTransitionThreshold(upgraderules.TransitionMajor, 0.3)
+	patch := upgraderules.DeploymentInfo{Transition: upgraderules.TransitionPatch}
+	major := upgraderules.DeploymentInfo{Transition: upgraderules.TransitionMajor}
+	if err := p.Evaluate(context.Background(), major); err != nil {
+		t.Errorf("Expected enough headroom, got %s", err)
+	}
+	if err := strict.Evaluate(context.Background(), patch); err != nil {
+		t.Errorf("Expected enough headroom for patch upgrade, got %s", err)
+	}
+	err := strict.Evaluate(context.Background(), major)
+	if expected := "Less than 30% free disk space on DBServer0002 (25% free)"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+	if p.Threshold(upgraderules.TransitionMajor) != 0.2 {
+		t.Error("WithTransitionThreshold must not modify the original precondition")
+	}
+}
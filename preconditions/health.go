@@ -0,0 +1,157 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// HealthSnapshot describes the health of a cluster at a moment in time.
+type HealthSnapshot struct {
+	// Servers contains the health of every server, including agents
+	Servers []ServerHealth
+	// CollectionsNotInSync contains the names (<database>/<collection>)
+	// of collections with shards that are not in sync
+	CollectionsNotInSync []string
+}
+
+// ServerHealth is the health of a single server of a cluster.
+type ServerHealth struct {
+	// Name identifies the server (e.g. its short name)
+	Name string
+	// Role of the server
+	Role driver.ServerRole
+	// Status of the server
+	Status driver.ServerStatus
+}
+
+// HealthSource provides a health snapshot of a deployment.
+type HealthSource interface {
+	// Health returns the current health of the given deployment.
+	Health(ctx context.Context, info upgraderules.DeploymentInfo) (HealthSnapshot, error)
+}
+
+// HealthSourceFunc is a function implementing HealthSource.
+type HealthSourceFunc func(ctx context.Context, info upgraderules.DeploymentInfo) (HealthSnapshot, error)
+
+// Health calls f.
+func (f HealthSourceFunc) Health(ctx context.Context, info upgraderules.DeploymentInfo) (HealthSnapshot, error) {
+	return f(ctx, info)
+}
+
+// NewDriverHealthSource returns a HealthSource that fetches the cluster
+// health and the shard synchronization state of all collections of all
+// databases using the given go-driver client.
+func NewDriverHealthSource(c driver.Client) HealthSource {
+	return HealthSourceFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) (HealthSnapshot, error) {
+		cluster, err := c.Cluster(ctx)
+		if err != nil {
+			return HealthSnapshot{}, err
+		}
+		health, err := cluster.Health(ctx)
+		if err != nil {
+			return HealthSnapshot{}, err
+		}
+		var s HealthSnapshot
+		for id, h := range health.Health {
+			name := h.ShortName
+			if name == "" {
+				name = string(id)
+			}
+			s.Servers = append(s.Servers, ServerHealth{Name: name, Role: h.Role, Status: h.Status})
+		}
+		dbs, err := c.Databases(ctx)
+		if err != nil {
+			return HealthSnapshot{}, err
+		}
+		for _, db := range dbs {
+			inventory, err := cluster.DatabaseInventory(ctx, db)
+			if err != nil {
+				return HealthSnapshot{}, err
+			}
+			for _, col := range inventory.Collections {
+				if !col.AllInSync || !col.IsReady {
+					s.CollectionsNotInSync = append(s.CollectionsNotInSync, db.Name()+"/"+col.Parameters.Name)
+				}
+			}
+		}
+		return s, nil
+	})
+}
+
+// ClusterHealthy is a precondition requiring that all servers of a
+// cluster are healthy, including all agents, and all shards are in sync.
+type ClusterHealthy struct {
+	source HealthSource
+}
+
+var _ upgraderules.Precondition = &ClusterHealthy{}
+
+// NewClusterHealthy creates a ClusterHealthy precondition using the given source.
+func NewClusterHealthy(source HealthSource) *ClusterHealthy {
+	return &ClusterHealthy{source: source}
+}
+
+// Name returns "cluster-healthy".
+func (p *ClusterHealthy) Name() string {
+	return "cluster-healthy"
+}
+
+// Evaluate returns an error describing the unhealthy servers and
+// collections that are not in sync, or nil when the cluster is healthy.
+func (p *ClusterHealthy) Evaluate(ctx context.Context, info upgraderules.DeploymentInfo) error {
+	s, err := p.source.Health(ctx, info)
+	if err != nil {
+		return upgraderules.PreconditionUnknownError(fmt.Errorf("Failed to get cluster health: %s", err))
+	}
+	var problems []string
+	agents := 0
+	var unhealthy []string
+	for _, server := range s.Servers {
+		if server.Role == driver.ServerRoleAgent {
+			agents++
+		}
+		if server.Status != driver.ServerStatusGood {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s %s is %s", server.Role, server.Name, server.Status))
+		}
+	}
+	sort.Strings(unhealthy)
+	problems = append(problems, unhealthy...)
+	if agents == 0 && len(s.Servers) > 0 {
+		problems = append(problems, "agency has no agents")
+	}
+	if n := len(s.CollectionsNotInSync); n > 0 {
+		names := append([]string(nil), s.CollectionsNotInSync...)
+		sort.Strings(names)
+		problems = append(problems, fmt.Sprintf("%d collection(s) not in sync: %s", n, strings.Join(names, ", ")))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("Cluster is not healthy: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
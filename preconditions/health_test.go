@@ -0,0 +1,118 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+type testDatabase string
+
+func (db testDatabase) Name() string { return string(db) }
+
+type testCluster struct {
+	health      driver.ClusterHealth
+	inventories map[string]driver.DatabaseInventory
+}
+
+func (c *testCluster) Health(ctx context.Context) (driver.ClusterHealth, error) {
+	return c.health, nil
+}
+
+func (c *testCluster) DatabaseInventory(ctx context.Context, db driver.Database) (driver.DatabaseInventory, error) {
+	return c.inventories[db.Name()], nil
+}
+
+// testClient implements the cluster methods of driver.Client, calling
+// any other method panics.
+type testClient struct {
+	driver.Client
+	cluster *testCluster
+}
+
+func (c testClient) Cluster(ctx context.Context) (driver.Cluster, error) {
+	if c.cluster == nil {
+		return nil, errors.New("not a cluster")
+	}
+	return c.cluster, nil
+}
+
+func (c testClient) Databases(ctx context.Context) ([]driver.Database, error) {
+	var result []driver.Database
+	for name := range c.cluster.inventories {
+		result = append(result, testDatabase(name))
+	}
+	return result, nil
+}
+
+func TestClusterHealthyDriver(t *testing.T) {
+	cluster := &testCluster{
+		health: driver.ClusterHealth{Health: map[driver.ServerID]driver.ServerHealth{
+			"AGNT-1": {Role: driver.ServerRoleAgent, Status: driver.ServerStatusGood},
+			"PRMR-1": {Role: driver.ServerRoleDBServer, ShortName: "DBServer0001", Status: driver.ServerStatusGood},
+			"CRDN-1": {Role: driver.ServerRoleCoordinator, ShortName: "Coordinator0001", Status: driver.ServerStatusGood},
+		}},
+		inventories: map[string]driver.DatabaseInventory{
+			"_system": {Collections: []driver.InventoryCollection{
+				{Parameters: driver.InventoryCollectionParameters{Name: "users"}, IsReady: true, AllInSync: true},
+			}},
+		},
+	}
+	p := NewClusterHealthy(NewDriverHealthSource(testClient{cluster: cluster}))
+	if err := p.Evaluate(context.Background(), upgraderules.DeploymentInfo{}); err != nil {
+		t.Errorf("Expected healthy cluster, got %s", err)
+	}
+	cluster.health.Health["PRMR-1"] = driver.ServerHealth{Role: driver.ServerRoleDBServer, ShortName: "DBServer0001", Status: driver.ServerStatusFailed}
+	cluster.inventories["db"] = driver.DatabaseInventory{Collections: []driver.InventoryCollection{
+		{Parameters: driver.InventoryCollectionParameters{Name: "orders"}, IsReady: true},
+	}}
+	err := p.Evaluate(context.Background(), upgraderules.DeploymentInfo{})
+	expected := "Cluster is not healthy: DBServer DBServer0001 is FAILED; 1 collection(s) not in sync: db/orders"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+	if err := NewClusterHealthy(NewDriverHealthSource(testClient{})).Evaluate(context.Background(), upgraderules.DeploymentInfo{}); err == nil {
+		t.Error("Expected failure when the health cannot be fetched")
+	}
+}
+
+func TestClusterHealthySnapshot(t *testing.T) {
+	snapshot := HealthSnapshot{Servers: []ServerHealth{
+		{Name: "DBServer0001", Role: driver.ServerRoleDBServer, Status: driver.ServerStatusGood},
+	}}
+	p := NewClusterHealthy(HealthSourceFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) (HealthSnapshot, error) {
+		return snapshot, nil
+	}))
+	err := p.Evaluate(context.Background(), upgraderules.DeploymentInfo{})
+	if err == nil || err.Error() != "Cluster is not healthy: agency has no agents" {
+		t.Errorf("Expected missing agency, got %v", err)
+	}
+	d := upgraderules.Check("3.11.8", "3.11.9", upgraderules.WithPrecondition(p))
+	if d.Allowed() || len(d.UnmetPreconditions) != 1 || d.UnmetPreconditions[0].Name != "cluster-healthy" {
+		t.Errorf("Expected unhealthy cluster to deny the upgrade, got %v", d.Err)
+	}
+}
@@ -0,0 +1,105 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"sort"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// RemediationKind classifies a Remediation.
+type RemediationKind string
+
+const (
+	// RemediationComplete upgrades the servers on older versions to the
+	// newest version
+	RemediationComplete RemediationKind = "complete"
+	// RemediationRollBack downgrades the servers on newer versions to the
+	// oldest version
+	RemediationRollBack RemediationKind = "roll-back"
+)
+
+// Remediation is a way to bring the servers of a partially upgraded
+// cluster back to a single version.
+type Remediation struct {
+	// Kind of the remediation
+	Kind RemediationKind
+	// Target is the version all servers run afterwards
+	Target upgraderules.VersionString
+	// Servers contains the servers that must change to Target
+	Servers []ServerVersion
+	// Decisions contains the decision of the rules for changing every
+	// version of Servers to Target, ordered by version
+	Decisions []upgraderules.Decision
+}
+
+// Allowed returns true when the rules allow every change of the remediation.
+func (r Remediation) Allowed() bool {
+	for _, d := range r.Decisions {
+		if !d.Allowed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Remediations returns the ways to bring the servers of a cluster running
+// different versions back to a single version, outside of an active
+// upgrade: completing the upgrade to the newest version and rolling back
+// the servers on newer versions to the oldest one. Every change is
+// checked using the given options. It returns nil when all servers run
+// the same version.
+func Remediations(s ClusterState, opts ...upgraderules.Option) []Remediation {
+	var versions []upgraderules.VersionString
+	seen := make(map[upgraderules.VersionString]bool)
+	for _, server := range s.Servers {
+		if !seen[server.Version] {
+			seen[server.Version] = true
+			versions = append(versions, server.Version)
+		}
+	}
+	if len(versions) <= 1 {
+		return nil
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].CompareTo(versions[j]) < 0 })
+	return []Remediation{
+		remediation(RemediationComplete, versions[len(versions)-1], versions, s.Servers, opts),
+		remediation(RemediationRollBack, versions[0], versions, s.Servers, opts),
+	}
+}
+
+// remediation returns the remediation changing all servers to target.
+// The versions are sorted.
+func remediation(kind RemediationKind, target upgraderules.VersionString, versions []upgraderules.VersionString, servers []ServerVersion, opts []upgraderules.Option) Remediation {
+	r := Remediation{Kind: kind, Target: target}
+	for _, server := range servers {
+		if server.Version != target {
+			r.Servers = append(r.Servers, server)
+		}
+	}
+	for _, v := range versions {
+		if v != target {
+			r.Decisions = append(r.Decisions, upgraderules.Check(v, target, opts...))
+		}
+	}
+	return r
+}
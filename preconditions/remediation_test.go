@@ -0,0 +1,58 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"testing"
+
+	driver "github.com/arangodb/go-driver"
+)
+
+func TestRemediations(t *testing.T) {
+	s := ClusterState{Servers: []ServerVersion{
+		{Name: "DBServer0001", Role: driver.ServerRoleDBServer, Version: "3.11.8"},
+		{Name: "DBServer0002", Role: driver.ServerRoleDBServer, Version: "3.12.1"},
+		{Name: "Coordinator0001", Role: driver.ServerRoleCoordinator, Version: "3.11.8"},
+	}}
+	remediations := Remediations(s)
+	if len(remediations) != 2 {
+		t.Fatalf("Expected 2 remediations, got %+v", remediations)
+	}
+	complete, rollBack := remediations[0], remediations[1]
+	if complete.Kind != RemediationComplete || complete.Target != "3.12.1" || len(complete.Servers) != 2 || !complete.Allowed() {
+		t.Errorf("Unexpected remediation %+v", complete)
+	}
+	if rollBack.Kind != RemediationRollBack || rollBack.Target != "3.11.8" || len(rollBack.Servers) != 1 {
+		t.Errorf("Unexpected remediation %+v", rollBack)
+	}
+	if rollBack.Allowed() || len(rollBack.Decisions) != 1 || rollBack.Decisions[0].From != "3.12.1" {
+		t.Errorf("Expected the roll back to be denied by the rules, got %v", rollBack.Decisions)
+	}
+
+	s.Servers[1].Version = "3.11.9"
+	if remediations := Remediations(s); !remediations[0].Allowed() || !remediations[1].Allowed() {
+		t.Errorf("Expected both remediations of a patch upgrade to be allowed, got %+v", remediations)
+	}
+	s.Servers[1].Version = "3.11.8"
+	if remediations := Remediations(s); remediations != nil {
+		t.Errorf("Expected no remediations, got %+v", remediations)
+	}
+}
@@ -0,0 +1,170 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// ClusterState describes the versions and the supervision of a cluster,
+// e.g. taken from the agency, to detect an unfinished rolling upgrade.
+type ClusterState struct {
+	// Servers contains the version of every server
+	Servers []ServerVersion
+	// PendingJobs contains the IDs of supervision jobs that are still to
+	// do or pending
+	PendingJobs []string
+}
+
+// ServerVersion is the version of a single server of a cluster.
+type ServerVersion struct {
+	// Name identifies the server (e.g. its short name)
+	Name string
+	// Role of the server
+	Role driver.ServerRole
+	// Version the server runs
+	Version upgraderules.VersionString
+}
+
+// ClusterStateSource provides the state of a deployment.
+type ClusterStateSource interface {
+	// ClusterState returns the current state of the given deployment.
+	ClusterState(ctx context.Context, info upgraderules.DeploymentInfo) (ClusterState, error)
+}
+
+// ClusterStateSourceFunc is a function implementing ClusterStateSource.
+type ClusterStateSourceFunc func(ctx context.Context, info upgraderules.DeploymentInfo) (ClusterState, error)
+
+// ClusterState calls f.
+func (f ClusterStateSourceFunc) ClusterState(ctx context.Context, info upgraderules.DeploymentInfo) (ClusterState, error) {
+	return f(ctx, info)
+}
+
+// NewDriverClusterStateSource returns a ClusterStateSource that reads the
+// versions of the servers from the cluster health using the given
+// go-driver client. The cluster health does not contain the supervision
+// jobs; use a ClusterStateSourceFunc reading the agency to include them.
+func NewDriverClusterStateSource(c driver.Client) ClusterStateSource {
+	return ClusterStateSourceFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) (ClusterState, error) {
+		cluster, err := c.Cluster(ctx)
+		if err != nil {
+			return ClusterState{}, err
+		}
+		health, err := cluster.Health(ctx)
+		if err != nil {
+			return ClusterState{}, err
+		}
+		var s ClusterState
+		for id, h := range health.Health {
+			name := h.ShortName
+			if name == "" {
+				name = string(id)
+			}
+			s.Servers = append(s.Servers, ServerVersion{Name: name, Role: h.Role, Version: upgraderules.VersionString(h.Version)})
+		}
+		return s, nil
+	})
+}
+
+// UpgradeInProgressError is the error of NoUpgradeInProgress.
+// Retrieve it from the error of a decision with errors.As.
+type UpgradeInProgressError struct {
+	// Versions contains the distinct versions the servers run, sorted
+	Versions []upgraderules.VersionString
+	// PendingJobs contains the IDs of the pending supervision jobs
+	PendingJobs []string
+	// To is the version of the refused upgrade
+	To upgraderules.VersionString
+}
+
+// Error describes the unfinished upgrade.
+func (e *UpgradeInProgressError) Error() string {
+	var parts []string
+	if len(e.Versions) > 1 {
+		versions := make([]string, 0, len(e.Versions))
+		for _, v := range e.Versions {
+			versions = append(versions, string(v))
+		}
+		parts = append(parts, "servers run versions "+strings.Join(versions, ", "))
+	}
+	if n := len(e.PendingJobs); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d supervision job(s) pending", n))
+	}
+	return fmt.Sprintf("Upgrade in progress (%s), finish or roll it back before upgrading to %s", strings.Join(parts, "; "), e.To)
+}
+
+// NoUpgradeInProgress is a precondition refusing a new upgrade while a
+// previous one is unfinished. An upgrade is in progress when the servers
+// run different versions or supervision jobs are pending. While it is,
+// only upgrades to a version that a server already runs are allowed,
+// i.e. finishing the upgrade or rolling it back.
+type NoUpgradeInProgress struct {
+	source ClusterStateSource
+}
+
+var _ upgraderules.Precondition = &NoUpgradeInProgress{}
+
+// NewNoUpgradeInProgress creates a NoUpgradeInProgress precondition using
+// the given source.
+func NewNoUpgradeInProgress(source ClusterStateSource) *NoUpgradeInProgress {
+	return &NoUpgradeInProgress{source: source}
+}
+
+// Name returns "no-upgrade-in-progress".
+func (p *NoUpgradeInProgress) Name() string {
+	return "no-upgrade-in-progress"
+}
+
+// Evaluate returns an *UpgradeInProgressError when an upgrade is in
+// progress and the upgrade to info.To would neither finish nor roll it back.
+func (p *NoUpgradeInProgress) Evaluate(ctx context.Context, info upgraderules.DeploymentInfo) error {
+	s, err := p.source.ClusterState(ctx, info)
+	if err != nil {
+		return upgraderules.PreconditionUnknownError(fmt.Errorf("Failed to get cluster state: %s", err))
+	}
+	seen := make(map[upgraderules.VersionString]bool)
+	var versions []upgraderules.VersionString
+	for _, server := range s.Servers {
+		if !seen[server.Version] {
+			seen[server.Version] = true
+			versions = append(versions, server.Version)
+		}
+	}
+	if len(versions) <= 1 && len(s.PendingJobs) == 0 {
+		return nil
+	}
+	if seen[info.To] {
+		return nil
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].CompareTo(versions[j]) < 0 })
+	return &UpgradeInProgressError{
+		Versions:    versions,
+		PendingJobs: append([]string(nil), s.PendingJobs...),
+		To:          info.To,
+	}
+}
@@ -0,0 +1,96 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package preconditions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// staticClusterState returns a source of the given server versions and
+// pending jobs.
+func staticClusterState(jobs []string, versions ...upgraderules.VersionString) ClusterStateSource {
+	return ClusterStateSourceFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) (ClusterState, error) {
+		s := ClusterState{PendingJobs: jobs}
+		for _, v := range versions {
+			s.Servers = append(s.Servers, ServerVersion{Role: driver.ServerRoleDBServer, Version: v})
+		}
+		return s, nil
+	})
+}
+
+func TestNoUpgradeInProgress(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Source ClusterStateSource
+		To     upgraderules.VersionString
+		Met    bool
+	}{
+		{"uniform", staticClusterState(nil, "3.11.8", "3.11.8"), "3.12.1", true},
+		{"finish", staticClusterState(nil, "3.11.8", "3.12.1"), "3.12.1", true},
+		{"roll back", staticClusterState(nil, "3.11.8", "3.12.1"), "3.11.8", true},
+		{"new target", staticClusterState(nil, "3.11.8", "3.12.1"), "3.12.2", false},
+		{"pending jobs", staticClusterState([]string{"1234"}, "3.11.8"), "3.12.1", false},
+		{"source fails", ClusterStateSourceFunc(func(context.Context, upgraderules.DeploymentInfo) (ClusterState, error) {
+			return ClusterState{}, errors.New("connection refused")
+		}), "3.12.1", false},
+	}
+	for _, test := range tests {
+		err := NewNoUpgradeInProgress(test.Source).Evaluate(context.Background(), upgraderules.DeploymentInfo{From: "3.11.8", To: test.To})
+		if test.Met && err != nil {
+			t.Errorf("%s: expected precondition to be met, got %s", test.Name, err)
+		} else if !test.Met && err == nil {
+			t.Errorf("%s: expected precondition to be unmet", test.Name)
+		}
+	}
+}
+
+func TestUpgradeInProgressError(t *testing.T) {
+	p := NewNoUpgradeInProgress(staticClusterState([]string{"1234"}, "3.12.1", "3.11.8", "3.12.1"))
+	d := upgraderules.Check("3.11.8", "3.12.2", upgraderules.WithPrecondition(p))
+	var inProgress *UpgradeInProgressError
+	if !errors.As(d.Err, &inProgress) {
+		t.Fatalf("Expected an UpgradeInProgressError, got %v", d.Err)
+	}
+	if len(inProgress.Versions) != 2 || inProgress.Versions[0] != "3.11.8" || inProgress.To != "3.12.2" {
+		t.Errorf("Unexpected error details %+v", inProgress)
+	}
+	expected := "Upgrade in progress (servers run versions 3.11.8, 3.12.1; 1 supervision job(s) pending), finish or roll it back before upgrading to 3.12.2"
+	if inProgress.Error() != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, inProgress.Error())
+	}
+}
+
+func TestDriverClusterStateSource(t *testing.T) {
+	cluster := &testCluster{health: driver.ClusterHealth{Health: map[driver.ServerID]driver.ServerHealth{
+		"PRMR-1": {ShortName: "DBServer0001", Role: driver.ServerRoleDBServer, Status: driver.ServerStatusGood, Version: "3.11.8"},
+		"PRMR-2": {ShortName: "DBServer0002", Role: driver.ServerRoleDBServer, Status: driver.ServerStatusGood, Version: "3.12.1"},
+	}}}
+	p := NewNoUpgradeInProgress(NewDriverClusterStateSource(testClient{cluster: cluster}))
+	if err := p.Evaluate(context.Background(), upgraderules.DeploymentInfo{From: "3.11.8", To: "3.12.2"}); err == nil {
+		t.Error("Expected mixed versions to be detected")
+	}
+}
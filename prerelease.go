@@ -0,0 +1,105 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+)
+
+// RulePreReleaseTarget denies upgrades to pre-releases (e.g. 3.12.0-rc.1,
+// 3.2.rc7 or 4.0.0-devel), unless WithAllowPreRelease or WithChannels
+// is given.
+const RulePreReleaseTarget RuleID = "pre-release-target"
+
+// WithAllowPreRelease allows upgrades to pre-releases, which are denied by
+// RulePreReleaseTarget by default. Such upgrades still get a
+// WarningPreReleaseTarget.
+func WithAllowPreRelease() Option {
+	return func(o *options) {
+		o.allowPreRelease = true
+	}
+}
+
+// RuleGAToPreRelease denies upgrades from a generally available release
+// to a pre-release of the same patch version, e.g. 3.12.0 to 3.12.0-rc.2,
+// unless WithAllowGAToPreRelease is given. The opposite direction, e.g.
+// 3.12.0-rc.2 to 3.12.0, is an ordinary upgrade and allowed.
+const RuleGAToPreRelease RuleID = "ga-to-pre-release"
+
+// WithAllowGAToPreRelease allows going back from a generally available
+// release to a pre-release of the same patch version, which test
+// environments use to verify release candidates again. Such upgrades must
+// also be allowed by WithAllowPreRelease or WithChannels.
+func WithAllowGAToPreRelease() Option {
+	return func(o *options) {
+		o.allowGAToPre = true
+	}
+}
+
+// ruleGAToPreRelease implements RuleGAToPreRelease.
+var ruleGAToPreRelease = Rule{
+	ID:      RuleGAToPreRelease,
+	Applies: func(in RuleInput) bool { return !in.AllowGAToPreRelease && in.changesVersion() },
+	Check:   func(in RuleInput) error { return checkGAToPreRelease(in.From, in.To) },
+}
+
+// checkGAToPreRelease implements RuleGAToPreRelease.
+func checkGAToPreRelease(from, to ParsedVersion) error {
+	if from.IsPreRelease() || !to.IsPreRelease() || from.major != to.major || from.minor != to.minor {
+		return nil
+	}
+	fromPatch, ok := from.Patch()
+	toPatch, toOk := to.Patch()
+	if ok && toOk && fromPatch == toPatch {
+		cause := &DowngradeError{From: from.version, To: to.version}
+		return newCausedError(cause, RuleGAToPreRelease, MessageGAToPreRelease, string(from.version), string(to.version))
+	}
+	return nil
+}
+
+// PreReleaseError details a denial by RulePreReleaseTarget.
+type PreReleaseError struct {
+	// Version is the pre-release being upgraded to
+	Version VersionString
+}
+
+// Error describes the pre-release.
+func (e *PreReleaseError) Error() string {
+	return fmt.Sprintf("Version %s is a pre-release", e.Version)
+}
+
+// rulePreReleaseTarget implements RulePreReleaseTarget.
+// Allowed release channels replace it by RuleChannel.
+var rulePreReleaseTarget = Rule{
+	ID: RulePreReleaseTarget,
+	Applies: func(in RuleInput) bool {
+		return !in.AllowPreRelease && len(in.Channels) == 0 && in.changesVersion()
+	},
+	Check: func(in RuleInput) error { return checkPreReleaseTarget(in.To) },
+}
+
+// checkPreReleaseTarget implements RulePreReleaseTarget.
+func checkPreReleaseTarget(to ParsedVersion) error {
+	if to.IsPreRelease() {
+		return newCausedError(&PreReleaseError{Version: to.version}, RulePreReleaseTarget, MessagePreReleaseTarget, string(to.version))
+	}
+	return nil
+}
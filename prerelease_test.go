@@ -0,0 +1,101 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestPreReleaseTarget(t *testing.T) {
+	tests := []struct {
+		From, To VersionString
+		Opts     []Option
+		Rule     RuleID
+	}{
+		{"3.11.8", "3.12.0-rc.1", nil, RulePreReleaseTarget},
+		{"3.11.8", "3.11.rc7", nil, RulePreReleaseTarget},
+		{"3.12.0", "3.12.1-devel", nil, RulePreReleaseTarget},
+		{"3.12.0-rc.1", "3.12.0-rc.1", nil, ""},
+		{"3.12.0-rc.1", "3.12.0", nil, ""},
+		{"3.11.8", "3.12.0-rc.1", []Option{WithAllowPreRelease()}, ""},
+		{"3.11.8", "3.12.0-rc.1", []Option{WithChannels(ChannelGA, ChannelPreRelease)}, ""},
+		{"3.11.8", "3.12.0-rc.1", []Option{WithChannels(ChannelGA)}, RuleChannel},
+		{"3.10.8", "3.12.0-rc.1", nil, RuleMinorIncrement},
+	}
+	for _, test := range tests {
+		d := Check(test.From, test.To, test.Opts...)
+		if d.Rule != test.Rule {
+			t.Errorf("%s -> %s: expected rule '%s', got '%s' (%v)", test.From, test.To, test.Rule, d.Rule, d.Err)
+		}
+	}
+	d := Check("3.11.8", "3.12.0-rc.1")
+	if msg := d.Err.Error(); msg != "Version 3.12.0-rc.1 is a pre-release (from 3.11.8 to 3.12.0-rc.1)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	d = Check("3.11.8", "3.12.0-rc.1", WithAllowPreRelease())
+	if len(d.Warnings) != 1 || d.Warnings[0].Code != WarningPreReleaseTarget {
+		t.Errorf("Expected a pre-release warning, got %v", d.Warnings)
+	}
+}
+
+func TestGAToPreRelease(t *testing.T) {
+	pre := WithAllowPreRelease()
+	tests := []struct {
+		From, To VersionString
+		Opts     []Option
+		Rule     RuleID
+	}{
+		{"3.12.0-rc.2", "3.12.0", nil, ""},
+		{"3.12.0-rc.1", "3.12.0-rc.2", []Option{pre}, ""},
+		{"3.12.0", "3.12.0-rc.2", []Option{pre}, RuleGAToPreRelease},
+		{"3.2.7", "3.2.rc7", []Option{pre}, ""},
+		{"3.12.0", "3.12.1-rc.1", []Option{pre}, ""},
+		{"3.12.1", "3.12.0-rc.2", []Option{pre}, ""},
+		{"3.12.0", "3.12.0-rc.2", []Option{pre, WithAllowGAToPreRelease()}, ""},
+		{"3.12.0", "3.12.0-rc.2", []Option{WithAllowGAToPreRelease()}, RulePreReleaseTarget},
+	}
+	for _, test := range tests {
+		d := Check(test.From, test.To, test.Opts...)
+		if d.Rule != test.Rule {
+			t.Errorf("%s -> %s: expected rule '%s', got '%s' (%v)", test.From, test.To, test.Rule, d.Rule, d.Err)
+		}
+	}
+	d := Check("3.12.0", "3.12.0-rc.2", pre)
+	if msg := d.Err.Error(); msg != "Going back from 3.12.0 to its pre-release 3.12.0-rc.2 is not allowed (from 3.12.0 to 3.12.0-rc.2)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
+
+func TestMergePoliciesAllowGAToPreRelease(t *testing.T) {
+	e, err := MergePolicies(
+		PolicyLayer{Name: "org", Policy: Policy{AllowGAToPreRelease: true}},
+		PolicyLayer{Name: "prod", Policy: Policy{}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Policy.AllowGAToPreRelease {
+		t.Error("Expected the stricter layer to win")
+	}
+	if s := e.Sources[PolicyFieldAllowGAToPreRelease]; len(s) != 1 || s[0] != "prod" {
+		t.Errorf("Unexpected sources %v", s)
+	}
+}
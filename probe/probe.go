@@ -0,0 +1,145 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package probe connects to a running deployment, collects what is needed
+// to decide about an upgrade and checks the upgrade, for a one-call
+// "can this deployment be upgraded to X?" in scripts.
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+	"github.com/arangodb/go-upgrade-rules/preconditions"
+)
+
+// Report is the result of probing a deployment for an upgrade.
+type Report struct {
+	// Version is the version the deployment runs
+	Version upgraderules.VersionString `json:"version"`
+	// License is the license of the deployment
+	License upgraderules.License `json:"license"`
+	// Role is the role of the server connected to, e.g. Coordinator
+	Role driver.ServerRole `json:"role"`
+	// Mode is the mode of the server connected to, e.g. readonly
+	Mode driver.ServerMode `json:"mode"`
+	// Engine is the storage engine of the deployment
+	Engine driver.EngineType `json:"engine,omitempty"`
+	// Health is the health of the cluster, nil for single servers
+	Health *preconditions.HealthSnapshot `json:"health,omitempty"`
+	// Decision is the decision about the upgrade, including the
+	// preconditions
+	Decision upgraderules.Decision `json:"decision"`
+	// Readiness is the state of every precondition of the upgrade
+	Readiness upgraderules.ReadinessReport `json:"readiness"`
+}
+
+// Allowed returns true when the deployment can be upgraded.
+func (r Report) Allowed() bool {
+	return r.Decision.Allowed()
+}
+
+// engineInfoer is implemented by a driver.Database that can report the
+// storage engine.
+type engineInfoer interface {
+	EngineInfo(ctx context.Context) (driver.EngineInfo, error)
+}
+
+// Probe collects the version, license, role, mode, storage engine and (for
+// clusters) health of the deployment reachable with the given client, then
+// checks the upgrade to the given version with its license.
+// Clusters must additionally be healthy (see preconditions.ClusterHealthy).
+// The given options are applied after those set by the probe, e.g. to add
+// a policy or more preconditions.
+// An error is only returned when the deployment cannot be probed; a denied
+// upgrade is reported in the Decision. Errors caused by the network or a
+// server error (5xx) are retryable, see upgraderules.IsRetryable.
+func Probe(ctx context.Context, c driver.Client, to upgraderules.VersionString, opts ...upgraderules.Option) (Report, error) {
+	info, err := c.Version(ctx)
+	if err != nil {
+		return Report{}, probeError("get version", err)
+	}
+	// Versions before 3.5 do not report the license
+	license := upgraderules.LicenseCommunity
+	if info.License != "" {
+		if license, err = upgraderules.ParseLicense(info.License); err != nil {
+			return Report{}, err
+		}
+	}
+	r := Report{Version: upgraderules.VersionString(info.Version), License: license}
+	if r.Role, err = c.ServerRole(ctx); err != nil {
+		return Report{}, probeError("get server role", err)
+	}
+	if r.Mode, err = c.ServerMode(ctx); err != nil {
+		return Report{}, probeError("get server mode", err)
+	}
+	db, err := c.Database(ctx, "_system")
+	if err != nil {
+		return Report{}, probeError("open database _system", err)
+	}
+	if e, ok := db.(engineInfoer); ok {
+		engine, err := e.EngineInfo(ctx)
+		if err != nil {
+			return Report{}, probeError("get storage engine", err)
+		}
+		r.Engine = engine.Type
+	}
+	all := []upgraderules.Option{
+		upgraderules.WithContext(ctx),
+		upgraderules.WithLicenses(license, license),
+	}
+	if r.Role == driver.ServerRoleCoordinator {
+		health, err := preconditions.NewDriverHealthSource(c).Health(ctx, upgraderules.DeploymentInfo{})
+		if err != nil {
+			return Report{}, probeError("get cluster health", err)
+		}
+		r.Health = &health
+		collected := preconditions.HealthSourceFunc(func(context.Context, upgraderules.DeploymentInfo) (preconditions.HealthSnapshot, error) {
+			return health, nil
+		})
+		all = append(all, upgraderules.WithPrecondition(preconditions.NewClusterHealthy(collected)))
+	}
+	all = append(all, opts...)
+	r.Decision = upgraderules.Check(r.Version, to, all...)
+	r.Readiness = upgraderules.Readiness(r.Version, to, all...)
+	return r, nil
+}
+
+// probeError returns the given error of the driver, failing to do what,
+// marked retryable when it is caused by the network or a server error (5xx).
+func probeError(what string, err error) error {
+	wrapped := fmt.Errorf("Failed to %s: %w", what, err)
+	if ae, ok := driver.AsArangoError(err); ok {
+		if ae.Code >= 500 {
+			return upgraderules.MarkRetryable(wrapped)
+		}
+		return wrapped
+	}
+	var ne net.Error
+	if errors.As(err, &ne) {
+		return upgraderules.MarkRetryable(wrapped)
+	}
+	return wrapped
+}
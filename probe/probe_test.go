@@ -0,0 +1,137 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package probe
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	driver "github.com/arangodb/go-driver"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+type testDatabase struct {
+	driver.Database
+	engine driver.EngineType
+}
+
+func (db testDatabase) EngineInfo(ctx context.Context) (driver.EngineInfo, error) {
+	return driver.EngineInfo{Type: db.engine}, nil
+}
+
+type testCluster struct {
+	driver.Cluster
+	health driver.ClusterHealth
+}
+
+func (c testCluster) Health(ctx context.Context) (driver.ClusterHealth, error) {
+	return c.health, nil
+}
+
+// testClient implements the methods of driver.Client used by Probe,
+// calling any other method panics.
+type testClient struct {
+	driver.Client
+	version driver.VersionInfo
+	err     error
+	role    driver.ServerRole
+	cluster *testCluster
+}
+
+func (c testClient) Version(ctx context.Context) (driver.VersionInfo, error) {
+	return c.version, c.err
+}
+
+func (c testClient) ServerRole(ctx context.Context) (driver.ServerRole, error) {
+	return c.role, nil
+}
+
+func (c testClient) ServerMode(ctx context.Context) (driver.ServerMode, error) {
+	return driver.ServerModeDefault, nil
+}
+
+func (c testClient) Database(ctx context.Context, name string) (driver.Database, error) {
+	return testDatabase{engine: driver.EngineTypeRocksDB}, nil
+}
+
+func (c testClient) Cluster(ctx context.Context) (driver.Cluster, error) {
+	if c.cluster == nil {
+		return nil, errors.New("not a cluster")
+	}
+	return c.cluster, nil
+}
+
+func (c testClient) Databases(ctx context.Context) ([]driver.Database, error) {
+	return nil, nil
+}
+
+func TestProbeSingle(t *testing.T) {
+	c := testClient{version: driver.VersionInfo{Version: "3.11.8", License: "enterprise"}, role: driver.ServerRoleSingle}
+	r, err := Probe(context.Background(), c, "3.12.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Allowed() || r.License != upgraderules.LicenseEnterprise || r.Engine != driver.EngineTypeRocksDB || r.Health != nil {
+		t.Errorf("Unexpected report %+v", r)
+	}
+	if r, _ := Probe(context.Background(), c, "3.13.1"); r.Allowed() || r.Decision.Rule != upgraderules.RuleMinorIncrement {
+		t.Errorf("Expected upgrade to be denied, got %v", r.Decision.Err)
+	}
+}
+
+func TestProbeCluster(t *testing.T) {
+	cluster := &testCluster{health: driver.ClusterHealth{Health: map[driver.ServerID]driver.ServerHealth{
+		"AGNT-1": {ShortName: "Agent0001", Role: driver.ServerRoleAgent, Status: driver.ServerStatusGood},
+		"PRMR-1": {ShortName: "DBServer0001", Role: driver.ServerRoleDBServer, Status: driver.ServerStatusFailed},
+	}}}
+	c := testClient{version: driver.VersionInfo{Version: "3.11.8"}, role: driver.ServerRoleCoordinator, cluster: cluster}
+	r, err := Probe(context.Background(), c, "3.12.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Allowed() || r.Health == nil || r.License != upgraderules.LicenseCommunity {
+		t.Errorf("Expected unhealthy cluster to be denied, got %+v", r)
+	}
+	if len(r.Readiness.Items) != 1 || r.Readiness.Items[0].Status != upgraderules.PreconditionUnmet {
+		t.Errorf("Expected unmet cluster-healthy precondition, got %+v", r.Readiness.Items)
+	}
+}
+
+func TestProbeErrors(t *testing.T) {
+	tests := []struct {
+		Err       error
+		Retryable bool
+	}{
+		{driver.ArangoError{HasError: true, Code: 503, ErrorMessage: "service unavailable"}, true},
+		{driver.ArangoError{HasError: true, Code: 401, ErrorMessage: "not authorized"}, false},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{errors.New("invalid response"), false},
+	}
+	for _, test := range tests {
+		_, err := Probe(context.Background(), testClient{err: test.Err}, "3.12.1")
+		if !errors.Is(err, test.Err) || upgraderules.IsRetryable(err) != test.Retryable {
+			t.Errorf("Expected %v to be wrapped with retryable=%t, got %v", test.Err, test.Retryable, err)
+		}
+	}
+}
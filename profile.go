@@ -0,0 +1,84 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+)
+
+// Profile is a named, built-in policy for a type of environment.
+type Profile string
+
+const (
+	// ProfileProduction uses the strict rules and only allows upgrades to
+	// generally available releases. It has no preconditions, since they
+	// depend on the deployment: the caller must add them with
+	// WithPrecondition, e.g. a recent backup (see package preconditions).
+	ProfileProduction Profile = "production"
+	// ProfileStaging uses the strict rules and also allows upgrades to
+	// pre-releases, to test release candidates before production, even
+	// after the release itself was installed.
+	ProfileStaging Profile = "staging"
+	// ProfileDevelopment uses the soft rules, allowing to jump multiple
+	// minor versions, and allows pre-releases. Like the other profiles, it
+	// does not allow going from Enterprise to Community.
+	ProfileDevelopment Profile = "development"
+)
+
+// Profiles returns all built-in profiles.
+func Profiles() []Profile {
+	return []Profile{ProfileProduction, ProfileStaging, ProfileDevelopment}
+}
+
+// ParseProfile converts the name of a profile into a Profile.
+func ParseProfile(name string) (Profile, error) {
+	for _, p := range Profiles() {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown profile '%s'", name)
+}
+
+// Policy returns the policy of the profile.
+// It returns the zero Policy for an unknown profile.
+func (p Profile) Policy() Policy {
+	switch p {
+	case ProfileProduction:
+		return Policy{Channels: []Channel{ChannelGA}}
+	case ProfileStaging:
+		return Policy{Channels: []Channel{ChannelGA, ChannelPreRelease}, AllowGAToPreRelease: true}
+	case ProfileDevelopment:
+		return Policy{
+			Soft:                true,
+			Channels:            []Channel{ChannelGA, ChannelPreRelease},
+			AllowGAToPreRelease: true,
+		}
+	default:
+		return Policy{}
+	}
+}
+
+// WithProfile applies the policy of the given profile.
+// Options given after it refine the profile, e.g. add preconditions.
+func WithProfile(p Profile) Option {
+	return WithPolicy(p.Policy())
+}
@@ -0,0 +1,87 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseProfile(t *testing.T) {
+	for _, p := range Profiles() {
+		parsed, err := ParseProfile(string(p))
+		if err != nil || parsed != p {
+			t.Errorf("ParseProfile(%s) returned %s, %v", p, parsed, err)
+		}
+	}
+	if _, err := ParseProfile("qa"); err == nil {
+		t.Error("Expected unknown profile to fail")
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	tests := []struct {
+		From, To string
+		Licensed bool
+		Allowed  map[Profile]bool
+	}{
+		{"3.11.8", "3.12.1", false, map[Profile]bool{ProfileProduction: true, ProfileStaging: true, ProfileDevelopment: true}},
+		{"3.10.8", "3.12.1", false, map[Profile]bool{ProfileProduction: false, ProfileStaging: false, ProfileDevelopment: true}},
+		{"3.11.8", "3.12.0-rc.1", false, map[Profile]bool{ProfileProduction: false, ProfileStaging: true, ProfileDevelopment: true}},
+		{"3.12.0", "3.12.0-rc.2", false, map[Profile]bool{ProfileProduction: false, ProfileStaging: true, ProfileDevelopment: true}},
+		{"3.12.0-rc.2", "3.12.0", false, map[Profile]bool{ProfileProduction: true, ProfileStaging: true, ProfileDevelopment: true}},
+		{"3.11.8", "3.12.1", true, map[Profile]bool{ProfileProduction: false, ProfileStaging: false, ProfileDevelopment: false}},
+	}
+	for _, test := range tests {
+		for p, allowed := range test.Allowed {
+			opts := []Option{WithProfile(p)}
+			if test.Licensed {
+				opts = append(opts, WithLicenses(LicenseEnterprise, LicenseCommunity))
+			}
+			if d := Check(VersionString(test.From), VersionString(test.To), opts...); d.Allowed() != allowed {
+				t.Errorf("%s: %s -> %s (licensed %t): expected allowed=%t, got %s", p, test.From, test.To, test.Licensed, allowed, d.Err)
+			}
+		}
+	}
+	for _, p := range Profiles() {
+		if d := Check("3.11.8", "3.12.1", WithProfile(p), WithLicenses(LicenseCommunity, LicenseEnterprise)); !d.Allowed() {
+			t.Errorf("%s: expected Community to Enterprise to be allowed, got %s", p, d.Err)
+		}
+	}
+}
+
+func TestProfileRefinedByOptions(t *testing.T) {
+	backup := &testPrecondition{name: "backup"}
+	d := Check("3.11.8", "3.12.1", WithProfile(ProfileProduction), WithPrecondition(backup))
+	if !d.Allowed() || len(backup.infos) != 1 {
+		t.Errorf("Expected precondition to be evaluated, got %+v", d)
+	}
+	if p := ProfileProduction.Policy(); len(p.Preconditions) > 0 {
+		t.Errorf("Expected the caller to add the preconditions, got %v", p.Preconditions)
+	}
+	backup.err = errors.New("No recent backup")
+	if d := Check("3.11.8", "3.12.1", WithProfile(ProfileProduction), WithPrecondition(backup)); d.Rule != RulePreconditions {
+		t.Errorf("Expected denial by %s, got %q", RulePreconditions, d.Rule)
+	}
+	if p := Profile("unknown").Policy(); p.Soft || len(p.Channels) > 0 {
+		t.Errorf("Expected zero policy for unknown profile, got %+v", p)
+	}
+}
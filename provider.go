@@ -0,0 +1,141 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// RuleProvider provides a pack of optional rules, e.g. rules specific to
+// Kubernetes deployments, that live in a separate package.
+// See RegisterRuleProvider.
+type RuleProvider interface {
+	// Name identifies the provider, e.g. "k8s"
+	Name() string
+	// Rules returns the rules of the provider, evaluated in the given
+	// order after the built-in rules on versions and licenses, and before
+	// the built-in policy rules (e.g. RuleMaintenanceWindow).
+	Rules() []Rule
+}
+
+// providers holds the registered rule providers.
+var providers struct {
+	mutex sync.Mutex
+	list  []RuleProvider
+	// sets holds the built-in rule sets extended with the rules of all
+	// registered providers, as a providedRuleSets.
+	sets atomic.Value
+}
+
+// providedRuleSets maps the built-in rule sets to the same sets extended
+// with the rules of the registered providers.
+type providedRuleSets map[*RuleSet]*RuleSet
+
+// RegisterRuleProvider adds the rules of the given provider to the
+// built-in rules, i.e. to DefaultRuleSet and DefaultRuleSetFor.
+// It is intended to be called from the init function of the package
+// implementing the provider, so the rules are only loaded when that
+// package is imported. Rule sets given with WithRuleSet are not affected.
+//
+// RegisterRuleProvider panics when a provider with the same name is
+// already registered, or when a rule ID is already in use.
+func RegisterRuleProvider(p RuleProvider) {
+	providers.mutex.Lock()
+	defer providers.mutex.Unlock()
+	if p == nil {
+		panic("upgraderules: RegisterRuleProvider provider is nil")
+	}
+	ids := make(map[RuleID]string)
+	for _, r := range defaultRuleSet.rules {
+		ids[r.ID] = "built-in rules"
+	}
+	for _, existing := range providers.list {
+		if existing.Name() == p.Name() {
+			panic(fmt.Sprintf("upgraderules: RegisterRuleProvider called twice for provider %s", p.Name()))
+		}
+		for _, r := range existing.Rules() {
+			ids[r.ID] = "provider " + existing.Name()
+		}
+	}
+	for _, r := range p.Rules() {
+		if owner, found := ids[r.ID]; found {
+			panic(fmt.Sprintf("upgraderules: rule %s of provider %s is already provided by %s", r.ID, p.Name(), owner))
+		}
+		ids[r.ID] = "provider " + p.Name()
+	}
+	providers.list = append(providers.list, p)
+	storeProvidedRuleSets(providers.list)
+}
+
+// RuleProviders returns the names of the registered rule providers, in
+// order of registration.
+func RuleProviders() []string {
+	providers.mutex.Lock()
+	defer providers.mutex.Unlock()
+	names := make([]string, 0, len(providers.list))
+	for _, p := range providers.list {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+// storeProvidedRuleSets extends the built-in rule sets with the rules of
+// the given providers. The mutex of providers must be held.
+func storeProvidedRuleSets(list []RuleProvider) {
+	sets := providedRuleSets{}
+	if len(list) == 0 {
+		providers.sets.Store(sets)
+		return
+	}
+	var rules []Rule
+	for _, p := range list {
+		rules = append(rules, p.Rules()...)
+	}
+	extend := func(s *RuleSet) {
+		at := len(s.rules)
+		for i, r := range s.rules {
+			if isPolicyRule(r.ID) {
+				at = i
+				break
+			}
+		}
+		extended := append(append(append([]Rule(nil), s.rules[:at]...), rules...), s.rules[at:]...)
+		sets[s] = &RuleSet{rules: extended, hooks: s.hooks}
+	}
+	extend(defaultRuleSet)
+	for _, s := range majorRuleSets {
+		extend(s)
+	}
+	providers.sets.Store(sets)
+}
+
+// withProvidedRules returns the given built-in rule set extended with the
+// rules of the registered providers.
+func withProvidedRules(s *RuleSet) *RuleSet {
+	if sets, ok := providers.sets.Load().(providedRuleSets); ok {
+		if extended, found := sets[s]; found {
+			return extended
+		}
+	}
+	return s
+}
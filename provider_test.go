@@ -0,0 +1,97 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+)
+
+// testRuleProvider is a RuleProvider with fixed rules.
+type testRuleProvider struct {
+	name  string
+	rules []Rule
+}
+
+func (p testRuleProvider) Name() string  { return p.name }
+func (p testRuleProvider) Rules() []Rule { return p.rules }
+
+// resetRuleProviders removes all registered rule providers.
+func resetRuleProviders() {
+	providers.mutex.Lock()
+	defer providers.mutex.Unlock()
+	providers.list = nil
+	storeProvidedRuleSets(nil)
+}
+
+func TestRegisterRuleProvider(t *testing.T) {
+	defer resetRuleProviders()
+	noPatchZero := Rule{
+		ID:      "no-patch-zero",
+		Applies: func(in RuleInput) bool { return in.From.Minor() != in.To.Minor() },
+		Check: func(in RuleInput) error {
+			if patch, ok := in.To.Patch(); ok && patch == 0 {
+				return errors.New("Patch 0 is not supported")
+			}
+			return nil
+		},
+	}
+	RegisterRuleProvider(testRuleProvider{name: "test", rules: []Rule{noPatchZero}})
+	if names := RuleProviders(); len(names) != 1 || names[0] != "test" {
+		t.Errorf("Expected provider test, got %v", names)
+	}
+	rules := DefaultRuleSet().Rules()
+	if at := len(defaultRuleSet.rules) - len(policyRules); len(rules) != len(defaultRuleSet.rules)+1 || rules[at].ID != "no-patch-zero" {
+		t.Errorf("Expected provided rule between the version and the policy rules, got %v", rules)
+	}
+	if d := Check("3.11.8", "3.12.0"); d.Rule != "no-patch-zero" {
+		t.Errorf("Expected the provided rule to deny, got %v", d.Err)
+	}
+	if d := Check("3.11.8", "3.12.1"); !d.Allowed() {
+		t.Errorf("Expected upgrade to be allowed, got %v", d.Err)
+	}
+	if d := Check("3.11.8", "3.12.0", WithRuleSet(NewRuleSet(ruleMajorVersion))); !d.Allowed() {
+		t.Errorf("Expected explicit rule set not to include provided rules, got %v", d.Err)
+	}
+	if err := CheckUpgradeRules("3.11.8", "3.12.0"); err == nil || err.Error() != "Patch 0 is not supported (from 3.11.8 to 3.12.0)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestRegisterRuleProviderConflicts(t *testing.T) {
+	defer resetRuleProviders()
+	expectPanic := func(name string, p RuleProvider) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s: expected a panic", name)
+			}
+		}()
+		RegisterRuleProvider(p)
+	}
+	RegisterRuleProvider(testRuleProvider{name: "a", rules: []Rule{{ID: "a-rule"}}})
+	expectPanic("nil", nil)
+	expectPanic("duplicate name", testRuleProvider{name: "a"})
+	expectPanic("duplicate rule", testRuleProvider{name: "b", rules: []Rule{{ID: "a-rule"}}})
+	expectPanic("built-in rule", testRuleProvider{name: "c", rules: []Rule{{ID: RuleMajorVersion}}})
+	if names := RuleProviders(); len(names) != 1 {
+		t.Errorf("Expected only provider a, got %v", names)
+	}
+}
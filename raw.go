@@ -0,0 +1,133 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxRawVersionLength is the maximum length of a version accepted by EvaluateRaw.
+const maxRawVersionLength = 64
+
+// InputError is returned by EvaluateRaw for input that cannot be evaluated.
+type InputError struct {
+	// Field is the name of the invalid argument: "from", "to", "fromLicense",
+	// "toLicense", "fromImage" or "toImage"
+	Field string
+	// Value is the invalid value, truncated to a reasonable length
+	Value string
+	// Reason describes why the value is invalid
+	Reason string
+}
+
+// Error returns a description of the invalid input.
+func (e *InputError) Error() string {
+	return fmt.Sprintf("Invalid %s '%s': %s", e.Field, e.Value, e.Reason)
+}
+
+// EvaluateRaw checks an upgrade given as untrusted strings, e.g. from
+// user input or a fuzzer. The versions must look like <major>.<minor>[.<sub>]
+// with decimal major and minor parts. The licenses are either both empty,
+// in which case they are not part of the check, or both license names
+// (see ParseLicense).
+//
+// EvaluateRaw never panics. Invalid input results in an *InputError; a
+// denied upgrade is not an error, but a Decision with Err set.
+func EvaluateRaw(fromStr, toStr, fromLic, toLic string, opts ...Option) (d Decision, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			d, err = Decision{}, fmt.Errorf("Evaluation of %s -> %s failed: %v", truncateRaw(fromStr), truncateRaw(toStr), r)
+		}
+	}()
+	if err := validateRawVersion("from", fromStr); err != nil {
+		return Decision{}, err
+	}
+	if err := validateRawVersion("to", toStr); err != nil {
+		return Decision{}, err
+	}
+	o := defaultOptions()
+	if fromLic != "" || toLic != "" {
+		fromLicense, err := parseRawLicense("fromLicense", fromLic)
+		if err != nil {
+			return Decision{}, err
+		}
+		toLicense, err := parseRawLicense("toLicense", toLic)
+		if err != nil {
+			return Decision{}, err
+		}
+		o.setLicenses(fromLicense, toLicense)
+	}
+	return check(ParseVersion(VersionString(fromStr)), ParseVersion(VersionString(toStr)), o, opts), nil
+}
+
+// validateRawVersion returns an *InputError when s is not a valid version.
+func validateRawVersion(field, s string) error {
+	invalid := func(reason string) error {
+		return &InputError{Field: field, Value: truncateRaw(s), Reason: reason}
+	}
+	if s == "" {
+		return invalid("version is empty")
+	}
+	if len(s) > maxRawVersionLength {
+		return invalid(fmt.Sprintf("version is longer than %d characters", maxRawVersionLength))
+	}
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '.' || c == '-' || c == '+') {
+			return invalid(fmt.Sprintf("version contains invalid character %q", c))
+		}
+	}
+	parts := strings.SplitN(s, ".", 3)
+	if len(parts) < 2 {
+		return invalid("version must have at least a major and minor part")
+	}
+	for _, p := range parts[:2] {
+		if p == "" || len(p) > 9 || strings.Trim(p, "0123456789") != "" {
+			return invalid("version must have a decimal major and minor part")
+		}
+	}
+	if len(parts) == 3 && parts[2] == "" {
+		return invalid("version has an empty patch part")
+	}
+	return nil
+}
+
+// parseRawLicense parses s, returning an *InputError when it is not a license name.
+func parseRawLicense(field, s string) (License, error) {
+	if s == "" {
+		return LicenseCommunity, &InputError{Field: field, Reason: "licenses must be given together"}
+	}
+	l, err := ParseLicense(s)
+	if err != nil {
+		return LicenseCommunity, &InputError{Field: field, Value: truncateRaw(s), Reason: "unknown license"}
+	}
+	return l, nil
+}
+
+// truncateRaw shortens and escapes untrusted input for use in error messages.
+func truncateRaw(s string) string {
+	if len(s) > maxRawVersionLength {
+		s = s[:maxRawVersionLength] + "..."
+	}
+	q := strconv.Quote(s)
+	return q[1 : len(q)-1]
+}
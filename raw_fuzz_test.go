@@ -0,0 +1,46 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build go1.18
+// +build go1.18
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func FuzzEvaluateRaw(f *testing.F) {
+	f.Add("3.3.8", "3.4.1", "", "")
+	f.Add("3.11.0-rc.1", "3.12.0", "enterprise", "community")
+	f.Add("3.a", "4.0.0", "community", "")
+	f.Fuzz(func(t *testing.T, from, to, fromLic, toLic string) {
+		d, err := EvaluateRaw(from, to, fromLic, toLic)
+		if err != nil {
+			if _, ok := err.(*InputError); !ok {
+				t.Fatalf("Unexpected error type %T: %s", err, err)
+			}
+			return
+		}
+		if d.Err != nil && d.Rule == "" {
+			t.Fatalf("Denied decision without rule: %s", d.Err)
+		}
+	})
+}
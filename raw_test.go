@@ -0,0 +1,85 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluateRaw(t *testing.T) {
+	d, err := EvaluateRaw("3.3.8", "3.4.1", "", "")
+	if err != nil || !d.Allowed() || d.Licensed {
+		t.Errorf("Expected unlicensed 3.3.8 -> 3.4.1 to be allowed, got %v, %v", d.Err, err)
+	}
+	d, err = EvaluateRaw("3.3.8", "3.4.1", "enterprise", "community")
+	if err != nil || d.Allowed() || d.Rule != RuleEditionDowngrade {
+		t.Errorf("Expected denial by %s, got %s (%s), %v", RuleEditionDowngrade, d.Outcome(), d.Rule, err)
+	}
+	d, err = EvaluateRaw("3.3.8", "3.6.0", "", "", WithSoft())
+	if err != nil || !d.Allowed() {
+		t.Errorf("Expected soft 3.3.8 -> 3.6.0 to be allowed, got %v, %v", d.Err, err)
+	}
+}
+
+func TestEvaluateRawInvalidInput(t *testing.T) {
+	tests := []struct {
+		From, To, FromLicense, ToLicense string
+		Field                            string
+	}{
+		{"", "3.4.1", "", "", "from"},
+		{"3", "3.4.1", "", "", "from"},
+		{"3.x.1", "3.4.1", "", "", "from"},
+		{"3.-1.1", "3.4.1", "", "", "from"},
+		{"3.4.", "3.4.1", "", "", "from"},
+		{"3.3.8", "3.4.1 ", "", "", "to"},
+		{"3.3.8", "3.4.1\x00", "", "", "to"},
+		{"3.3.8", "3.99999999999999999999.1", "", "", "to"},
+		{"3.3.8", "3.4." + strings.Repeat("1", 100), "", "", "to"},
+		{"3.3.8", "3.4.1", "enterprise", "", "toLicense"},
+		{"3.3.8", "3.4.1", "gold", "community", "fromLicense"},
+	}
+	for _, test := range tests {
+		_, err := EvaluateRaw(test.From, test.To, test.FromLicense, test.ToLicense)
+		e, ok := err.(*InputError)
+		if !ok {
+			t.Errorf("EvaluateRaw(%q, %q, %q, %q) should return an *InputError, got %v", test.From, test.To, test.FromLicense, test.ToLicense, err)
+			continue
+		}
+		if e.Field != test.Field {
+			t.Errorf("EvaluateRaw(%q, %q, %q, %q) returned error for %s, expected %s", test.From, test.To, test.FromLicense, test.ToLicense, e.Field, test.Field)
+		}
+		if len(e.Value) > 2*maxRawVersionLength {
+			t.Errorf("Error value is not truncated: %s", e.Value)
+		}
+	}
+}
+
+func TestEvaluateRawRecoversPanics(t *testing.T) {
+	panicking := Rule{
+		ID:    "panic",
+		Check: func(in RuleInput) error { panic("boom") },
+	}
+	_, err := EvaluateRaw("3.3.8", "3.4.1", "", "", WithRuleSet(NewRuleSet(panicking)))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected error from recovered panic, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+// PreconditionStatus is the state of a precondition in a ReadinessReport.
+type PreconditionStatus string
+
+const (
+	// PreconditionMet means the precondition is met
+	PreconditionMet PreconditionStatus = "met"
+	// PreconditionUnmet means the precondition is not met
+	PreconditionUnmet PreconditionStatus = "unmet"
+	// PreconditionUnknown means the precondition could not be evaluated,
+	// see PreconditionUnknownError
+	PreconditionUnknown PreconditionStatus = "unknown"
+)
+
+// ReadinessItem is the state of a single precondition.
+type ReadinessItem struct {
+	// Name of the precondition
+	Name string `json:"name"`
+	// Status of the precondition
+	Status PreconditionStatus `json:"status"`
+	// Reason describes why the precondition is unmet or unknown
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReadinessReport is the result of evaluating the preconditions of an
+// upgrade without enforcing them, e.g. to show a pre-upgrade checklist.
+type ReadinessReport struct {
+	From VersionString `json:"from"`
+	To   VersionString `json:"to"`
+	// Items contains one item per applicable precondition, in the
+	// order in which they were given
+	Items []ReadinessItem `json:"items"`
+}
+
+// Ready returns true when all preconditions are met.
+func (r ReadinessReport) Ready() bool {
+	for _, item := range r.Items {
+		if item.Status != PreconditionMet {
+			return false
+		}
+	}
+	return true
+}
+
+// unknownError marks an error of a precondition that could not be evaluated.
+type unknownError struct {
+	err error
+}
+
+// PreconditionUnknownError wraps an error of a Precondition that could not
+// determine whether it is met, e.g. because a backend is unreachable.
+// Checks treat such preconditions as unmet, readiness reports show them as
+// unknown.
+func PreconditionUnknownError(err error) error {
+	return unknownError{err: err}
+}
+
+// Error returns the message of the wrapped error.
+func (e unknownError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e unknownError) Unwrap() error {
+	return e.err
+}
+
+// IsPreconditionUnknown returns true when the given error, or an error it
+// wraps, was created by PreconditionUnknownError.
+func IsPreconditionUnknown(err error) bool {
+	for err != nil {
+		if _, ok := err.(unknownError); ok {
+			return true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// Readiness evaluates all preconditions configured by the given options
+// (see WithPrecondition) that apply to an upgrade from `from` to `to`,
+// without enforcing them. The rules are not evaluated.
+func Readiness(from, to VersionString, opts ...Option) ReadinessReport {
+	o := newOptions(opts)
+	pfrom, pto := ParseVersion(from), ParseVersion(to)
+	info := o.deployment
+	info.From, info.To = from, to
+	info.Licensed, info.FromLicense, info.ToLicense = o.licensed, o.fromLicense, o.toLicense
+	info.Transition = TransitionOf(pfrom, pto)
+	report := ReadinessReport{From: from, To: to, Items: []ReadinessItem{}}
+	for _, p := range o.preconditions {
+		if !p.appliesTo(info.Transition) {
+			continue
+		}
+		item := ReadinessItem{Name: p.precondition.Name(), Status: PreconditionMet}
+		if err := p.precondition.Evaluate(o.ctx, info); err != nil {
+			item.Status, item.Reason = PreconditionUnmet, err.Error()
+			if IsPreconditionUnknown(err) {
+				item.Status = PreconditionUnknown
+			}
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report
+}
@@ -0,0 +1,71 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestReadiness(t *testing.T) {
+	backup := &testPrecondition{name: "backup", err: errors.New("No backup")}
+	health := &testPrecondition{name: "health"}
+	disk := &testPrecondition{name: "disk", err: PreconditionUnknownError(errors.New("Metrics unavailable"))}
+	opts := []Option{
+		WithPrecondition(backup, TransitionMinor),
+		WithPrecondition(health),
+		WithPrecondition(disk),
+	}
+	// The rules deny skipping a minor, readiness ignores that
+	report := Readiness("3.10.1", "3.12.1", opts...)
+	if report.Ready() {
+		t.Error("Expected report not to be ready")
+	}
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal failed: %s", err)
+	}
+	expected := `{"from":"3.10.1","to":"3.12.1","items":[` +
+		`{"name":"backup","status":"unmet","reason":"No backup"},` +
+		`{"name":"health","status":"met"},` +
+		`{"name":"disk","status":"unknown","reason":"Metrics unavailable"}]}`
+	if string(encoded) != expected {
+		t.Errorf("Expected %s, got %s", expected, encoded)
+	}
+	// Unknown preconditions deny upgrades when enforced
+	d := Check("3.12.0", "3.12.1", opts...)
+	if d.Allowed() || len(d.UnmetPreconditions) != 1 || d.UnmetPreconditions[0].Name != "disk" {
+		t.Errorf("Expected unknown precondition to deny the upgrade, got %v", d.Err)
+	}
+	if report := Readiness("3.12.0", "3.12.1", WithPrecondition(health)); !report.Ready() || len(report.Items) != 1 {
+		t.Errorf("Expected ready report, got %+v", report)
+	}
+}
+
+func TestIsPreconditionUnknown(t *testing.T) {
+	if IsPreconditionUnknown(errors.New("x")) || IsPreconditionUnknown(nil) {
+		t.Error("Plain errors are not unknown")
+	}
+	if !IsPreconditionUnknown(retryableError{PreconditionUnknownError(errors.New("x"))}) {
+		t.Error("Wrapped unknown errors are unknown")
+	}
+}
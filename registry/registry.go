@@ -0,0 +1,454 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package registry implements upgraderules.ImageResolver by inspecting
+// images in their registry using the OCI distribution API.
+// It uses the annotations of the image manifest and the labels of the
+// image configuration to determine the version and edition of ArangoDB.
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+const (
+	// DefaultVersionLabel is the label or annotation read for the version
+	DefaultVersionLabel = "org.opencontainers.image.version"
+	// DefaultEditionLabel is the label or annotation read for the edition.
+	// Its value is a license name, see upgraderules.ParseLicense.
+	DefaultEditionLabel = "com.arangodb.edition"
+	// DefaultTTL is the time for which resolved tags are cached
+	DefaultTTL = 5 * time.Minute
+
+	// dockerHub is the registry of images without an explicit registry
+	dockerHub = "registry-1.docker.io"
+	// dockerHubAuth issues the bearer tokens of dockerHub
+	dockerHubAuth = "auth.docker.io"
+)
+
+// Media types of manifests
+const (
+	mediaTypeOCIManifest    = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex       = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerList     = "application/vnd.docker.distribution.manifest.list.v2+json"
+)
+
+// Credentials returns the username and password for the given registry
+// host, or empty strings for anonymous access.
+type Credentials func(registry string) (username, password string, err error)
+
+// Resolver resolves images by inspecting them in their registry.
+// Resolved images are cached: images referenced by digest forever,
+// images referenced by tag for TTL.
+// It is safe for concurrent use. The zero value is ready to use
+// with anonymous access over HTTPS.
+type Resolver struct {
+	// Client is the HTTP client, http.DefaultClient if nil
+	Client *http.Client
+	// Credentials for the registries, anonymous access if nil
+	Credentials Credentials
+	// VersionLabel is read for the version, DefaultVersionLabel if empty
+	VersionLabel string
+	// EditionLabel is read for the edition, DefaultEditionLabel if empty
+	EditionLabel string
+	// TTL for which resolved tags are cached, DefaultTTL if 0.
+	// A negative TTL disables caching of tags.
+	TTL time.Duration
+	// Now returns the current time, time.Now if nil
+	Now func() time.Time
+	// TokenHosts holds, by registry host, the other hosts that may issue
+	// bearer tokens for the registry. Without an entry, tokens are only
+	// fetched from the registry itself, and from auth.docker.io for
+	// Docker Hub.
+	TokenHosts map[string][]string
+
+	mutex sync.Mutex
+	cache map[string]cacheEntry
+}
+
+var _ upgraderules.ImageResolver = &Resolver{}
+
+// cacheEntry is a cached resolved image.
+type cacheEntry struct {
+	resolved upgraderules.ResolvedImage
+	expires  time.Time // zero for images referenced by digest
+}
+
+// manifest contains the fields used of image manifests and indexes.
+type manifest struct {
+	MediaType   string            `json:"mediaType"`
+	Annotations map[string]string `json:"annotations"`
+	Config      struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// imageConfig contains the fields used of image configurations.
+type imageConfig struct {
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// ResolveImage returns the version and edition of the given image.
+// The edition is only set when the image has an edition label.
+// Network failures and server errors (5xx) of the registry are retryable,
+// see upgraderules.IsRetryable.
+func (r *Resolver) ResolveImage(ctx context.Context, img upgraderules.Image) (upgraderules.ResolvedImage, error) {
+	key := img.String()
+	if resolved, found := r.cached(key); found {
+		return resolved, nil
+	}
+	s := &session{r: r, ctx: ctx, host: img.Registry, repository: img.Repository}
+	if s.host == "" || s.host == "docker.io" {
+		s.host = dockerHub
+		if !strings.Contains(s.repository, "/") {
+			s.repository = "library/" + s.repository
+		}
+	}
+	reference := img.Digest
+	if reference == "" {
+		reference = img.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+	m, digest, err := s.manifest(reference)
+	if err != nil {
+		return upgraderules.ResolvedImage{}, err
+	}
+	if len(m.Manifests) > 0 {
+		// An index; the platforms contain the same version
+		child := m.Manifests[0].Digest
+		for _, c := range m.Manifests {
+			if c.Platform.OS == "linux" && c.Platform.Architecture == "amd64" {
+				child = c.Digest
+				break
+			}
+		}
+		if m, _, err = s.manifest(child); err != nil {
+			return upgraderules.ResolvedImage{}, err
+		}
+	}
+	labels := m.Annotations
+	if m.Config.Digest != "" {
+		var config imageConfig
+		if _, err := s.get("/blobs/"+m.Config.Digest, "", &config); err != nil {
+			return upgraderules.ResolvedImage{}, err
+		}
+		labels = merge(config.Config.Labels, m.Annotations)
+	}
+	resolved := upgraderules.ResolvedImage{Digest: digest}
+	version := labels[orDefault(r.VersionLabel, DefaultVersionLabel)]
+	if version == "" {
+		return upgraderules.ResolvedImage{}, fmt.Errorf("Image %s has no version label", key)
+	}
+	resolved.Version = upgraderules.VersionString(version)
+	if edition := labels[orDefault(r.EditionLabel, DefaultEditionLabel)]; edition != "" {
+		l, err := upgraderules.ParseLicense(edition)
+		if err != nil {
+			return upgraderules.ResolvedImage{}, fmt.Errorf("Image %s has an invalid edition label: %w", key, err)
+		}
+		resolved.License, resolved.Licensed = l, true
+	}
+	r.store(key, img.Digest != "", resolved)
+	return resolved, nil
+}
+
+// cached returns the resolved image cached for the given key.
+func (r *Resolver) cached(key string) (upgraderules.ResolvedImage, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	e, found := r.cache[key]
+	if !found || (!e.expires.IsZero() && !r.now().Before(e.expires)) {
+		return upgraderules.ResolvedImage{}, false
+	}
+	return e.resolved, true
+}
+
+// store caches the resolved image for the given key.
+func (r *Resolver) store(key string, byDigest bool, resolved upgraderules.ResolvedImage) {
+	ttl := r.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if !byDigest && ttl < 0 {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.cache == nil {
+		r.cache = make(map[string]cacheEntry)
+	}
+	e := cacheEntry{resolved: resolved}
+	if !byDigest {
+		e.expires = r.now().Add(ttl)
+	}
+	r.cache[key] = e
+}
+
+// now returns the current time.
+func (r *Resolver) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// session contains the state of resolving a single image.
+type session struct {
+	r          *Resolver
+	ctx        context.Context
+	host       string
+	repository string
+	auth       string // value of the Authorization header
+}
+
+// manifest fetches the manifest with the given reference and returns it
+// with its digest.
+func (s *session) manifest(reference string) (manifest, string, error) {
+	var m manifest
+	accept := strings.Join([]string{mediaTypeOCIManifest, mediaTypeOCIIndex, mediaTypeDockerManifest, mediaTypeDockerList}, ", ")
+	header, err := s.get("/manifests/"+reference, accept, &m)
+	if err != nil {
+		return manifest{}, "", err
+	}
+	digest := header.Get("Docker-Content-Digest")
+	if digest == "" && strings.HasPrefix(reference, "sha256:") {
+		digest = reference
+	}
+	return m, digest, nil
+}
+
+// get fetches the given path of the repository and decodes the JSON
+// response into result, returning the headers of the response.
+// It authenticates when the registry asks for it.
+func (s *session) get(path, accept string, result interface{}) (http.Header, error) {
+	u := "https://" + s.host + "/v2/" + s.repository + path
+	resp, err := s.do(u, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && s.auth == "" {
+		challenge := resp.Header.Get("Www-Authenticate")
+		resp.Body.Close()
+		if err := s.authenticate(challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = s.do(u, accept); err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp, fmt.Errorf("GET %s returned %s", u, resp.Status))
+	}
+	return resp.Header, json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(result)
+}
+
+// do sends a GET request accepting the given media types.
+func (s *session) do(u, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if s.auth != "" {
+		req.Header.Set("Authorization", s.auth)
+	}
+	resp, err := s.client().Do(req.WithContext(s.ctx))
+	if err != nil {
+		return nil, upgraderules.MarkRetryable(err)
+	}
+	return resp, nil
+}
+
+// statusError returns the given error for an unexpected status of the
+// given response, marked retryable for server errors (5xx).
+func statusError(resp *http.Response, err error) error {
+	if resp.StatusCode >= 500 {
+		return upgraderules.MarkRetryable(err)
+	}
+	return err
+}
+
+// authenticate sets the Authorization header for the given
+// WWW-Authenticate challenge, using a basic or bearer token scheme.
+func (s *session) authenticate(challenge string) error {
+	username, password, err := s.credentials()
+	if err != nil {
+		return err
+	}
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if username == "" {
+			return fmt.Errorf("Registry %s requires credentials", s.host)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(username, password)
+		s.auth = req.Header.Get("Authorization")
+		return nil
+	case "bearer":
+		return s.fetchToken(params, username, password)
+	}
+	return fmt.Errorf("Registry %s uses unsupported authentication %q", s.host, challenge)
+}
+
+// fetchToken fetches a bearer token from the realm of the challenge.
+// The credentials are sent to the realm, so it must use HTTPS, like the
+// registry, and be on a host trusted to issue tokens for the registry.
+func (s *session) fetchToken(params map[string]string, username, password string) error {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme == "" || realm.Host == "" {
+		return fmt.Errorf("Registry %s returned an invalid token realm %q", s.host, params["realm"])
+	}
+	if realm.Scheme != "https" {
+		return fmt.Errorf("Registry %s returned token realm %q that does not use HTTPS", s.host, params["realm"])
+	}
+	if !s.issuesTokens(realm.Hostname()) {
+		return fmt.Errorf("Registry %s returned token realm %q on another host, see Resolver.TokenHosts", s.host, params["realm"])
+	}
+	q := realm.Query()
+	if service := params["service"]; service != "" {
+		q.Set("service", service)
+	}
+	q.Set("scope", "repository:"+s.repository+":pull")
+	realm.RawQuery = q.Encode()
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := s.client().Do(req.WithContext(s.ctx))
+	if err != nil {
+		return upgraderules.MarkRetryable(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp, fmt.Errorf("Token request for %s returned %s", s.host, resp.Status))
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&token); err != nil {
+		return err
+	}
+	bearer := orDefault(token.Token, token.AccessToken)
+	if bearer == "" {
+		return fmt.Errorf("Token request for %s returned no token", s.host)
+	}
+	s.auth = "Bearer " + bearer
+	return nil
+}
+
+// issuesTokens returns true when the given host may issue bearer tokens
+// for the registry.
+func (s *session) issuesTokens(host string) bool {
+	registry := s.host
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		registry = h
+	}
+	if host == registry || (s.host == dockerHub && host == dockerHubAuth) {
+		return true
+	}
+	for _, h := range s.r.TokenHosts[s.host] {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+// credentials returns the credentials for the registry.
+func (s *session) credentials() (string, string, error) {
+	if s.r.Credentials == nil {
+		return "", "", nil
+	}
+	return s.r.Credentials(s.host)
+}
+
+// client returns the HTTP client to use.
+func (s *session) client() *http.Client {
+	if s.r.Client != nil {
+		return s.r.Client
+	}
+	return http.DefaultClient
+}
+
+// parseChallenge splits a WWW-Authenticate header such as
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`
+// into its lower case scheme and parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest := challenge, ""
+	if i := strings.IndexByte(challenge, ' '); i >= 0 {
+		scheme, rest = challenge[:i], challenge[i+1:]
+	}
+	params := make(map[string]string)
+	for _, part := range strings.Split(rest, ",") {
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			params[strings.ToLower(strings.TrimSpace(part[:i]))] = strings.Trim(strings.TrimSpace(part[i+1:]), `"`)
+		}
+	}
+	return strings.ToLower(scheme), params
+}
+
+// merge returns the union of the given maps, later maps taking precedence.
+func merge(maps ...map[string]string) map[string]string {
+	result := make(map[string]string)
+	for _, m := range maps {
+		for k, v := range m {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// orDefault returns s, or def when s is empty.
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,227 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package registry
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// fakeRegistry serves an index for arangodb/enterprise:latest, pointing
+// to an amd64 manifest whose configuration has the version label.
+// Requests must carry a bearer token obtained from /token.
+func fakeRegistry(t *testing.T, requests *int32) *httptest.Server {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, pass, _ := r.BasicAuth(); user != "alice" || pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if scope := r.URL.Query().Get("scope"); scope != "repository:arangodb/enterprise:pull" {
+				t.Errorf("Unexpected scope %q", scope)
+			}
+			w.Write([]byte(`{"token":"t0k3n"}`))
+			return
+		}
+		atomic.AddInt32(requests, 1)
+		if r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.Header().Set("Www-Authenticate", `Bearer realm="`+srv.URL+`/token",service="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/arangodb/enterprise/manifests/latest":
+			if !strings.Contains(r.Header.Get("Accept"), mediaTypeOCIIndex) {
+				t.Errorf("Expected index to be accepted, got %q", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+			w.Write([]byte(`{"mediaType":"` + mediaTypeOCIIndex + `","manifests":[` +
+				`{"digest":"sha256:arm","platform":{"architecture":"arm64","os":"linux"}},` +
+				`{"digest":"sha256:amd","platform":{"architecture":"amd64","os":"linux"}}]}`))
+		case "/v2/arangodb/enterprise/manifests/sha256:amd":
+			w.Write([]byte(`{"mediaType":"` + mediaTypeOCIManifest + `","config":{"digest":"sha256:config"},` +
+				`"annotations":{"com.arangodb.edition":"enterprise"}}`))
+		case "/v2/arangodb/enterprise/blobs/sha256:config":
+			w.Write([]byte(`{"config":{"Labels":{"org.opencontainers.image.version":"3.12.1"}}}`))
+		case "/v2/arangodb/enterprise/manifests/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv
+}
+
+func TestResolveImage(t *testing.T) {
+	var requests int32
+	srv := fakeRegistry(t, &requests)
+	defer srv.Close()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	r := &Resolver{
+		Client: srv.Client(),
+		Credentials: func(registry string) (string, string, error) {
+			return "alice", "secret", nil
+		},
+		Now: func() time.Time { return now },
+	}
+	img, err := upgraderules.ParseImage(strings.TrimPrefix(srv.URL, "https://") + "/arangodb/enterprise:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := r.ResolveImage(context.Background(), img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := upgraderules.ResolvedImage{Version: "3.12.1", License: upgraderules.LicenseEnterprise, Licensed: true, Digest: "sha256:index"}
+	if resolved != expected {
+		t.Errorf("Expected %+v, got %+v", expected, resolved)
+	}
+
+	// Served from the cache until the TTL expires
+	count := atomic.LoadInt32(&requests)
+	if _, err := r.ResolveImage(context.Background(), img); err != nil || atomic.LoadInt32(&requests) != count {
+		t.Errorf("Expected image to be cached, got %d requests, %v", atomic.LoadInt32(&requests)-count, err)
+	}
+	now = now.Add(DefaultTTL)
+	if _, err := r.ResolveImage(context.Background(), img); err != nil || atomic.LoadInt32(&requests) == count {
+		t.Errorf("Expected cached image to expire, %v", err)
+	}
+}
+
+func TestResolveImageErrors(t *testing.T) {
+	var requests int32
+	srv := fakeRegistry(t, &requests)
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	anonymous := &Resolver{Client: srv.Client()}
+	withCredentials := &Resolver{Client: srv.Client(), Credentials: func(string) (string, string, error) { return "alice", "secret", nil }}
+	tests := []struct {
+		Resolver  *Resolver
+		Image     string
+		Retryable bool
+	}{
+		{anonymous, host + "/arangodb/enterprise:latest", false},
+		{withCredentials, host + "/arangodb/enterprise:missing", false},
+		{&Resolver{Client: srv.Client(), Credentials: withCredentials.Credentials, VersionLabel: "version"}, host + "/arangodb/enterprise:latest", false},
+		{withCredentials, host + "/arangodb/enterprise:unavailable", true},
+		{withCredentials, "127.0.0.1:0/arangodb/enterprise:latest", true},
+	}
+	for _, test := range tests {
+		img, _ := upgraderules.ParseImage(test.Image)
+		if _, err := test.Resolver.ResolveImage(context.Background(), img); err == nil {
+			t.Errorf("Expected resolving %s to fail", test.Image)
+		} else if upgraderules.IsRetryable(err) != test.Retryable {
+			t.Errorf("Expected resolving %s to fail with retryable=%t, got %v", test.Image, test.Retryable, err)
+		}
+	}
+}
+
+func TestCheckImageUpgrade(t *testing.T) {
+	var requests int32
+	srv := fakeRegistry(t, &requests)
+	defer srv.Close()
+	r := &Resolver{Client: srv.Client(), Credentials: func(string) (string, string, error) { return "alice", "secret", nil }}
+	to := strings.TrimPrefix(srv.URL, "https://") + "/arangodb/enterprise:latest"
+	d, err := upgraderules.CheckImageUpgrade("arangodb/arangodb:3.11.8", to, upgraderules.WithImageResolver(r))
+	if err != nil || !d.Allowed() || d.To != "3.12.1" || d.ToLicense != upgraderules.LicenseEnterprise {
+		t.Errorf("Unexpected decision %+v, %v", d, err)
+	}
+}
+
+func TestResolveImageInMirror(t *testing.T) {
+	var requests int32
+	srv := fakeRegistry(t, &requests)
+	defer srv.Close()
+	r := &Resolver{Client: srv.Client(), Credentials: func(string) (string, string, error) { return "alice", "secret", nil }}
+	mirror := strings.TrimPrefix(srv.URL, "https://") + "/arangodb"
+	// A chained mirror must not be applied twice
+	mirrors := upgraderules.WithImageMirrors(
+		upgraderules.ImageMirror{Upstream: "docker.io/arangodb", Mirror: mirror},
+		upgraderules.ImageMirror{Upstream: mirror, Mirror: "mirror.invalid/arangodb"},
+	)
+	d, err := upgraderules.CheckImageUpgrade("arangodb/arangodb:3.11.8", "arangodb/enterprise:latest", upgraderules.WithImageResolver(r), mirrors)
+	if err != nil || d.To != "3.12.1" {
+		t.Errorf("Expected image to be resolved in the mirror, got %+v, %v", d, err)
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`)
+	if scheme != "bearer" || params["realm"] != "https://auth.docker.io/token" || params["service"] != "registry.docker.io" {
+		t.Errorf("Unexpected challenge %s %v", scheme, params)
+	}
+	if scheme, _ := parseChallenge(`Basic realm="registry"`); scheme != "basic" {
+		t.Errorf("Expected basic, got %s", scheme)
+	}
+}
+
+// dialing returns a client that sends all requests to the given server,
+// whose certificate is valid for *.example.com.
+func dialing(srv *httptest.Server) *http.Client {
+	c := srv.Client()
+	transport := c.Transport.(*http.Transport).Clone()
+	transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+	}
+	c.Transport = transport
+	return c
+}
+
+func TestFetchToken(t *testing.T) {
+	var requests int32
+	srv := fakeRegistry(t, &requests)
+	defer srv.Close()
+	empty := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer empty.Close()
+	trusting := &Resolver{Client: dialing(srv), TokenHosts: map[string][]string{"registry.example.com": {"auth.example.com"}}}
+	tests := []struct {
+		Resolver *Resolver
+		Realm    string
+		Error    string
+	}{
+		{&Resolver{Client: dialing(srv)}, "https://registry.example.com/token", ""},
+		{trusting, "https://auth.example.com/token", ""},
+		{&Resolver{Client: dialing(srv)}, "https://auth.example.com/token", "on another host"},
+		{trusting, "http://registry.example.com/token", "does not use HTTPS"},
+		{trusting, "/token", "invalid token realm"},
+		{&Resolver{Client: dialing(empty)}, "https://registry.example.com/token", "returned no token"},
+	}
+	for _, test := range tests {
+		s := &session{r: test.Resolver, ctx: context.Background(), host: "registry.example.com", repository: "arangodb/enterprise"}
+		err := s.fetchToken(map[string]string{"realm": test.Realm}, "alice", "secret")
+		if test.Error == "" && (err != nil || s.auth != "Bearer t0k3n") {
+			t.Errorf("Realm %s: expected token, got %q, %v", test.Realm, s.auth, err)
+		} else if test.Error != "" && (err == nil || !strings.Contains(err.Error(), test.Error)) {
+			t.Errorf("Realm %s: expected error %q, got %v", test.Realm, test.Error, err)
+		}
+	}
+}
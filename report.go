@@ -0,0 +1,59 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// DeploymentDecision is the decision for upgrading a specific deployment.
+type DeploymentDecision struct {
+	// Deployment identifies the deployment
+	Deployment string
+	// Decision for upgrading the deployment
+	Decision Decision
+}
+
+// csvHeader is the first row written by WriteCSV.
+var csvHeader = []string{"deployment", "from", "to", "outcome", "rule", "reason"}
+
+// WriteCSV writes the given decisions as CSV, with a header row followed
+// by one row per decision with the columns deployment, from, to,
+// outcome, rule and reason.
+func WriteCSV(w io.Writer, decisions []DeploymentDecision) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, x := range decisions {
+		d := x.Decision
+		reason := ""
+		if d.Err != nil {
+			reason = Reason(d.Err)
+		}
+		if err := cw.Write([]string{x.Deployment, string(d.From), string(d.To), string(d.Outcome()), string(d.Rule), reason}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
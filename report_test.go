@@ -0,0 +1,45 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []DeploymentDecision{
+		{"prod-eu", Check("3.11.8", "3.12.1")},
+		{"prod-us", Check("3.10.8", "3.12.1")},
+		{"dev, test", Check("3.10.8", "3.12.1", WithOverrides(Override{From: "3.10", To: "3.12"}))},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "deployment,from,to,outcome,rule,reason\n" +
+		"prod-eu,3.11.8,3.12.1,allowed,,\n" +
+		"prod-us,3.10.8,3.12.1,denied,minor-increment,Minor versions may only increment by 1\n" +
+		"\"dev, test\",3.10.8,3.12.1,overridden,,\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,70 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"fmt"
+)
+
+// PolicyResolver returns the policy of a deployment, e.g. by looking up
+// the policy of its tenant on a multi-tenant platform.
+type PolicyResolver interface {
+	// ResolvePolicy returns the policy for upgrading the given deployment.
+	// Network failures, and errors marked with MarkRetryable, make the
+	// check retryable (see IsRetryable).
+	ResolvePolicy(ctx context.Context, info DeploymentInfo) (Policy, error)
+}
+
+// PolicyResolverFunc is a function implementing PolicyResolver.
+type PolicyResolverFunc func(ctx context.Context, info DeploymentInfo) (Policy, error)
+
+// ResolvePolicy calls f.
+func (f PolicyResolverFunc) ResolvePolicy(ctx context.Context, info DeploymentInfo) (Policy, error) {
+	return f(ctx, info)
+}
+
+// WithPolicyResolver resolves the policy of every check from the
+// deployment given by WithDeployment, so a single batch (see CheckMany)
+// can apply the policies of different tenants. The options of the
+// resolved policy are applied before all other options of the check.
+// The resolver is called for every check, so it should be fast.
+// Checks whose policy cannot be resolved are denied with the error of
+// the resolver.
+func WithPolicyResolver(r PolicyResolver) Option {
+	return func(o *options) {
+		o.policyResolver = r
+	}
+}
+
+// resolvePolicy returns base with the options of the policy resolved for
+// the deployment of o applied, followed by the given options.
+func resolvePolicy(base, o options, opts []Option, from, to ParsedVersion) (options, error) {
+	info := o.deployment
+	info.From, info.To = from.version, to.version
+	info.Licensed, info.FromLicense, info.ToLicense = o.licensed, o.fromLicense, o.toLicense
+	info.Transition = TransitionOf(from, to)
+	p, err := o.policyResolver.ResolvePolicy(o.ctx, info)
+	if err != nil {
+		return o, markNetworkError(fmt.Errorf("Failed to resolve the policy of deployment '%s': %w", info.Name, err))
+	}
+	return base.apply(p.Options()).apply(opts), nil
+}
@@ -0,0 +1,100 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestWithPolicyResolver(t *testing.T) {
+	policies := map[string]Policy{
+		"relaxed": {Soft: true, Channels: []Channel{ChannelGA, ChannelPreRelease}},
+		"strict":  {Channels: []Channel{ChannelGA}},
+	}
+	unknown := errors.New("unknown tenant")
+	var resolved []DeploymentInfo
+	resolver := WithPolicyResolver(PolicyResolverFunc(func(ctx context.Context, info DeploymentInfo) (Policy, error) {
+		resolved = append(resolved, info)
+		p, found := policies[info.Namespace]
+		if !found {
+			return Policy{}, unknown
+		}
+		return p, nil
+	}))
+	requests := []CheckRequest{
+		{From: "3.10.8", To: "3.12.1", Options: []Option{WithDeployment(DeploymentInfo{Name: "a", Namespace: "relaxed"})}},
+		{From: "3.10.8", To: "3.12.1", Options: []Option{WithDeployment(DeploymentInfo{Name: "b", Namespace: "strict"})}},
+		{From: "3.11.8", To: "3.12.0-rc.1", Options: []Option{WithDeployment(DeploymentInfo{Name: "c", Namespace: "relaxed"})}},
+		{From: "3.11.8", To: "3.12.0-rc.1", Options: []Option{WithDeployment(DeploymentInfo{Name: "d", Namespace: "strict"})}},
+		{From: "3.11.8", To: "3.12.1", Options: []Option{WithDeployment(DeploymentInfo{Name: "e", Namespace: "unknown"})}},
+	}
+	decisions := CheckMany(context.Background(), requests, resolver, WithParallelism(1))
+	expected := []struct {
+		Allowed bool
+		Rule    RuleID
+	}{
+		{true, ""},
+		{false, RuleMinorIncrement},
+		{true, ""},
+		{false, RuleChannel},
+		{false, ""},
+	}
+	for i, d := range decisions {
+		if d.Allowed() != expected[i].Allowed || d.Rule != expected[i].Rule {
+			t.Errorf("Request %d: expected allowed=%t (%s), got %s (%s): %v", i, expected[i].Allowed, expected[i].Rule, d.Outcome(), d.Rule, d.Err)
+		}
+	}
+	if d := decisions[4]; d.Err == nil || d.Err.Error() != "Failed to resolve the policy of deployment 'e': unknown tenant (from 3.11.8 to 3.12.1)" {
+		t.Errorf("Unexpected error %v", d.Err)
+	}
+	if d := decisions[4]; !errors.Is(d.Err, unknown) || IsRetryable(d.Err) {
+		t.Errorf("Expected the terminal error of the resolver to be wrapped, got %#v", d.Err)
+	}
+	if len(resolved) != len(requests) || resolved[0].From != "3.10.8" || resolved[0].To != "3.12.1" || resolved[0].Transition != TransitionMinor {
+		t.Errorf("Unexpected resolved deployments %+v", resolved)
+	}
+}
+
+func TestWithPolicyResolverOptionsOverridePolicy(t *testing.T) {
+	resolver := WithPolicyResolver(PolicyResolverFunc(func(ctx context.Context, info DeploymentInfo) (Policy, error) {
+		return Policy{Licenses: LicenseMatrix{{From: LicenseEnterprise, To: LicenseCommunity}: true}}, nil
+	}))
+	if d := Check("3.11.8", "3.12.1", resolver, WithLicenses(LicenseEnterprise, LicenseCommunity)); !d.Allowed() {
+		t.Errorf("Expected the resolved license matrix to apply, got %v", d.Err)
+	}
+	if d := Check("3.11.8", "3.12.1", resolver, WithLicenseMatrix(nil), WithLicenses(LicenseEnterprise, LicenseCommunity)); d.Rule != RuleEditionDowngrade {
+		t.Errorf("Expected explicit options to override the resolved policy, got %q", d.Rule)
+	}
+}
+
+func TestWithPolicyResolverNetworkError(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	resolver := WithPolicyResolver(PolicyResolverFunc(func(ctx context.Context, info DeploymentInfo) (Policy, error) {
+		return Policy{}, refused
+	}))
+	d := Check("3.11.8", "3.12.1", resolver)
+	if !IsRetryable(d.Err) || !errors.Is(d.Err, refused) {
+		t.Errorf("Expected a retryable network error, got %#v", d.Err)
+	}
+}
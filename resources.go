@@ -0,0 +1,105 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"sort"
+)
+
+// ResourceKind classifies a ResourceHint.
+type ResourceKind string
+
+const (
+	// ResourceMemory is a change of the memory needed
+	ResourceMemory ResourceKind = "memory"
+	// ResourceCPU is a change of the CPU needed, e.g. instruction sets
+	ResourceCPU ResourceKind = "cpu"
+	// ResourceDisk is a change of the disk space or throughput needed
+	ResourceDisk ResourceKind = "disk"
+	// ResourceKernel is a new or changed required kernel setting
+	ResourceKernel ResourceKind = "kernel"
+)
+
+// ResourceHint is a change of the default resource needs introduced by a
+// minor version, to check the capacity of a deployment before upgrading.
+type ResourceHint struct {
+	// Major version that introduced the change
+	Major int `json:"major"`
+	// Minor version that introduced the change
+	Minor int `json:"minor"`
+	// Kind of resource
+	Kind ResourceKind `json:"kind"`
+	// Summary describes the change and how to prepare for it
+	Summary string `json:"summary"`
+}
+
+// resourceHints is the embedded list of resource hints, ordered by version.
+var resourceHints = []ResourceHint{
+	{Major: 3, Minor: 7, Kind: ResourceMemory, Summary: "Deployments migrated from MMFiles use RocksDB, whose block cache (--rocksdb.block-cache-size) and write buffers (--rocksdb.total-write-buffer-size) default to a share of the memory above 2 GB; set them explicitly for containers with memory limits"},
+}
+
+// AllResourceHints returns a copy of the embedded list of resource hints,
+// ordered by version.
+func AllResourceHints() []ResourceHint {
+	return append([]ResourceHint(nil), resourceHints...)
+}
+
+// ResourceHints returns the embedded resource hints of the minor versions
+// an upgrade from `from` to `to` enters, ordered by version.
+// It returns nil for downgrades and upgrades within a minor version.
+func ResourceHints(from, to ParsedVersion) []ResourceHint {
+	return appendResourceHints(nil, resourceHints, from, to)
+}
+
+// WithResourceHints adds resource hints to the embedded ones (or those of
+// the catalog given with WithReleaseCatalog), e.g. for settings specific
+// to an organization. The hints of a decision are ordered by version.
+func WithResourceHints(hints ...ResourceHint) Option {
+	return func(o *options) {
+		o.resourceHints = append(append([]ResourceHint(nil), o.resourceHints...), hints...)
+	}
+}
+
+// appendResourceHints appends the hints of the minor versions an upgrade
+// from `from` to `to` enters to result.
+func appendResourceHints(result, hints []ResourceHint, from, to ParsedVersion) []ResourceHint {
+	for _, h := range hints {
+		if versionBefore(from, h.Major, h.Minor) && !versionBefore(to, h.Major, h.Minor) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// decisionResourceHints returns the base and the extra resource hints
+// of an upgrade from `from` to `to`, ordered by version.
+func decisionResourceHints(base, extra []ResourceHint, from, to ParsedVersion) []ResourceHint {
+	result := appendResourceHints(nil, base, from, to)
+	if len(extra) == 0 {
+		return result
+	}
+	result = appendResourceHints(result, extra, from, to)
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		return a.Major < b.Major || a.Major == b.Major && a.Minor < b.Minor
+	})
+	return result
+}
@@ -0,0 +1,63 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestResourceHints(t *testing.T) {
+	tests := []struct {
+		From, To VersionString
+		Count    int
+	}{
+		{"3.6.4", "3.7.1", 1},
+		{"3.3.23", "3.12.1", 1},
+		{"3.11.8", "3.12.1", 0},
+		{"3.7.1", "3.6.4", 0},
+	}
+	for _, test := range tests {
+		if hints := ResourceHints(ParseVersion(test.From), ParseVersion(test.To)); len(hints) != test.Count {
+			t.Errorf("%s -> %s: expected %d hints, got %v", test.From, test.To, test.Count, hints)
+		}
+	}
+}
+
+func TestAllResourceHints(t *testing.T) {
+	all := AllResourceHints()
+	for i := 1; i < len(all); i++ {
+		prev, h := all[i-1], all[i]
+		if h.Major < prev.Major || h.Major == prev.Major && h.Minor < prev.Minor {
+			t.Errorf("Resource hints are not ordered at %d", i)
+		}
+	}
+}
+
+func TestDecisionResourceHints(t *testing.T) {
+	kernel := ResourceHint{Major: 3, Minor: 5, Kind: ResourceKernel, Summary: "Raise vm.max_map_count"}
+	d := Check("3.4.1", "3.8.0", WithSoft(), WithResourceHints(kernel, ResourceHint{Major: 3, Minor: 12, Kind: ResourceDisk, Summary: "later"}))
+	if len(d.ResourceHints) != 2 || d.ResourceHints[0] != kernel || d.ResourceHints[1].Minor != 7 {
+		t.Errorf("Unexpected hints %v", d.ResourceHints)
+	}
+	if d := Check("3.7.1", "3.7.2", WithResourceHints(kernel)); d.ResourceHints != nil {
+		t.Errorf("Expected no hints within a minor version, got %v", d.ResourceHints)
+	}
+}
@@ -23,9 +23,9 @@
 package upgraderules
 
 import (
+	"context"
 	"fmt"
-
-	driver "github.com/arangodb/go-driver"
+	"time"
 )
 
 // License is a strongly typed ArangoDB license type
@@ -38,25 +38,37 @@ const (
 	LicenseEnterprise
 )
 
+// String returns the name of the license ("community" or "enterprise").
+func (l License) String() string {
+	switch l {
+	case LicenseCommunity:
+		return "community"
+	case LicenseEnterprise:
+		return "enterprise"
+	default:
+		return fmt.Sprintf("License(%d)", int(l))
+	}
+}
+
+// ParseLicense converts the name of a license (as returned by License.String)
+// into a License.
+func ParseLicense(name string) (License, error) {
+	switch name {
+	case "community":
+		return LicenseCommunity, nil
+	case "enterprise":
+		return LicenseEnterprise, nil
+	default:
+		return LicenseCommunity, fmt.Errorf("Unknown license '%s'", name)
+	}
+}
+
 // CheckUpgradeRules checks if it is allowed to upgrade an ArangoDB
 // deployment from given `from` version to given `to` version.
 // If this is allowed, nil is returned, otherwise and error is
 // returning describing why the upgrade is not allowed.
-func CheckUpgradeRules(from, to driver.Version) error {
-	// Image changed, check if change is allowed
-	if from.Major() != to.Major() {
-		// E.g. 3.x -> 4.x, we cannot allow automatically
-		return fmt.Errorf("Major versions are different")
-	}
-	if from.Minor() != to.Minor() {
-		// Only allow upgrade from 3.x to 3.y when y=x+1
-		if from.Minor()+1 != to.Minor() {
-			return fmt.Errorf("Minor versions may only increment by 1")
-		}
-	} else {
-		// Patch version only diff. That is allowed in upgrade & downgrade.
-	}
-	return nil
+func CheckUpgradeRules(from, to VersionString, opts ...Option) error {
+	return check(ParseVersion(from), ParseVersion(to), defaultOptions(), opts).Err
 }
 
 // CheckSoftUpgradeRules checks if it is allowed to upgrade an ArangoDB
@@ -64,21 +76,10 @@ func CheckUpgradeRules(from, to driver.Version) error {
 // If this is allowed, nil is returned, otherwise and error is
 // returning describing why the upgrade is not allowed.
 // This function allows to jump more than one minor version.
-func CheckSoftUpgradeRules(from, to driver.Version) error {
-	// Image changed, check if change is allowed
-	if from.Major() != to.Major() {
-		// E.g. 3.x -> 4.x, we cannot allow automatically
-		return fmt.Errorf("Major versions are different")
-	}
-	if from.Minor() != to.Minor() {
-		// Only allow upgrade from 3.x to 3.y when y > x
-		if from.Minor() > to.Minor() {
-			return fmt.Errorf("Downgrade is not possible")
-		}
-	} else {
-		// Patch version only diff. That is allowed in upgrade & downgrade.
-	}
-	return nil
+func CheckSoftUpgradeRules(from, to VersionString, opts ...Option) error {
+	o := defaultOptions()
+	o.soft = true
+	return check(ParseVersion(from), ParseVersion(to), o, opts).Err
 }
 
 // CheckUpgradeRulesWithLicense checks if it is allowed to upgrade an ArangoDB
@@ -86,11 +87,10 @@ func CheckSoftUpgradeRules(from, to driver.Version) error {
 // If also includes the given `fromLicense` and `toLicense` in this check.
 // If this is allowed, nil is returned, otherwise and error is
 // returning describing why the upgrade is not allowed.
-func CheckUpgradeRulesWithLicense(fromVersion, toVersion driver.Version, fromLicense, toLicense License) error {
-	if fromLicense != toLicense && fromLicense == LicenseEnterprise {
-		return fmt.Errorf("Upgrade from Enterprise to Community edition is not possible")
-	}
-	return CheckUpgradeRules(fromVersion, toVersion)
+func CheckUpgradeRulesWithLicense(fromVersion, toVersion VersionString, fromLicense, toLicense License, opts ...Option) error {
+	o := defaultOptions()
+	o.setLicenses(fromLicense, toLicense)
+	return check(ParseVersion(fromVersion), ParseVersion(toVersion), o, opts).Err
 }
 
 // CheckUpgradeRulesWithLicense checks if it is allowed to upgrade an ArangoDB
@@ -99,9 +99,224 @@ func CheckUpgradeRulesWithLicense(fromVersion, toVersion driver.Version, fromLic
 // If this is allowed, nil is returned, otherwise and error is
 // returning describing why the upgrade is not allowed.
 // This function allows to jump more than one minor version.
-func CheckSoftUpgradeRulesWithLicense(fromVersion, toVersion driver.Version, fromLicense, toLicense License) error {
-	if fromLicense != toLicense && fromLicense == LicenseEnterprise {
-		return fmt.Errorf("Upgrade from Enterprise to Community edition is not possible")
+func CheckSoftUpgradeRulesWithLicense(fromVersion, toVersion VersionString, fromLicense, toLicense License, opts ...Option) error {
+	o := defaultOptions()
+	o.soft = true
+	o.setLicenses(fromLicense, toLicense)
+	return check(ParseVersion(fromVersion), ParseVersion(toVersion), o, opts).Err
+}
+
+// Rule is a single rule evaluated by Check.
+// Rules are evaluated in the order of their RuleSet, the first rule
+// that denies an upgrade determines the decision.
+type Rule struct {
+	// ID identifies the rule in decisions, errors and traces
+	ID RuleID
+	// Applies returns true when the rule must be evaluated for the given
+	// input. A nil Applies means the rule always applies.
+	Applies func(in RuleInput) bool
+	// Check returns an error when the rule does not allow the upgrade.
+	// It is called concurrently and must not modify shared state.
+	Check func(in RuleInput) error
+	// Overridable is set when an Override can allow an upgrade denied by this rule
+	Overridable bool
+}
+
+// RuleInput is the input of a single rule evaluation.
+// It is passed by value, so evaluating the built-in rules does not allocate.
+type RuleInput struct {
+	// Context of the check, see WithContext
+	Context context.Context
+	// From is the version before the upgrade
+	From ParsedVersion
+	// To is the version after the upgrade
+	To ParsedVersion
+	// Soft is set when the soft rules are requested, see WithSoft
+	Soft bool
+	// Licensed is set when the licenses are part of the check, see WithLicenses
+	Licensed bool
+	// FromLicense is the license before the upgrade
+	FromLicense License
+	// ToLicense is the license after the upgrade
+	ToLicense License
+	// LicenseMatrix holds the allowed license transitions, see WithLicenseMatrix
+	LicenseMatrix LicenseMatrix
+	// MaxMinorSkip is the largest increase of the minor version allowed by
+	// the soft rules, unlimited when 0 (see WithMaxMinorSkip)
+	MaxMinorSkip int
+	// Time at which the check is made, see WithClock
+	Time time.Time
+	// Warnings holds the advisories about the upgrade
+	Warnings []Warning
+	// WarningsAsErrors holds the codes of warnings that deny the upgrade,
+	// see WithWarningsAsErrors
+	WarningsAsErrors []WarningCode
+	// Intermediates holds the mandatory intermediate versions, see WithIntermediates
+	Intermediates []Intermediate
+	// AllowPreRelease is set when upgrades to pre-releases are allowed,
+	// see WithAllowPreRelease
+	AllowPreRelease bool
+	// AllowGAToPreRelease is set when going back from a release to its
+	// pre-release is allowed, see WithAllowGAToPreRelease
+	AllowGAToPreRelease bool
+	// SkipVersions holds the embargoed versions, see WithSkipVersions
+	SkipVersions []VersionString
+	// Architectures holds the CPU architectures of the deployment, see WithArchitectures
+	Architectures []Architecture
+	// RequiredFixes holds the fixes the target must contain, see WithRequiredFixes
+	RequiredFixes []RequiredFix
+	// DenyByDefault is set when only the upgrades in AllowList are
+	// allowed, see WithDenyByDefault
+	DenyByDefault bool
+	// AllowList holds the allowed upgrades, see WithDenyByDefault
+	AllowList []Transition
+	// Channels holds the allowed release channels, see WithChannels
+	Channels []Channel
+	// MaintenanceWindows holds the windows of every use of
+	// WithMaintenanceWindows; the check must be inside one window of each
+	MaintenanceWindows [][]MaintenanceWindow
+	// Cooldown holds the minimum times between upgrades, see WithCooldown
+	Cooldown Cooldown
+	// History holds the earlier upgrades of the deployment, see WithHistory
+	History []HistoryEntry
+	// Trace receives the conditions evaluated by the rule, it is nil
+	// unless WithTrace is used
+	Trace *RuleTrace
+
+	// preconditions holds the preconditions of the deployment, see WithPrecondition
+	preconditions []attachedPrecondition
+	// deployment describes the deployment, see WithDeployment
+	deployment DeploymentInfo
+}
+
+// changesVersion returns true when the upgrade changes the version.
+// Most policy rules only apply to such upgrades.
+func (in RuleInput) changesVersion() bool {
+	return in.From.version != in.To.version
+}
+
+// applies returns true when r must be evaluated for the given input.
+func (r Rule) applies(in RuleInput) bool {
+	return r.Applies == nil || r.Applies(in)
+}
+
+// The built-in rules, see DefaultRuleSet.
+var (
+	// ruleEditionDowngrade denies switching from the Enterprise to the
+	// Community edition, or any license transition not in the license
+	// matrix when one is set. It only applies when licenses are part of
+	// the check.
+	ruleEditionDowngrade = Rule{
+		ID:      RuleEditionDowngrade,
+		Applies: func(in RuleInput) bool { return in.Licensed },
+		Check:   checkEditionDowngrade,
+	}
+	// ruleMajorVersion denies changing the major version.
+	ruleMajorVersion = Rule{
+		ID:          RuleMajorVersion,
+		Check:       checkMajorVersion,
+		Overridable: true,
+	}
+	// ruleMinorIncrement denies changing the minor version by anything
+	// but +1. It only applies to the strict rules.
+	ruleMinorIncrement = Rule{
+		ID:          RuleMinorIncrement,
+		Applies:     func(in RuleInput) bool { return !in.Soft },
+		Check:       checkMinorIncrement,
+		Overridable: true,
+	}
+	// ruleMinorDowngrade denies decreasing the minor version.
+	// It only applies to the soft rules.
+	ruleMinorDowngrade = Rule{
+		ID:          RuleMinorDowngrade,
+		Applies:     func(in RuleInput) bool { return in.Soft },
+		Check:       checkMinorDowngrade,
+		Overridable: true,
+	}
+	// ruleMaxMinorSkip denies increasing the minor version by more than
+	// the configured maximum. It only applies to the soft rules with a
+	// maximum.
+	ruleMaxMinorSkip = Rule{
+		ID:          RuleMaxMinorSkip,
+		Applies:     func(in RuleInput) bool { return in.Soft && in.MaxMinorSkip > 0 },
+		Check:       checkMaxMinorSkip,
+		Overridable: true,
+	}
+)
+
+// checkMajorVersion implements RuleMajorVersion.
+func checkMajorVersion(in RuleInput) error {
+	// Image changed, check if change is allowed
+	equal := in.From.major == in.To.major
+	if in.Trace != nil {
+		in.Trace.Condition("major versions are equal", equal, "from.major", in.From.major, "to.major", in.To.major)
+	}
+	if !equal {
+		// E.g. 3.x -> 4.x, we cannot allow automatically
+		return newCausedError(&MajorVersionError{From: in.From.version, To: in.To.version}, RuleMajorVersion, MessageMajorVersionDifferent)
+	}
+	return nil
+}
+
+// checkMinorIncrement implements RuleMinorIncrement.
+func checkMinorIncrement(in RuleInput) error {
+	if !minorCondition(in, "minor versions are equal", in.From.minor == in.To.minor) {
+		// Only allow upgrade from 3.x to 3.y when y=x+1
+		if !minorCondition(in, "minor version increments by 1", in.From.minor+1 == in.To.minor) {
+			return newCausedError(&MinorSkipError{From: in.From.version, To: in.To.version, MaxSkip: 1}, RuleMinorIncrement, MessageMinorIncrementTooLarge)
+		}
+	} else {
+		// Patch version only diff. That is allowed in upgrade & downgrade.
+	}
+	return nil
+}
+
+// checkMinorDowngrade implements RuleMinorDowngrade.
+func checkMinorDowngrade(in RuleInput) error {
+	if !minorCondition(in, "minor versions are equal", in.From.minor == in.To.minor) {
+		// Only allow upgrade from 3.x to 3.y when y > x
+		if !minorCondition(in, "minor version increases", in.From.minor < in.To.minor) {
+			return newCausedError(&DowngradeError{From: in.From.version, To: in.To.version}, RuleMinorDowngrade, MessageDowngradeNotPossible)
+		}
+	} else {
+		// Patch version only diff. That is allowed in upgrade & downgrade.
+	}
+	return nil
+}
+
+// checkMaxMinorSkip implements RuleMaxMinorSkip.
+func checkMaxMinorSkip(in RuleInput) error {
+	if !minorCondition(in, "minor version increases by at most the maximum", in.To.minor-in.From.minor <= in.MaxMinorSkip) {
+		return newCausedError(&MinorSkipError{From: in.From.version, To: in.To.version, MaxSkip: in.MaxMinorSkip}, RuleMaxMinorSkip, MessageMinorSkipTooLarge, in.MaxMinorSkip)
+	}
+	return nil
+}
+
+// checkEditionDowngrade implements RuleEditionDowngrade.
+func checkEditionDowngrade(in RuleInput) error {
+	allowed := in.LicenseMatrix.Allows(in.FromLicense, in.ToLicense)
+	if in.Trace != nil {
+		desc := "edition is kept or upgraded"
+		if in.LicenseMatrix != nil {
+			desc = "license transition is in the license matrix"
+		}
+		in.Trace.Condition(desc, allowed, "from.license", in.FromLicense, "to.license", in.ToLicense)
+	}
+	if !allowed {
+		cause := &LicenseTransitionError{From: in.FromLicense, To: in.ToLicense}
+		if in.FromLicense == LicenseEnterprise && in.ToLicense == LicenseCommunity {
+			return newCausedError(cause, RuleEditionDowngrade, MessageEditionDowngradeNotPossible)
+		}
+		return newCausedError(cause, RuleEditionDowngrade, MessageLicenseTransitionNotAllowed)
+	}
+	return nil
+}
+
+// minorCondition records a condition on the minor versions and returns its result.
+// The values are only boxed when the check is traced.
+func minorCondition(in RuleInput, desc string, result bool) bool {
+	if in.Trace != nil {
+		in.Trace.Condition(desc, result, "from.minor", in.From.minor, "to.minor", in.To.minor)
 	}
-	return CheckSoftUpgradeRules(fromVersion, toVersion)
+	return result
 }
@@ -24,14 +24,12 @@ package upgraderules
 
 import (
 	"testing"
-
-	driver "github.com/arangodb/go-driver"
 )
 
 func TestCheckUpgradeRules(t *testing.T) {
 	tests := []struct {
-		From    driver.Version
-		To      driver.Version
+		From    VersionString
+		To      VersionString
 		Allowed bool
 		Soft bool
 	}{
@@ -49,11 +47,11 @@ func TestCheckUpgradeRules(t *testing.T) {
 		// Same major & minor, different patch
 		{"3.2.2", "3.2.88", true, false},
 		{"3.2.88", "3.2.8", true, false},
-		{"3.2.88", "3.2.rc7", true, false},
+		{"3.2.88", "3.2.rc7", false, false},
 		// Soft
 		{"3.2.1", "3.3.1", true, true},
 		{"3.2.1", "3.4.8", true, true},
-		{"3.2.1", "3.5.rc7", true, true},
+		{"3.2.1", "3.5.rc7", false, true},
 	}
 	for _, test := range tests {
 		checkUpgradeRules := CheckUpgradeRules
@@ -124,3 +122,89 @@ func TestCheckUpgradeRules(t *testing.T) {
 		}
 	}
 }
+
+func TestParseLicense(t *testing.T) {
+	for _, l := range []License{LicenseCommunity, LicenseEnterprise} {
+		parsed, err := ParseLicense(l.String())
+		if err != nil {
+			t.Errorf("ParseLicense(%s) failed: %s", l, err)
+		} else if parsed != l {
+			t.Errorf("ParseLicense(%s) returned %s", l, parsed)
+		}
+	}
+	if _, err := ParseLicense("gold"); err == nil {
+		t.Error("ParseLicense(gold) should fail, got no error")
+	}
+}
+
+func TestCheckUpgradeRulesAllowedDoesNotAllocate(t *testing.T) {
+	checks := map[string]func() error{
+		"strict":       func() error { return CheckUpgradeRules("3.3.8", "3.4.1") },
+		"soft":         func() error { return CheckSoftUpgradeRules("3.3.8", "3.6.1") },
+		"license":      func() error { return CheckUpgradeRulesWithLicense("3.3.8", "3.4.1", LicenseCommunity, LicenseEnterprise) },
+		"soft license": func() error { return CheckSoftUpgradeRulesWithLicense("3.3.8", "3.6.1", LicenseEnterprise, LicenseEnterprise) },
+	}
+	for name, check := range checks {
+		if allocs := testing.AllocsPerRun(100, func() {
+			if err := check(); err != nil {
+				t.Fatalf("%s: unexpected error: %s", name, err)
+			}
+		}); allocs != 0 {
+			t.Errorf("%s: expected no allocations, got %v", name, allocs)
+		}
+	}
+}
+
+func BenchmarkCheckUpgradeRulesAllowed(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CheckUpgradeRules("3.3.8", "3.4.1")
+	}
+}
+
+func BenchmarkCheckUpgradeRulesDenied(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CheckUpgradeRules("3.3.8", "3.5.1")
+	}
+}
+
+func BenchmarkCheckSoftUpgradeRulesWithLicense(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CheckSoftUpgradeRulesWithLicense("3.3.8", "3.6.1", LicenseCommunity, LicenseEnterprise)
+	}
+}
+
+func BenchmarkCheckWithTrace(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Check("3.3.8", "3.4.1", WithTrace())
+	}
+}
+
+func TestWithMaxMinorSkip(t *testing.T) {
+	tests := []struct {
+		From, To VersionString
+		Opts     []Option
+		Rule     RuleID
+	}{
+		{"3.10.8", "3.12.1", []Option{WithSoft(), WithMaxMinorSkip(2)}, ""},
+		{"3.10.8", "3.13.0", []Option{WithSoft(), WithMaxMinorSkip(2)}, RuleMaxMinorSkip},
+		{"3.2.1", "3.12.0", []Option{WithSoft(), WithMaxMinorSkip(2)}, RuleMaxMinorSkip},
+		{"3.12.1", "3.10.8", []Option{WithSoft(), WithMaxMinorSkip(2)}, RuleMinorDowngrade},
+		{"3.2.1", "3.12.0", []Option{WithSoft(), WithMaxMinorSkip(0)}, ""},
+		{"3.10.8", "3.12.1", []Option{WithMaxMinorSkip(2)}, RuleMinorIncrement},
+		{"3.10.8", "3.13.0", []Option{WithSoft(), WithMaxMinorSkip(2), WithOverrides(Override{From: "3.10", To: "3.13", Rules: []RuleID{RuleMaxMinorSkip}})}, ""},
+	}
+	for _, test := range tests {
+		d := Check(test.From, test.To, test.Opts...)
+		if d.Rule != test.Rule {
+			t.Errorf("%s -> %s: expected rule '%s', got '%s' (%v)", test.From, test.To, test.Rule, d.Rule, d.Err)
+		}
+	}
+	err := CheckSoftUpgradeRules("3.10.8", "3.13.0", WithMaxMinorSkip(2))
+	if err == nil || err.Error() != "Minor versions may only increment by up to 2 (from 3.10.8 to 3.13.0)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
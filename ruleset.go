@@ -0,0 +1,196 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"sync/atomic"
+)
+
+// RuleSet is an ordered list of rules evaluated by Check.
+//
+// A RuleSet is immutable once created, so it is safe for concurrent use
+// without locking. Changing the rules means creating a new RuleSet; use an
+// AtomicRuleSet to replace the rules while checks are running.
+type RuleSet struct {
+	rules []Rule
+	hooks []Hooks
+}
+
+// RuleSetSource provides the RuleSet used by a check, see WithRuleSet.
+type RuleSetSource interface {
+	// Snapshot returns the RuleSet to use for a single check.
+	Snapshot() *RuleSet
+}
+
+// defaultRuleSet holds the built-in rules: the rules on the versions and
+// licenses, followed by the policy rules.
+var defaultRuleSet = NewRuleSet(append([]Rule{
+	ruleEditionDowngrade,
+	ruleMajorVersion,
+	ruleMinorIncrement,
+	ruleMinorDowngrade,
+	ruleMaxMinorSkip,
+	ruleMinimumPatch,
+}, policyRules...)...)
+
+// policyRules are the built-in rules that enforce the policy given with
+// options such as WithChannels or WithMaintenanceWindows. Except for
+// RulePreReleaseTarget and RuleGAToPreRelease, they only apply when their
+// option is given. Rules of providers are evaluated before them.
+var policyRules = []Rule{
+	ruleWarningsAsErrors,
+	ruleMandatoryIntermediate,
+	rulePreReleaseTarget,
+	ruleGAToPreRelease,
+	ruleSkippedVersion,
+	ruleArchitecture,
+	ruleRequiredFix,
+	ruleAllowList,
+	ruleChannel,
+	ruleMaintenanceWindow,
+	ruleCooldown,
+	rulePreconditions,
+}
+
+// isPolicyRule returns true when id identifies one of the policyRules.
+func isPolicyRule(id RuleID) bool {
+	for _, r := range policyRules {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRuleSet creates a RuleSet that evaluates the given rules in order.
+func NewRuleSet(rules ...Rule) *RuleSet {
+	return &RuleSet{rules: append([]Rule(nil), rules...)}
+}
+
+// DefaultRuleSet returns the built-in rules, used when WithRuleSet is
+// not given. It includes the rules of registered providers, see
+// RegisterRuleProvider, and the rules enforcing the policy options, e.g.
+// RuleMaintenanceWindow for WithMaintenanceWindows. Derive custom rule
+// sets from it (see RuleSet.WithRule) to keep enforcing those options.
+func DefaultRuleSet() *RuleSet {
+	return withProvidedRules(defaultRuleSet)
+}
+
+// majorRuleSets holds the built-in rules of major versions that differ
+// from defaultRuleSet, by the major version being upgraded from.
+// The rules of a new major version are added here, followed by the
+// policy rules, e.g.
+// 4: NewRuleSet(append([]Rule{ruleEditionDowngrade, ruleMajorVersion, ...}, policyRules...)...).
+var majorRuleSets = map[int]*RuleSet{}
+
+// DefaultRuleSetFor returns the built-in rules for upgrades from the
+// given major version. Without specific rules for the major version,
+// it returns DefaultRuleSet.
+func DefaultRuleSetFor(major int) *RuleSet {
+	if s, found := majorRuleSets[major]; found {
+		return withProvidedRules(s)
+	}
+	return DefaultRuleSet()
+}
+
+// Rules returns a copy of the rules of the set, in order of evaluation.
+func (s *RuleSet) Rules() []Rule {
+	return append([]Rule(nil), s.rules...)
+}
+
+// Rule returns the rule with given ID.
+func (s *RuleSet) Rule(id RuleID) (Rule, bool) {
+	for _, r := range s.rules {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Clone returns a copy of the RuleSet with the same rules and hooks.
+func (s *RuleSet) Clone() *RuleSet {
+	return &RuleSet{rules: s.Rules(), hooks: s.Hooks()}
+}
+
+// WithRule returns a copy of the RuleSet in which r replaces the rule with
+// the same ID, or that evaluates r after all other rules when there is no
+// such rule.
+func (s *RuleSet) WithRule(r Rule) *RuleSet {
+	c := s.Clone()
+	for i, existing := range c.rules {
+		if existing.ID == r.ID {
+			c.rules[i] = r
+			return c
+		}
+	}
+	c.rules = append(c.rules, r)
+	return c
+}
+
+// WithoutRule returns a copy of the RuleSet without the rule with given ID.
+func (s *RuleSet) WithoutRule(id RuleID) *RuleSet {
+	c := &RuleSet{rules: make([]Rule, 0, len(s.rules)), hooks: s.Hooks()}
+	for _, r := range s.rules {
+		if r.ID != id {
+			c.rules = append(c.rules, r)
+		}
+	}
+	return c
+}
+
+// Snapshot returns s itself, since a RuleSet never changes.
+func (s *RuleSet) Snapshot() *RuleSet {
+	return s
+}
+
+// AtomicRuleSet holds a RuleSet that can be replaced while checks are
+// running (copy-on-write). A check reads the current RuleSet once when it
+// starts and evaluates that snapshot, so a concurrent Store never affects
+// a check in progress. Reading the RuleSet does not lock.
+// The zero value holds DefaultRuleSet.
+type AtomicRuleSet struct {
+	v atomic.Value
+}
+
+// NewAtomicRuleSet creates an AtomicRuleSet holding the given RuleSet.
+func NewAtomicRuleSet(s *RuleSet) *AtomicRuleSet {
+	a := &AtomicRuleSet{}
+	a.Store(s)
+	return a
+}
+
+// Snapshot returns the current RuleSet.
+func (a *AtomicRuleSet) Snapshot() *RuleSet {
+	if s, ok := a.v.Load().(*RuleSet); ok {
+		return s
+	}
+	return DefaultRuleSet()
+}
+
+// Store replaces the current RuleSet. Checks that already started keep
+// evaluating the RuleSet they started with.
+func (a *AtomicRuleSet) Store(s *RuleSet) {
+	if s == nil {
+		s = DefaultRuleSet()
+	}
+	a.v.Store(s)
+}
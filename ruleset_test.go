@@ -0,0 +1,221 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDefaultRuleSet(t *testing.T) {
+	var ids []RuleID
+	for _, r := range DefaultRuleSet().Rules() {
+		ids = append(ids, r.ID)
+	}
+	expected := []RuleID{RuleEditionDowngrade, RuleMajorVersion, RuleMinorIncrement, RuleMinorDowngrade, RuleMaxMinorSkip, RuleMinimumPatch,
+		RuleWarningsAsErrors, RuleMandatoryIntermediate, RulePreReleaseTarget, RuleGAToPreRelease, RuleSkippedVersion, RuleArchitecture,
+		RuleRequiredFix, RuleAllowList, RuleChannel, RuleMaintenanceWindow, RuleCooldown, RulePreconditions}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected rules %v, got %v", expected, ids)
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Errorf("Expected rules %v, got %v", expected, ids)
+		}
+	}
+	if _, found := DefaultRuleSet().Rule(RuleMinorIncrement); !found {
+		t.Errorf("Rule %s not found", RuleMinorIncrement)
+	}
+	if _, found := DefaultRuleSet().Rule("unknown"); found {
+		t.Error("Rule unknown should not be found")
+	}
+}
+
+func TestRuleSetIsImmutable(t *testing.T) {
+	rules := []Rule{ruleMajorVersion}
+	s := NewRuleSet(rules...)
+	rules[0] = ruleMinorIncrement
+	s.Rules()[0] = ruleMinorIncrement
+	if r := s.Rules()[0]; r.ID != RuleMajorVersion {
+		t.Errorf("Expected rule %s, got %s", RuleMajorVersion, r.ID)
+	}
+}
+
+func TestWithRuleSet(t *testing.T) {
+	denyAll := Rule{
+		ID:    "deny-all",
+		Check: func(in RuleInput) error { return errors.New("No upgrades today") },
+	}
+	d := Check("3.3.8", "3.3.9", WithRuleSet(NewRuleSet(denyAll)))
+	if d.Allowed() || d.Rule != "deny-all" || d.Err.Error() != "No upgrades today (from 3.3.8 to 3.3.9)" || Reason(d.Err) != "No upgrades today" {
+		t.Errorf("Expected denial by deny-all, got %s (%s): %v", d.Outcome(), d.Rule, d.Err)
+	}
+	// Without the minor increment rule, skipping a minor version is allowed
+	d = Check("3.3.8", "3.5.0", WithRuleSet(NewRuleSet(ruleMajorVersion)))
+	if !d.Allowed() {
+		t.Errorf("Expected 3.3.8 -> 3.5.0 to be allowed, got %s", d.Err)
+	}
+}
+
+func TestAtomicRuleSet(t *testing.T) {
+	var a AtomicRuleSet
+	if a.Snapshot() != DefaultRuleSet() {
+		t.Error("Zero AtomicRuleSet should hold the default rules")
+	}
+	if d := Check("3.3.8", "3.5.0", WithRuleSet(&a)); d.Allowed() {
+		t.Error("Expected 3.3.8 -> 3.5.0 to be denied by the default rules")
+	}
+	a.Store(NewRuleSet(ruleMajorVersion))
+	if d := Check("3.3.8", "3.5.0", WithRuleSet(&a)); !d.Allowed() {
+		t.Errorf("Expected 3.3.8 -> 3.5.0 to be allowed after reload, got %s", d.Err)
+	}
+	a.Store(nil)
+	if a.Snapshot() != DefaultRuleSet() {
+		t.Error("Storing nil should restore the default rules")
+	}
+}
+
+func TestAtomicRuleSetConcurrentReload(t *testing.T) {
+	a := NewAtomicRuleSet(DefaultRuleSet())
+	relaxed := NewRuleSet(ruleMajorVersion)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				d := Check("3.3.8", "3.5.0", WithRuleSet(a))
+				if !d.Allowed() && d.Rule != RuleMinorIncrement {
+					t.Errorf("Unexpected rule %s", d.Rule)
+				}
+			}
+		}()
+	}
+	for j := 0; j < 1000; j++ {
+		if j%2 == 0 {
+			a.Store(relaxed)
+		} else {
+			a.Store(DefaultRuleSet())
+		}
+	}
+	wg.Wait()
+}
+
+func TestDefaultRuleSetFor(t *testing.T) {
+	if DefaultRuleSetFor(3) != DefaultRuleSet() {
+		t.Error("Expected default rules for 3.x")
+	}
+	four := NewRuleSet(ruleMajorVersion)
+	majorRuleSets[4] = four
+	defer delete(majorRuleSets, 4)
+	if DefaultRuleSetFor(4) != four {
+		t.Error("Expected specific rules for 4.x")
+	}
+	// 4.0 -> 4.2 is only denied by the 3.x rules
+	if d := Check("4.0.1", "4.2.0"); !d.Allowed() {
+		t.Errorf("Expected 4.x rules to be selected by the from version, got %s", d.Err)
+	}
+	if d := Check("3.10.1", "3.12.0"); d.Rule != RuleMinorIncrement {
+		t.Errorf("Expected 3.x rules, got %q", d.Rule)
+	}
+}
+
+func TestWithMajorRuleSet(t *testing.T) {
+	opts := []Option{
+		WithRuleSet(NewRuleSet(ruleMajorVersion)),
+		WithMajorRuleSet(4, DefaultRuleSet()),
+	}
+	if d := Check("3.10.1", "3.12.0", opts...); !d.Allowed() {
+		t.Errorf("Expected rules of WithRuleSet for 3.x, got %s", d.Err)
+	}
+	if d := Check("4.0.1", "4.2.0", opts...); d.Rule != RuleMinorIncrement {
+		t.Errorf("Expected rules of WithMajorRuleSet for 4.x, got %q", d.Rule)
+	}
+}
+
+func TestRuleSetWithRule(t *testing.T) {
+	base := DefaultRuleSet()
+	n := len(base.Rules())
+	relaxed := base.WithoutRule(RuleMinorIncrement)
+	if len(relaxed.Rules()) != n-1 {
+		t.Errorf("Expected %d rules, got %d", n-1, len(relaxed.Rules()))
+	}
+	if d := Check("3.10.8", "3.12.1", WithRuleSet(relaxed)); !d.Allowed() {
+		t.Errorf("Expected upgrade to be allowed without %s, got %v", RuleMinorIncrement, d.Err)
+	}
+	if d := Check("3.10.8", "3.12.1"); d.Rule != RuleMinorIncrement {
+		t.Errorf("Expected the default rules to be unchanged, got %q", d.Rule)
+	}
+
+	custom := Rule{ID: "custom", Check: func(in RuleInput) error { return errors.New("Denied") }}
+	extended := base.WithRule(custom)
+	if rules := extended.Rules(); len(rules) != n+1 || rules[n].ID != "custom" || len(base.Rules()) != n {
+		t.Errorf("Expected custom rule to be appended to a copy, got %v", rules)
+	}
+	replaced := extended.WithRule(Rule{ID: "custom", Check: func(in RuleInput) error { return nil }})
+	if rules := replaced.Rules(); len(rules) != n+1 {
+		t.Errorf("Expected custom rule to be replaced, got %v", rules)
+	}
+	if d := Check("3.11.8", "3.12.1", WithRuleSet(replaced)); !d.Allowed() {
+		t.Errorf("Expected replaced rule to allow, got %v", d.Err)
+	}
+	if d := Check("3.11.8", "3.12.1", WithRuleSet(extended)); d.Rule != "custom" {
+		t.Errorf("Expected original custom rule to deny, got %q", d.Rule)
+	}
+
+	hooked := base.WithHooks(Hooks{})
+	if c := hooked.Clone(); c == hooked || len(c.Hooks()) != 1 || len(c.Rules()) != n {
+		t.Error("Expected Clone to copy rules and hooks")
+	}
+	if len(hooked.WithoutRule(RuleMinorIncrement).Hooks()) != 1 || len(hooked.WithRule(custom).Hooks()) != 1 {
+		t.Error("Expected hooks to be kept")
+	}
+}
+
+func TestPolicyRulesInRuleSet(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	opts := []Option{
+		WithClock(func() time.Time { return now }),
+		WithMaintenanceWindows(MustParseMaintenanceWindow("0 2 * * 6", time.Hour, nil)),
+		WithTrace(),
+	}
+	d := Check("3.11.8", "3.12.1", opts...)
+	if d.Rule != RuleMaintenanceWindow {
+		t.Fatalf("Expected %s, got %q", RuleMaintenanceWindow, d.Rule)
+	}
+	if last := d.Trace.Rules[len(d.Trace.Rules)-1]; last.Rule != RuleMaintenanceWindow || last.Allowed {
+		t.Errorf("Expected the trace to end with the denying %s rule, got %+v", RuleMaintenanceWindow, last)
+	}
+	var seen []RuleID
+	hooked := DefaultRuleSet().WithHooks(Hooks{AfterRule: func(r Rule, in RuleInput, err error) error {
+		seen = append(seen, r.ID)
+		return err
+	}})
+	Check("3.11.8", "3.12.1", append(opts, WithRuleSet(hooked))...)
+	if len(seen) == 0 || seen[len(seen)-1] != RuleMaintenanceWindow {
+		t.Errorf("Expected hooks to see %s, got %v", RuleMaintenanceWindow, seen)
+	}
+	if d := Check("3.11.8", "3.12.1", append(opts, WithRuleSet(DefaultRuleSet().WithoutRule(RuleMaintenanceWindow)))...); !d.Allowed() {
+		t.Errorf("Expected the upgrade to be allowed without %s, got %v", RuleMaintenanceWindow, d.Err)
+	}
+}
@@ -0,0 +1,84 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package blang adapts versions of github.com/blang/semver to
+// upgraderules.Version. It is a separate package so the rules
+// themselves do not depend on that library.
+package blang
+
+import (
+	"strings"
+
+	"github.com/blang/semver/v4"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+var _ upgraderules.Version = version{}
+
+// Version returns the given version as an upgraderules.Version.
+func Version(v semver.Version) upgraderules.Version {
+	return version{v}
+}
+
+// version implements upgraderules.Version for a semver.Version.
+type version struct {
+	v semver.Version
+}
+
+// Major returns the major version.
+func (v version) Major() int {
+	return int(v.v.Major)
+}
+
+// Minor returns the minor version.
+func (v version) Minor() int {
+	return int(v.v.Minor)
+}
+
+// Patch returns the patch version.
+func (v version) Patch() int {
+	return int(v.v.Patch)
+}
+
+// PreRelease returns the pre-release identifiers joined by '.'.
+func (v version) PreRelease() string {
+	if len(v.v.Pre) == 0 {
+		return ""
+	}
+	parts := make([]string, len(v.v.Pre))
+	for i, p := range v.v.Pre {
+		parts[i] = p.String()
+	}
+	return strings.Join(parts, ".")
+}
+
+// Compare compares the version with the other version.
+func (v version) Compare(other upgraderules.Version) int {
+	if o, ok := other.(version); ok {
+		return v.v.Compare(o.v)
+	}
+	return upgraderules.CompareVersions(v, other)
+}
+
+// String returns the version.
+func (v version) String() string {
+	return v.v.String()
+}
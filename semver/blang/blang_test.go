@@ -0,0 +1,51 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package blang
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestVersion(t *testing.T) {
+	v := Version(semver.MustParse("3.12.0-rc.2"))
+	if v.Major() != 3 || v.Minor() != 12 || v.Patch() != 0 || v.PreRelease() != "rc.2" {
+		t.Errorf("Unexpected version %d.%d.%d %q", v.Major(), v.Minor(), v.Patch(), v.PreRelease())
+	}
+	if c := v.Compare(Version(semver.MustParse("3.12.0"))); c != -1 {
+		t.Errorf("Expected pre-release to be older than release, got %d", c)
+	}
+	if c := v.Compare(upgraderules.DriverVersion("3.12.0-rc.2")); c != 0 {
+		t.Errorf("Expected equal driver version, got %d", c)
+	}
+}
+
+func TestCheckVersions(t *testing.T) {
+	if d := upgraderules.CheckVersions(Version(semver.MustParse("3.11.8")), Version(semver.MustParse("3.12.1"))); !d.Allowed() {
+		t.Errorf("Expected 3.11.8 -> 3.12.1 to be allowed, got %s", d.Err)
+	}
+	if d := upgraderules.CheckVersions(Version(semver.MustParse("3.10.8")), Version(semver.MustParse("3.12.1"))); d.Rule != upgraderules.RuleMinorIncrement {
+		t.Errorf("Expected 3.10.8 -> 3.12.1 to be denied by %s, got %q", upgraderules.RuleMinorIncrement, d.Rule)
+	}
+}
@@ -0,0 +1,154 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"sort"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// DefaultEnvironmentLabel is the label holding the environment of a
+// deployment when CanaryStrategy.EnvironmentLabel is empty.
+const DefaultEnvironmentLabel = "environment"
+
+// unlabeledWave is the name of the wave of deployments without environment.
+const unlabeledWave = "unlabeled"
+
+// CanaryStrategy configures how a fleet is partitioned into waves.
+type CanaryStrategy struct {
+	// EnvironmentLabel is the label holding the environment of a
+	// deployment, DefaultEnvironmentLabel if empty
+	EnvironmentLabel string
+	// Environments lists the environments in rollout order, e.g.
+	// "dev", "staging", "production". Deployments of other environments
+	// are rolled out last, in alphabetical order of their environment,
+	// with deployments without environment in a wave named "unlabeled".
+	Environments []string
+	// Risk returns the risk score of upgrading a deployment. Deployments
+	// of an environment are rolled out in order of increasing risk, so
+	// the canaries are the deployments with the lowest risk.
+	// The order of the fleet is kept when nil.
+	Risk func(info upgraderules.DeploymentInfo) float64
+	// CanarySize is the number of deployments of each environment that
+	// are upgraded in a separate canary wave before the rest of the
+	// environment. There are no canary waves when 0.
+	CanarySize int
+	// Gate are the criteria that must be met before the next wave starts
+	Gate Gate
+}
+
+// Gate contains the criteria a wave must meet before the next wave starts.
+type Gate struct {
+	// SoakTime is the time the upgraded deployments must run without
+	// problems after the last upgrade of the wave
+	SoakTime time.Duration `json:"soakTime,omitempty"`
+	// MaxFailures is the number of failed upgrades of the wave that
+	// still allow the next wave to start
+	MaxFailures int `json:"maxFailures"`
+}
+
+// Wave is a set of deployments that are upgraded together.
+type Wave struct {
+	// Name of the wave, e.g. "staging-canary" or "staging"
+	Name string `json:"name"`
+	// Environment of the deployments
+	Environment string `json:"environment"`
+	// Canary is set for the canary wave of an environment
+	Canary bool `json:"canary,omitempty"`
+	// Deployments are the names of the deployments of the wave, in order
+	// of increasing risk
+	Deployments []string `json:"deployments"`
+	// Gate must be met before the next wave starts (zero for the last wave)
+	Gate Gate `json:"gate"`
+}
+
+// RolloutPlan is a staged rollout of a fleet.
+type RolloutPlan struct {
+	Waves []Wave `json:"waves"`
+}
+
+// PlanWaves partitions the given fleet into waves: one per environment
+// in rollout order, each preceded by a canary wave when
+// CanarySize is set. Combine it with AssessFleet to leave out deployments
+// that cannot reach the target.
+func PlanWaves(fleet []upgraderules.DeploymentInfo, s CanaryStrategy) RolloutPlan {
+	label := s.EnvironmentLabel
+	if label == "" {
+		label = DefaultEnvironmentLabel
+	}
+	rank := make(map[string]int, len(s.Environments))
+	for i, env := range s.Environments {
+		rank[env] = i
+	}
+	byEnv := make(map[string][]upgraderules.DeploymentInfo)
+	var envs []string
+	for _, info := range fleet {
+		env := info.Labels[label]
+		if _, found := byEnv[env]; !found {
+			envs = append(envs, env)
+		}
+		byEnv[env] = append(byEnv[env], info)
+	}
+	sort.SliceStable(envs, func(i, j int) bool {
+		ri, iKnown := rank[envs[i]]
+		rj, jKnown := rank[envs[j]]
+		switch {
+		case iKnown && jKnown:
+			return ri < rj
+		case iKnown != jKnown:
+			return iKnown
+		}
+		return envs[i] < envs[j]
+	})
+	var plan RolloutPlan
+	for _, env := range envs {
+		deployments := byEnv[env]
+		if s.Risk != nil {
+			sort.SliceStable(deployments, func(i, j int) bool {
+				return s.Risk(deployments[i]) < s.Risk(deployments[j])
+			})
+		}
+		name := env
+		if name == "" {
+			name = unlabeledWave
+		}
+		if s.CanarySize > 0 && len(deployments) > s.CanarySize {
+			plan.Waves = append(plan.Waves, newWave(name+"-canary", env, true, deployments[:s.CanarySize], s.Gate))
+			deployments = deployments[s.CanarySize:]
+		}
+		plan.Waves = append(plan.Waves, newWave(name, env, false, deployments, s.Gate))
+	}
+	if n := len(plan.Waves); n > 0 {
+		plan.Waves[n-1].Gate = Gate{}
+	}
+	return plan
+}
+
+// newWave creates a wave of the given deployments.
+func newWave(name, env string, canary bool, deployments []upgraderules.DeploymentInfo, gate Gate) Wave {
+	w := Wave{Name: name, Environment: env, Canary: canary, Gate: gate}
+	for _, info := range deployments {
+		w.Deployments = append(w.Deployments, info.Name)
+	}
+	return w
+}
@@ -0,0 +1,84 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestPlanWaves(t *testing.T) {
+	deployment := func(name, env string, risk int) upgraderules.DeploymentInfo {
+		return upgraderules.DeploymentInfo{Name: name, Labels: map[string]string{"env": env, "risk": string(rune('0' + risk))}}
+	}
+	fleet := []upgraderules.DeploymentInfo{
+		deployment("p1", "production", 5),
+		deployment("p2", "production", 1),
+		deployment("p3", "production", 3),
+		deployment("s1", "staging", 2),
+		deployment("x1", "lab", 0),
+		deployment("d1", "dev", 9),
+		deployment("d2", "dev", 4),
+		{Name: "unlabeled"},
+	}
+	gate := Gate{SoakTime: 24 * time.Hour, MaxFailures: 0}
+	plan := PlanWaves(fleet, CanaryStrategy{
+		EnvironmentLabel: "env",
+		Environments:     []string{"dev", "staging", "production"},
+		Risk: func(info upgraderules.DeploymentInfo) float64 {
+			return float64(info.Labels["risk"][0] - '0')
+		},
+		CanarySize: 1,
+		Gate:       gate,
+	})
+	expected := []Wave{
+		{Name: "dev-canary", Environment: "dev", Canary: true, Deployments: []string{"d2"}, Gate: gate},
+		{Name: "dev", Environment: "dev", Deployments: []string{"d1"}, Gate: gate},
+		{Name: "staging", Environment: "staging", Deployments: []string{"s1"}, Gate: gate},
+		{Name: "production-canary", Environment: "production", Canary: true, Deployments: []string{"p2"}, Gate: gate},
+		{Name: "production", Environment: "production", Deployments: []string{"p3", "p1"}, Gate: gate},
+		// Environments that are not listed follow in alphabetical order
+		{Name: "unlabeled", Environment: "", Deployments: []string{"unlabeled"}, Gate: gate},
+		{Name: "lab", Environment: "lab", Deployments: []string{"x1"}},
+	}
+	if !reflect.DeepEqual(plan.Waves, expected) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", expected, plan.Waves)
+	}
+	if _, err := json.Marshal(plan); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPlanWavesDefaults(t *testing.T) {
+	fleet := []upgraderules.DeploymentInfo{
+		{Name: "b", Labels: map[string]string{DefaultEnvironmentLabel: "prod"}},
+		{Name: "a", Labels: map[string]string{DefaultEnvironmentLabel: "prod"}},
+	}
+	plan := PlanWaves(fleet, CanaryStrategy{})
+	expected := []Wave{{Name: "prod", Environment: "prod", Deployments: []string{"b", "a"}}}
+	if !reflect.DeepEqual(plan.Waves, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, plan.Waves)
+	}
+}
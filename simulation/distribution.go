@@ -0,0 +1,132 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"sort"
+	"strconv"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// DistributionSpec configures the analysis of the versions of a fleet.
+type DistributionSpec struct {
+	// Latest is the latest version, from which distances are measured
+	Latest upgraderules.VersionString
+	// Versions are the released versions. Their minor versions are
+	// counted by the distance from the latest version. All minor
+	// versions of the major version of Latest up to Latest are assumed
+	// to be released.
+	Versions []upgraderules.VersionString
+	// EndOfLife lists the minor versions (e.g. "3.9") that are end of life
+	EndOfLife []upgraderules.VersionString
+}
+
+// Distribution contains statistics about the versions of a fleet.
+type Distribution struct {
+	// Total is the number of deployments
+	Total int `json:"total"`
+	// Latest is the version distances are measured from
+	Latest upgraderules.VersionString `json:"latest"`
+	// Versions counts the deployments by version
+	Versions map[upgraderules.VersionString]int `json:"versions"`
+	// Minors counts the deployments by minor version, e.g. "3.11"
+	Minors map[string]int `json:"minors"`
+	// EndOfLife is the number of deployments on an end of life version
+	EndOfLife int `json:"endOfLife"`
+	// EndOfLifePercent is the percentage of deployments on an end of life version
+	EndOfLifePercent float64 `json:"endOfLifePercent"`
+	// MedianMinorDistance is the median of the number of minor versions
+	// the deployments are behind the latest version
+	MedianMinorDistance float64 `json:"medianMinorDistance"`
+}
+
+// minorVersion identifies a minor version.
+type minorVersion struct {
+	major, minor int
+}
+
+// String returns the minor version, e.g. "3.11".
+func (m minorVersion) String() string {
+	return strconv.Itoa(m.major) + "." + strconv.Itoa(m.minor)
+}
+
+// less returns true when m is older than other.
+func (m minorVersion) less(other minorVersion) bool {
+	return m.major < other.major || m.major == other.major && m.minor < other.minor
+}
+
+// minorOf returns the minor version of v.
+func minorOf(v upgraderules.VersionString) minorVersion {
+	return minorVersion{v.Major(), v.Minor()}
+}
+
+// AnalyzeDistribution computes statistics about the versions of the
+// given fleet, whose current versions are their From versions.
+func AnalyzeDistribution(fleet []upgraderules.DeploymentInfo, s DistributionSpec) Distribution {
+	result := Distribution{
+		Total:    len(fleet),
+		Latest:   s.Latest,
+		Versions: make(map[upgraderules.VersionString]int),
+		Minors:   make(map[string]int),
+	}
+	latest := minorOf(s.Latest)
+	released := make(map[minorVersion]bool)
+	for i := 0; i <= latest.minor; i++ {
+		released[minorVersion{latest.major, i}] = true
+	}
+	for _, v := range s.Versions {
+		if m := minorOf(v); m.less(latest) {
+			released[m] = true
+		}
+	}
+	eol := make(map[minorVersion]bool)
+	for _, v := range s.EndOfLife {
+		eol[minorOf(v)] = true
+	}
+	distances := make([]int, 0, len(fleet))
+	for _, info := range fleet {
+		m := minorOf(info.From)
+		result.Versions[info.From]++
+		result.Minors[m.String()]++
+		if eol[m] {
+			result.EndOfLife++
+		}
+		distance := 0
+		for r := range released {
+			if m.less(r) && !latest.less(r) {
+				distance++
+			}
+		}
+		distances = append(distances, distance)
+	}
+	if len(fleet) > 0 {
+		result.EndOfLifePercent = 100 * float64(result.EndOfLife) / float64(len(fleet))
+		sort.Ints(distances)
+		mid := len(distances) / 2
+		if len(distances)%2 == 1 {
+			result.MedianMinorDistance = float64(distances[mid])
+		} else {
+			result.MedianMinorDistance = float64(distances[mid-1]+distances[mid]) / 2
+		}
+	}
+	return result
+}
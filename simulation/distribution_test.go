@@ -0,0 +1,73 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"reflect"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestAnalyzeDistribution(t *testing.T) {
+	fleet := []upgraderules.DeploymentInfo{
+		{Name: "a", From: "3.12.1"},
+		{Name: "b", From: "3.11.8"},
+		{Name: "c", From: "3.11.8"},
+		{Name: "d", From: "3.9.5"},
+		{Name: "e", From: "2.8.11"},
+		{Name: "f", From: "3.12.0"},
+	}
+	dist := AnalyzeDistribution(fleet, DistributionSpec{
+		Latest:    "3.12.1",
+		Versions:  []upgraderules.VersionString{"2.8.11"},
+		EndOfLife: []upgraderules.VersionString{"2.8", "3.9"},
+	})
+	expected := Distribution{
+		Total:  6,
+		Latest: "3.12.1",
+		Versions: map[upgraderules.VersionString]int{
+			"3.12.1": 1, "3.12.0": 1, "3.11.8": 2, "3.9.5": 1, "2.8.11": 1,
+		},
+		Minors:           map[string]int{"3.12": 2, "3.11": 2, "3.9": 1, "2.8": 1},
+		EndOfLife:        2,
+		EndOfLifePercent: 100 * 2.0 / 6,
+		// Distances 0, 1, 1, 3, 13, 0: 3.0 - 3.12 are released
+		MedianMinorDistance: 1,
+	}
+	if !reflect.DeepEqual(dist, expected) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", expected, dist)
+	}
+}
+
+func TestAnalyzeDistributionMedian(t *testing.T) {
+	fleet := []upgraderules.DeploymentInfo{{From: "3.10.0"}, {From: "3.12.0"}, {From: "3.11.0"}, {From: "3.11.5"}}
+	if dist := AnalyzeDistribution(fleet, DistributionSpec{Latest: "3.12.1"}); dist.MedianMinorDistance != 1 {
+		t.Errorf("Expected median 1, got %v", dist.MedianMinorDistance)
+	}
+	fleet = fleet[:2]
+	if dist := AnalyzeDistribution(fleet, DistributionSpec{Latest: "3.12.1"}); dist.MedianMinorDistance != 1 {
+		t.Errorf("Expected median 1, got %v", dist.MedianMinorDistance)
+	}
+	if dist := AnalyzeDistribution(nil, DistributionSpec{Latest: "3.12.1"}); dist.Total != 0 || dist.MedianMinorDistance != 0 || dist.EndOfLifePercent != 0 {
+		t.Errorf("Unexpected distribution of an empty fleet %+v", dist)
+	}
+}
@@ -0,0 +1,148 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Spec describes the target of an upgrade campaign.
+type Spec struct {
+	// Version is the version all deployments should be upgraded to
+	Version upgraderules.VersionString
+	// Versions are the released versions that may be used as
+	// intermediate steps of multi-hop upgrades
+	Versions []upgraderules.VersionString
+	// Options configure the checks, e.g. upgraderules.WithPolicy
+	Options []upgraderules.Option
+}
+
+// AssessmentStatus classifies how a deployment can reach the target.
+type AssessmentStatus string
+
+const (
+	// StatusUpToDate means the deployment already runs the target version
+	StatusUpToDate AssessmentStatus = "up-to-date"
+	// StatusDirect means the deployment can upgrade to the target directly
+	StatusDirect AssessmentStatus = "direct"
+	// StatusMultiHop means the deployment can reach the target through
+	// intermediate versions
+	StatusMultiHop AssessmentStatus = "multi-hop"
+	// StatusBlocked means the deployment cannot reach the target
+	StatusBlocked AssessmentStatus = "blocked"
+)
+
+// DeploymentAssessment is the assessment of a single deployment.
+type DeploymentAssessment struct {
+	// Deployment is the name of the deployment
+	Deployment string `json:"deployment"`
+	// Namespace of the deployment, if any
+	Namespace string `json:"namespace,omitempty"`
+	// From is the version the deployment runs
+	From upgraderules.VersionString `json:"from"`
+	// Status classifies how the deployment can reach the target
+	Status AssessmentStatus `json:"status"`
+	// Path contains the versions of a multi-hop upgrade, ending with the target
+	Path []upgraderules.VersionString `json:"path,omitempty"`
+	// Decision is the decision for upgrading directly to the target
+	Decision upgraderules.Decision `json:"decision"`
+}
+
+// FleetReport contains the assessment of a fleet of deployments for
+// an upgrade campaign.
+type FleetReport struct {
+	// Target is the version the deployments should be upgraded to
+	Target upgraderules.VersionString `json:"target"`
+	// Total is the number of deployments
+	Total int `json:"total"`
+	// UpToDate is the number of deployments that run the target version
+	UpToDate int `json:"upToDate"`
+	// Direct is the number of deployments that can upgrade directly
+	Direct int `json:"direct"`
+	// MultiHop is the number of deployments that need intermediate versions
+	MultiHop int `json:"multiHop"`
+	// Blocked is the number of deployments that cannot reach the target
+	Blocked int `json:"blocked"`
+	// BlockedBy counts the blocked deployments by the rule denying their
+	// direct upgrade, or by the error code when no rule denied it
+	BlockedBy map[string]int `json:"blockedBy,omitempty"`
+	// Deployments contains the assessment of every deployment, in the
+	// order in which they were given
+	Deployments []DeploymentAssessment `json:"deployments"`
+}
+
+// AssessFleet assesses how each of the given deployments can reach the
+// target version. The From version of each deployment is its current
+// version. Deployments keep their license, when Licensed is set, and
+// are passed to preconditions and policy resolvers (see
+// upgraderules.WithPolicyResolver) using upgraderules.WithDeployment.
+// Deployments that cannot upgrade directly are upgraded along the
+// shortest path through the versions of the spec (see FindPath).
+func AssessFleet(deployments []upgraderules.DeploymentInfo, target Spec) FleetReport {
+	report := FleetReport{
+		Target:      target.Version,
+		Total:       len(deployments),
+		Deployments: make([]DeploymentAssessment, 0, len(deployments)),
+	}
+	for _, info := range deployments {
+		opts := append(append([]upgraderules.Option(nil), target.Options...), upgraderules.WithDeployment(info))
+		if info.Licensed {
+			opts = append(opts, upgraderules.WithLicenses(info.FromLicense, info.FromLicense))
+		}
+		a := DeploymentAssessment{
+			Deployment: info.Name,
+			Namespace:  info.Namespace,
+			From:       info.From,
+			Decision:   upgraderules.Check(info.From, target.Version, opts...),
+		}
+		switch {
+		case a.Decision.IsNoOp():
+			a.Status = StatusUpToDate
+			report.UpToDate++
+		case a.Decision.Allowed():
+			a.Status = StatusDirect
+			report.Direct++
+		default:
+			if path, found := FindPath(info.From, target.Version, target.Versions, opts...); found {
+				a.Status, a.Path = StatusMultiHop, path
+				report.MultiHop++
+			} else {
+				a.Status = StatusBlocked
+				report.Blocked++
+				if report.BlockedBy == nil {
+					report.BlockedBy = make(map[string]int)
+				}
+				report.BlockedBy[blockedReason(a.Decision)]++
+			}
+		}
+		report.Deployments = append(report.Deployments, a)
+	}
+	return report
+}
+
+// blockedReason returns the rule that denied the given decision, or
+// "Error" when no rule denied it.
+func blockedReason(d upgraderules.Decision) string {
+	if d.Rule != "" {
+		return string(d.Rule)
+	}
+	return "Error"
+}
@@ -0,0 +1,133 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// frozen is a precondition that is not met by deployments labeled frozen.
+type frozen struct{}
+
+func (frozen) Name() string { return "not-frozen" }
+
+func (frozen) Evaluate(ctx context.Context, info upgraderules.DeploymentInfo) error {
+	if info.Labels["frozen"] == "true" {
+		return errors.New("deployment is frozen")
+	}
+	return nil
+}
+
+func TestAssessFleet(t *testing.T) {
+	fleet := []upgraderules.DeploymentInfo{
+		{Name: "current", From: "3.12.0"},
+		{Name: "direct", From: "3.11.8", Licensed: true, FromLicense: upgraderules.LicenseEnterprise},
+		{Name: "hop", From: "3.10.2", Namespace: "prod"},
+		{Name: "old", From: "3.8.5"},
+		{Name: "ahead", From: "4.0.0"},
+		{Name: "frozen", From: "3.11.8", Labels: map[string]string{"frozen": "true"}},
+	}
+	spec := Spec{
+		Version:  "3.12.0",
+		Versions: []upgraderules.VersionString{"3.10.2", "3.11.0", "3.11.8", "3.12.0"},
+		Options:  []upgraderules.Option{upgraderules.WithPrecondition(frozen{})},
+	}
+	report := AssessFleet(fleet, spec)
+	if report.Target != "3.12.0" || report.Total != 6 || report.UpToDate != 1 || report.Direct != 1 || report.MultiHop != 1 || report.Blocked != 3 {
+		t.Errorf("Unexpected statistics %d/%d/%d/%d of %d", report.UpToDate, report.Direct, report.MultiHop, report.Blocked, report.Total)
+	}
+	expectedBlockedBy := map[string]int{
+		string(upgraderules.RuleMinorIncrement): 1,
+		string(upgraderules.RuleMajorVersion):   1,
+		string(upgraderules.RulePreconditions):  1,
+	}
+	if !reflect.DeepEqual(report.BlockedBy, expectedBlockedBy) {
+		t.Errorf("Expected blocked by %v, got %v", expectedBlockedBy, report.BlockedBy)
+	}
+	expected := []struct {
+		Status AssessmentStatus
+		Path   []upgraderules.VersionString
+	}{
+		{StatusUpToDate, nil},
+		{StatusDirect, nil},
+		{StatusMultiHop, []upgraderules.VersionString{"3.11.0", "3.12.0"}},
+		{StatusBlocked, nil},
+		{StatusBlocked, nil},
+		{StatusBlocked, nil},
+	}
+	for i, a := range report.Deployments {
+		if a.Deployment != fleet[i].Name || a.From != fleet[i].From || a.Namespace != fleet[i].Namespace {
+			t.Errorf("Deployment %d: unexpected assessment of %s", i, a.Deployment)
+		}
+		if a.Status != expected[i].Status || !reflect.DeepEqual(a.Path, expected[i].Path) {
+			t.Errorf("%s: expected %s %v, got %s %v", a.Deployment, expected[i].Status, expected[i].Path, a.Status, a.Path)
+		}
+	}
+	if d := report.Deployments[1].Decision; !d.Licensed || d.ToLicense != upgraderules.LicenseEnterprise {
+		t.Errorf("Expected the license to be kept, got %+v", d)
+	}
+}
+
+func TestFleetReportJSON(t *testing.T) {
+	report := AssessFleet([]upgraderules.DeploymentInfo{{Name: "old", From: "3.8.5"}}, Spec{Version: "3.12.0"})
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Target      string         `json:"target"`
+		Blocked     int            `json:"blocked"`
+		BlockedBy   map[string]int `json:"blockedBy"`
+		Deployments []struct {
+			Status   string `json:"status"`
+			Decision struct {
+				Rule string `json:"rule"`
+			} `json:"decision"`
+		} `json:"deployments"`
+	}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Target != "3.12.0" || decoded.Blocked != 1 || decoded.BlockedBy["minor-increment"] != 1 ||
+		len(decoded.Deployments) != 1 || decoded.Deployments[0].Status != "blocked" || decoded.Deployments[0].Decision.Rule != "minor-increment" {
+		t.Errorf("Unexpected JSON %s", encoded)
+	}
+}
+
+func TestAssessFleetWithPolicyResolver(t *testing.T) {
+	resolver := upgraderules.WithPolicyResolver(upgraderules.PolicyResolverFunc(func(ctx context.Context, info upgraderules.DeploymentInfo) (upgraderules.Policy, error) {
+		return upgraderules.Policy{Soft: info.Namespace == "dev"}, nil
+	}))
+	fleet := []upgraderules.DeploymentInfo{
+		{Name: "a", Namespace: "dev", From: "3.10.2"},
+		{Name: "b", Namespace: "prod", From: "3.10.2"},
+	}
+	report := AssessFleet(fleet, Spec{Version: "3.12.0", Options: []upgraderules.Option{resolver}})
+	if report.Deployments[0].Status != StatusDirect || report.Deployments[1].Status != StatusBlocked {
+		t.Errorf("Expected the policies of the tenants to apply, got %s and %s", report.Deployments[0].Status, report.Deployments[1].Status)
+	}
+}
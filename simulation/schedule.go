@@ -0,0 +1,198 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+const (
+	// DefaultHorizon is the period scheduled when Campaign.Horizon is 0
+	DefaultHorizon = 90 * 24 * time.Hour
+	// DefaultUpgradeDuration is the time reserved for a single upgrade
+	// when Campaign.UpgradeDuration is 0
+	DefaultUpgradeDuration = time.Hour
+)
+
+// CampaignDeployment is a deployment to be upgraded by a campaign.
+type CampaignDeployment struct {
+	// Info describes the deployment; From is its current version
+	Info upgraderules.DeploymentInfo
+	// Windows are the maintenance windows of the deployment.
+	// The deployment may be upgraded at any time when empty.
+	Windows []upgraderules.MaintenanceWindow
+}
+
+// Campaign configures the scheduling of an upgrade campaign.
+type Campaign struct {
+	// Target of the campaign
+	Target Spec
+	// Start is the earliest time of an upgrade
+	Start time.Time
+	// Horizon is the period after Start in which upgrades are scheduled
+	Horizon time.Duration
+	// UpgradeDuration is the time reserved for a single upgrade.
+	// Subsequent upgrades of a multi-hop path start after it.
+	UpgradeDuration time.Duration
+	// MaxParallel limits the number of upgrades starting at the same time,
+	// unlimited when 0
+	MaxParallel int
+}
+
+// ScheduledUpgrade is a single upgrade of a timetable.
+type ScheduledUpgrade struct {
+	// Deployment is the name of the deployment
+	Deployment string `json:"deployment"`
+	// Namespace of the deployment, if any
+	Namespace string `json:"namespace,omitempty"`
+	// From is the version before the upgrade
+	From upgraderules.VersionString `json:"from"`
+	// To is the version after the upgrade
+	To upgraderules.VersionString `json:"to"`
+	// Time at which the upgrade starts
+	Time time.Time `json:"time"`
+}
+
+// UnscheduledDeployment is a deployment that could not be scheduled.
+type UnscheduledDeployment struct {
+	// Deployment is the name of the deployment
+	Deployment string `json:"deployment"`
+	// Namespace of the deployment, if any
+	Namespace string `json:"namespace,omitempty"`
+	// From is the version of the deployment
+	From upgraderules.VersionString `json:"from"`
+	// Reason describes why the deployment could not be scheduled
+	Reason string `json:"reason"`
+}
+
+// Timetable is the proposed rollout of an upgrade campaign.
+type Timetable struct {
+	// Upgrades are the scheduled upgrades, ordered by time
+	Upgrades []ScheduledUpgrade `json:"upgrades"`
+	// Unscheduled are the deployments that cannot reach the target
+	// within the horizon of the campaign
+	Unscheduled []UnscheduledDeployment `json:"unscheduled,omitempty"`
+}
+
+// Schedule proposes a timetable for upgrading the given fleet to the
+// target of the campaign. It is greedy: deployments are scheduled in
+// the given order, each at the earliest time allowed by the checks.
+//
+// Every upgrade is checked at its scheduled time, with the options of
+// the target, the deployment (see upgraderules.WithDeployment), its
+// license when Licensed is set, its maintenance windows and the earlier
+// upgrades of its path as history (see upgraderules.WithHistory), so
+// all policies, including maintenance windows and cooldowns, are honored.
+// Multi-hop upgrades follow the shortest path through the versions of
+// the target (see FindPath).
+func Schedule(fleet []CampaignDeployment, c Campaign) Timetable {
+	horizon, upgradeDuration := c.Horizon, c.UpgradeDuration
+	if horizon <= 0 {
+		horizon = DefaultHorizon
+	}
+	if upgradeDuration <= 0 {
+		upgradeDuration = DefaultUpgradeDuration
+	}
+	end := c.Start.Add(horizon)
+	started := make(map[time.Time]int)
+	var result Timetable
+	for _, x := range fleet {
+		info := x.Info
+		unscheduled := func(reason string) {
+			result.Unscheduled = append(result.Unscheduled, UnscheduledDeployment{Deployment: info.Name, Namespace: info.Namespace, From: info.From, Reason: reason})
+		}
+		opts := append(append([]upgraderules.Option(nil), c.Target.Options...), upgraderules.WithDeployment(info))
+		if info.Licensed {
+			opts = append(opts, upgraderules.WithLicenses(info.FromLicense, info.FromLicense))
+		}
+		if len(x.Windows) > 0 {
+			opts = append(opts, upgraderules.WithMaintenanceWindows(x.Windows...))
+		}
+		if upgraderules.IsNoOp(info.From, c.Target.Version) {
+			continue
+		}
+		// Upgrades that are only denied for now may be part of the path
+		path, found := findPath(info.From, c.Target.Version, c.Target.Versions, opts, func(d upgraderules.Decision) bool {
+			return d.Allowed() || upgraderules.IsRetryable(d.Err)
+		})
+		if !found {
+			unscheduled(upgraderules.Reason(upgraderules.Check(info.From, c.Target.Version, opts...).Err))
+			continue
+		}
+		var upgrades []ScheduledUpgrade
+		var history []upgraderules.HistoryEntry
+		from, t := info.From, c.Start
+		for _, to := range path {
+			var reason string
+			t, reason = earliest(from, to, t, end, started, c.MaxParallel, upgradeDuration,
+				append(append([]upgraderules.Option(nil), opts...), upgraderules.WithHistory(history...)))
+			if reason != "" {
+				unscheduled(reason)
+				upgrades = nil
+				break
+			}
+			upgrades = append(upgrades, ScheduledUpgrade{Deployment: info.Name, Namespace: info.Namespace, From: from, To: to, Time: t})
+			history = append(history, upgraderules.HistoryEntry{From: from, To: to, Time: t, Outcome: upgraderules.UpgradeSucceeded})
+			from, t = to, t.Add(upgradeDuration)
+		}
+		for _, u := range upgrades {
+			started[u.Time]++
+		}
+		result.Upgrades = append(result.Upgrades, upgrades...)
+	}
+	sort.SliceStable(result.Upgrades, func(i, j int) bool {
+		return result.Upgrades[i].Time.Before(result.Upgrades[j].Time)
+	})
+	return result
+}
+
+// earliest returns the earliest time at or after t and before end at
+// which the upgrade from `from` to `to` is allowed and fewer than
+// maxParallel upgrades start, or the reason why there is none.
+func earliest(from, to upgraderules.VersionString, t, end time.Time, started map[time.Time]int, maxParallel int, upgradeDuration time.Duration, opts []upgraderules.Option) (time.Time, string) {
+	for t.Before(end) {
+		at := t
+		d := upgraderules.Check(from, to, append(opts, upgraderules.WithClock(func() time.Time { return at }))...)
+		if d.Allowed() {
+			if maxParallel > 0 && started[t] >= maxParallel {
+				t = t.Add(upgradeDuration)
+				continue
+			}
+			return t, ""
+		}
+		var next time.Time
+		switch err := d.Err.(type) {
+		case *upgraderules.MaintenanceWindowError:
+			next = err.Next
+		case *upgraderules.CooldownError:
+			next = err.Next
+		}
+		if !next.After(t) {
+			return time.Time{}, upgraderules.Reason(d.Err)
+		}
+		t = next
+	}
+	return time.Time{}, fmt.Sprintf("Upgrade from %s to %s cannot be scheduled before %s", from, to, end.Format(time.RFC3339))
+}
@@ -0,0 +1,93 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestSchedule(t *testing.T) {
+	// Monday
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	saturday := upgraderules.MustParseMaintenanceWindow("0 2 * * 6", 4*time.Hour, nil)
+	fleet := []CampaignDeployment{
+		{Info: upgraderules.DeploymentInfo{Name: "weekend", From: "3.11.8"}, Windows: []upgraderules.MaintenanceWindow{saturday}},
+		{Info: upgraderules.DeploymentInfo{Name: "anytime", From: "3.10.2", Namespace: "dev"}},
+		{Info: upgraderules.DeploymentInfo{Name: "weekend2", From: "3.11.8"}, Windows: []upgraderules.MaintenanceWindow{saturday}},
+		{Info: upgraderules.DeploymentInfo{Name: "current", From: "3.12.0"}},
+		{Info: upgraderules.DeploymentInfo{Name: "old", From: "3.8.5"}},
+	}
+	c := Campaign{
+		Target: Spec{
+			Version:  "3.12.0",
+			Versions: []upgraderules.VersionString{"3.10.2", "3.11.0", "3.11.8", "3.12.0"},
+			Options:  []upgraderules.Option{upgraderules.WithCooldown(upgraderules.Cooldown{AfterUpgrade: 24 * time.Hour})},
+		},
+		Start:       start,
+		MaxParallel: 1,
+	}
+	table := Schedule(fleet, c)
+	at := func(day, hour int) time.Time { return time.Date(2024, 1, day, hour, 0, 0, 0, time.UTC) }
+	expected := []ScheduledUpgrade{
+		{Deployment: "anytime", Namespace: "dev", From: "3.10.2", To: "3.11.0", Time: at(1, 0)},
+		{Deployment: "anytime", Namespace: "dev", From: "3.11.0", To: "3.12.0", Time: at(2, 0)},
+		{Deployment: "weekend", From: "3.11.8", To: "3.12.0", Time: at(6, 2)},
+		{Deployment: "weekend2", From: "3.11.8", To: "3.12.0", Time: at(6, 3)},
+	}
+	if !reflect.DeepEqual(table.Upgrades, expected) {
+		t.Errorf("Expected\n%+v\ngot\n%+v", expected, table.Upgrades)
+	}
+	if len(table.Unscheduled) != 1 || table.Unscheduled[0].Deployment != "old" || table.Unscheduled[0].Reason != "Minor versions may only increment by 1" {
+		t.Errorf("Expected old to be unscheduled, got %+v", table.Unscheduled)
+	}
+}
+
+func TestScheduleHorizon(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fleet := []CampaignDeployment{
+		{Info: upgraderules.DeploymentInfo{Name: "yearly", From: "3.11.8"}, Windows: []upgraderules.MaintenanceWindow{
+			upgraderules.MustParseMaintenanceWindow("0 0 1 12 *", time.Hour, nil),
+		}},
+		{Info: upgraderules.DeploymentInfo{Name: "hop", From: "3.10.2"}},
+	}
+	c := Campaign{
+		Target: Spec{
+			Version:  "3.12.0",
+			Versions: []upgraderules.VersionString{"3.11.0", "3.12.0"},
+			Options:  []upgraderules.Option{upgraderules.WithCooldown(upgraderules.Cooldown{BetweenMinors: 60 * 24 * time.Hour})},
+		},
+		Start:   start,
+		Horizon: 30 * 24 * time.Hour,
+	}
+	table := Schedule(fleet, c)
+	if len(table.Upgrades) != 0 {
+		t.Errorf("Expected no upgrades, got %+v", table.Upgrades)
+	}
+	if len(table.Unscheduled) != 2 || !strings.Contains(table.Unscheduled[0].Reason, "cannot be scheduled before 2024-01-31") ||
+		!strings.Contains(table.Unscheduled[1].Reason, "3.11.0 to 3.12.0") {
+		t.Errorf("Unexpected unscheduled deployments %+v", table.Unscheduled)
+	}
+}
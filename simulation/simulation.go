@@ -0,0 +1,209 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package simulation simulates the upgrades of a fleet of ArangoDB
+// deployments over a sequence of releases, to evaluate the effect of
+// rule changes before rolling them out.
+package simulation
+
+import (
+	"sort"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Deployment is a single deployment of the simulated fleet.
+type Deployment struct {
+	// Name identifies the deployment
+	Name string
+	// Version is the version the deployment runs at the start of the simulation
+	Version upgraderules.VersionString
+	// License of the deployment, which is kept during upgrades
+	License upgraderules.License
+}
+
+// Release is a version that becomes available at a given time.
+type Release struct {
+	Version upgraderules.VersionString
+	Time    time.Time
+}
+
+// Step is the simulated state of the fleet after a single release.
+type Step struct {
+	// Release that triggered this step
+	Release Release
+	// Upgrades contains the result for every deployment, in fleet order
+	Upgrades []Upgrade
+}
+
+// Upgrade is the result of moving a single deployment to the latest
+// available version in a Step.
+type Upgrade struct {
+	// Deployment is the name of the deployment
+	Deployment string
+	// From is the version before the step
+	From upgraderules.VersionString
+	// To is the version after the step
+	To upgraderules.VersionString
+	// Path contains the versions the deployment is upgraded through,
+	// ending with To. It is empty when the deployment did not change.
+	Path []upgraderules.VersionString
+	// Stranded is set when there is no allowed path to the latest version
+	Stranded bool
+}
+
+// Result is the outcome of a simulation.
+type Result struct {
+	Steps []Step
+}
+
+// Stranded returns the names of the deployments that are stranded after
+// the last step, i.e. cannot reach the latest release.
+func (r Result) Stranded() []string {
+	if len(r.Steps) == 0 {
+		return nil
+	}
+	var result []string
+	for _, u := range r.Steps[len(r.Steps)-1].Upgrades {
+		if u.Stranded {
+			result = append(result, u.Deployment)
+		}
+	}
+	return result
+}
+
+// Run simulates the given releases, in order of their time.
+// After every release, each deployment is upgraded to the latest released
+// version along the shortest path through the released versions that
+// the rules (configured by the given options) allow. Deployments without
+// such a path stay at their version and are marked stranded.
+func Run(fleet []Deployment, releases []Release, opts ...upgraderules.Option) Result {
+	releases = append([]Release(nil), releases...)
+	sort.SliceStable(releases, func(i, j int) bool {
+		return releases[i].Time.Before(releases[j].Time)
+	})
+	current := make([]upgraderules.VersionString, len(fleet))
+	for i, d := range fleet {
+		current[i] = d.Version
+	}
+	var available []upgraderules.VersionString
+	var result Result
+	for _, rel := range releases {
+		available = append(available, rel.Version)
+		latest := available[0]
+		for _, v := range available[1:] {
+			if v.CompareTo(latest) > 0 {
+				latest = v
+			}
+		}
+		step := Step{Release: rel}
+		for i, d := range fleet {
+			u := Upgrade{Deployment: d.Name, From: current[i], To: current[i]}
+			if current[i] != latest {
+				licenseOpts := append(append([]upgraderules.Option(nil), opts...), upgraderules.WithLicenses(d.License, d.License))
+				if path, found := FindPath(current[i], latest, available, licenseOpts...); found {
+					u.To, u.Path = latest, path
+					current[i] = latest
+				} else {
+					u.Stranded = true
+				}
+			}
+			step.Upgrades = append(step.Upgrades, u)
+		}
+		result.Steps = append(result.Steps, step)
+	}
+	return result
+}
+
+// FindPath returns the shortest sequence of upgrades from `from` to `to`
+// through the given versions, that is allowed by the rules configured by
+// the given options. The returned path excludes `from` and ends with `to`.
+// Among paths of equal length, the one through the lowest versions is returned.
+// Paths pass mandatory intermediate versions (see
+// upgraderules.WithMandatoryIntermediates) at their latest patch release
+// and avoid skipped versions (see upgraderules.WithSkipVersions).
+func FindPath(from, to upgraderules.VersionString, versions []upgraderules.VersionString, opts ...upgraderules.Option) ([]upgraderules.VersionString, bool) {
+	return findPath(from, to, versions, opts, func(d upgraderules.Decision) bool {
+		return d.Allowed()
+	})
+}
+
+// findPath implements FindPath, using the given function to decide
+// whether a single upgrade may be part of the path.
+func findPath(from, to upgraderules.VersionString, versions []upgraderules.VersionString, opts []upgraderules.Option, usable func(upgraderules.Decision) bool) ([]upgraderules.VersionString, bool) {
+	if from == to {
+		return nil, true
+	}
+	candidates := []upgraderules.VersionString{to}
+	for _, v := range versions {
+		if !upgraderules.IsVersionSkipped(v, opts...) {
+			candidates = append(candidates, v)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].CompareTo(candidates[j]) < 0
+	})
+	for _, i := range upgraderules.MandatoryIntermediates(opts...) {
+		candidates = latestOfMinor(candidates, i.Via, to)
+	}
+	previous := map[upgraderules.VersionString]upgraderules.VersionString{from: ""}
+	queue := []upgraderules.VersionString{from}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, next := range candidates {
+			if _, visited := previous[next]; visited {
+				continue
+			}
+			if !usable(upgraderules.Check(v, next, opts...)) {
+				continue
+			}
+			previous[next] = v
+			if next == to {
+				var path []upgraderules.VersionString
+				for x := to; x != from; x = previous[x] {
+					path = append([]upgraderules.VersionString{x}, path...)
+				}
+				return path, true
+			}
+			queue = append(queue, next)
+		}
+	}
+	return nil, false
+}
+
+// latestOfMinor removes all versions of the minor version of `minor`
+// from the sorted candidates, except the latest one and `keep`.
+func latestOfMinor(candidates []upgraderules.VersionString, minor, keep upgraderules.VersionString) []upgraderules.VersionString {
+	latest := -1
+	for i, v := range candidates {
+		if v.Major() == minor.Major() && v.Minor() == minor.Minor() {
+			latest = i
+		}
+	}
+	var result []upgraderules.VersionString
+	for i, v := range candidates {
+		if i == latest || v == keep || v.Major() != minor.Major() || v.Minor() != minor.Minor() {
+			result = append(result, v)
+		}
+	}
+	return result
+}
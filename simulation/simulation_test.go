@@ -0,0 +1,117 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package simulation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestFindPath(t *testing.T) {
+	versions := []upgraderules.VersionString{"3.12.0", "3.10.2", "3.11.1", "3.10.0", "3.11.0"}
+	path, found := FindPath("3.8.5", "3.12.0", versions)
+	if found {
+		t.Errorf("Expected no path without a 3.9 version, got %v", path)
+	}
+	path, found = FindPath("3.9.5", "3.12.0", versions)
+	expected := []upgraderules.VersionString{"3.10.0", "3.11.0", "3.12.0"}
+	if !found || !reflect.DeepEqual(path, expected) {
+		t.Errorf("Expected path %v, got %v (%v)", expected, path, found)
+	}
+	path, found = FindPath("3.10.1", "3.12.0", versions, upgraderules.WithSoft())
+	if !found || !reflect.DeepEqual(path, []upgraderules.VersionString{"3.12.0"}) {
+		t.Errorf("Expected direct soft path, got %v (%v)", path, found)
+	}
+}
+
+func TestFindPathMandatoryIntermediate(t *testing.T) {
+	versions := []upgraderules.VersionString{"3.12.0", "3.10.2", "3.11.1", "3.10.0", "3.11.0"}
+	opts := []upgraderules.Option{
+		upgraderules.WithSoft(),
+		upgraderules.WithMandatoryIntermediates(upgraderules.Intermediate{Before: "3.11", Since: "3.12", Via: "3.11"}),
+	}
+	path, found := FindPath("3.10.1", "3.12.0", versions, opts...)
+	expected := []upgraderules.VersionString{"3.11.1", "3.12.0"}
+	if !found || !reflect.DeepEqual(path, expected) {
+		t.Errorf("Expected path %v, got %v (%v)", expected, path, found)
+	}
+	path, found = FindPath("3.10.1", "3.11.0", versions, opts...)
+	if !found || !reflect.DeepEqual(path, []upgraderules.VersionString{"3.11.0"}) {
+		t.Errorf("Expected direct path to the target, got %v (%v)", path, found)
+	}
+}
+
+func TestRun(t *testing.T) {
+	day := func(n int) time.Time { return time.Date(2024, 1, n, 0, 0, 0, 0, time.UTC) }
+	fleet := []Deployment{
+		{Name: "a", Version: "3.10.1"},
+		{Name: "b", Version: "3.9.5", License: upgraderules.LicenseEnterprise},
+	}
+	releases := []Release{
+		{Version: "3.12.0", Time: day(3)},
+		{Version: "3.11.0", Time: day(1)},
+		{Version: "3.11.1", Time: day(2)},
+	}
+	result := Run(fleet, releases)
+	if len(result.Steps) != 3 {
+		t.Fatalf("Expected 3 steps, got %d", len(result.Steps))
+	}
+	first := result.Steps[0]
+	if first.Release.Version != "3.11.0" {
+		t.Errorf("Expected first step for 3.11.0, got %s", first.Release.Version)
+	}
+	if u := first.Upgrades[0]; u.To != "3.11.0" || u.Stranded {
+		t.Errorf("Expected a to be upgraded to 3.11.0, got %+v", u)
+	}
+	if u := first.Upgrades[1]; u.To != "3.9.5" || !u.Stranded {
+		t.Errorf("Expected b to be stranded, got %+v", u)
+	}
+	last := result.Steps[2]
+	if u := last.Upgrades[0]; u.From != "3.11.1" || u.To != "3.12.0" || !reflect.DeepEqual(u.Path, []upgraderules.VersionString{"3.12.0"}) {
+		t.Errorf("Expected a to be upgraded from 3.11.1 to 3.12.0, got %+v", u)
+	}
+	if stranded := result.Stranded(); !reflect.DeepEqual(stranded, []string{"b"}) {
+		t.Errorf("Expected b to be stranded, got %v", stranded)
+	}
+	if stranded := Run(fleet, releases, upgraderules.WithSoft()).Stranded(); len(stranded) != 0 {
+		t.Errorf("Expected no stranded deployments with soft rules, got %v", stranded)
+	}
+}
+
+func TestFindPathSkipVersions(t *testing.T) {
+	versions := []upgraderules.VersionString{"3.11.0", "3.11.1", "3.12.0"}
+	opts := []upgraderules.Option{
+		upgraderules.WithSoft(),
+		upgraderules.WithMandatoryIntermediates(upgraderules.Intermediate{Before: "3.11", Since: "3.12", Via: "3.11"}),
+		upgraderules.WithSkipVersions("3.11.1"),
+	}
+	path, found := FindPath("3.10.1", "3.12.0", versions, opts...)
+	expected := []upgraderules.VersionString{"3.11.0", "3.12.0"}
+	if !found || !reflect.DeepEqual(path, expected) {
+		t.Errorf("Expected path %v, got %v (%v)", expected, path, found)
+	}
+	if _, found := FindPath("3.10.1", "3.12.0", versions, append(opts, upgraderules.WithSkipVersions("3.12.0"))...); found {
+		t.Error("Expected no path to a skipped target")
+	}
+}
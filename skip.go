@@ -0,0 +1,90 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+)
+
+// RuleSkippedVersion denies upgrades from and to versions that are
+// embargoed, see WithSkipVersions.
+const RuleSkippedVersion RuleID = "skipped-version"
+
+// WithSkipVersions embargoes the given versions, e.g. while a release is
+// withdrawn. Upgrades from and to them are denied by RuleSkippedVersion,
+// which cannot be overridden, and planners do not use them as intermediate
+// versions. A deployment running a skipped version therefore stays on it
+// until the embargo is lifted. A version without patch component, e.g.
+// "3.12", skips all patch releases of that minor version.
+func WithSkipVersions(versions ...VersionString) Option {
+	return func(o *options) {
+		o.skipVersions = append(append([]VersionString(nil), o.skipVersions...), versions...)
+	}
+}
+
+// IsVersionSkipped returns true when the given version is embargoed by
+// the given options, for planners that must not pass through it.
+func IsVersionSkipped(v VersionString, opts ...Option) bool {
+	return matchesAnyVersion(newOptions(opts).skipVersions, v)
+}
+
+// matchesAnyVersion returns true when `v` matches one of the given
+// versions, see versionMatches.
+func matchesAnyVersion(patterns []VersionString, v VersionString) bool {
+	for _, p := range patterns {
+		if versionMatches(p, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkippedVersionError details a denial by RuleSkippedVersion.
+type SkippedVersionError struct {
+	// Version is the embargoed version
+	Version VersionString
+	// Source is set when Version is the version being upgraded from,
+	// rather than the version being upgraded to
+	Source bool
+}
+
+// Error describes the embargoed version.
+func (e *SkippedVersionError) Error() string {
+	return fmt.Sprintf("Version %s is embargoed", e.Version)
+}
+
+// ruleSkippedVersion implements RuleSkippedVersion.
+var ruleSkippedVersion = Rule{
+	ID:      RuleSkippedVersion,
+	Applies: func(in RuleInput) bool { return len(in.SkipVersions) > 0 && in.changesVersion() },
+	Check:   func(in RuleInput) error { return checkSkipVersions(in.SkipVersions, in.From.version, in.To.version) },
+}
+
+// checkSkipVersions implements RuleSkippedVersion.
+func checkSkipVersions(skipped []VersionString, from, to VersionString) error {
+	if matchesAnyVersion(skipped, to) {
+		return newCausedError(&SkippedVersionError{Version: to}, RuleSkippedVersion, MessageVersionSkipped, string(to))
+	}
+	if matchesAnyVersion(skipped, from) {
+		return newCausedError(&SkippedVersionError{Version: from, Source: true}, RuleSkippedVersion, MessageVersionSkipped, string(from))
+	}
+	return nil
+}
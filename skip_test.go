@@ -0,0 +1,80 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWithSkipVersions(t *testing.T) {
+	skip := WithSkipVersions("3.11.2", "3.12")
+	tests := []struct {
+		From    VersionString
+		To      VersionString
+		Allowed bool
+	}{
+		{"3.11.1", "3.11.2", false},
+		{"3.11.1", "3.11.3", true},
+		{"3.11.8", "3.12.0", false},
+		{"3.11.8", "3.12.4", false},
+		{"3.11.2", "3.11.3", false},
+		{"3.11.2", "3.11.2", true},
+		{"3.12.1", "3.13.0", false},
+		{"3.11.8", "3.11.9", true},
+	}
+	for _, test := range tests {
+		d := Check(test.From, test.To, skip)
+		if d.Allowed() != test.Allowed {
+			t.Errorf("%s -> %s: expected allowed=%v, got %v", test.From, test.To, test.Allowed, d.Err)
+		}
+		if !test.Allowed && d.Rule != RuleSkippedVersion {
+			t.Errorf("%s -> %s: expected rule %s, got %s", test.From, test.To, RuleSkippedVersion, d.Rule)
+		}
+	}
+	d := Check("3.11.1", "3.11.2", skip, WithOverrides(Override{From: "3.11", To: "3.11"}))
+	if d.Allowed() {
+		t.Error("Expected skipped version not to be overridable")
+	}
+	if msg := d.Err.Error(); msg != "Version 3.11.2 is embargoed (from 3.11.1 to 3.11.2)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	var target, source *SkippedVersionError
+	if !errors.As(d.Err, &target) || target.Version != "3.11.2" || target.Source {
+		t.Errorf("Expected embargoed target, got %+v", target)
+	}
+	d = Check("3.12.1", "3.13.0", skip)
+	if !errors.As(d.Err, &source) || source.Version != "3.12.1" || !source.Source {
+		t.Errorf("Expected embargoed source, got %+v", source)
+	}
+	if msg := d.Err.Error(); msg != "Version 3.12.1 is embargoed (from 3.12.1 to 3.13.0)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+}
+
+func TestIsVersionSkipped(t *testing.T) {
+	opts := []Option{WithSkipVersions("3.11.2"), WithSkipVersions("3.12")}
+	for v, expected := range map[VersionString]bool{"3.11.2": true, "3.11.3": false, "3.12.7": true, "3.13.0": false} {
+		if skipped := IsVersionSkipped(v, opts...); skipped != expected {
+			t.Errorf("%s: expected skipped=%v, got %v", v, expected, skipped)
+		}
+	}
+}
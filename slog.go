@@ -0,0 +1,48 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build go1.21
+// +build go1.21
+
+package upgraderules
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger returns a Logger that writes to the given slog.Logger.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// Info writes a record at slog.LevelInfo.
+func (s slogLogger) Info(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	s.l.Log(ctx, slog.LevelInfo, msg, keysAndValues...)
+}
+
+// Warn writes a record at slog.LevelWarn.
+func (s slogLogger) Warn(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	s.l.Log(ctx, slog.LevelWarn, msg, keysAndValues...)
+}
@@ -0,0 +1,60 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build go1.21
+// +build go1.21
+
+package upgraderules
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := WithLogger(NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	Check("3.11.8", "3.12.1", l)
+	Check("3.10.8", "3.12.1", l, WithLicenses(LicenseEnterprise, LicenseEnterprise))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(lines))
+	}
+	var allowed, denied map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &allowed); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &denied); err != nil {
+		t.Fatal(err)
+	}
+	if allowed["level"] != "INFO" || allowed["outcome"] != "allowed" || allowed["from"] != "3.11.8" {
+		t.Errorf("Unexpected record for allowed upgrade: %s", lines[0])
+	}
+	if _, found := allowed["from_license"]; found {
+		t.Errorf("Unexpected license in record for check without license: %s", lines[0])
+	}
+	if denied["level"] != "WARN" || denied["rule"] != string(RuleMinorIncrement) || denied["to_license"] != "enterprise" {
+		t.Errorf("Unexpected record for denied upgrade: %s", lines[1])
+	}
+}
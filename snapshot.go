@@ -0,0 +1,210 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// snapshotHeader is the first line of a written snapshot.
+const snapshotHeader = "# upgraderules snapshot v1"
+
+// Snapshot is a canonical representation of the decisions of a Matrix,
+// meant to be stored as a golden file. Comparing a stored snapshot with
+// a fresh one detects when a new version of this library changes the
+// decisions a project depends on.
+type Snapshot struct {
+	// Entries holds one entry per upgrade, sorted by From and To
+	Entries []SnapshotEntry
+}
+
+// SnapshotEntry is the decision for a single upgrade in a Snapshot.
+type SnapshotEntry struct {
+	From    VersionString
+	To      VersionString
+	Outcome Outcome
+	// Rule is the rule that denied the upgrade, empty when allowed
+	Rule RuleID
+}
+
+// SnapshotChange describes an upgrade whose decision differs between
+// two snapshots. An upgrade missing from one of the snapshots has
+// an empty outcome on that side.
+type SnapshotChange struct {
+	From       VersionString
+	To         VersionString
+	OldOutcome Outcome
+	OldRule    RuleID
+	NewOutcome Outcome
+	NewRule    RuleID
+}
+
+// Snapshot returns the canonical snapshot of the matrix.
+func (m Matrix) Snapshot() Snapshot {
+	var s Snapshot
+	for i, row := range m.Decisions {
+		for j, d := range row {
+			s.Entries = append(s.Entries, SnapshotEntry{
+				From:    m.Versions[i],
+				To:      m.Versions[j],
+				Outcome: d.Outcome(),
+				Rule:    d.Rule,
+			})
+		}
+	}
+	s.sort()
+	return s
+}
+
+// WriteTo writes the snapshot in its canonical text format: a header
+// line followed by one `<from> -> <to> <outcome> [<rule>]` line per entry.
+func (s Snapshot) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	sb.WriteString(snapshotHeader + "\n")
+	for _, e := range s.Entries {
+		sb.WriteString(e.String() + "\n")
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// ReadSnapshot reads a snapshot written by Snapshot.WriteTo.
+// Empty lines and lines starting with '#' are ignored.
+func ReadSnapshot(r io.Reader) (Snapshot, error) {
+	var s Snapshot
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 4 || len(fields) > 5 || fields[1] != "->" {
+			return Snapshot{}, fmt.Errorf("Invalid snapshot line %d: '%s'", lineNo, line)
+		}
+		e := SnapshotEntry{
+			From:    VersionString(fields[0]),
+			To:      VersionString(fields[2]),
+			Outcome: Outcome(fields[3]),
+		}
+		switch e.Outcome {
+		case OutcomeAllowed, OutcomeDenied, OutcomeOverridden, OutcomeNoOp:
+		default:
+			return Snapshot{}, fmt.Errorf("Unknown outcome '%s' in snapshot line %d", fields[3], lineNo)
+		}
+		if len(fields) == 5 {
+			e.Rule = RuleID(fields[4])
+		}
+		s.Entries = append(s.Entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return Snapshot{}, err
+	}
+	s.sort()
+	return s, nil
+}
+
+// CompareSnapshots returns the upgrades whose decision differs between
+// the old and the new snapshot, sorted by From and To.
+func CompareSnapshots(old, new Snapshot) []SnapshotChange {
+	type pair struct{ from, to VersionString }
+	changes := make(map[pair]*SnapshotChange)
+	get := func(e SnapshotEntry) *SnapshotChange {
+		key := pair{e.From, e.To}
+		c, found := changes[key]
+		if !found {
+			c = &SnapshotChange{From: e.From, To: e.To}
+			changes[key] = c
+		}
+		return c
+	}
+	for _, e := range old.Entries {
+		c := get(e)
+		c.OldOutcome, c.OldRule = e.Outcome, e.Rule
+	}
+	for _, e := range new.Entries {
+		c := get(e)
+		c.NewOutcome, c.NewRule = e.Outcome, e.Rule
+	}
+	var result []SnapshotChange
+	for _, c := range changes {
+		if c.OldOutcome != c.NewOutcome || c.OldRule != c.NewRule {
+			result = append(result, *c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return lessUpgrade(result[i].From, result[i].To, result[j].From, result[j].To)
+	})
+	return result
+}
+
+// String returns the entry in the canonical snapshot format.
+func (e SnapshotEntry) String() string {
+	if e.Rule == "" {
+		return fmt.Sprintf("%s -> %s %s", e.From, e.To, e.Outcome)
+	}
+	return fmt.Sprintf("%s -> %s %s %s", e.From, e.To, e.Outcome, e.Rule)
+}
+
+// String returns a human readable description of the change.
+func (c SnapshotChange) String() string {
+	return fmt.Sprintf("%s -> %s: %s -> %s", c.From, c.To, snapshotResult(c.OldOutcome, c.OldRule), snapshotResult(c.NewOutcome, c.NewRule))
+}
+
+// snapshotResult formats one side of a SnapshotChange.
+func snapshotResult(outcome Outcome, rule RuleID) string {
+	switch {
+	case outcome == "":
+		return "missing"
+	case rule != "":
+		return fmt.Sprintf("%s (%s)", outcome, rule)
+	default:
+		return string(outcome)
+	}
+}
+
+// sort orders the entries by From and To.
+func (s Snapshot) sort() {
+	sort.Slice(s.Entries, func(i, j int) bool {
+		return lessUpgrade(s.Entries[i].From, s.Entries[i].To, s.Entries[j].From, s.Entries[j].To)
+	})
+}
+
+// lessUpgrade orders upgrades by their versions, using the string
+// representation for versions that compare equal (e.g. "3.4" and "3.4.0").
+func lessUpgrade(fromA, toA, fromB, toB VersionString) bool {
+	if c := compareVersions(fromA, fromB); c != 0 {
+		return c < 0
+	}
+	return compareVersions(toA, toB) < 0
+}
+
+// compareVersions orders versions by driver.Version.CompareTo and then by string.
+func compareVersions(a, b VersionString) int {
+	if c := a.CompareTo(b); c != 0 {
+		return c
+	}
+	return strings.Compare(string(a), string(b))
+}
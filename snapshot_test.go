@@ -0,0 +1,90 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	m := NewMatrix([]VersionString{"3.4.0", "3.3.8", "3.5.1"})
+	var buf bytes.Buffer
+	if _, err := m.Snapshot().WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %s", err)
+	}
+	expected := `# upgraderules snapshot v1
+3.3.8 -> 3.3.8 no-op
+3.3.8 -> 3.4.0 allowed
+3.3.8 -> 3.5.1 denied minor-increment
+3.4.0 -> 3.3.8 denied minor-increment
+3.4.0 -> 3.4.0 no-op
+3.4.0 -> 3.5.1 allowed
+3.5.1 -> 3.3.8 denied minor-increment
+3.5.1 -> 3.4.0 denied minor-increment
+3.5.1 -> 3.5.1 no-op
+`
+	if buf.String() != expected {
+		t.Errorf("Expected snapshot\n%s\ngot\n%s", expected, buf.String())
+	}
+	s, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("ReadSnapshot failed: %s", err)
+	}
+	if changes := CompareSnapshots(s, m.Snapshot()); len(changes) != 0 {
+		t.Errorf("Expected no changes after round trip, got %v", changes)
+	}
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	versions := []VersionString{"3.3.8", "3.5.1", "3.4.0"}
+	old := NewMatrix(versions).Snapshot()
+	new := NewMatrix(versions[:2], WithSoft()).Snapshot()
+	var changes []string
+	for _, c := range CompareSnapshots(old, new) {
+		changes = append(changes, c.String())
+	}
+	expected := []string{
+		"3.3.8 -> 3.4.0: allowed -> missing",
+		"3.3.8 -> 3.5.1: denied (minor-increment) -> allowed",
+		"3.4.0 -> 3.3.8: denied (minor-increment) -> missing",
+		"3.4.0 -> 3.4.0: no-op -> missing",
+		"3.4.0 -> 3.5.1: allowed -> missing",
+		"3.5.1 -> 3.3.8: denied (minor-increment) -> denied (minor-downgrade)",
+		"3.5.1 -> 3.4.0: denied (minor-increment) -> missing",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected changes\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(changes, "\n"))
+	}
+}
+
+func TestReadSnapshotInvalid(t *testing.T) {
+	for _, input := range []string{
+		"3.3.8 3.4.0 allowed",
+		"3.3.8 -> 3.4.0 maybe",
+		"3.3.8 -> 3.4.0 denied rule extra",
+	} {
+		if _, err := ReadSnapshot(strings.NewReader(input)); err == nil {
+			t.Errorf("ReadSnapshot(%q) should fail", input)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// MessageTemplates replaces the messages of the errors of given rules,
+// see WithMessageTemplates. It is safe for concurrent use.
+type MessageTemplates struct {
+	templates map[RuleID]*template.Template
+}
+
+// NewMessageTemplates parses the given text/template's, one per rule.
+// A template is executed with the *Error of its rule, so it can use the
+// fields .Rule, .Message (the default message), .From, .To, .Licensed,
+// .FromLicense and .ToLicense, e.g.
+//
+//	"Version {{.From}} cannot be updated to {{.To}} in one step"
+func NewMessageTemplates(templates map[RuleID]string) (*MessageTemplates, error) {
+	result := &MessageTemplates{templates: make(map[RuleID]*template.Template, len(templates))}
+	for rule, text := range templates {
+		t, err := template.New(string(rule)).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid message template for rule '%s': %s", rule, err)
+		}
+		result.templates[rule] = t
+	}
+	return result, nil
+}
+
+// MustNewMessageTemplates is NewMessageTemplates, panicking on invalid templates.
+// It is intended for templates that are constants of the program.
+func MustNewMessageTemplates(templates map[RuleID]string) *MessageTemplates {
+	result, err := NewMessageTemplates(templates)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+// apply replaces the message of the given error when there is a template
+// for its rule. When executing the template fails, the message is kept
+// and the failure is returned.
+func (m *MessageTemplates) apply(e *Error) error {
+	t, found := m.templates[e.Rule]
+	if !found {
+		return nil
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, e); err != nil {
+		return err
+	}
+	e.Message, e.templated = sb.String(), true
+	return nil
+}
@@ -0,0 +1,81 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"context"
+	"testing"
+)
+
+// warningLogger records the messages of warnings.
+type warningLogger struct {
+	warnings []string
+}
+
+func (l *warningLogger) Info(ctx context.Context, msg string, keysAndValues ...interface{}) {}
+
+func (l *warningLogger) Warn(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	l.warnings = append(l.warnings, msg)
+}
+
+func TestWithMessageTemplates(t *testing.T) {
+	templates := MustNewMessageTemplates(map[RuleID]string{
+		RuleMinorIncrement:   "Version {{.From}} cannot be updated to {{.To}} in one step ({{.Message}})",
+		RuleEditionDowngrade: "{{.FromLicense}} -> {{.ToLicense}} is not supported",
+	})
+	err := CheckUpgradeRules("3.3.8", "3.5.0", WithMessageTemplates(templates))
+	expected := "Version 3.3.8 cannot be updated to 3.5.0 in one step (Minor versions may only increment by 1)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+	if msg := Localize(err, "de"); msg != "Die Nebenversion darf nur um 1 erhöht werden" {
+		t.Errorf("Expected templated error to keep its translation, got %q", msg)
+	}
+	err = CheckUpgradeRulesWithLicense("3.3.8", "3.3.9", LicenseEnterprise, LicenseCommunity, WithMessageTemplates(templates))
+	if err == nil || err.Error() != "enterprise -> community is not supported" {
+		t.Errorf("Unexpected error %v", err)
+	}
+	// Rules without template keep their message
+	err = CheckUpgradeRules("3.3.8", "4.0.0", WithMessageTemplates(templates))
+	if err == nil || err.Error() != "Major versions are different (from 3.3.8 to 4.0.0)" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestMessageTemplateFailure(t *testing.T) {
+	templates := MustNewMessageTemplates(map[RuleID]string{
+		RuleMinorIncrement: "{{.Unknown}}",
+	})
+	var logger warningLogger
+	err := CheckUpgradeRules("3.3.8", "3.5.0", WithMessageTemplates(templates), WithLogger(&logger))
+	if err == nil || err.Error() != "Minor versions may only increment by 1 (from 3.3.8 to 3.5.0)" {
+		t.Errorf("Expected default message when the template fails, got %v", err)
+	}
+	if len(logger.warnings) != 2 {
+		t.Errorf("Expected the template failure to be logged, got %v", logger.warnings)
+	}
+}
+
+func TestNewMessageTemplatesInvalid(t *testing.T) {
+	if _, err := NewMessageTemplates(map[RuleID]string{RuleMajorVersion: "{{.From"}); err == nil {
+		t.Error("Expected invalid template to fail")
+	}
+}
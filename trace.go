@@ -0,0 +1,158 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Trace is the evaluation tree of a decision.
+// It is only created when WithTrace is used.
+type Trace struct {
+	// Rules contains the rules in order of evaluation
+	Rules []*RuleTrace `json:"rules"`
+}
+
+// RuleTrace describes the evaluation of a single rule.
+type RuleTrace struct {
+	// Rule identifies the rule
+	Rule RuleID `json:"rule"`
+	// Allowed is set when the rule allowed the upgrade
+	Allowed bool `json:"allowed"`
+	// Overridden is set when the rule denied the upgrade, but an override allowed it
+	Overridden bool `json:"overridden,omitempty"`
+	// Reason describes why the rule denied the upgrade
+	Reason string `json:"reason,omitempty"`
+	// Conditions contains the conditions evaluated by the rule in order of evaluation
+	Conditions []ConditionTrace `json:"conditions,omitempty"`
+}
+
+// ConditionTrace describes a single condition evaluated by a rule.
+type ConditionTrace struct {
+	// Description of the condition
+	Description string `json:"description"`
+	// Values used to evaluate the condition
+	Values map[string]string `json:"values,omitempty"`
+	// Result of the condition
+	Result bool `json:"result"`
+}
+
+// String returns a human readable, multi line representation of the trace.
+func (t *Trace) String() string {
+	if t == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, r := range t.Rules {
+		switch {
+		case r.Overridden:
+			fmt.Fprintf(&sb, "rule %s: overridden (%s)\n", r.Rule, r.Reason)
+		case r.Allowed:
+			fmt.Fprintf(&sb, "rule %s: allowed\n", r.Rule)
+		default:
+			fmt.Fprintf(&sb, "rule %s: denied (%s)\n", r.Rule, r.Reason)
+		}
+		for _, c := range r.Conditions {
+			keys := make([]string, 0, len(c.Values))
+			for k := range c.Values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			values := make([]string, 0, len(keys))
+			for _, k := range keys {
+				values = append(values, k+"="+c.Values[k])
+			}
+			fmt.Fprintf(&sb, "  %s [%s]: %v\n", c.Description, strings.Join(values, ", "), c.Result)
+		}
+	}
+	return sb.String()
+}
+
+// copy returns a deep copy of the trace, or nil for a nil trace.
+func (t *Trace) copy() *Trace {
+	if t == nil {
+		return nil
+	}
+	c := &Trace{Rules: make([]*RuleTrace, len(t.Rules))}
+	for i, r := range t.Rules {
+		x := *r
+		x.Conditions = make([]ConditionTrace, len(r.Conditions))
+		for j, cond := range r.Conditions {
+			values := make(map[string]string, len(cond.Values))
+			for k, v := range cond.Values {
+				values[k] = v
+			}
+			cond.Values = values
+			x.Conditions[j] = cond
+		}
+		c.Rules[i] = &x
+	}
+	return c
+}
+
+// startRule adds a trace for the given rule.
+// It is safe to call on a nil trace, in which case nil is returned.
+func (t *Trace) startRule(id RuleID) *RuleTrace {
+	if t == nil {
+		return nil
+	}
+	r := &RuleTrace{Rule: id}
+	t.Rules = append(t.Rules, r)
+	return r
+}
+
+// Condition records a condition evaluated by a rule and returns its result.
+// The key/value pairs are the values used to evaluate the condition.
+// It is safe to call on a nil trace. Rules that care about allocations
+// should only call it when RuleInput.Trace is set, since boxing the
+// values allocates.
+func (r *RuleTrace) Condition(description string, result bool, keysAndValues ...interface{}) bool {
+	if r == nil {
+		return result
+	}
+	c := ConditionTrace{
+		Description: description,
+		Result:      result,
+	}
+	if len(keysAndValues) > 0 {
+		c.Values = make(map[string]string, len(keysAndValues)/2)
+		for i := 0; i+1 < len(keysAndValues); i += 2 {
+			c.Values[fmt.Sprint(keysAndValues[i])] = fmt.Sprint(keysAndValues[i+1])
+		}
+	}
+	r.Conditions = append(r.Conditions, c)
+	return result
+}
+
+// finish records the result of a rule.
+// It is safe to call on a nil trace.
+func (r *RuleTrace) finish(err error, overridden bool) {
+	if r == nil {
+		return
+	}
+	r.Allowed = err == nil
+	r.Overridden = overridden
+	if err != nil {
+		r.Reason = err.Error()
+	}
+}
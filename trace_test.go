@@ -0,0 +1,87 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	if d := Check("3.10.8", "3.12.1"); d.Trace != nil {
+		t.Error("Expected no trace without WithTrace")
+	}
+
+	d := Check("3.10.8", "3.12.1", WithTrace(), WithLicenses(LicenseEnterprise, LicenseEnterprise))
+	if d.Trace == nil {
+		t.Fatal("Expected trace with WithTrace")
+	}
+	rules := d.Trace.Rules
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(rules))
+	}
+	if r := rules[0]; r.Rule != RuleEditionDowngrade || !r.Allowed || r.Conditions[0].Values["from.license"] != "enterprise" {
+		t.Errorf("Unexpected first rule %+v", r)
+	}
+	if r := rules[1]; r.Rule != RuleMajorVersion || !r.Allowed || r.Conditions[0].Values["to.major"] != "3" {
+		t.Errorf("Unexpected second rule %+v", r)
+	}
+	r := rules[2]
+	if r.Rule != RuleMinorIncrement || r.Allowed || r.Reason != Reason(d.Err) {
+		t.Errorf("Unexpected third rule %+v", r)
+	}
+	if len(r.Conditions) != 2 || r.Conditions[1].Result || r.Conditions[1].Values["to.minor"] != "12" {
+		t.Errorf("Unexpected conditions of third rule %+v", r.Conditions)
+	}
+
+	s := d.Trace.String()
+	for _, expected := range []string{
+		"rule minor-increment: denied (Minor versions may only increment by 1)",
+		"  minor version increments by 1 [from.minor=10, to.minor=12]: false",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("Expected trace to contain '%s', got:\n%s", expected, s)
+		}
+	}
+
+	encoded, err := json.Marshal(d.Trace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Trace
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Rules) != 3 || decoded.Rules[2].Rule != RuleMinorIncrement {
+		t.Errorf("Unexpected decoded trace %s", encoded)
+	}
+}
+
+func TestTraceOverride(t *testing.T) {
+	d := Check("3.10.8", "3.12.1", WithTrace(), WithOverrides(Override{From: "3.10", To: "3.12"}))
+	if r := d.Trace.Rules[1]; !r.Overridden || r.Allowed {
+		t.Errorf("Expected overridden rule, got %+v", r)
+	}
+	if !strings.Contains(d.Trace.String(), "rule minor-increment: overridden") {
+		t.Errorf("Unexpected trace:\n%s", d.Trace)
+	}
+}
@@ -0,0 +1,109 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package tracing creates OpenTelemetry spans for upgrade checks.
+// It is a separate package so the rules themselves do not depend on
+// OpenTelemetry.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+const (
+	// instrumentationName is the name of the OpenTelemetry tracer
+	instrumentationName = "github.com/arangodb/go-upgrade-rules"
+
+	// Attribute keys
+	keyFrom        = "arangodb.upgrade.from"
+	keyTo          = "arangodb.upgrade.to"
+	keyFromLicense = "arangodb.upgrade.from_license"
+	keyToLicense   = "arangodb.upgrade.to_license"
+	keySoft        = "arangodb.upgrade.soft"
+	keyOutcome     = "arangodb.upgrade.outcome"
+	keyRule        = "arangodb.upgrade.rule"
+	keyAllowed     = "arangodb.upgrade.allowed"
+	keyReason      = "arangodb.upgrade.reason"
+)
+
+// Tracer implements upgraderules.Tracer using OpenTelemetry.
+// Every check results in a span named "upgraderules.Check" with a
+// child span per evaluated rule.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ upgraderules.Tracer = &Tracer{}
+
+// NewTracer creates a Tracer that creates spans using given provider.
+// Pass it to the checks using upgraderules.WithTracer, together with
+// upgraderules.WithContext to make the spans part of the callers trace.
+func NewTracer(tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer: tp.Tracer(instrumentationName),
+	}
+}
+
+// StartCheck starts the span of a check.
+func (t *Tracer) StartCheck(ctx context.Context, from, to upgraderules.VersionString) (context.Context, func(upgraderules.Decision)) {
+	ctx, span := t.tracer.Start(ctx, "upgraderules.Check", trace.WithAttributes(
+		attribute.String(keyFrom, string(from)),
+		attribute.String(keyTo, string(to)),
+	))
+	return ctx, func(d upgraderules.Decision) {
+		attrs := []attribute.KeyValue{
+			attribute.Bool(keySoft, d.Soft),
+			attribute.String(keyOutcome, string(d.Outcome())),
+		}
+		if d.Licensed {
+			attrs = append(attrs,
+				attribute.String(keyFromLicense, d.FromLicense.String()),
+				attribute.String(keyToLicense, d.ToLicense.String()),
+			)
+		}
+		if d.Err != nil {
+			attrs = append(attrs,
+				attribute.String(keyRule, string(d.Rule)),
+				attribute.String(keyReason, upgraderules.Reason(d.Err)),
+			)
+		}
+		span.SetAttributes(attrs...)
+		span.End()
+	}
+}
+
+// StartRule starts the span of a single rule.
+func (t *Tracer) StartRule(ctx context.Context, rule upgraderules.RuleID) func(error) {
+	_, span := t.tracer.Start(ctx, "upgraderules.Rule/"+string(rule), trace.WithAttributes(
+		attribute.String(keyRule, string(rule)),
+	))
+	return func(err error) {
+		span.SetAttributes(attribute.Bool(keyAllowed, err == nil))
+		if err != nil {
+			span.SetAttributes(attribute.String(keyReason, err.Error()))
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,76 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package tracing
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func attributeMap(attrs []attribute.KeyValue) map[attribute.Key]attribute.Value {
+	result := make(map[attribute.Key]attribute.Value)
+	for _, kv := range attrs {
+		result[kv.Key] = kv.Value
+	}
+	return result
+}
+
+func TestTracer(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	upgraderules.Check("3.10.8", "3.12.1",
+		upgraderules.WithTracer(NewTracer(tp)),
+		upgraderules.WithLicenses(upgraderules.LicenseEnterprise, upgraderules.LicenseEnterprise))
+
+	spans := sr.Ended()
+	// edition-downgrade, major-version, minor-increment, check
+	if len(spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(spans))
+	}
+	check := spans[len(spans)-1]
+	if check.Name() != "upgraderules.Check" {
+		t.Errorf("Expected check span last, got %s", check.Name())
+	}
+	attrs := attributeMap(check.Attributes())
+	if v := attrs[keyOutcome].AsString(); v != string(upgraderules.OutcomeDenied) {
+		t.Errorf("Expected outcome denied, got %s", v)
+	}
+	if v := attrs[keyRule].AsString(); v != string(upgraderules.RuleMinorIncrement) {
+		t.Errorf("Expected rule %s, got %s", upgraderules.RuleMinorIncrement, v)
+	}
+	if v := attrs[keyFromLicense].AsString(); v != "enterprise" {
+		t.Errorf("Expected from license enterprise, got %s", v)
+	}
+	for _, s := range spans[:len(spans)-1] {
+		if s.Parent().SpanID() != check.SpanContext().SpanID() {
+			t.Errorf("Expected rule span %s to be a child of the check span", s.Name())
+		}
+	}
+	if v := attributeMap(spans[2].Attributes())[keyAllowed].AsBool(); v {
+		t.Errorf("Expected rule span %s to deny", spans[2].Name())
+	}
+}
@@ -0,0 +1,68 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+// Package upgraderulestest provides helpers for testing code that
+// uses the upgrade rules.
+package upgraderulestest
+
+import (
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// TB is the subset of testing.TB used by the assertion helpers.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// MustAllow fails the test when upgrading from given `from` version
+// to given `to` version is not allowed.
+func MustAllow(t TB, from, to upgraderules.VersionString, opts ...upgraderules.Option) upgraderules.Decision {
+	t.Helper()
+	d := upgraderules.Check(from, to, opts...)
+	if !d.Allowed() {
+		t.Fatalf("%s -> %s should be allowed, got %s (%s)", from, to, d.Err, d.Rule)
+	}
+	return d
+}
+
+// MustDeny fails the test when upgrading from given `from` version
+// to given `to` version is allowed.
+func MustDeny(t TB, from, to upgraderules.VersionString, opts ...upgraderules.Option) upgraderules.Decision {
+	t.Helper()
+	d := upgraderules.Check(from, to, opts...)
+	if d.Allowed() {
+		t.Fatalf("%s -> %s should be denied, got %s", from, to, d.Outcome())
+	}
+	return d
+}
+
+// MustDenyWithRule fails the test when upgrading from given `from` version
+// to given `to` version is not denied by the given rule.
+func MustDenyWithRule(t TB, from, to upgraderules.VersionString, rule upgraderules.RuleID, opts ...upgraderules.Option) upgraderules.Decision {
+	t.Helper()
+	d := upgraderules.Check(from, to, opts...)
+	if d.Allowed() {
+		t.Fatalf("%s -> %s should be denied by %s, got %s", from, to, rule, d.Outcome())
+	} else if d.Rule != rule {
+		t.Fatalf("%s -> %s should be denied by %s, got denied by %s (%s)", from, to, rule, d.Rule, d.Err)
+	}
+	return d
+}
@@ -0,0 +1,76 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"fmt"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	failures []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestMustAllow(t *testing.T) {
+	var f fakeTB
+	MustAllow(&f, "3.3.8", "3.4.0")
+	if len(f.failures) != 0 {
+		t.Errorf("Expected no failures, got %v", f.failures)
+	}
+	MustAllow(&f, "3.3.8", "3.5.0")
+	if len(f.failures) != 1 {
+		t.Errorf("Expected 1 failure, got %v", f.failures)
+	}
+}
+
+func TestMustDeny(t *testing.T) {
+	var f fakeTB
+	MustDeny(&f, "3.3.8", "3.5.0")
+	if len(f.failures) != 0 {
+		t.Errorf("Expected no failures, got %v", f.failures)
+	}
+	MustDeny(&f, "3.3.8", "3.5.0", upgraderules.WithSoft())
+	if len(f.failures) != 1 {
+		t.Errorf("Expected 1 failure, got %v", f.failures)
+	}
+}
+
+func TestMustDenyWithRule(t *testing.T) {
+	var f fakeTB
+	MustDenyWithRule(&f, "3.3.8", "4.0.0", upgraderules.RuleMajorVersion)
+	if len(f.failures) != 0 {
+		t.Errorf("Expected no failures, got %v", f.failures)
+	}
+	MustDenyWithRule(&f, "3.3.8", "3.5.0", upgraderules.RuleMajorVersion)
+	MustDenyWithRule(&f, "3.3.8", "3.3.9", upgraderules.RuleMajorVersion)
+	if len(f.failures) != 2 {
+		t.Errorf("Expected 2 failures, got %v", f.failures)
+	}
+}
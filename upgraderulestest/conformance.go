@@ -0,0 +1,188 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// defaultConformanceTimeout is the time a rule may take to return
+// after its context is canceled.
+const defaultConformanceTimeout = time.Second
+
+// RuleConformance checks that a custom upgraderules.Rule keeps the
+// contract expected by Check, using a battery of generated upgrades.
+type RuleConformance struct {
+	// Pairs are the upgrades to evaluate the rule with. When empty, every
+	// combination of the patches 0-2 of 3.9 up to 3.13, a pre-release and
+	// the next major version is used.
+	Pairs []Pair
+	// Timeout is the time the rule may take to return when its context
+	// is canceled, 1 second when 0.
+	Timeout time.Duration
+}
+
+// AssertRuleConformance fails the test when the given rule does not keep
+// the contract of a Rule, using the default RuleConformance.
+func AssertRuleConformance(t TB, r upgraderules.Rule) {
+	t.Helper()
+	RuleConformance{}.Assert(t, r)
+}
+
+// Assert fails the test when the given rule does not keep the contract of
+// a Rule. Every upgrade is evaluated with and without the soft rules and
+// for every combination of licenses. The rule must:
+//   - have an ID and a Check function,
+//   - not panic,
+//   - be deterministic, i.e. return the same result for the same input,
+//   - return within the timeout when its context is canceled.
+func (c RuleConformance) Assert(t TB, r upgraderules.Rule) {
+	t.Helper()
+	if r.ID == "" {
+		t.Fatalf("Rule has no ID")
+		return
+	}
+	if r.Check == nil {
+		t.Fatalf("Rule %s has no Check function", r.ID)
+		return
+	}
+	pairs := c.Pairs
+	if len(pairs) == 0 {
+		versions := append(Versions(3, 9, 13, 3), "3.13.0-rc.1", "4.0.0")
+		pairs = Pairs(versions)
+	}
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = defaultConformanceTimeout
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, p := range pairs {
+		for _, in := range conformanceInputs(p) {
+			first, err := evaluateRule(r, in)
+			if err != nil {
+				t.Fatalf("Rule %s: %s", r.ID, err)
+				return
+			}
+			second, err := evaluateRule(r, in)
+			if err != nil {
+				t.Fatalf("Rule %s: %s", r.ID, err)
+				return
+			}
+			if first != second {
+				t.Fatalf("Rule %s is not deterministic for %s: got %s, then %s", r.ID, describeInput(in), first, second)
+				return
+			}
+			in.Context = canceled
+			done := make(chan error, 1)
+			go func() {
+				_, err := evaluateRule(r, in)
+				done <- err
+			}()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Fatalf("Rule %s with canceled context: %s", r.ID, err)
+					return
+				}
+			case <-time.After(timeout):
+				t.Fatalf("Rule %s did not return within %s after its context was canceled for %s", r.ID, timeout, describeInput(in))
+				return
+			}
+		}
+	}
+}
+
+// conformanceInputs returns the inputs to evaluate a rule with for the
+// given upgrade.
+func conformanceInputs(p Pair) []upgraderules.RuleInput {
+	base := upgraderules.RuleInput{
+		Context: context.Background(),
+		From:    upgraderules.ParseVersion(p.From),
+		To:      upgraderules.ParseVersion(p.To),
+	}
+	licenses := []upgraderules.License{upgraderules.LicenseCommunity, upgraderules.LicenseEnterprise}
+	var result []upgraderules.RuleInput
+	for _, soft := range []bool{false, true} {
+		in := base
+		in.Soft = soft
+		result = append(result, in)
+		for _, from := range licenses {
+			for _, to := range licenses {
+				in.Licensed, in.FromLicense, in.ToLicense = true, from, to
+				result = append(result, in)
+			}
+		}
+	}
+	return result
+}
+
+// ruleResult is the comparable result of a single rule evaluation.
+type ruleResult struct {
+	applies bool
+	err     string
+}
+
+// String returns the result as text.
+func (r ruleResult) String() string {
+	switch {
+	case !r.applies:
+		return "not applied"
+	case r.err == "":
+		return "allowed"
+	default:
+		return fmt.Sprintf("denied (%s)", r.err)
+	}
+}
+
+// evaluateRule evaluates the rule like Check does. It returns an error
+// when the rule panics.
+func evaluateRule(r upgraderules.Rule, in upgraderules.RuleInput) (result ruleResult, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic for %s: %v", describeInput(in), p)
+		}
+	}()
+	if r.Applies != nil && !r.Applies(in) {
+		return ruleResult{}, nil
+	}
+	result.applies = true
+	if ruleErr := r.Check(in); ruleErr != nil {
+		result.err = ruleErr.Error()
+	}
+	return result, nil
+}
+
+// describeInput returns a short description of the input for messages.
+func describeInput(in upgraderules.RuleInput) string {
+	s := fmt.Sprintf("%s -> %s", in.From.Version(), in.To.Version())
+	if in.Soft {
+		s += " (soft)"
+	}
+	if in.Licensed {
+		s += fmt.Sprintf(" (%s -> %s)", in.FromLicense, in.ToLicense)
+	}
+	return s
+}
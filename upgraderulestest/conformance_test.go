@@ -0,0 +1,72 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestAssertRuleConformanceBuiltInRules(t *testing.T) {
+	for _, r := range upgraderules.DefaultRuleSet().Rules() {
+		AssertRuleConformance(t, r)
+	}
+}
+
+func TestAssertRuleConformanceViolations(t *testing.T) {
+	calls := 0
+	pairs := []Pair{{From: "3.11.8", To: "3.12.1"}}
+	tests := []struct {
+		Name     string
+		Rule     upgraderules.Rule
+		Expected string
+	}{
+		{"no ID", upgraderules.Rule{Check: func(upgraderules.RuleInput) error { return nil }}, "Rule has no ID"},
+		{"no check", upgraderules.Rule{ID: "custom"}, "Rule custom has no Check function"},
+		{"panic", upgraderules.Rule{ID: "custom", Check: func(in upgraderules.RuleInput) error {
+			panic("boom")
+		}}, "Rule custom: panic for 3.11.8 -> 3.12.1: boom"},
+		{"not deterministic", upgraderules.Rule{ID: "custom", Check: func(in upgraderules.RuleInput) error {
+			calls++
+			if calls%2 == 0 {
+				return errors.New("Denied")
+			}
+			return nil
+		}}, "Rule custom is not deterministic for 3.11.8 -> 3.12.1: got allowed, then denied (Denied)"},
+		{"ignores cancellation", upgraderules.Rule{ID: "custom", Check: func(in upgraderules.RuleInput) error {
+			if in.Context.Err() != nil {
+				time.Sleep(time.Second)
+			}
+			return nil
+		}}, "Rule custom did not return within 10ms after its context was canceled for 3.11.8 -> 3.12.1"},
+	}
+	for _, test := range tests {
+		var f fakeTB
+		RuleConformance{Pairs: pairs, Timeout: 10 * time.Millisecond}.Assert(&f, test.Rule)
+		if len(f.failures) != 1 || !strings.HasPrefix(f.failures[0], test.Expected) {
+			t.Errorf("%s: expected failure '%s', got %v", test.Name, test.Expected, f.failures)
+		}
+	}
+}
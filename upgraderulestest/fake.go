@@ -0,0 +1,130 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"sync"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Call is a single call recorded by a FakeChecker.
+type Call struct {
+	From upgraderules.VersionString
+	To   upgraderules.VersionString
+	// Licensed is set for calls to CheckWithLicense
+	Licensed    bool
+	FromLicense upgraderules.License
+	ToLicense   upgraderules.License
+}
+
+// FakeChecker is an upgraderules.Checker with scripted decisions.
+// Upgrades that are not scripted are allowed, unless DenyByDefault is used.
+// All calls are recorded. It is safe for concurrent use.
+type FakeChecker struct {
+	mutex         sync.Mutex
+	scripted      map[Pair]upgraderules.RuleID
+	denyByDefault bool
+	calls         []Call
+}
+
+var _ upgraderules.Checker = &FakeChecker{}
+
+// NewFakeChecker creates a FakeChecker that allows all upgrades.
+func NewFakeChecker() *FakeChecker {
+	return &FakeChecker{
+		scripted: make(map[Pair]upgraderules.RuleID),
+	}
+}
+
+// Allow scripts the upgrade from `from` to `to` to be allowed.
+func (f *FakeChecker) Allow(from, to upgraderules.VersionString) *FakeChecker {
+	return f.script(from, to, "")
+}
+
+// Deny scripts the upgrade from `from` to `to` to be denied by the given rule.
+func (f *FakeChecker) Deny(from, to upgraderules.VersionString, rule upgraderules.RuleID) *FakeChecker {
+	return f.script(from, to, rule)
+}
+
+// DenyByDefault makes the fake deny upgrades that are not scripted.
+// They are denied without a rule.
+func (f *FakeChecker) DenyByDefault() *FakeChecker {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.denyByDefault = true
+	return f
+}
+
+// Calls returns the calls made so far, in order.
+func (f *FakeChecker) Calls() []Call {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Check returns the scripted decision for upgrading from `from` to `to`.
+func (f *FakeChecker) Check(from, to upgraderules.VersionString) upgraderules.Decision {
+	return f.decide(Call{From: from, To: to})
+}
+
+// CheckWithLicense returns the scripted decision for upgrading from `from` to `to`.
+// The licenses are recorded, but do not influence the decision.
+func (f *FakeChecker) CheckWithLicense(from, to upgraderules.VersionString, fromLicense, toLicense upgraderules.License) upgraderules.Decision {
+	return f.decide(Call{From: from, To: to, Licensed: true, FromLicense: fromLicense, ToLicense: toLicense})
+}
+
+// script sets the rule denying the given upgrade, or allows it for an empty rule.
+func (f *FakeChecker) script(from, to upgraderules.VersionString, rule upgraderules.RuleID) *FakeChecker {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.scripted[Pair{From: from, To: to}] = rule
+	return f
+}
+
+// decide records the call and returns its scripted decision.
+func (f *FakeChecker) decide(c Call) upgraderules.Decision {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls = append(f.calls, c)
+	d := upgraderules.Decision{
+		From:        c.From,
+		To:          c.To,
+		Licensed:    c.Licensed,
+		FromLicense: c.FromLicense,
+		ToLicense:   c.ToLicense,
+	}
+	rule, scripted := f.scripted[Pair{From: c.From, To: c.To}]
+	if !scripted && !f.denyByDefault || scripted && rule == "" {
+		return d
+	}
+	d.Rule = rule
+	d.Err = &upgraderules.Error{
+		Rule:        rule,
+		Message:     "Upgrade is denied by the fake checker",
+		From:        c.From,
+		To:          c.To,
+		Licensed:    c.Licensed,
+		FromLicense: c.FromLicense,
+		ToLicense:   c.ToLicense,
+	}
+	return d
+}
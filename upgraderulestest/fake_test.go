@@ -0,0 +1,58 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestFakeChecker(t *testing.T) {
+	f := NewFakeChecker().
+		Deny("3.3.8", "3.4.0", upgraderules.RuleMinorIncrement).
+		Allow("3.3.8", "4.0.0")
+	if d := f.Check("3.3.8", "3.4.0"); d.Allowed() || d.Rule != upgraderules.RuleMinorIncrement {
+		t.Errorf("Expected denial by %s, got %s (%s)", upgraderules.RuleMinorIncrement, d.Outcome(), d.Rule)
+	} else if msg := d.Err.Error(); msg != "Upgrade is denied by the fake checker (from 3.3.8 to 3.4.0)" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if d := f.Check("3.3.8", "4.0.0"); !d.Allowed() {
+		t.Errorf("Expected scripted upgrade to be allowed, got %s", d.Err)
+	}
+	if d := f.CheckWithLicense("3.3.8", "3.5.0", upgraderules.LicenseEnterprise, upgraderules.LicenseCommunity); !d.Allowed() {
+		t.Errorf("Expected unscripted upgrade to be allowed, got %s", d.Err)
+	}
+	f.DenyByDefault()
+	if d := f.Check("3.3.8", "3.5.0"); d.Allowed() {
+		t.Error("Expected unscripted upgrade to be denied")
+	}
+	if d := f.Check("3.3.8", "4.0.0"); !d.Allowed() {
+		t.Errorf("Expected scripted upgrade to be allowed, got %s", d.Err)
+	}
+	calls := f.Calls()
+	if len(calls) != 5 {
+		t.Fatalf("Expected 5 calls, got %d", len(calls))
+	}
+	if c := calls[2]; !c.Licensed || c.FromLicense != upgraderules.LicenseEnterprise || c.To != "3.5.0" {
+		t.Errorf("Unexpected call %+v", c)
+	}
+}
@@ -0,0 +1,58 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"fmt"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Pair is an upgrade from one version to another.
+type Pair struct {
+	From upgraderules.VersionString
+	To   upgraderules.VersionString
+}
+
+// Versions returns the versions <major>.<minor>.<patch> for every minor
+// from firstMinor up to and including lastMinor, and every patch
+// from 0 up to, but excluding, patches.
+func Versions(major, firstMinor, lastMinor, patches int) []upgraderules.VersionString {
+	var result []upgraderules.VersionString
+	for minor := firstMinor; minor <= lastMinor; minor++ {
+		for patch := 0; patch < patches; patch++ {
+			result = append(result, upgraderules.VersionString(fmt.Sprintf("%d.%d.%d", major, minor, patch)))
+		}
+	}
+	return result
+}
+
+// Pairs returns every combination of the given versions,
+// including upgrades to the same version.
+func Pairs(versions []upgraderules.VersionString) []Pair {
+	result := make([]Pair, 0, len(versions)*len(versions))
+	for _, from := range versions {
+		for _, to := range versions {
+			result = append(result, Pair{From: from, To: to})
+		}
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestVersions(t *testing.T) {
+	versions := Versions(3, 10, 11, 2)
+	expected := []string{"3.10.0", "3.10.1", "3.11.0", "3.11.1"}
+	if len(versions) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, versions)
+	}
+	for i, v := range versions {
+		if string(v) != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, versions)
+		}
+	}
+}
+
+func TestPairs(t *testing.T) {
+	pairs := Pairs(Versions(3, 10, 12, 1))
+	if len(pairs) != 9 {
+		t.Fatalf("Expected 9 pairs, got %d", len(pairs))
+	}
+	for _, p := range pairs {
+		if p.From.Minor() == p.To.Minor() || p.From.Minor()+1 == p.To.Minor() {
+			MustAllow(t, p.From, p.To)
+		} else {
+			MustDenyWithRule(t, p.From, p.To, upgraderules.RuleMinorIncrement)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"fmt"
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// Case is a single expected outcome of a Table.
+type Case struct {
+	From upgraderules.VersionString
+	To   upgraderules.VersionString
+	// Allowed is set when the upgrade must be allowed
+	Allowed bool
+	// Rule is the rule that must deny the upgrade.
+	// When empty any rule may deny it.
+	Rule upgraderules.RuleID
+}
+
+// String returns a name for the case, used as name of its subtest.
+func (c Case) String() string {
+	if c.Allowed {
+		return fmt.Sprintf("%s->%s allowed", c.From, c.To)
+	}
+	if c.Rule != "" {
+		return fmt.Sprintf("%s->%s denied by %s", c.From, c.To, c.Rule)
+	}
+	return fmt.Sprintf("%s->%s denied", c.From, c.To)
+}
+
+// Table is a list of expected outcomes.
+// The Allow and Deny methods return a new table, so tables can be
+// built from a shared base without affecting it.
+type Table []Case
+
+// Allow returns a copy of the table expecting the given upgrade to be allowed.
+func (tbl Table) Allow(from, to upgraderules.VersionString) Table {
+	return tbl.with(Case{From: from, To: to, Allowed: true})
+}
+
+// Deny returns a copy of the table expecting the given upgrade to be denied
+// by the given rule. Pass an empty rule to accept any rule.
+func (tbl Table) Deny(from, to upgraderules.VersionString, rule upgraderules.RuleID) Table {
+	return tbl.with(Case{From: from, To: to, Rule: rule})
+}
+
+// with returns a copy of the table with given case appended.
+func (tbl Table) with(c Case) Table {
+	return append(tbl[:len(tbl):len(tbl)], c)
+}
+
+// Run runs a subtest per case, checking it with the given options.
+func (tbl Table) Run(t *testing.T, opts ...upgraderules.Option) {
+	t.Helper()
+	for _, c := range tbl {
+		c := c
+		t.Run(c.String(), func(t *testing.T) {
+			switch {
+			case c.Allowed:
+				MustAllow(t, c.From, c.To, opts...)
+			case c.Rule != "":
+				MustDenyWithRule(t, c.From, c.To, c.Rule, opts...)
+			default:
+				MustDeny(t, c.From, c.To, opts...)
+			}
+		})
+	}
+}
+
+// StrictTable returns the expected outcomes of the strict rules,
+// without licenses.
+func StrictTable() Table {
+	return Table{}.
+		// Same version
+		Allow("1.2.3", "1.2.3").
+		// Different major
+		Deny("2.2.3", "1.2.3", upgraderules.RuleMajorVersion).
+		Deny("1.2.3", "2.2.3", upgraderules.RuleMajorVersion).
+		// Same major, different minor
+		Allow("3.2.2", "3.3.0").
+		Allow("3.2.2", "3.3.10").
+		Deny("3.3.2", "3.2.10", upgraderules.RuleMinorIncrement).
+		Deny("3.3.2", "3.5.10", upgraderules.RuleMinorIncrement).
+		// Same major & minor, different patch
+		Allow("3.2.2", "3.2.88").
+		Allow("3.2.88", "3.2.8").
+		Deny("3.2.88", "3.2.rc7", upgraderules.RulePreReleaseTarget)
+}
+
+// SoftTable returns the expected outcomes of the soft rules (see
+// upgraderules.WithSoft), without licenses.
+func SoftTable() Table {
+	return Table{}.
+		// Same version
+		Allow("1.2.3", "1.2.3").
+		// Different major
+		Deny("2.2.3", "1.2.3", upgraderules.RuleMajorVersion).
+		Deny("1.2.3", "2.2.3", upgraderules.RuleMajorVersion).
+		// Same major, different minor
+		Allow("3.2.1", "3.3.1").
+		Allow("3.2.1", "3.4.8").
+		Deny("3.2.1", "3.5.rc7", upgraderules.RulePreReleaseTarget).
+		Deny("3.3.2", "3.2.10", upgraderules.RuleMinorDowngrade).
+		// Same major & minor, different patch
+		Allow("3.2.88", "3.2.8")
+}
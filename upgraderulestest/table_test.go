@@ -0,0 +1,49 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderulestest
+
+import (
+	"testing"
+
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+func TestStrictTable(t *testing.T) {
+	StrictTable().Run(t)
+}
+
+func TestSoftTable(t *testing.T) {
+	SoftTable().Run(t, upgraderules.WithSoft())
+}
+
+func TestTableIsNotShared(t *testing.T) {
+	base := Table{}.Allow("3.3.8", "3.3.9")
+	a := base.Allow("3.3.8", "3.4.0")
+	b := base.Deny("3.3.8", "3.5.0", upgraderules.RuleMinorIncrement)
+	if len(base) != 1 || len(a) != 2 || len(b) != 2 {
+		t.Fatalf("Unexpected lengths %d, %d, %d", len(base), len(a), len(b))
+	}
+	if !a[1].Allowed || b[1].Allowed {
+		t.Errorf("Tables share their cases: %v, %v", a, b)
+	}
+	a.Run(t)
+	b.Run(t)
+}
@@ -0,0 +1,113 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParsedVersion is a driver.Version that has been split into its
+// components once. Use it with CheckParsed when evaluating the same
+// version against many others, to avoid parsing it on every check.
+// The zero value is the empty version.
+type ParsedVersion struct {
+	version VersionString
+	major   int
+	minor   int
+	sub     string
+}
+
+// ParseVersion splits the given version the same way as driver.Version
+// does. It does not allocate for well-formed versions.
+func ParseVersion(v VersionString) ParsedVersion {
+	s := string(v)
+	p := ParsedVersion{version: v}
+	major, rest, ok := cutDot(s)
+	p.major, _ = strconv.Atoi(major)
+	if !ok {
+		return p
+	}
+	minor, rest, ok := cutDot(rest)
+	p.minor, _ = strconv.Atoi(minor)
+	if ok {
+		p.sub = rest
+	}
+	return p
+}
+
+// Version returns the version that was parsed.
+func (p ParsedVersion) Version() VersionString {
+	return p.version
+}
+
+// Major returns the major version, like driver.Version.Major.
+func (p ParsedVersion) Major() int {
+	return p.major
+}
+
+// Minor returns the minor version, like driver.Version.Minor.
+func (p ParsedVersion) Minor() int {
+	return p.minor
+}
+
+// Sub returns everything after the minor version, like driver.Version.Sub.
+func (p ParsedVersion) Sub() string {
+	return p.sub
+}
+
+// Patch returns the leading number of Sub, e.g. 1 for "3.12.1" and
+// "3.12.1-rc.2". It returns false when Sub does not start with a number.
+func (p ParsedVersion) Patch() (int, bool) {
+	end := 0
+	for end < len(p.sub) && end < 9 && p.sub[end] >= '0' && p.sub[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, false
+	}
+	patch, _ := strconv.Atoi(p.sub[:end])
+	return patch, true
+}
+
+// IsPreRelease returns true when Sub contains a letter, as in
+// "3.12.0-rc.1", "3.2.rc7" or "4.0.0-devel".
+func (p ParsedVersion) IsPreRelease() bool {
+	for i := 0; i < len(p.sub); i++ {
+		if c := p.sub[i] | 0x20; c >= 'a' && c <= 'z' {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the version that was parsed.
+func (p ParsedVersion) String() string {
+	return string(p.version)
+}
+
+// cutDot slices s around the first '.'.
+func cutDot(s string) (before, after string, found bool) {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return s[:i], s[i+1:], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,33 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build !nodriver
+// +build !nodriver
+
+package upgraderules
+
+import (
+	driver "github.com/arangodb/go-driver"
+)
+
+// VersionString is the version type used throughout the API of this
+// package. It is driver.Version, unless the package is built with the
+// nodriver build tag (see version_nodriver.go).
+type VersionString = driver.Version
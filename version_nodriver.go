@@ -0,0 +1,84 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build nodriver
+// +build nodriver
+
+package upgraderules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// VersionString is the version type used throughout the API of this
+// package. When built with the nodriver build tag it replaces
+// driver.Version, so small binaries embedding the rules do not depend
+// on go-driver. Its methods behave exactly like those of driver.Version.
+type VersionString string
+
+// Major returns the major part of the version, e.g. 3 for "3.12.1".
+func (v VersionString) Major() int {
+	parts := strings.Split(string(v), ".")
+	result, _ := strconv.Atoi(parts[0])
+	return result
+}
+
+// Minor returns the minor part of the version, e.g. 12 for "3.12.1".
+func (v VersionString) Minor() int {
+	parts := strings.Split(string(v), ".")
+	if len(parts) >= 2 {
+		result, _ := strconv.Atoi(parts[1])
+		return result
+	}
+	return 0
+}
+
+// Sub returns everything after the minor part, e.g. "1" for "3.12.1".
+func (v VersionString) Sub() string {
+	parts := strings.SplitN(string(v), ".", 3)
+	if len(parts) == 3 {
+		return parts[2]
+	}
+	return ""
+}
+
+// SubInt returns Sub as an integer, or false when it is not a number.
+func (v VersionString) SubInt() (int, bool) {
+	result, err := strconv.Atoi(v.Sub())
+	return result, err == nil
+}
+
+// CompareTo returns -1, 0 or 1 when v is older than, equal to or newer
+// than other.
+func (v VersionString) CompareTo(other VersionString) int {
+	if c := compareInts(v.Major(), other.Major()); c != 0 {
+		return c
+	}
+	if c := compareInts(v.Minor(), other.Minor()); c != 0 {
+		return c
+	}
+	a, aIsInt := v.SubInt()
+	b, bIsInt := other.SubInt()
+	if !aIsInt || !bIsInt {
+		return strings.Compare(v.Sub(), other.Sub())
+	}
+	return compareInts(a, b)
+}
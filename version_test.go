@@ -0,0 +1,62 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, v := range []VersionString{"3.4.5", "3.4", "3", "", "3.12.1-rc.2", "3.x.1", "4.0.0-devel", "3.11.", ".5"} {
+		p := ParseVersion(v)
+		if p.Major() != v.Major() || p.Minor() != v.Minor() || p.Sub() != v.Sub() {
+			t.Errorf("ParseVersion(%q) = %d.%d.%q, expected %d.%d.%q", v, p.Major(), p.Minor(), p.Sub(), v.Major(), v.Minor(), v.Sub())
+		}
+		if p.Version() != v || p.String() != string(v) {
+			t.Errorf("ParseVersion(%q) returned version %q", v, p.Version())
+		}
+	}
+}
+
+func TestCheckParsed(t *testing.T) {
+	from := ParseVersion("3.3.8")
+	for _, to := range []VersionString{"3.3.9", "3.4.0", "3.5.0", "4.0.0", "3.2.1"} {
+		expected := Check(from.Version(), to, WithTrace())
+		d := CheckParsed(from, ParseVersion(to), WithTrace())
+		if d.From != from.Version() || d.To != to {
+			t.Errorf("%s -> %s: decision has versions %s -> %s", from, to, d.From, d.To)
+		}
+		if d.Outcome() != expected.Outcome() || d.Rule != expected.Rule {
+			t.Errorf("%s -> %s: expected %s (%s), got %s (%s)", from, to, expected.Outcome(), expected.Rule, d.Outcome(), d.Rule)
+		}
+		if d.Trace.String() != expected.Trace.String() {
+			t.Errorf("%s -> %s: expected trace\n%s\ngot\n%s", from, to, expected.Trace, d.Trace)
+		}
+	}
+}
+
+func BenchmarkCheckParsed(b *testing.B) {
+	from, to := ParseVersion("3.3.8"), ParseVersion("3.4.1")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CheckParsed(from, to)
+	}
+}
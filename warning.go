@@ -0,0 +1,146 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"fmt"
+)
+
+// WarningCode identifies the kind of a Warning.
+type WarningCode string
+
+const (
+	// WarningPreReleaseTarget means the version being upgraded to is a
+	// pre-release (e.g. 3.12.0-rc.1), which is not meant for production
+	WarningPreReleaseTarget WarningCode = "pre-release-target"
+	// WarningPreReleaseSource means the version being upgraded from is a
+	// pre-release, upgrades from which are not supported
+	WarningPreReleaseSource WarningCode = "pre-release-source"
+	// WarningFirstRelease means the version being upgraded to is the first
+	// release (.0) of its minor version. When the releases are known (see
+	// WithReleases), it is only given when a later patch release exists.
+	WarningFirstRelease WarningCode = "first-release"
+)
+
+// Warning is an advisory about an upgrade. Unlike an error, a warning
+// does not deny the upgrade: the upgrade is allowed with caveats.
+type Warning struct {
+	// Code identifies the kind of warning
+	Code WarningCode `json:"code"`
+	// Message describes the warning
+	Message string `json:"message"`
+
+	// id identifies Message independent of its language, see Catalog
+	id MessageID
+	// args holds the values of the placeholders in the message
+	args []interface{}
+}
+
+// newWarning creates a warning with the English message for the given
+// message ID formatted with the given arguments.
+func newWarning(code WarningCode, id MessageID, args ...interface{}) Warning {
+	return Warning{Code: code, Message: fmt.Sprintf(catalogEnglish[id], args...), id: id, args: args}
+}
+
+// String returns the message of the warning.
+func (w Warning) String() string {
+	return w.Message
+}
+
+// CheckWithWarnings is Check returning the advisory warnings and the
+// error of the decision, for callers that want to allow upgrades with
+// caveats, but do not need the full Decision.
+func CheckWithWarnings(from, to VersionString, opts ...Option) ([]Warning, error) {
+	d := Check(from, to, opts...)
+	return d.Warnings, d.Err
+}
+
+// warnings returns the warnings for an upgrade from `from` to `to`,
+// given the known releases (if any).
+// It returns nil, without allocating, when there are none.
+func warnings(from, to ParsedVersion, releases []ParsedVersion) []Warning {
+	var result []Warning
+	if from.IsPreRelease() {
+		result = append(result, newWarning(WarningPreReleaseSource, MessagePreReleaseSourceWarning, from))
+	}
+	if to.IsPreRelease() {
+		result = append(result, newWarning(WarningPreReleaseTarget, MessagePreReleaseTargetWarning, to))
+	} else if patch, ok := to.Patch(); ok && patch == 0 && from.version != to.version {
+		if releases == nil {
+			result = append(result, newWarning(WarningFirstRelease, MessageFirstReleaseWarning, to, to.major, to.minor))
+		} else if latest, found := latestPatch(releases, to); found {
+			result = append(result, newWarning(WarningFirstRelease, MessageFirstReleaseLaterPatchWarning, to, to.major, to.minor, latest))
+		}
+	}
+	return result
+}
+
+// latestPatch returns the latest release of the minor version of v, that
+// is later than v. Pre-releases are ignored.
+func latestPatch(releases []ParsedVersion, v ParsedVersion) (ParsedVersion, bool) {
+	var latest ParsedVersion
+	found := false
+	for _, r := range releases {
+		if r.major != v.major || r.minor != v.minor || r.IsPreRelease() || !versionLess(v, r) {
+			continue
+		}
+		if !found || versionLess(latest, r) {
+			latest, found = r, true
+		}
+	}
+	return latest, found
+}
+
+// RuleWarningsAsErrors denies upgrades with a warning that is promoted to
+// an error, see WithWarningsAsErrors. The rule of such a decision is the
+// code of the warning, e.g. RuleID(WarningFirstRelease).
+const RuleWarningsAsErrors RuleID = "warnings-as-errors"
+
+// ruleWarningsAsErrors implements RuleWarningsAsErrors.
+var ruleWarningsAsErrors = Rule{
+	ID:      RuleWarningsAsErrors,
+	Applies: func(in RuleInput) bool { return len(in.WarningsAsErrors) > 0 },
+	Check:   func(in RuleInput) error { return promotedWarning(in.Warnings, in.WarningsAsErrors) },
+}
+
+// WarningError details a denial by a warning promoted to an error, see
+// WithWarningsAsErrors.
+type WarningError struct {
+	Warning Warning
+}
+
+// Error returns the message of the warning.
+func (e *WarningError) Error() string {
+	return e.Warning.Message
+}
+
+// promotedWarning returns an *Error for the first of the given warnings
+// that is promoted to an error, or nil if there is none.
+func promotedWarning(warnings []Warning, promoted []WarningCode) error {
+	for _, w := range warnings {
+		for _, c := range promoted {
+			if w.Code == c {
+				return &Error{Rule: RuleID(w.Code), Message: w.Message, MessageID: w.id, Args: w.args, cause: &WarningError{Warning: w}}
+			}
+		}
+	}
+	return nil
+}
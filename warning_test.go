@@ -0,0 +1,120 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package upgraderules
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		From     VersionString
+		To       VersionString
+		Warnings []WarningCode
+	}{
+		{"3.11.8", "3.11.9", nil},
+		{"3.11.8", "3.12.0", []WarningCode{WarningFirstRelease}},
+		{"3.12.0", "3.12.0", nil},
+		{"3.11.8", "3.12.0-rc.1", []WarningCode{WarningPreReleaseTarget}},
+		{"3.11.8", "3.12.rc7", []WarningCode{WarningPreReleaseTarget}},
+		{"3.12.0-rc.1", "3.12.0", []WarningCode{WarningPreReleaseSource, WarningFirstRelease}},
+		{"3.11.8", "4.0.0", []WarningCode{WarningFirstRelease}},
+	}
+	for _, test := range tests {
+		warnings, err := CheckWithWarnings(test.From, test.To, WithSoft())
+		var codes []string
+		for _, w := range warnings {
+			codes = append(codes, string(w.Code))
+		}
+		var expected []string
+		for _, c := range test.Warnings {
+			expected = append(expected, string(c))
+		}
+		if strings.Join(codes, ",") != strings.Join(expected, ",") {
+			t.Errorf("%s -> %s: expected warnings %v, got %v", test.From, test.To, expected, codes)
+		}
+		if test.To == "4.0.0" && err == nil {
+			t.Errorf("%s -> %s: expected error next to warnings", test.From, test.To)
+		}
+	}
+}
+
+func TestWarningsJSON(t *testing.T) {
+	d := Check("3.11.8", "3.12.0")
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("Marshal failed: %s", err)
+	}
+	expected := `"warnings":[{"code":"first-release","message":"Version 3.12.0 is the first release of 3.12, consider waiting for the first patch release"}]`
+	if !strings.Contains(string(encoded), expected) {
+		t.Errorf("Expected %s in %s", expected, encoded)
+	}
+}
+
+func TestParsedVersionPatch(t *testing.T) {
+	tests := map[VersionString]int{"3.12.1": 1, "3.12.10-rc.2": 10, "3.12.0": 0}
+	for v, expected := range tests {
+		if patch, ok := ParseVersion(v).Patch(); !ok || patch != expected {
+			t.Errorf("Patch of %s = %d (%v), expected %d", v, patch, ok, expected)
+		}
+	}
+	for _, v := range []VersionString{"3.12", "3.2.rc7"} {
+		if _, ok := ParseVersion(v).Patch(); ok {
+			t.Errorf("%s should not have a patch", v)
+		}
+	}
+}
+
+func TestWarningFirstReleaseWithReleases(t *testing.T) {
+	releases := WithReleases("3.11.8", "3.12.0", "3.12.1", "3.12.2", "3.12.3-rc.1", "3.13.0")
+	warnings, _ := CheckWithWarnings("3.11.8", "3.12.0", releases)
+	if len(warnings) != 1 || warnings[0].Code != WarningFirstRelease {
+		t.Fatalf("Expected first-release warning, got %v", warnings)
+	}
+	if msg := warnings[0].Message; msg != "Version 3.12.0 is the first release of 3.12, consider upgrading to 3.12.2 instead" {
+		t.Errorf("Unexpected message %q", msg)
+	}
+	if warnings, _ := CheckWithWarnings("3.12.3", "3.13.0", releases); len(warnings) != 0 {
+		t.Errorf("Expected no warning without a later patch release, got %v", warnings)
+	}
+}
+
+func TestWithWarningsAsErrors(t *testing.T) {
+	d := Check("3.11.8", "3.12.0", WithWarningsAsErrors(WarningFirstRelease))
+	if d.Rule != RuleID(WarningFirstRelease) || IsRetryable(d.Err) {
+		t.Fatalf("Expected terminal denial by %s, got %q (%v)", WarningFirstRelease, d.Rule, d.Err)
+	}
+	if e, ok := d.Err.(*Error); !ok || e.From != "3.11.8" || e.Message != d.Warnings[0].Message {
+		t.Errorf("Unexpected error %#v", d.Err)
+	}
+	if d := Check("3.11.8", "3.12.0", WithReleases("3.12.0"), WithWarningsAsErrors(WarningFirstRelease)); !d.Allowed() {
+		t.Errorf("Expected upgrade without later patch release to be allowed, got %s", d.Err)
+	}
+	if d := Check("3.11.8", "3.12.1", WithWarningsAsErrors(WarningFirstRelease)); !d.Allowed() {
+		t.Errorf("Expected upgrade without warning to be allowed, got %s", d.Err)
+	}
+	p := Policy{WarningsAsErrors: []WarningCode{WarningPreReleaseTarget}}
+	if d := p.Check("3.11.8", "3.12.0-rc.1"); d.Rule != RuleID(WarningPreReleaseTarget) {
+		t.Errorf("Expected denial by policy, got %q", d.Rule)
+	}
+}
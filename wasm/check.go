@@ -0,0 +1,64 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import (
+	upgraderules "github.com/arangodb/go-upgrade-rules"
+)
+
+// checkRequest holds the arguments of the JavaScript check function.
+type checkRequest struct {
+	From        string
+	To          string
+	FromLicense string
+	ToLicense   string
+	Soft        bool
+}
+
+// check runs the rules for the given request and returns an empty string
+// when the upgrade is allowed, or why it is not.
+// It implements the JavaScript check function in plain Go, so it is tested
+// without a JavaScript runtime. The arguments are untrusted input, so they
+// are checked with upgraderules.EvaluateRaw.
+func check(req checkRequest) string {
+	fromLicense, toLicense := req.FromLicense, req.ToLicense
+	if fromLicense != "" || toLicense != "" {
+		// A missing license defaults to community
+		if fromLicense == "" {
+			fromLicense = upgraderules.LicenseCommunity.String()
+		}
+		if toLicense == "" {
+			toLicense = upgraderules.LicenseCommunity.String()
+		}
+	}
+	var opts []upgraderules.Option
+	if req.Soft {
+		opts = append(opts, upgraderules.WithSoft())
+	}
+	d, err := upgraderules.EvaluateRaw(req.From, req.To, fromLicense, toLicense, opts...)
+	if err != nil {
+		return err.Error()
+	}
+	if d.Err != nil {
+		return d.Err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+package main
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		Request  checkRequest
+		Expected string
+	}{
+		{checkRequest{From: "3.11.8", To: "3.12.1"}, ""},
+		{checkRequest{From: "3.11.8", To: "3.12.1", FromLicense: "enterprise", ToLicense: "enterprise"}, ""},
+		{checkRequest{From: "3.10.8", To: "3.12.1"}, "Minor versions may only increment by 1 (from 3.10.8 to 3.12.1)"},
+		{checkRequest{From: "3.11.8", To: "3.12.1", FromLicense: "enterprise"}, "Upgrade from Enterprise to Community edition is not possible (from 3.11.8 enterprise to 3.12.1 community)"},
+		{checkRequest{From: "3.11.8", To: "3.12.1", ToLicense: "enterprise"}, ""},
+		{checkRequest{From: "3.10.8", To: "3.12.1", Soft: true}, ""},
+		{checkRequest{From: "3.11.8", To: "latest"}, "Invalid to 'latest': version must have at least a major and minor part"},
+		{checkRequest{From: "3.11.8", To: "3.12.1", FromLicense: "gold"}, "Invalid fromLicense 'gold': unknown license"},
+	}
+	for _, test := range tests {
+		if reason := check(test.Request); reason != test.Expected {
+			t.Errorf("Request %+v: expected %q, got %q", test.Request, test.Expected, reason)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+//
+// DISCLAIMER
+//
+// Copyright 2018 ArangoDB GmbH, Cologne, Germany
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Copyright holder is ArangoDB GmbH, Cologne, Germany
+//
+
+//go:build js && wasm
+// +build js,wasm
+
+// Command wasm exposes the upgrade rules to JavaScript.
+// Build it with:
+//
+//	GOOS=js GOARCH=wasm go build -tags nodriver -o upgraderules.wasm ./wasm
+//
+// and load the result with upgraderules.js.
+package main
+
+import (
+	"syscall/js"
+)
+
+func main() {
+	js.Global().Set("goUpgradeRules", js.ValueOf(map[string]interface{}{
+		"check": js.FuncOf(jsCheck),
+	}))
+	// Keep the Go runtime alive so the callbacks stay usable.
+	select {}
+}
+
+// jsCheck is called from JavaScript as check(from, to, options).
+// Options is an optional object with fields `soft` (bool),
+// `fromLicense` and `toLicense` ("community" or "enterprise").
+// It returns null when the upgrade is allowed, or a string describing
+// why it is not.
+func jsCheck(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 {
+		return "expected at least 2 arguments (from, to)"
+	}
+	req := checkRequest{From: args[0].String(), To: args[1].String()}
+	if len(args) > 2 && args[2].Type() == js.TypeObject {
+		opts := args[2]
+		if v := opts.Get("soft"); v.Type() == js.TypeBoolean {
+			req.Soft = v.Bool()
+		}
+		if v := opts.Get("fromLicense"); v.Type() == js.TypeString {
+			req.FromLicense = v.String()
+		}
+		if v := opts.Get("toLicense"); v.Type() == js.TypeString {
+			req.ToLicense = v.String()
+		}
+	}
+	if reason := check(req); reason != "" {
+		return reason
+	}
+	return nil
+}
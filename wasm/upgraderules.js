@@ -0,0 +1,25 @@
+// Thin wrapper around upgraderules.wasm.
+//
+// Requires wasm_exec.js (shipped with Go in $(go env GOROOT)/misc/wasm, or
+// lib/wasm for newer Go releases) to be loaded first, so the `Go` class is
+// available.
+//
+// Usage:
+//
+//   const rules = await loadUpgradeRules("upgraderules.wasm");
+//   const reason = rules.check("3.11.8", "3.12.1", { fromLicense: "enterprise", toLicense: "enterprise" });
+//   if (reason !== null) { /* upgrade not allowed, reason says why */ }
+async function loadUpgradeRules(url) {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  // go.run only resolves when the Go program exits, which it never does.
+  go.run(instance);
+  const impl = globalThis.goUpgradeRules;
+  return {
+    // check returns null when the upgrade from `from` to `to` is allowed,
+    // otherwise a string describing why it is not.
+    check(from, to, options) {
+      return impl.check(String(from), String(to), options || {});
+    },
+  };
+}